@@ -17,6 +17,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/koding/multiconfig"
 
@@ -24,10 +25,29 @@ import (
 )
 
 type Config struct {
-	Log     LogConfig
-	Mysql   MysqlConfig
-	Jenkins JenkinsConfig
-	Sonar   SonarConfig
+	Log          LogConfig
+	Mysql        MysqlConfig
+	Jenkins      JenkinsConfig
+	Sonar        SonarConfig
+	Webhook      WebhookConfig
+	Lint         LintConfig
+	Strength     StrengthConfig
+	Verify       VerifyConfig
+	Reachability ReachabilityConfig
+	UsageAlert   UsageAlertConfig
+	Graph        GraphConfig
+	ListCache    ListCacheConfig
+	Creator      CreatorConfig
+	BreakGlass   BreakGlassConfig
+	ConfigScan   ConfigScanConfig
+	Transparency TransparencyConfig
+	ContentRead  ContentReadConfig
+	Trash        TrashConfig
+	Encryption   EncryptionConfig
+	Description  DescriptionConfig
+	Expiry       ExpiryConfig
+	Roles        CredentialRolesConfig
+	Idempotency  IdempotencyConfig
 }
 
 type LogConfig struct {
@@ -40,6 +60,8 @@ type MysqlConfig struct {
 	User     string `default:"root"`
 	Password string `default:"password"`
 	Database string `default:"kubesphere"`
+	// SchemaDir holds the migration scripts applied at startup, nothing is migrated when it does not exist.
+	SchemaDir string `default:"/usr/local/share/ks-devops/schema"`
 }
 
 type JenkinsConfig struct {
@@ -47,6 +69,15 @@ type JenkinsConfig struct {
 	User     string `default:"magicsong"`
 	Password string `default:"devops"`
 	MaxConn  string `default:"20"`
+	// timeout of the Jenkins health check
+	HealthTimeout time.Duration `default:"3s"`
+	// attempts of the credential calls failing with a 5xx status or a connection error, and the
+	// delay before the first retry, doubled for every following one
+	RetryAttempts  int           `default:"3"`
+	RetryBaseDelay time.Duration `default:"500ms"`
+	// timeout of creating a credential in Jenkins and recording it in db, they go on when the client
+	// disconnects so both stay consistent, 0 disables the timeout
+	WriteTimeout time.Duration `default:"30s"`
 }
 
 type SonarConfig struct {
@@ -54,6 +85,179 @@ type SonarConfig struct {
 	Token   string `default:""`
 }
 
+type WebhookConfig struct {
+	PreCreateUrl              string        `default:""`
+	PreCreateFailOpen         bool          `default:"false"`
+	NotificationUrl           string        `default:""`
+	ContentValidationUrl      string        `default:""`
+	ContentValidationFailOpen bool          `default:"false"`
+	Secret                    string        `default:""`
+	Timeout                   time.Duration `default:"5s"`
+	ChangeUrls                []string      `default:""`
+	ChangeRetries             int           `default:"3"`
+	ChangeRetryInterval       time.Duration `default:"2s"`
+}
+
+type LintConfig struct {
+	DisabledChecks []string `default:""`
+	StaleDays      int      `default:"180"`
+	MinSecretScore int      `default:"2"`
+	// smallest rsa and dsa keys, and smallest elliptic curve keys, in bits
+	MinRsaKeyBits int `default:"2048"`
+	MinEcKeyBits  int `default:"256"`
+	// credential types that must be restricted to a domain instead of the global one
+	DomainScopedTypes []string `default:""`
+}
+
+// StrengthConfig holds the entropy bits a secret needs to reach each score from 1 to 4.
+type StrengthConfig struct {
+	Enabled        bool `default:"false"`
+	WeakBits       int  `default:"28"`
+	FairBits       int  `default:"36"`
+	StrongBits     int  `default:"60"`
+	VeryStrongBits int  `default:"80"`
+}
+
+// VerifyConfig sets how credentials are verified against their servers. Loopback, link-local and private
+// addresses are refused unless they are in AllowedNetworks, ssh servers must have their host key in
+// KnownHostsFile.
+type VerifyConfig struct {
+	Timeout         time.Duration `default:"10s"`
+	AllowedNetworks []string      `default:""`
+	KnownHostsFile  string        `default:""`
+}
+
+type ReachabilityConfig struct {
+	Enabled  bool          `default:"false"`
+	Timeout  time.Duration `default:"3s"`
+	CacheTtl time.Duration `default:"1m"`
+}
+
+// UsageAlertConfig sets how often credential usage thresholds are evaluated, 0 disables the
+// evaluation. Alerts are only sent when the notification webhook is configured.
+type UsageAlertConfig struct {
+	Interval time.Duration `default:"10m"`
+}
+
+type GraphConfig struct {
+	CacheTtl time.Duration `default:"30s"`
+}
+
+// ListCacheConfig sets how long the credentials listed in a project folder are cached, 0 disables the cache.
+type ListCacheConfig struct {
+	Ttl time.Duration `default:"10s"`
+}
+
+// CreatorConfig sets how long the display names of credential creators read from Jenkins are cached.
+type CreatorConfig struct {
+	CacheTtl time.Duration `default:"5m"`
+}
+
+// BreakGlassConfig lists the project roles allowed to create or reveal credentials bypassing the credential
+// policy in an emergency, no roles disables break-glass. AlertUrl receives the security alerts.
+type BreakGlassConfig struct {
+	Roles    []string `default:""`
+	AlertUrl string   `default:""`
+}
+
+// ConfigScanConfig enables reading the project folder configuration to find credentials
+// referenced by shared libraries.
+type ConfigScanConfig struct {
+	Enabled bool `default:"false"`
+}
+
+// TransparencyConfig holds the key signing the entries of the credential transparency log,
+// entries are only hash chained when it is empty.
+type TransparencyConfig struct {
+	SigningKey string `default:""`
+}
+
+// ContentReadConfig limits the credential content reads of a user in a project per minute,
+// 0 disables the limit.
+type ContentReadConfig struct {
+	RateLimit int `default:"30"`
+}
+
+// TrashConfig keeps deleted credentials restorable for Retention, their content is stored encrypted with
+// a key derived from Key. Credentials are deleted permanently when Key is empty.
+type TrashConfig struct {
+	Key           string        `default:""`
+	Retention     time.Duration `default:"168h"`
+	PurgeInterval time.Duration `default:"1h"`
+}
+
+// EncryptionConfig encrypts the sensitive credential metadata columns with data keys wrapped by the
+// Provider key provider holding Key, the local provider derives its key from Key. Columns are stored
+// in plaintext when Provider is empty.
+type EncryptionConfig struct {
+	Provider string `default:""`
+	Key      string `default:""`
+}
+
+// DescriptionConfig limits the length of credential descriptions in characters, 0 disables the limit.
+type DescriptionConfig struct {
+	MaxLength int `default:"1024"`
+}
+
+// redactedSecret replaces the secrets of the config when it is logged, unset secrets are kept empty.
+const redactedSecret = "******"
+
+func redactSecret(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return redactedSecret
+}
+
+// String formats the config like %+v with the password redacted, so the config can be logged.
+func (m MysqlConfig) String() string {
+	type mysqlConfig MysqlConfig
+	m.Password = redactSecret(m.Password)
+	return fmt.Sprintf("%+v", mysqlConfig(m))
+}
+
+// String formats the config like %+v with the password redacted, so the config can be logged.
+func (j JenkinsConfig) String() string {
+	type jenkinsConfig JenkinsConfig
+	j.Password = redactSecret(j.Password)
+	return fmt.Sprintf("%+v", jenkinsConfig(j))
+}
+
+// String formats the config like %+v with the token redacted, so the config can be logged.
+func (s SonarConfig) String() string {
+	type sonarConfig SonarConfig
+	s.Token = redactSecret(s.Token)
+	return fmt.Sprintf("%+v", sonarConfig(s))
+}
+
+// String formats the config like %+v with the signing secret redacted, so the config can be logged.
+func (w WebhookConfig) String() string {
+	type webhookConfig WebhookConfig
+	w.Secret = redactSecret(w.Secret)
+	return fmt.Sprintf("%+v", webhookConfig(w))
+}
+
+// String formats the config like %+v with the signing key redacted, so the config can be logged.
+func (t TransparencyConfig) String() string {
+	type transparencyConfig TransparencyConfig
+	t.SigningKey = redactSecret(t.SigningKey)
+	return fmt.Sprintf("%+v", transparencyConfig(t))
+}
+
+// String formats the config like %+v with the key redacted, so the config can be logged.
+func (t TrashConfig) String() string {
+	type trashConfig TrashConfig
+	t.Key = redactSecret(t.Key)
+	return fmt.Sprintf("%+v", trashConfig(t))
+}
+
+// String formats the config like %+v with the key redacted, so the config can be logged.
+func (e EncryptionConfig) String() string {
+	type encryptionConfig EncryptionConfig
+	e.Key = redactSecret(e.Key)
+	return fmt.Sprintf("%+v", encryptionConfig(e))
+}
+
 func (m *MysqlConfig) GetUrl() string {
 	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s", m.User, m.Password, m.Host, m.Port, m.Database)
 }
@@ -95,3 +299,32 @@ func LoadConf() *Config {
 
 	return config
 }
+
+// ExpiryConfig sets how many days ahead credentials are reported as expiring, and how often expiring
+// credentials are logged and sent to the notification webhook, 0 disables the check.
+type ExpiryConfig struct {
+	WarningDays   int           `default:"14"`
+	CheckInterval time.Duration `default:"24h"`
+}
+
+// CredentialRolesConfig lists the project roles allowed to perform each credential action,
+// View is reading the content of credentials and List is listing them and getting their metadata.
+// RenameDomain, DeleteAll and Sync change all the credentials of a domain or of the project at once.
+type CredentialRolesConfig struct {
+	Create       []string `default:"owner,maintainer"`
+	Update       []string `default:"owner,maintainer"`
+	Delete       []string `default:"owner,maintainer"`
+	Rotate       []string `default:"owner,maintainer"`
+	View         []string `default:"owner,maintainer"`
+	List         []string `default:"owner,maintainer,developer"`
+	RenameDomain []string `default:"owner"`
+	DeleteAll    []string `default:"owner"`
+	Sync         []string `default:"owner"`
+}
+
+// IdempotencyConfig sets how long the idempotency keys of credential creations are kept,
+// and how often expired keys are purged, 0 disables the purge.
+type IdempotencyConfig struct {
+	Window        time.Duration `default:"24h"`
+	PurgeInterval time.Duration `default:"1h"`
+}
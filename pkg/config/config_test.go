@@ -0,0 +1,46 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestConfigStringRedactsSecrets(t *testing.T) {
+	config := &Config{}
+	config.Mysql.Host = "kubesphere-db"
+	config.Mysql.Password = "mysql-password"
+	config.Jenkins.Password = "jenkins-password"
+	config.Sonar.Token = "sonar-token"
+	config.Webhook.Secret = "webhook-secret"
+	config.Transparency.SigningKey = "signing-key"
+	config.Trash.Key = "trash-key"
+	config.Encryption.Key = "encryption-key"
+
+	logged := fmt.Sprintf("%+v", config)
+	for _, secret := range []string{"mysql-password", "jenkins-password", "sonar-token", "webhook-secret",
+		"signing-key", "trash-key", "encryption-key"} {
+		if strings.Contains(logged, secret) {
+			t.Errorf("logged config should not hold [%s]: %s", secret, logged)
+		}
+	}
+	if !strings.Contains(logged, "Host:kubesphere-db") || !strings.Contains(logged, "Password:"+redactedSecret) {
+		t.Errorf("logged config should keep the other fields and mark the secrets redacted: %s", logged)
+	}
+	if config.Mysql.Password != "mysql-password" || config.Encryption.Key != "encryption-key" {
+		t.Errorf("formatting the config should not change it")
+	}
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package db
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// EncryptedPrefix marks the column values written encrypted, values without it are read as plaintext
+// so rows written before encryption was enabled stay readable.
+const EncryptedPrefix = "enc:v1:"
+
+type Encryptor interface {
+	Encrypt(plaintext []byte) (string, error)
+	Decrypt(ciphertext string) ([]byte, error)
+}
+
+var encryptor Encryptor
+
+// SetEncryptor enables the encryption of EncryptedString columns, nil stores them in plaintext.
+func SetEncryptor(e Encryptor) {
+	encryptor = e
+}
+
+func EncryptionEnabled() bool {
+	return encryptor != nil
+}
+
+// EncryptedString is a string column encrypted on insert and update and decrypted on select.
+type EncryptedString string
+
+func (s EncryptedString) Value() (driver.Value, error) {
+	if encryptor == nil || s == "" {
+		return string(s), nil
+	}
+	ciphertext, err := encryptor.Encrypt([]byte(s))
+	if err != nil {
+		return nil, err
+	}
+	return EncryptedPrefix + ciphertext, nil
+}
+
+func (s *EncryptedString) Scan(src interface{}) error {
+	var value string
+	switch v := src.(type) {
+	case nil:
+		value = ""
+	case string:
+		value = v
+	case []byte:
+		value = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into an encrypted string", src)
+	}
+	if !strings.HasPrefix(value, EncryptedPrefix) {
+		*s = EncryptedString(value)
+		return nil
+	}
+	if encryptor == nil {
+		return fmt.Errorf("column is encrypted but no encryptor is configured")
+	}
+	plaintext, err := encryptor.Decrypt(strings.TrimPrefix(value, EncryptedPrefix))
+	if err != nil {
+		return err
+	}
+	*s = EncryptedString(plaintext)
+	return nil
+}
+
+// IsEncrypted reports whether a raw column value was written encrypted.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, EncryptedPrefix)
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package db
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type reverseEncryptor struct{}
+
+func (reverseEncryptor) Encrypt(plaintext []byte) (string, error) {
+	runes := []rune(string(plaintext))
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes), nil
+}
+
+func (e reverseEncryptor) Decrypt(ciphertext string) ([]byte, error) {
+	plaintext, err := e.Encrypt([]byte(ciphertext))
+	return []byte(plaintext), err
+}
+
+func TestEncryptedString(t *testing.T) {
+	defer SetEncryptor(nil)
+
+	value, err := EncryptedString("admin").Value()
+	assert.NoError(t, err)
+	assert.Equal(t, "admin", value)
+
+	var s EncryptedString
+	assert.Error(t, s.Scan(EncryptedPrefix+"nimda"), "encrypted values need an encryptor")
+
+	SetEncryptor(reverseEncryptor{})
+	value, err = EncryptedString("admin").Value()
+	assert.NoError(t, err)
+	assert.Equal(t, EncryptedPrefix+"nimda", value)
+	assert.True(t, IsEncrypted(value.(string)))
+
+	assert.NoError(t, s.Scan([]byte(value.(string))))
+	assert.Equal(t, EncryptedString("admin"), s)
+	assert.NoError(t, s.Scan("plain"))
+	assert.Equal(t, EncryptedString("plain"), s)
+	assert.NoError(t, s.Scan(nil))
+	assert.Equal(t, EncryptedString(""), s)
+
+	value, err = EncryptedString("").Value()
+	assert.NoError(t, err)
+	assert.False(t, strings.HasPrefix(value.(string), EncryptedPrefix))
+}
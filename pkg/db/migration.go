@@ -0,0 +1,236 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package db
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SchemaHistoryTable is the table flyway records the applied migrations in. Migrations applied at startup
+// are recorded there too, so the flyway job and the startup migration skip the ones the other applied.
+const SchemaHistoryTable = "flyway_schema_history"
+
+const (
+	migrationLockName    = "ks_devops_schema_migration"
+	migrationLockTimeout = 60
+	migrationInstalledBy = "ks-devops"
+)
+
+const createSchemaHistoryTable = "CREATE TABLE IF NOT EXISTS `" + SchemaHistoryTable + "` (" +
+	"`installed_rank` INT NOT NULL, " +
+	"`version` VARCHAR(50), " +
+	"`description` VARCHAR(200) NOT NULL, " +
+	"`type` VARCHAR(20) NOT NULL, " +
+	"`script` VARCHAR(1000) NOT NULL, " +
+	"`checksum` INT, " +
+	"`installed_by` VARCHAR(100) NOT NULL, " +
+	"`installed_on` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, " +
+	"`execution_time` INT NOT NULL, " +
+	"`success` TINYINT(1) NOT NULL, " +
+	"PRIMARY KEY (`installed_rank`), " +
+	"INDEX `" + SchemaHistoryTable + "_s_idx` (`success`))"
+
+var migrationScriptPattern = regexp.MustCompile(`^V([0-9]+(?:[._][0-9]+)*)__(.+)\.sql$`)
+
+// Migration is a versioned sql script named like flyway ones, V<version>__<description>.sql.
+type Migration struct {
+	Version     string
+	Description string
+	Script      string
+	Sql         string
+	Checksum    int32
+}
+
+// LoadMigrations reads the migration scripts of dir sorted by version, other files are left out.
+func LoadMigrations(dir string) ([]*Migration, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	migrations := make([]*Migration, 0, len(files))
+	versions := make(map[string]string, len(files))
+	for _, file := range files {
+		match := migrationScriptPattern.FindStringSubmatch(file.Name())
+		if file.IsDir() || match == nil {
+			continue
+		}
+		content, err := ioutil.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		version := strings.Replace(match[1], "_", ".", -1)
+		if script, ok := versions[version]; ok {
+			return nil, fmt.Errorf("migrations [%s] and [%s] have the same version %s", script, file.Name(), version)
+		}
+		versions[version] = file.Name()
+		migrations = append(migrations, &Migration{
+			Version:     version,
+			Description: strings.Replace(match[2], "_", " ", -1),
+			Script:      file.Name(),
+			Sql:         string(content),
+			Checksum:    migrationChecksum(string(content)),
+		})
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return compareVersions(migrations[i].Version, migrations[j].Version) < 0
+	})
+	return migrations, nil
+}
+
+// compareVersions compares dotted versions part by part as numbers, so 0.10 comes after 0.9.
+func compareVersions(a, b string) int {
+	aParts := strings.Split(a, ".")
+	bParts := strings.Split(b, ".")
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		var aPart, bPart int
+		if i < len(aParts) {
+			aPart, _ = strconv.Atoi(aParts[i])
+		}
+		if i < len(bParts) {
+			bPart, _ = strconv.Atoi(bParts[i])
+		}
+		if aPart != bPart {
+			if aPart < bPart {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// migrationChecksum is the CRC32 flyway computes for a script, over its lines without line endings.
+func migrationChecksum(content string) int32 {
+	checksum := crc32.NewIEEE()
+	scanner := bufio.NewScanner(strings.NewReader(strings.TrimPrefix(content, "\uFEFF")))
+	scanner.Buffer(make([]byte, 64*1024), len(content)+1)
+	for scanner.Scan() {
+		checksum.Write([]byte(strings.TrimSuffix(scanner.Text(), "\r")))
+	}
+	return int32(checksum.Sum32())
+}
+
+// Migrate applies the migrations not recorded as applied in SchemaHistoryTable, in order, and records them.
+// It holds a database lock while migrating so that replicas starting together apply every migration once.
+// The number of migrations applied is returned, a failed migration stops the migration.
+func (db *Database) Migrate(migrations []*Migration) (int, error) {
+	ctx := context.Background()
+	conn, err := db.Session.Connection.DB.Conn(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	var locked sql.NullInt64
+	err = conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", migrationLockName, migrationLockTimeout).Scan(&locked)
+	if err != nil {
+		return 0, err
+	}
+	if !locked.Valid || locked.Int64 != 1 {
+		return 0, fmt.Errorf("failed to get lock [%s] to migrate the schema", migrationLockName)
+	}
+	defer conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", migrationLockName)
+
+	_, err = conn.ExecContext(ctx, createSchemaHistoryTable)
+	if err != nil {
+		return 0, err
+	}
+	applied, rank, err := loadAppliedMigrations(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, migration := range migrations {
+		if applied[migration.Version] {
+			continue
+		}
+		start := time.Now()
+		_, err = conn.ExecContext(ctx, migration.Sql)
+		if err != nil {
+			return count, fmt.Errorf("failed to apply migration [%s]: %v", migration.Script, err)
+		}
+		rank++
+		_, err = conn.ExecContext(ctx, "INSERT INTO `"+SchemaHistoryTable+"` (`installed_rank`, `version`, "+
+			"`description`, `type`, `script`, `checksum`, `installed_by`, `execution_time`, `success`) "+
+			"VALUES (?, ?, ?, 'SQL', ?, ?, ?, ?, 1)", rank, migration.Version, migration.Description,
+			migration.Script, migration.Checksum, migrationInstalledBy, int(time.Since(start)/time.Millisecond))
+		if err != nil {
+			return count, fmt.Errorf("failed to record migration [%s]: %v", migration.Script, err)
+		}
+		count++
+	}
+	return count, nil
+}
+
+// loadAppliedMigrations returns the versions of the successful migrations and the last installed rank.
+func loadAppliedMigrations(ctx context.Context, conn *sql.Conn) (map[string]bool, int, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT `installed_rank`, `version`, `success` FROM `"+
+		SchemaHistoryTable+"`")
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	applied := make(map[string]bool)
+	rank := 0
+	for rows.Next() {
+		var installedRank int
+		var version sql.NullString
+		var success bool
+		err = rows.Scan(&installedRank, &version, &success)
+		if err != nil {
+			return nil, 0, err
+		}
+		if installedRank > rank {
+			rank = installedRank
+		}
+		if version.Valid && success {
+			applied[version.String] = true
+		}
+	}
+	return applied, rank, rows.Err()
+}
+
+// MissingColumns returns the columns of the table which do not exist in the current database,
+// all of them when the table does not exist.
+func (db *Database) MissingColumns(table string, columns []string) ([]string, error) {
+	var existing []string
+	_, err := db.Select("column_name").From("information_schema.columns").
+		Where("table_schema = DATABASE() AND table_name = ?", table).Load(&existing)
+	if err != nil {
+		return nil, err
+	}
+	exists := make(map[string]bool, len(existing))
+	for _, column := range existing {
+		exists[strings.ToLower(column)] = true
+	}
+	missing := make([]string, 0)
+	for _, column := range columns {
+		if !exists[strings.ToLower(strings.Trim(column, "`"))] {
+			missing = append(missing, column)
+		}
+	}
+	return missing, nil
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package db_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"kubesphere.io/devops/pkg/config/test_config"
+	"kubesphere.io/devops/pkg/db"
+)
+
+func writeMigrations(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "migration")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		err = ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadMigrations(t *testing.T) {
+	dir := writeMigrations(t, map[string]string{
+		"V0_10__add_column.sql": "ALTER TABLE t ADD COLUMN c INT;\n",
+		"V0_9__add_index.sql":   "CREATE INDEX i ON t (c);\n",
+		"V0_1__init.sql":        "CREATE TABLE t (id INT);\nINSERT INTO t VALUES (1);\n",
+		"README.md":             "not a migration",
+	})
+	defer os.RemoveAll(dir)
+
+	migrations, err := db.LoadMigrations(dir)
+	assert.NoError(t, err)
+	if assert.Len(t, migrations, 3) {
+		assert.Equal(t, "0.1", migrations[0].Version)
+		assert.Equal(t, "init", migrations[0].Description)
+		assert.Equal(t, "V0_1__init.sql", migrations[0].Script)
+		assert.Equal(t, "0.9", migrations[1].Version)
+		assert.Equal(t, "add index", migrations[1].Description)
+		assert.Equal(t, "0.10", migrations[2].Version)
+	}
+}
+
+func TestLoadMigrationsChecksumIgnoresLineEndings(t *testing.T) {
+	dir := writeMigrations(t, map[string]string{
+		"V1__unix.sql":    "CREATE TABLE t (id INT);\nINSERT INTO t VALUES (1);\n",
+		"V2__windows.sql": "\uFEFFCREATE TABLE t (id INT);\r\nINSERT INTO t VALUES (1);\r\n",
+		"V3__changed.sql": "CREATE TABLE t (id INT);\nINSERT INTO t VALUES (2);\n",
+	})
+	defer os.RemoveAll(dir)
+
+	migrations, err := db.LoadMigrations(dir)
+	assert.NoError(t, err)
+	if assert.Len(t, migrations, 3) {
+		assert.Equal(t, migrations[0].Checksum, migrations[1].Checksum)
+		assert.NotEqual(t, migrations[0].Checksum, migrations[2].Checksum)
+	}
+}
+
+func TestLoadMigrationsDuplicateVersion(t *testing.T) {
+	dir := writeMigrations(t, map[string]string{
+		"V0_1__init.sql":  "SELECT 1;",
+		"V0.1__other.sql": "SELECT 2;",
+	})
+	defer os.RemoveAll(dir)
+
+	_, err := db.LoadMigrations(dir)
+	assert.Error(t, err)
+}
+
+func TestLoadSchemaMigrations(t *testing.T) {
+	migrations, err := db.LoadMigrations("schema/devops")
+	assert.NoError(t, err)
+	if assert.NotEmpty(t, migrations) {
+		assert.Equal(t, "0.1", migrations[0].Version)
+	}
+}
+
+func TestMissingColumnsWithDb(t *testing.T) {
+	tc := test_config.NewDbTestConfig()
+	tc.CheckDbUnitTest(t)
+	d := tc.GetDatabaseConn()
+
+	_, err := d.Exec("CREATE TABLE IF NOT EXISTS `migration_test` (`id` VARCHAR(50) NOT NULL, `rank` INT NOT NULL)")
+	assert.NoError(t, err)
+	defer d.Exec("DROP TABLE `migration_test`")
+
+	missing, err := d.MissingColumns("migration_test", []string{"id", "rank", "modified_by"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"modified_by"}, missing)
+
+	missing, err = d.MissingColumns("migration_test_not_exist", []string{"id"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"id"}, missing)
+}
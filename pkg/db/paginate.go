@@ -0,0 +1,42 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package db
+
+import (
+	"strings"
+)
+
+// Paginate orders the select by orderBy and selects the page of limit rows after offset. A column
+// prefixed with "-" is ordered descending. Pages are only stable when the last column is unique,
+// e.g. "-create_time", "uuid". A zero limit selects DefaultSelectLimit rows.
+func Paginate(b *SelectQuery, limit, offset uint64, orderBy ...string) *SelectQuery {
+	for _, column := range orderBy {
+		if strings.HasPrefix(column, "-") {
+			b.OrderDir(strings.TrimPrefix(column, "-"), false)
+		} else {
+			b.OrderDir(column, true)
+		}
+	}
+	if limit == 0 {
+		limit = DefaultSelectLimit
+	}
+	b.SelectBuilder.Limit(GetLimit(limit))
+	b.SelectBuilder.Offset(GetOffset(offset))
+	return b
+}
+
+// Count counts the rows the select matches regardless of its page.
+func Count(b *SelectQuery) (uint32, error) {
+	return b.Count()
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package db_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"kubesphere.io/devops/pkg/config/test_config"
+	"kubesphere.io/devops/pkg/db"
+)
+
+func TestPaginateWithDb(t *testing.T) {
+	tc := test_config.NewDbTestConfig()
+	tc.CheckDbUnitTest(t)
+	d := tc.GetDatabaseConn()
+
+	// a temporary table only exists on the connection of the pool which created it
+	_, err := d.Exec("CREATE TABLE IF NOT EXISTS `paginate_test` (`id` VARCHAR(50) NOT NULL, `rank` INT NOT NULL)")
+	assert.NoError(t, err)
+	defer d.Exec("DROP TABLE `paginate_test`")
+	for i := 0; i < 5; i++ {
+		_, err = d.InsertInto("paginate_test").Columns("id", "rank").
+			Values(fmt.Sprintf("row-%d", i), i%2).Exec()
+		assert.NoError(t, err)
+	}
+
+	var ids []string
+	_, err = db.Paginate(d.Select("id").From("paginate_test"), 2, 1, "-rank", "id").Load(&ids)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"row-3", "row-0"}, ids)
+
+	count, err := db.Count(db.Paginate(d.Select("id").From("paginate_test"), 2, 1, "id"))
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(5), count)
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package db
+
+import (
+	"testing"
+
+	"github.com/gocraft/dbr"
+	"github.com/gocraft/dbr/dialect"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaginate(t *testing.T) {
+	for _, test := range []struct {
+		limit   uint64
+		offset  uint64
+		orderBy []string
+		query   string
+	}{
+		{
+			limit:   10,
+			offset:  20,
+			orderBy: []string{"-create_time", "uuid"},
+			query:   "SELECT uuid FROM t ORDER BY create_time DESC, uuid ASC LIMIT 10 OFFSET 20",
+		},
+		{
+			query: "SELECT uuid FROM t LIMIT 200 OFFSET 0",
+		},
+		{
+			limit:  1000,
+			offset: 1000,
+			query:  "SELECT uuid FROM t LIMIT 200 OFFSET 1000",
+		},
+	} {
+		query := &SelectQuery{&dbr.SelectBuilder{SelectStmt: dbr.Select("uuid")}, 0}
+		b := Paginate(query.From("t"), test.limit, test.offset, test.orderBy...)
+		buf := dbr.NewBuffer()
+		err := b.Build(dialect.MySQL, buf)
+		assert.NoError(t, err)
+		assert.Equal(t, test.query, buf.String())
+	}
+}
@@ -0,0 +1,2 @@
+ALTER TABLE `project_credential_policy`
+  ADD COLUMN `content_validation_webhook` TINYINT(1) NOT NULL DEFAULT 0;
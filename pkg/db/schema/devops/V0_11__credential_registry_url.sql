@@ -0,0 +1,2 @@
+ALTER TABLE `project_credential`
+  ADD COLUMN `registry_url` VARCHAR(255) NOT NULL DEFAULT '';
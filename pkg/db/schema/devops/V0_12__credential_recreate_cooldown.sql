@@ -0,0 +1,11 @@
+ALTER TABLE `project_credential_policy`
+  ADD COLUMN `recreate_cooldown` INT NOT NULL DEFAULT 0;
+
+CREATE TABLE `project_credential_deletion` (
+  `project_id`    VARCHAR(50)  NOT NULL,
+  `credential_id` VARCHAR(255) NOT NULL,
+  `domain`        VARCHAR(255) NOT NULL,
+  `operator`      VARCHAR(50)  NOT NULL,
+  `delete_time`   TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`project_id`, `credential_id`, `domain`)
+);
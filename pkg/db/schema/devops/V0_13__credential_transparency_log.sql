@@ -0,0 +1,14 @@
+CREATE TABLE `project_credential_transparency_log` (
+  `project_id`    VARCHAR(50)  NOT NULL,
+  `seq`           BIGINT       NOT NULL,
+  `credential_id` VARCHAR(255) NOT NULL,
+  `domain`        VARCHAR(255) NOT NULL,
+  `operation`     VARCHAR(50)  NOT NULL,
+  `operator`      VARCHAR(50)  NOT NULL,
+  `content_hash`  VARCHAR(64)  NOT NULL,
+  `create_time`   TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `prev_hash`     VARCHAR(64)  NOT NULL,
+  `hash`          VARCHAR(64)  NOT NULL,
+  `signature`     VARCHAR(64)  NOT NULL DEFAULT '',
+  PRIMARY KEY (`project_id`, `seq`)
+);
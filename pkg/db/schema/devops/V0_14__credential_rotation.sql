@@ -0,0 +1,10 @@
+CREATE TABLE `project_credential_rotation` (
+  `rotation_id`   VARCHAR(50)  NOT NULL,
+  `project_id`    VARCHAR(50)  NOT NULL,
+  `credential_id` VARCHAR(255) NOT NULL,
+  `domain`        VARCHAR(255) NOT NULL,
+  `operator`      VARCHAR(50)  NOT NULL,
+  `rotate_time`   TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`rotation_id`),
+  INDEX `credential_rotation_index` (`project_id`, `credential_id`, `domain`)
+);
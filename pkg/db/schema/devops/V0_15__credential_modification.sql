@@ -0,0 +1,5 @@
+ALTER TABLE `project_credential`
+  ADD COLUMN `modified_by`   VARCHAR(50) NOT NULL DEFAULT '',
+  ADD COLUMN `modified_time` TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+UPDATE `project_credential` SET `modified_by` = `creator`, `modified_time` = `create_time`;
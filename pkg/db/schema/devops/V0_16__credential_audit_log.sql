@@ -0,0 +1,14 @@
+CREATE TABLE `project_credential_audit_log` (
+  `audit_id`      VARCHAR(50)   NOT NULL,
+  `project_id`    VARCHAR(50)   NOT NULL,
+  `credential_id` VARCHAR(255)  NOT NULL,
+  `domain`        VARCHAR(255)  NOT NULL,
+  `operator`      VARCHAR(50)   NOT NULL,
+  `action`        VARCHAR(20)   NOT NULL,
+  `status`        INT           NOT NULL,
+  `severity`      VARCHAR(20)   NOT NULL DEFAULT 'info',
+  `reason`        VARCHAR(1024) NOT NULL DEFAULT '',
+  `create_time`   TIMESTAMP     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`audit_id`),
+  INDEX `credential_audit_log_index` (`project_id`, `create_time`)
+);
@@ -0,0 +1,15 @@
+CREATE TABLE `project_credential_trash` (
+  `trash_id`      VARCHAR(50)  NOT NULL,
+  `project_id`    VARCHAR(50)  NOT NULL,
+  `credential_id` VARCHAR(255) NOT NULL,
+  `domain`        VARCHAR(255) NOT NULL,
+  `store`         VARCHAR(16)  NOT NULL DEFAULT 'folder',
+  `type`          VARCHAR(50)  NOT NULL,
+  `content`       MEDIUMTEXT   NOT NULL,
+  `operator`      VARCHAR(50)  NOT NULL,
+  `delete_time`   TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `expire_time`   TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`trash_id`),
+  INDEX `credential_trash_project_index` (`project_id`),
+  INDEX `credential_trash_expire_index` (`expire_time`)
+);
@@ -0,0 +1,5 @@
+ALTER TABLE `project_credential`
+  MODIFY COLUMN `creator`         VARCHAR(1024) NOT NULL,
+  MODIFY COLUMN `modified_by`     VARCHAR(1024) NOT NULL DEFAULT '',
+  MODIFY COLUMN `strength_reason` VARCHAR(1024) NOT NULL DEFAULT '',
+  MODIFY COLUMN `registry_url`    VARCHAR(1024) NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+ALTER TABLE `project_credential`
+  ADD COLUMN `store` VARCHAR(16) NOT NULL DEFAULT 'folder';
@@ -0,0 +1,11 @@
+CREATE TABLE `project_credential_idempotency_key` (
+  `project_id`      VARCHAR(50)  NOT NULL,
+  `idempotency_key` VARCHAR(255) NOT NULL,
+  `request_hash`    VARCHAR(64)  NOT NULL DEFAULT '',
+  `credential_id`   VARCHAR(255) NOT NULL,
+  `domain`          VARCHAR(255) NOT NULL,
+  `create_time`     TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `expire_time`     TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`project_id`, `idempotency_key`),
+  INDEX `credential_idempotency_key_expire_index` (`expire_time`)
+);
@@ -0,0 +1,2 @@
+ALTER TABLE `project_credential`
+  ADD COLUMN `intended_jobs` VARCHAR(4096) NOT NULL DEFAULT '';
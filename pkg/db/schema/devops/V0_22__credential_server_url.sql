@@ -0,0 +1,2 @@
+ALTER TABLE `project_credential`
+  ADD COLUMN `server_url` VARCHAR(1024) NOT NULL DEFAULT '';
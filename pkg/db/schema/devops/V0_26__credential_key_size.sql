@@ -0,0 +1,3 @@
+ALTER TABLE `project_credential`
+  ADD COLUMN `key_algorithm` VARCHAR(16) NOT NULL DEFAULT '',
+  ADD COLUMN `key_bits`      SMALLINT    NULL     DEFAULT NULL;
@@ -0,0 +1,5 @@
+CREATE TABLE `project_credential_policy` (
+  `project_id`         VARCHAR(50) NOT NULL,
+  `pre_create_webhook` TINYINT(1)  NOT NULL DEFAULT 0,
+  PRIMARY KEY (`project_id`)
+);
@@ -0,0 +1,2 @@
+ALTER TABLE `project_credential`
+  ADD COLUMN `expires_at` TIMESTAMP NULL DEFAULT NULL;
@@ -0,0 +1,9 @@
+ALTER TABLE `project_credential`
+  ADD COLUMN `uuid` VARCHAR(50) NOT NULL DEFAULT '';
+
+UPDATE `project_credential`
+SET `uuid` = CONCAT('credential-', REPLACE(UUID(), '-', ''))
+WHERE `uuid` = '';
+
+ALTER TABLE `project_credential`
+  ADD UNIQUE INDEX `uuid_unique` (`uuid`);
@@ -0,0 +1,3 @@
+ALTER TABLE `project_credential`
+  ADD COLUMN `strength_score`  TINYINT      NULL     DEFAULT NULL,
+  ADD COLUMN `strength_reason` VARCHAR(255) NOT NULL DEFAULT '';
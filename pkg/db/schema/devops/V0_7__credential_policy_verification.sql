@@ -0,0 +1,2 @@
+ALTER TABLE `project_credential_policy`
+  ADD COLUMN `require_verification` TINYINT(1) NOT NULL DEFAULT 0;
@@ -0,0 +1,11 @@
+CREATE TABLE `project_credential_usage_threshold` (
+  `project_id`          VARCHAR(50)  NOT NULL,
+  `credential_id`       VARCHAR(255) NOT NULL,
+  `domain`              VARCHAR(255) NOT NULL,
+  `spike_threshold`     INT          NOT NULL DEFAULT 0,
+  `zero_alert`          TINYINT(1)   NOT NULL DEFAULT 0,
+  `last_usage`          INT          NULL,
+  `last_delta`          INT          NULL,
+  `last_evaluated_time` TIMESTAMP    NULL,
+  PRIMARY KEY (`project_id`, `credential_id`, `domain`)
+);
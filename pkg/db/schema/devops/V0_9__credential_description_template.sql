@@ -0,0 +1,6 @@
+CREATE TABLE `project_credential_description_template` (
+  `project_id`      VARCHAR(50)   NOT NULL,
+  `credential_type` VARCHAR(50)   NOT NULL,
+  `template`        VARCHAR(1024) NOT NULL,
+  PRIMARY KEY (`project_id`, `credential_type`)
+);
@@ -0,0 +1,33 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package db
+
+import (
+	"github.com/gocraft/dbr"
+)
+
+// WithTransaction runs fn in a transaction committed when fn returns nil. The transaction is rolled
+// back when fn returns an error or panics, the panic goes on once the transaction is rolled back.
+func (db *Database) WithTransaction(fn func(tx *dbr.Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.RollbackUnlessCommitted()
+	err = fn(tx)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
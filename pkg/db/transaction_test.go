@@ -0,0 +1,63 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/gocraft/dbr"
+	"github.com/gocraft/dbr/dialect"
+	"github.com/stretchr/testify/assert"
+)
+
+// txConnector opens connections only able to begin transactions, it counts their outcomes.
+type txConnector struct {
+	commits   int
+	rollbacks int
+}
+
+func (c *txConnector) Connect(context.Context) (driver.Conn, error) { return &txConn{c}, nil }
+func (c *txConnector) Driver() driver.Driver                        { return nil }
+
+type txConn struct{ connector *txConnector }
+
+func (c *txConn) Prepare(query string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *txConn) Close() error                              { return nil }
+func (c *txConn) Begin() (driver.Tx, error)                 { return c, nil }
+func (c *txConn) Commit() error                             { c.connector.commits++; return nil }
+func (c *txConn) Rollback() error                           { c.connector.rollbacks++; return nil }
+
+func TestWithTransaction(t *testing.T) {
+	connector := &txConnector{}
+	conn := &dbr.Connection{DB: sql.OpenDB(connector), Dialect: dialect.MySQL, EventReceiver: &dbr.NullEventReceiver{}}
+	d := &Database{Session: conn.NewSession(nil)}
+
+	assert.NoError(t, d.WithTransaction(func(tx *dbr.Tx) error { return nil }))
+	assert.Equal(t, 1, connector.commits)
+
+	failure := errors.New("failure")
+	assert.Equal(t, failure, d.WithTransaction(func(tx *dbr.Tx) error { return failure }))
+	assert.Equal(t, 1, connector.commits)
+	assert.Equal(t, 1, connector.rollbacks)
+
+	assert.Panics(t, func() {
+		d.WithTransaction(func(tx *dbr.Tx) error { panic("failure") })
+	})
+	assert.Equal(t, 1, connector.commits)
+	assert.Equal(t, 2, connector.rollbacks)
+}
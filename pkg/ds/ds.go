@@ -14,6 +14,8 @@ limitations under the License.
 package ds
 
 import (
+	"fmt"
+	"os"
 	"strconv"
 	"strings"
 
@@ -24,6 +26,8 @@ import (
 	"kubesphere.io/devops/pkg/db"
 	"kubesphere.io/devops/pkg/gojenkins"
 	"kubesphere.io/devops/pkg/logger"
+	"kubesphere.io/devops/pkg/models"
+	"kubesphere.io/devops/pkg/utils/cryptoutils"
 )
 
 type Ds struct {
@@ -48,15 +52,65 @@ func (p *Ds) openDatabase() *Ds {
 		panic(err)
 	}
 	p.Db = db
+	p.migrateDatabase()
+	p.setupEncryption()
 	return p
 }
 
+func (p *Ds) migrateDatabase() {
+	schemaDir := p.cfg.Mysql.SchemaDir
+	if _, err := os.Stat(schemaDir); err == nil {
+		migrations, err := db.LoadMigrations(schemaDir)
+		if err != nil {
+			logger.Critical("failed to load migrations from [%s]", schemaDir)
+			panic(err)
+		}
+		count, err := p.Db.Migrate(migrations)
+		if err != nil {
+			logger.Critical("failed to migrate database")
+			panic(err)
+		}
+		logger.Info("applied %d of %d migrations from [%s]", count, len(migrations), schemaDir)
+	} else {
+		logger.Warn("skip database migration, failed to read schema dir [%s]: %+v", schemaDir, err)
+	}
+
+	missing, err := p.Db.MissingColumns(models.ProjectCredentialTableName, models.ProjectCredentialColumns)
+	if err != nil {
+		logger.Critical("failed to check table [%s]", models.ProjectCredentialTableName)
+		panic(err)
+	}
+	if len(missing) > 0 {
+		logger.Critical("table [%s] is missing columns %v", models.ProjectCredentialTableName, missing)
+		panic(fmt.Errorf("database schema is not up to date, table [%s] is missing columns %v",
+			models.ProjectCredentialTableName, missing))
+	}
+}
+
+func (p *Ds) setupEncryption() {
+	if p.cfg.Encryption.Provider == "" {
+		return
+	}
+	provider, err := cryptoutils.NewKeyProvider(p.cfg.Encryption.Provider, p.cfg.Encryption.Key)
+	if err != nil {
+		logger.Critical("failed to create key provider")
+		panic(err)
+	}
+	envelope, err := cryptoutils.NewEnvelope(provider)
+	if err != nil {
+		logger.Critical("failed to create data key")
+		panic(err)
+	}
+	db.SetEncryptor(envelope)
+}
+
 func (p *Ds) connectJenkins() {
 	maxConnection, err := strconv.Atoi(p.cfg.Jenkins.MaxConn)
 	if err != nil {
 		panic(err)
 	}
 	jenkins := gojenkins.CreateJenkins(nil, p.cfg.Jenkins.Address, maxConnection, p.cfg.Jenkins.User, p.cfg.Jenkins.Password)
+	jenkins.SetRetryPolicy(p.cfg.Jenkins.RetryAttempts, p.cfg.Jenkins.RetryBaseDelay)
 	jenkins, err = jenkins.Init()
 	if err != nil {
 		logger.Critical("failed to connect jenkins")
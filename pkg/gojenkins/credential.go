@@ -13,13 +13,31 @@ limitations under the License.
 
 package gojenkins
 
+import (
+	"encoding/xml"
+	"regexp"
+	"strings"
+)
+
 const SSHCrenditalStaplerClass = "com.cloudbees.jenkins.plugins.sshcredentials.impl.BasicSSHUserPrivateKey"
 const DirectSSHCrenditalStaplerClass = "com.cloudbees.jenkins.plugins.sshcredentials.impl.BasicSSHUserPrivateKey$DirectEntryPrivateKeySource"
 const UsernamePassswordCredentialStaplerClass = "com.cloudbees.plugins.credentials.impl.UsernamePasswordCredentialsImpl"
 const SecretTextCredentialStaplerClass = "org.jenkinsci.plugins.plaincredentials.impl.StringCredentialsImpl"
 const KubeconfigCredentialStaplerClass = "com.microsoft.jenkins.kubernetes.credentials.KubeconfigCredentials"
 const DirectKubeconfigCredentialStaperClass = "com.microsoft.jenkins.kubernetes.credentials.KubeconfigCredentials$DirectEntryKubeconfigSource"
+const CertificateCredentialStaplerClass = "com.cloudbees.plugins.credentials.impl.CertificateCredentialsImpl"
+const UploadedKeyStoreSourceStaplerClass = "com.cloudbees.plugins.credentials.impl.CertificateCredentialsImpl$UploadedKeyStoreSource"
+const AWSCredentialStaplerClass = "com.cloudbees.jenkins.plugins.awscredentials.AWSCredentialsImpl"
 const GLOBALScope = "GLOBAL"
+const SYSTEMScope = "SYSTEM"
+
+// credentialScope defaults an empty scope to GLOBAL
+func credentialScope(scope string) string {
+	if scope == "" {
+		return GLOBALScope
+	}
+	return scope
+}
 
 type CreateSshCredentialRequest struct {
 	Credentials SshCredential `json:"credentials"`
@@ -37,6 +55,14 @@ type CreateKubeconfigCredentialRequest struct {
 	Credentials KubeconfigCredential `json:"credentials"`
 }
 
+type CreateCertificateCredentialRequest struct {
+	Credentials CertificateCredential `json:"credentials"`
+}
+
+type CreateAWSCredentialRequest struct {
+	Credentials AWSCredential `json:"credentials"`
+}
+
 type UsernamePasswordCredential struct {
 	Scope        string `json:"scope"`
 	Id           string `json:"id"`
@@ -72,16 +98,95 @@ type KubeconfigCredential struct {
 	StaplerClass     string           `json:"stapler-class"`
 }
 
+type CertificateCredential struct {
+	Scope          string         `json:"scope"`
+	Id             string         `json:"id"`
+	Password       string         `json:"password"`
+	KeyStoreSource KeyStoreSource `json:"keyStoreSource"`
+	Description    string         `json:"description"`
+	StaplerClass   string         `json:"stapler-class"`
+}
+
+type AWSCredential struct {
+	Scope        string `json:"scope"`
+	Id           string `json:"id"`
+	AccessKey    string `json:"accessKey"`
+	SecretKey    string `json:"secretKey"`
+	IamRoleArn   string `json:"iamRoleArn"`
+	Description  string `json:"description"`
+	StaplerClass string `json:"stapler-class"`
+}
+
 type PrivateKeySource struct {
 	StaplerClass string `json:"stapler-class"`
 	PrivateKey   string `json:"privateKey"`
 }
 
+// NewDirectEntryPrivateKeySource enters privateKeys directly. The source has a single privateKey, several keys
+// are concatenated into it with JoinPrivateKeys. The ssh credentials plugin stores that text as it is, which
+// of the keys gets offered is up to the plugin reading the credential, many only use the first one.
+func NewDirectEntryPrivateKeySource(privateKeys ...string) PrivateKeySource {
+	return PrivateKeySource{
+		StaplerClass: DirectSSHCrenditalStaplerClass,
+		PrivateKey:   JoinPrivateKeys(privateKeys),
+	}
+}
+
+// JoinPrivateKeys concatenates the PEM blocks of privateKeys, separated by an empty line, so SplitPrivateKeys
+// splits them back. This is a convention of devops, not a format of the ssh credentials plugin. A single key is
+// returned as it is.
+func JoinPrivateKeys(privateKeys []string) string {
+	if len(privateKeys) == 1 {
+		return privateKeys[0]
+	}
+	keys := make([]string, 0, len(privateKeys))
+	for _, privateKey := range privateKeys {
+		privateKey = strings.TrimSpace(privateKey)
+		if privateKey != "" {
+			keys = append(keys, privateKey+"\n")
+		}
+	}
+	return strings.Join(keys, "\n")
+}
+
+var pemBlockPattern = regexp.MustCompile(`(?s)-----BEGIN [A-Z0-9 ]+-----.*?-----END [A-Z0-9 ]+-----`)
+
+// SplitPrivateKeys splits the privateKey of a direct entry source into its PEM encoded keys,
+// a privateKey without PEM block is returned as its only key.
+func SplitPrivateKeys(privateKey string) []string {
+	blocks := pemBlockPattern.FindAllString(privateKey, -1)
+	if len(blocks) == 0 {
+		if strings.TrimSpace(privateKey) == "" {
+			return []string{}
+		}
+		return []string{privateKey}
+	}
+	keys := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		keys = append(keys, block+"\n")
+	}
+	return keys
+}
+
 type KubeconfigSource struct {
 	StaplerClass string `json:"stapler-class"`
 	Content      string `json:"content"`
 }
 
+// KeyStoreSource holds the base64 encoded PKCS#12 keystore uploaded to Jenkins
+type KeyStoreSource struct {
+	StaplerClass     string `json:"stapler-class"`
+	UploadedKeystore string `json:"uploadedKeystore"`
+}
+
+// CredentialDomain is the xml accepted by the createDomain endpoint of a credential store
+type CredentialDomain struct {
+	XMLName        xml.Name `xml:"com.cloudbees.plugins.credentials.domains.Domain"`
+	Name           string   `xml:"name"`
+	Description    string   `xml:"description"`
+	Specifications struct{} `xml:"specifications"`
+}
+
 type CredentialResponse struct {
 	Id          string `json:"id"`
 	TypeName    string `json:"typeName"`
@@ -89,7 +194,9 @@ type CredentialResponse struct {
 	Fingerprint *struct {
 		FileName string `json:"fileName,omitempty"`
 		Hash     string `json:"hash,omitempty"`
-		Usage    []*struct {
+		// milliseconds since the epoch when Jenkins first fingerprinted the credential, on its first use
+		Timestamp int64 `json:"timestamp,omitempty"`
+		Usage     []*struct {
 			Name   string `json:"name,omitempty"`
 			Ranges struct {
 				Ranges []*struct {
@@ -103,15 +210,12 @@ type CredentialResponse struct {
 	Domain      string `json:"domain"`
 }
 
-func NewCreateSshCredentialRequest(id, username, passphrase, privateKey, description string) *CreateSshCredentialRequest {
+func NewCreateSshCredentialRequest(id, username, passphrase, privateKey, description, scope string) *CreateSshCredentialRequest {
 
-	keySource := PrivateKeySource{
-		StaplerClass: DirectSSHCrenditalStaplerClass,
-		PrivateKey:   privateKey,
-	}
+	keySource := NewDirectEntryPrivateKeySource(privateKey)
 
 	sshCredential := SshCredential{
-		Scope:        GLOBALScope,
+		Scope:        credentialScope(scope),
 		Id:           id,
 		Username:     username,
 		Passphrase:   passphrase,
@@ -125,9 +229,9 @@ func NewCreateSshCredentialRequest(id, username, passphrase, privateKey, descrip
 
 }
 
-func NewCreateUsernamePasswordRequest(id, username, password, description string) *CreateUsernamePasswordCredentialRequest {
+func NewCreateUsernamePasswordRequest(id, username, password, description, scope string) *CreateUsernamePasswordCredentialRequest {
 	credential := UsernamePasswordCredential{
-		Scope:        GLOBALScope,
+		Scope:        credentialScope(scope),
 		Id:           id,
 		Username:     username,
 		Password:     password,
@@ -139,9 +243,9 @@ func NewCreateUsernamePasswordRequest(id, username, password, description string
 	}
 }
 
-func NewCreateSecretTextCredentialRequest(id, secret, description string) *CreateSecretTextCredentialRequest {
+func NewCreateSecretTextCredentialRequest(id, secret, description, scope string) *CreateSecretTextCredentialRequest {
 	credential := SecretTextCredential{
-		Scope:        GLOBALScope,
+		Scope:        credentialScope(scope),
 		Id:           id,
 		Secret:       secret,
 		Description:  description,
@@ -152,7 +256,7 @@ func NewCreateSecretTextCredentialRequest(id, secret, description string) *Creat
 	}
 }
 
-func NewCreateKubeconfigCredentialRequest(id, content, description string) *CreateKubeconfigCredentialRequest {
+func NewCreateKubeconfigCredentialRequest(id, content, description, scope string) *CreateKubeconfigCredentialRequest {
 
 	credentialSource := KubeconfigSource{
 		StaplerClass: DirectKubeconfigCredentialStaperClass,
@@ -160,7 +264,7 @@ func NewCreateKubeconfigCredentialRequest(id, content, description string) *Crea
 	}
 
 	credential := KubeconfigCredential{
-		Scope:            GLOBALScope,
+		Scope:            credentialScope(scope),
 		Id:               id,
 		Description:      description,
 		KubeconfigSource: credentialSource,
@@ -171,14 +275,11 @@ func NewCreateKubeconfigCredentialRequest(id, content, description string) *Crea
 	}
 }
 
-func NewSshCredential(id, username, passphrase, privateKey, description string) *SshCredential {
-	keySource := PrivateKeySource{
-		StaplerClass: DirectSSHCrenditalStaplerClass,
-		PrivateKey:   privateKey,
-	}
+func NewSshCredential(id, username, passphrase, privateKey, description, scope string) *SshCredential {
+	keySource := NewDirectEntryPrivateKeySource(privateKey)
 
 	return &SshCredential{
-		Scope:        GLOBALScope,
+		Scope:        credentialScope(scope),
 		Id:           id,
 		Username:     username,
 		Passphrase:   passphrase,
@@ -188,9 +289,9 @@ func NewSshCredential(id, username, passphrase, privateKey, description string)
 	}
 }
 
-func NewUsernamePasswordCredential(id, username, password, description string) *UsernamePasswordCredential {
+func NewUsernamePasswordCredential(id, username, password, description, scope string) *UsernamePasswordCredential {
 	return &UsernamePasswordCredential{
-		Scope:        GLOBALScope,
+		Scope:        credentialScope(scope),
 		Id:           id,
 		Username:     username,
 		Password:     password,
@@ -199,9 +300,9 @@ func NewUsernamePasswordCredential(id, username, password, description string) *
 	}
 }
 
-func NewSecretTextCredential(id, secret, description string) *SecretTextCredential {
+func NewSecretTextCredential(id, secret, description, scope string) *SecretTextCredential {
 	return &SecretTextCredential{
-		Scope:        GLOBALScope,
+		Scope:        credentialScope(scope),
 		Id:           id,
 		Secret:       secret,
 		Description:  description,
@@ -209,17 +310,57 @@ func NewSecretTextCredential(id, secret, description string) *SecretTextCredenti
 	}
 }
 
-func NewKubeconfigCredential(id, content, description string) *KubeconfigCredential {
+func NewKubeconfigCredential(id, content, description, scope string) *KubeconfigCredential {
 	credentialSource := KubeconfigSource{
 		StaplerClass: DirectKubeconfigCredentialStaperClass,
 		Content:      content,
 	}
 
 	return &KubeconfigCredential{
-		Scope:            GLOBALScope,
+		Scope:            credentialScope(scope),
 		Id:               id,
 		Description:      description,
 		KubeconfigSource: credentialSource,
 		StaplerClass:     KubeconfigCredentialStaplerClass,
 	}
 }
+
+func NewCreateCertificateCredentialRequest(id, keystore, password, description, scope string) *CreateCertificateCredentialRequest {
+	return &CreateCertificateCredentialRequest{
+		Credentials: *NewCertificateCredential(id, keystore, password, description, scope),
+	}
+}
+
+func NewCertificateCredential(id, keystore, password, description, scope string) *CertificateCredential {
+	keyStoreSource := KeyStoreSource{
+		StaplerClass:     UploadedKeyStoreSourceStaplerClass,
+		UploadedKeystore: keystore,
+	}
+
+	return &CertificateCredential{
+		Scope:          credentialScope(scope),
+		Id:             id,
+		Password:       password,
+		KeyStoreSource: keyStoreSource,
+		Description:    description,
+		StaplerClass:   CertificateCredentialStaplerClass,
+	}
+}
+
+func NewCreateAWSCredentialRequest(id, accessKey, secretKey, iamRoleArn, description, scope string) *CreateAWSCredentialRequest {
+	return &CreateAWSCredentialRequest{
+		Credentials: *NewAWSCredential(id, accessKey, secretKey, iamRoleArn, description, scope),
+	}
+}
+
+func NewAWSCredential(id, accessKey, secretKey, iamRoleArn, description, scope string) *AWSCredential {
+	return &AWSCredential{
+		Scope:        credentialScope(scope),
+		Id:           id,
+		AccessKey:    accessKey,
+		SecretKey:    secretKey,
+		IamRoleArn:   iamRoleArn,
+		Description:  description,
+		StaplerClass: AWSCredentialStaplerClass,
+	}
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gojenkins
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+var credentialIdElement = regexp.MustCompile(`<id>[^<]*</id>`)
+
+// CopySshCredentialInFolder creates the ssh credential newId in the domain with the config.xml of the ssh
+// credential sourceId, its private keys and passphrase are copied encrypted by Jenkins as they are.
+func (j *Jenkins) CopySshCredentialInFolder(domain, sourceId, newId string, folders ...string) (*string, error) {
+	if newId == "" || newId == sourceId {
+		return nil, fmt.Errorf("credential [%s] should be copied to another id", sourceId)
+	}
+	config, err := j.GetCredentialConfigInFolder(domain, sourceId, folders...)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.Contains(config, "<"+SSHCrenditalStaplerClass) {
+		return nil, fmt.Errorf("credential [%s] is not an ssh credential", sourceId)
+	}
+	if !credentialIdElement.MatchString(config) {
+		return nil, fmt.Errorf("id of credential [%s] not found in its config", sourceId)
+	}
+	replaced := false
+	config = credentialIdElement.ReplaceAllStringFunc(config, func(id string) string {
+		if replaced {
+			return id
+		}
+		replaced = true
+		return "<id>" + html.EscapeString(newId) + "</id>"
+	})
+	err = j.CreateCredentialFromConfigInFolder(domain, config, folders...)
+	if err != nil {
+		return nil, err
+	}
+	return &newId, nil
+}
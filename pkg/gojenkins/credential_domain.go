@@ -0,0 +1,160 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gojenkins
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"regexp"
+	"strconv"
+)
+
+var domainNameElement = regexp.MustCompile(`<name>[^<]*</name>`)
+
+// CreateCredentialFromConfigInFolder creates a credential in a domain from its config.xml, the secrets
+// encrypted by Jenkins in the config are accepted as they are.
+func (j *Jenkins) CreateCredentialFromConfigInFolder(domain, config string, folders ...string) error {
+	prePath := ""
+	if domain == "" {
+		domain = "_"
+	}
+	if len(folders) == 0 {
+		return fmt.Errorf("folder name shoud not be nil")
+	}
+	for _, folder := range folders {
+		prePath = prePath + fmt.Sprintf("/job/%s", folder)
+	}
+	responseString := ""
+	response, err := j.Requester.PostXML(prePath+
+		fmt.Sprintf("/credentials/store/folder/domain/%s/createCredentials", domain),
+		config, &responseString, nil)
+	if err != nil {
+		return createCredentialError(err)
+	}
+	if response.StatusCode != http.StatusOK {
+		return createCredentialError(errors.New(strconv.Itoa(response.StatusCode)))
+	}
+	return nil
+}
+
+// createRenamedCredentialDomainInFolder creates newDomain with the description and specifications of domain.
+func (j *Jenkins) createRenamedCredentialDomainInFolder(domain, newDomain string, folders ...string) error {
+	if domain == "_" {
+		_, err := j.CreateCredentialDomainInFolder(newDomain, "", "", folders...)
+		return err
+	}
+	config, err := j.GetDomainConfigInFolder(domain, folders...)
+	if err != nil {
+		return err
+	}
+	if !domainNameElement.MatchString(config) {
+		return fmt.Errorf("name of credential domain [%s] not found in its config", domain)
+	}
+	replaced := false
+	config = domainNameElement.ReplaceAllStringFunc(config, func(name string) string {
+		if replaced {
+			return name
+		}
+		replaced = true
+		return "<name>" + html.EscapeString(newDomain) + "</name>"
+	})
+	prePath := ""
+	for _, folder := range folders {
+		prePath = prePath + fmt.Sprintf("/job/%s", folder)
+	}
+	responseString := ""
+	response, err := j.Requester.PostXML(prePath+"/credentials/store/folder/createDomain",
+		config, &responseString, nil)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode != http.StatusOK {
+		return errors.New(strconv.Itoa(response.StatusCode))
+	}
+	return nil
+}
+
+// RenameCredentialDomainInFolder moves the credentials of domain to newDomain and deletes domain, the default
+// domain "_" is kept. newDomain is created like domain when it does not exist, otherwise the credentials are
+// merged into it, nothing is moved when one of their ids is used in newDomain. Credentials are copied with
+// their config.xml, which holds the secrets encrypted by Jenkins. The ids moved are returned, also when the
+// rename fails part way.
+func (j *Jenkins) RenameCredentialDomainInFolder(domain, newDomain string, folders ...string) ([]string, error) {
+	if domain == "" {
+		domain = "_"
+	}
+	if newDomain == "" || newDomain == domain {
+		return nil, fmt.Errorf("credential domain [%s] should be renamed to another domain", domain)
+	}
+	credentials, err := j.GetCredentialsInFolder(domain, folders...)
+	if err != nil {
+		return nil, err
+	}
+	domains, err := j.GetCredentialDomainsInFolder(folders...)
+	if err != nil {
+		return nil, err
+	}
+	targetExists := false
+	for _, existing := range domains {
+		targetExists = targetExists || existing == newDomain
+	}
+	if targetExists {
+		targetCredentials, err := j.GetCredentialsInFolder(newDomain, folders...)
+		if err != nil {
+			return nil, err
+		}
+		targetIds := make(map[string]bool, len(targetCredentials))
+		for _, credential := range targetCredentials {
+			targetIds[credential.Id] = true
+		}
+		for _, credential := range credentials {
+			if targetIds[credential.Id] {
+				return nil, fmt.Errorf("credential id [%s] has been used in domain [%s]: %w", credential.Id,
+					newDomain, ErrCredentialExists)
+			}
+		}
+	} else {
+		err = j.createRenamedCredentialDomainInFolder(domain, newDomain, folders...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	moved := make([]string, 0, len(credentials))
+	for _, credential := range credentials {
+		config, err := j.GetCredentialConfigInFolder(domain, credential.Id, folders...)
+		if err != nil {
+			return moved, err
+		}
+		err = j.CreateCredentialFromConfigInFolder(newDomain, config, folders...)
+		if err != nil {
+			return moved, err
+		}
+		_, err = j.DeleteCredentialInFolder(domain, credential.Id, folders...)
+		if err != nil {
+			return moved, fmt.Errorf("credential [%s] is copied to domain [%s] but not deleted from [%s]: %v",
+				credential.Id, newDomain, domain, err)
+		}
+		moved = append(moved, credential.Id)
+	}
+	if domain != "_" {
+		_, err = j.DeleteCredentialDomainInFolder(domain, folders...)
+		if err != nil {
+			return moved, err
+		}
+	}
+	return moved, nil
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gojenkins
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+var (
+	ErrCredentialNotFound = errors.New("credential not found")
+	ErrCredentialExists   = errors.New("credential already exists")
+)
+
+func errorStatusCode(err error) int {
+	if code, convErr := strconv.Atoi(err.Error()); convErr == nil {
+		return code
+	}
+	if jenkinsError, ok := err.(*ErrorResponse); ok {
+		return jenkinsError.Response.StatusCode
+	}
+	return 0
+}
+
+// credentialError returns ErrCredentialNotFound for a credential call answered 404 and ErrCredentialExists
+// for one answered 409, other errors are returned as they are.
+func credentialError(err error) error {
+	switch errorStatusCode(err) {
+	case http.StatusNotFound:
+		return ErrCredentialNotFound
+	case http.StatusConflict:
+		return ErrCredentialExists
+	}
+	return err
+}
+
+// createCredentialError returns ErrCredentialExists for a create answered 409, a create answered 404
+// targets a missing folder or domain so that error is returned as it is.
+func createCredentialError(err error) error {
+	if errorStatusCode(err) == http.StatusConflict {
+		return ErrCredentialExists
+	}
+	return err
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gojenkins
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// OpenShiftTokenCredentialStaplerClass is the token credential of the OpenShift client plugin.
+const OpenShiftTokenCredentialStaplerClass = "com.openshift.jenkins.plugins.OpenShiftTokenCredentials"
+
+type CreateOpenShiftTokenCredentialRequest struct {
+	Credentials OpenShiftTokenCredential `json:"credentials"`
+}
+
+// OpenShiftTokenCredential holds a bearer token of an OpenShift or Kubernetes cluster, the cluster itself
+// is configured in the jobs using it.
+type OpenShiftTokenCredential struct {
+	Scope        string `json:"scope"`
+	Id           string `json:"id"`
+	Secret       string `json:"secret"`
+	Description  string `json:"description"`
+	StaplerClass string `json:"stapler-class"`
+}
+
+func NewCreateOpenShiftTokenCredentialRequest(id, token, description,
+	scope string) *CreateOpenShiftTokenCredentialRequest {
+	return &CreateOpenShiftTokenCredentialRequest{
+		Credentials: *NewOpenShiftTokenCredential(id, token, description, scope),
+	}
+}
+
+func NewOpenShiftTokenCredential(id, token, description, scope string) *OpenShiftTokenCredential {
+	return &OpenShiftTokenCredential{
+		Scope:        credentialScope(scope),
+		Id:           id,
+		Secret:       token,
+		Description:  description,
+		StaplerClass: OpenShiftTokenCredentialStaplerClass,
+	}
+}
+
+func (j *Jenkins) CreateOpenShiftTokenCredentialInFolder(domain, id, token, description, scope string,
+	folders ...string) (*string, error) {
+	return j.CreateOpenShiftTokenCredentialInFolderContext(context.Background(), domain, id, token, description,
+		scope, folders...)
+}
+
+func (j *Jenkins) CreateOpenShiftTokenCredentialInFolderContext(ctx context.Context, domain, id, token, description,
+	scope string, folders ...string) (*string, error) {
+	requestStruct := NewCreateOpenShiftTokenCredentialRequest(id, token, description, scope)
+	param := map[string]string{"json": makeJson(requestStruct)}
+	responseString := ""
+	prePath := ""
+	if domain == "" {
+		domain = "_"
+	}
+	if len(folders) == 0 {
+		return nil, fmt.Errorf("folder name shoud not be nil")
+	}
+	for _, folder := range folders {
+		prePath = prePath + fmt.Sprintf("/job/%s", folder)
+	}
+	err := j.retryCredentialCall(ctx, func() (*http.Response, error) {
+		return j.Requester.PostContext(ctx, prePath+
+			fmt.Sprintf("/credentials/store/folder/domain/%s/createCredentials", domain),
+			nil, &responseString, param)
+	}, func() bool {
+		return j.credentialExistsInFolder(ctx, domain, id, folders...)
+	})
+	if err != nil {
+		return nil, createCredentialError(err)
+	}
+	return &requestStruct.Credentials.Id, nil
+}
+
+func (j *Jenkins) UpdateOpenShiftTokenCredentialInFolder(domain, id, token, description, scope string,
+	folders ...string) (*string, error) {
+	return j.UpdateOpenShiftTokenCredentialInFolderContext(context.Background(), domain, id, token, description,
+		scope, folders...)
+}
+
+func (j *Jenkins) UpdateOpenShiftTokenCredentialInFolderContext(ctx context.Context, domain, id, token, description,
+	scope string, folders ...string) (*string, error) {
+	requestStruct := NewOpenShiftTokenCredential(id, token, description, scope)
+	param := map[string]string{"json": makeJson(requestStruct)}
+	prePath := ""
+	if domain == "" {
+		domain = "_"
+	}
+	if len(folders) == 0 {
+		return nil, fmt.Errorf("folder name shoud not be nil")
+	}
+	for _, folder := range folders {
+		prePath = prePath + fmt.Sprintf("/job/%s", folder)
+	}
+	err := j.retryCredentialCall(ctx, func() (*http.Response, error) {
+		return j.Requester.PostContext(ctx, prePath+
+			fmt.Sprintf("/credentials/store/folder/domain/%s/credential/%s/updateSubmit", domain, id),
+			nil, nil, param)
+	}, nil)
+	if err != nil {
+		return nil, credentialError(err)
+	}
+	return &id, nil
+}
+
+func (j *Jenkins) CreateOpenShiftTokenCredentialInSystem(domain, id, token, description,
+	scope string) (*string, error) {
+	return j.createCredentialInSystem(domain, id,
+		NewCreateOpenShiftTokenCredentialRequest(id, token, description, scope))
+}
+
+func (j *Jenkins) UpdateOpenShiftTokenCredentialInSystem(domain, id, token, description,
+	scope string) (*string, error) {
+	return j.updateCredentialInSystem(domain, id, NewOpenShiftTokenCredential(id, token, description, scope))
+}
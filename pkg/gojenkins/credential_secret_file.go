@@ -0,0 +1,132 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gojenkins
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+const SecretFileCredentialStaplerClass = "org.jenkinsci.plugins.plaincredentials.impl.FileCredentialsImpl"
+
+type CreateSecretFileCredentialRequest struct {
+	Credentials SecretFileCredential `json:"credentials"`
+}
+
+// SecretFileCredential holds a file used by the jobs, SecretBytes is the base64 encoded content of the file
+// which Jenkins encrypts.
+type SecretFileCredential struct {
+	Scope        string `json:"scope"`
+	Id           string `json:"id"`
+	FileName     string `json:"fileName"`
+	SecretBytes  string `json:"secretBytes"`
+	Description  string `json:"description"`
+	StaplerClass string `json:"stapler-class"`
+}
+
+func NewCreateSecretFileCredentialRequest(id, fileName, content, description,
+	scope string) *CreateSecretFileCredentialRequest {
+	return &CreateSecretFileCredentialRequest{
+		Credentials: *NewSecretFileCredential(id, fileName, content, description, scope),
+	}
+}
+
+func NewSecretFileCredential(id, fileName, content, description, scope string) *SecretFileCredential {
+	return &SecretFileCredential{
+		Scope:        credentialScope(scope),
+		Id:           id,
+		FileName:     fileName,
+		SecretBytes:  content,
+		Description:  description,
+		StaplerClass: SecretFileCredentialStaplerClass,
+	}
+}
+
+func (j *Jenkins) CreateSecretFileCredentialInFolder(domain, id, fileName, content, description, scope string,
+	folders ...string) (*string, error) {
+	return j.CreateSecretFileCredentialInFolderContext(context.Background(), domain, id, fileName, content,
+		description, scope, folders...)
+}
+
+func (j *Jenkins) CreateSecretFileCredentialInFolderContext(ctx context.Context, domain, id, fileName, content,
+	description, scope string, folders ...string) (*string, error) {
+	requestStruct := NewCreateSecretFileCredentialRequest(id, fileName, content, description, scope)
+	param := map[string]string{"json": makeJson(requestStruct)}
+	responseString := ""
+	prePath := ""
+	if domain == "" {
+		domain = "_"
+	}
+	if len(folders) == 0 {
+		return nil, fmt.Errorf("folder name shoud not be nil")
+	}
+	for _, folder := range folders {
+		prePath = prePath + fmt.Sprintf("/job/%s", folder)
+	}
+	err := j.retryCredentialCall(ctx, func() (*http.Response, error) {
+		return j.Requester.PostContext(ctx, prePath+
+			fmt.Sprintf("/credentials/store/folder/domain/%s/createCredentials", domain),
+			nil, &responseString, param)
+	}, func() bool {
+		return j.credentialExistsInFolder(ctx, domain, id, folders...)
+	})
+	if err != nil {
+		return nil, createCredentialError(err)
+	}
+	return &requestStruct.Credentials.Id, nil
+}
+
+func (j *Jenkins) UpdateSecretFileCredentialInFolder(domain, id, fileName, content, description, scope string,
+	folders ...string) (*string, error) {
+	return j.UpdateSecretFileCredentialInFolderContext(context.Background(), domain, id, fileName, content,
+		description, scope, folders...)
+}
+
+func (j *Jenkins) UpdateSecretFileCredentialInFolderContext(ctx context.Context, domain, id, fileName, content,
+	description, scope string, folders ...string) (*string, error) {
+	requestStruct := NewSecretFileCredential(id, fileName, content, description, scope)
+	param := map[string]string{"json": makeJson(requestStruct)}
+	prePath := ""
+	if domain == "" {
+		domain = "_"
+	}
+	if len(folders) == 0 {
+		return nil, fmt.Errorf("folder name shoud not be nil")
+	}
+	for _, folder := range folders {
+		prePath = prePath + fmt.Sprintf("/job/%s", folder)
+	}
+	err := j.retryCredentialCall(ctx, func() (*http.Response, error) {
+		return j.Requester.PostContext(ctx, prePath+
+			fmt.Sprintf("/credentials/store/folder/domain/%s/credential/%s/updateSubmit", domain, id),
+			nil, nil, param)
+	}, nil)
+	if err != nil {
+		return nil, credentialError(err)
+	}
+	return &id, nil
+}
+
+func (j *Jenkins) CreateSecretFileCredentialInSystem(domain, id, fileName, content, description,
+	scope string) (*string, error) {
+	return j.createCredentialInSystem(domain, id,
+		NewCreateSecretFileCredentialRequest(id, fileName, content, description, scope))
+}
+
+func (j *Jenkins) UpdateSecretFileCredentialInSystem(domain, id, fileName, content, description,
+	scope string) (*string, error) {
+	return j.updateCredentialInSystem(domain, id,
+		NewSecretFileCredential(id, fileName, content, description, scope))
+}
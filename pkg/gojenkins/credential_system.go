@@ -0,0 +1,213 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gojenkins
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// the system store holds the credentials of the Jenkins instance, visible to the jobs of every folder.
+const systemCredentialStorePath = "/credentials/store/system/domain/%s"
+
+func systemCredentialDomain(domain string) string {
+	if domain == "" {
+		return "_"
+	}
+	return domain
+}
+
+// createCredentialInSystem posts the create request of a credential to a domain of the system store.
+func (j *Jenkins) createCredentialInSystem(domain, id string, requestStruct interface{}) (*string, error) {
+	ctx := context.Background()
+	domain = systemCredentialDomain(domain)
+	param := map[string]string{"json": makeJson(requestStruct)}
+	responseString := ""
+	err := j.retryCredentialCall(ctx, func() (*http.Response, error) {
+		return j.Requester.PostContext(ctx, fmt.Sprintf(systemCredentialStorePath+"/createCredentials", domain),
+			nil, &responseString, param)
+	}, func() bool {
+		_, err := j.GetCredentialInSystem(domain, id)
+		return err == nil
+	})
+	if err != nil {
+		return nil, createCredentialError(err)
+	}
+	return &id, nil
+}
+
+func (j *Jenkins) CreateSshCredentialInSystem(domain, id, username, passphrase, privateKey, description,
+	scope string) (*string, error) {
+	return j.createCredentialInSystem(domain, id,
+		NewCreateSshCredentialRequest(id, username, passphrase, privateKey, description, scope))
+}
+
+func (j *Jenkins) CreateUsernamePasswordCredentialInSystem(domain, id, username, password, description,
+	scope string) (*string, error) {
+	return j.createCredentialInSystem(domain, id,
+		NewCreateUsernamePasswordRequest(id, username, password, description, scope))
+}
+
+func (j *Jenkins) CreateSecretTextCredentialInSystem(domain, id, secret, description, scope string) (*string, error) {
+	return j.createCredentialInSystem(domain, id, NewCreateSecretTextCredentialRequest(id, secret, description, scope))
+}
+
+func (j *Jenkins) CreateKubeconfigCredentialInSystem(domain, id, content, description, scope string) (*string, error) {
+	return j.createCredentialInSystem(domain, id, NewCreateKubeconfigCredentialRequest(id, content, description, scope))
+}
+
+func (j *Jenkins) CreateCertificateCredentialInSystem(domain, id, keystore, password, description,
+	scope string) (*string, error) {
+	return j.createCredentialInSystem(domain, id,
+		NewCreateCertificateCredentialRequest(id, keystore, password, description, scope))
+}
+
+func (j *Jenkins) CreateAWSCredentialInSystem(domain, id, accessKey, secretKey, iamRoleArn, description,
+	scope string) (*string, error) {
+	return j.createCredentialInSystem(domain, id,
+		NewCreateAWSCredentialRequest(id, accessKey, secretKey, iamRoleArn, description, scope))
+}
+
+// updateCredentialInSystem posts the update request of a credential to a domain of the system store.
+func (j *Jenkins) updateCredentialInSystem(domain, id string, requestStruct interface{}) (*string, error) {
+	ctx := context.Background()
+	domain = systemCredentialDomain(domain)
+	param := map[string]string{"json": makeJson(requestStruct)}
+	err := j.retryCredentialCall(ctx, func() (*http.Response, error) {
+		return j.Requester.PostContext(ctx, fmt.Sprintf(systemCredentialStorePath+"/credential/%s/updateSubmit",
+			domain, id), nil, nil, param)
+	}, nil)
+	if err != nil {
+		return nil, credentialError(err)
+	}
+	return &id, nil
+}
+
+func (j *Jenkins) UpdateSshCredentialInSystem(domain, id, username, passphrase, privateKey, description,
+	scope string) (*string, error) {
+	return j.updateCredentialInSystem(domain, id,
+		NewSshCredential(id, username, passphrase, privateKey, description, scope))
+}
+
+func (j *Jenkins) UpdateUsernamePasswordCredentialInSystem(domain, id, username, password, description,
+	scope string) (*string, error) {
+	return j.updateCredentialInSystem(domain, id,
+		NewUsernamePasswordCredential(id, username, password, description, scope))
+}
+
+func (j *Jenkins) UpdateSecretTextCredentialInSystem(domain, id, secret, description, scope string) (*string, error) {
+	return j.updateCredentialInSystem(domain, id, NewSecretTextCredential(id, secret, description, scope))
+}
+
+func (j *Jenkins) UpdateKubeconfigCredentialInSystem(domain, id, content, description, scope string) (*string, error) {
+	return j.updateCredentialInSystem(domain, id, NewKubeconfigCredential(id, content, description, scope))
+}
+
+func (j *Jenkins) UpdateCertificateCredentialInSystem(domain, id, keystore, password, description,
+	scope string) (*string, error) {
+	return j.updateCredentialInSystem(domain, id,
+		NewCertificateCredential(id, keystore, password, description, scope))
+}
+
+func (j *Jenkins) UpdateAWSCredentialInSystem(domain, id, accessKey, secretKey, iamRoleArn, description,
+	scope string) (*string, error) {
+	return j.updateCredentialInSystem(domain, id,
+		NewAWSCredential(id, accessKey, secretKey, iamRoleArn, description, scope))
+}
+
+func (j *Jenkins) GetCredentialInSystem(domain, id string) (*CredentialResponse, error) {
+	domain = systemCredentialDomain(domain)
+	responseStruct := &CredentialResponse{}
+	response, err := j.Requester.GetJSON(fmt.Sprintf(systemCredentialStorePath+"/credential/%s", domain, id),
+		responseStruct, map[string]string{
+			"depth": "2",
+		})
+	if err != nil {
+		return nil, credentialError(err)
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, credentialError(errors.New(strconv.Itoa(response.StatusCode)))
+	}
+	responseStruct.Domain = domain
+	return responseStruct, nil
+}
+
+func (j *Jenkins) GetCredentialContentInSystem(domain, id string) (string, error) {
+	domain = systemCredentialDomain(domain)
+	responseStruct := ""
+	response, err := j.Requester.GetHtml(fmt.Sprintf(systemCredentialStorePath+"/credential/%s/update", domain, id),
+		&responseStruct, nil)
+	if err != nil {
+		return "", credentialError(err)
+	}
+	if response.StatusCode != http.StatusOK {
+		return "", credentialError(errors.New(strconv.Itoa(response.StatusCode)))
+	}
+	return responseStruct, nil
+}
+
+func (j *Jenkins) GetCredentialConfigInSystem(domain, id string) (string, error) {
+	domain = systemCredentialDomain(domain)
+	responseStruct := ""
+	response, err := j.Requester.GetXML(fmt.Sprintf(systemCredentialStorePath+"/credential/%s/config.xml", domain, id),
+		&responseStruct, nil)
+	if err != nil {
+		return "", credentialError(err)
+	}
+	if response.StatusCode != http.StatusOK {
+		return "", credentialError(errors.New(strconv.Itoa(response.StatusCode)))
+	}
+	return responseStruct, nil
+}
+
+// GetCredentialsInSystem lists the credentials of a domain of the system store.
+func (j *Jenkins) GetCredentialsInSystem(domain string) ([]*CredentialResponse, error) {
+	domain = systemCredentialDomain(domain)
+	var responseStruct = &struct {
+		Credentials []*CredentialResponse `json:"credentials"`
+	}{}
+	response, err := j.Requester.GetJSON(fmt.Sprintf(systemCredentialStorePath, domain),
+		responseStruct, map[string]string{
+			"depth": "2",
+		})
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.New(strconv.Itoa(response.StatusCode))
+	}
+	for _, credential := range responseStruct.Credentials {
+		credential.Domain = domain
+	}
+	return responseStruct.Credentials, nil
+}
+
+func (j *Jenkins) DeleteCredentialInSystem(domain, id string) (*string, error) {
+	ctx := context.Background()
+	domain = systemCredentialDomain(domain)
+	err := j.retryCredentialCall(ctx, func() (*http.Response, error) {
+		return j.Requester.PostContext(ctx, fmt.Sprintf(systemCredentialStorePath+"/credential/%s/doDelete",
+			domain, id), nil, nil, nil)
+	}, func() bool {
+		_, err := j.GetCredentialInSystem(domain, id)
+		return errors.Is(err, ErrCredentialNotFound)
+	})
+	if err != nil {
+		return nil, credentialError(err)
+	}
+	return &id, nil
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gojenkins
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// FirstUsedTime returns when Jenkins first fingerprinted the credential, nil when Jenkins has no
+// fingerprint of it. Jenkins only keeps a fingerprint once the credential has been used, so this is
+// when it was first used rather than when it was created.
+func (c *CredentialResponse) FirstUsedTime() *time.Time {
+	if c.Fingerprint == nil || c.Fingerprint.Timestamp <= 0 {
+		return nil
+	}
+	firstUsedTime := time.Unix(0, c.Fingerprint.Timestamp*int64(time.Millisecond))
+	return &firstUsedTime
+}
+
+// GetCredentialFirstUsedTimeInFolder reads only the fingerprint timestamp of a credential, it returns nil
+// when Jenkins has no fingerprint of the credential.
+func (j *Jenkins) GetCredentialFirstUsedTimeInFolder(domain, id string, folders ...string) (*time.Time, error) {
+	responseStruct := &CredentialResponse{}
+	prePath := ""
+	if domain == "" {
+		domain = "_"
+	}
+	if len(folders) == 0 {
+		return nil, fmt.Errorf("folder name shoud not be nil")
+	}
+	for _, folder := range folders {
+		prePath = prePath + fmt.Sprintf("/job/%s", folder)
+	}
+	response, err := j.Requester.GetJSON(prePath+
+		fmt.Sprintf("/credentials/store/folder/domain/%s/credential/%s", domain, id),
+		responseStruct, map[string]string{
+			"tree": "fingerprint[timestamp]",
+		})
+	if err != nil {
+		return nil, credentialError(err)
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, credentialError(errors.New(strconv.Itoa(response.StatusCode)))
+	}
+	return responseStruct.FirstUsedTime(), nil
+}
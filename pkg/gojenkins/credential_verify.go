@@ -0,0 +1,74 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gojenkins
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// GitUrlCheckClass is the descriptor validating the url of git remotes with a credential.
+const GitUrlCheckClass = "hudson.plugins.git.UserRemoteConfig"
+
+var formValidationTag = regexp.MustCompile(`<[^>]*>`)
+
+// parseFormValidation reads the html rendered for a Jenkins FormValidation, a validation passes when it
+// is not an error or a warning. message is the text of the validation.
+func parseFormValidation(body string) (ok bool, message string) {
+	message = strings.TrimSpace(html.UnescapeString(formValidationTag.ReplaceAllString(body, "")))
+	lower := strings.ToLower(body)
+	for _, kind := range []string{"error", "warning"} {
+		if strings.Contains(lower, "class="+kind) || strings.Contains(lower, `class="`+kind+`"`) {
+			return false, message
+		}
+	}
+	return true, message
+}
+
+// VerifyGitCredentialInFolder asks the git plugin to connect to the repository url with the credential id of
+// the folder. ok tells whether it connected, message is the reason given by Jenkins when it did not.
+func (j *Jenkins) VerifyGitCredentialInFolder(id, url string, folders ...string) (bool, string, error) {
+	return j.VerifyGitCredentialInFolderContext(context.Background(), id, url, folders...)
+}
+
+func (j *Jenkins) VerifyGitCredentialInFolderContext(ctx context.Context, id, url string,
+	folders ...string) (bool, string, error) {
+	prePath := ""
+	if len(folders) == 0 {
+		return false, "", fmt.Errorf("folder name shoud not be nil")
+	}
+	for _, folder := range folders {
+		prePath = prePath + fmt.Sprintf("/job/%s", folder)
+	}
+	ar := NewAPIRequest("POST", prePath+fmt.Sprintf("/descriptorByName/%s/checkUrl", GitUrlCheckClass), nil)
+	ar.Context = ctx
+	if err := j.Requester.SetCrumb(ar); err != nil {
+		return false, "", err
+	}
+	ar.SetHeader("Content-Type", "application/x-www-form-urlencoded")
+	ar.Suffix = ""
+	body := ""
+	_, err := j.Requester.Do(ar, &body, map[string]string{
+		"value":         url,
+		"credentialsId": id,
+	})
+	if err != nil {
+		return false, "", err
+	}
+	ok, message := parseFormValidation(body)
+	return ok, message, nil
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gojenkins
+
+import (
+	"testing"
+)
+
+func TestParseFormValidation(t *testing.T) {
+	for _, test := range []struct {
+		body    string
+		ok      bool
+		message string
+	}{
+		{body: "<div/>", ok: true},
+		{body: "", ok: true},
+		{body: `<div class="ok">Connected &amp; authenticated</div>`, ok: true,
+			message: "Connected & authenticated"},
+		{body: `<div class=error><img src="error.png" />Failed to connect to repository : ` +
+			`Authentication failed</div>`, message: "Failed to connect to repository : Authentication failed"},
+		{body: `<div class="warning">Cannot validate without credentials</div>`,
+			message: "Cannot validate without credentials"},
+		{body: `<DIV CLASS="ERROR">Host key verification failed</DIV>`, message: "Host key verification failed"},
+	} {
+		ok, message := parseFormValidation(test.body)
+		if ok != test.ok || message != test.message {
+			t.Fatalf("body [%s] should be ok: %t with message [%s], got %t and [%s]", test.body, test.ok,
+				test.message, ok, message)
+		}
+	}
+}
@@ -75,3 +75,12 @@ func (f *Folder) Poll() (int, error) {
 	}
 	return response.StatusCode, nil
 }
+
+func (f *Folder) GetConfig() (string, error) {
+	var data string
+	_, err := f.Jenkins.Requester.GetXML(f.Base+"/config.xml", &data, nil)
+	if err != nil {
+		return "", err
+	}
+	return data, nil
+}
@@ -16,15 +16,19 @@
 package gojenkins
 
 import (
+	"context"
 	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // Basic Authentication
@@ -38,6 +42,7 @@ type Jenkins struct {
 	Version   string
 	Raw       *ExecutorResponse
 	Requester *Requester
+	Retry     RetryPolicy
 }
 
 // Loggers
@@ -516,6 +521,29 @@ func (j *Jenkins) CreateView(name string, viewType string) (*View, error) {
 	return nil, errors.New(strconv.Itoa(r.StatusCode))
 }
 
+// Ping sends an authenticated request to Jenkins, which has to answer within timeout,
+// and returns the Jenkins version.
+func (j *Jenkins) Ping(timeout time.Duration) (string, error) {
+	client := *j.Requester.Client
+	client.Timeout = timeout
+	req, err := http.NewRequest("GET", j.Requester.Base+"/api/json?tree=mode", nil)
+	if err != nil {
+		return "", err
+	}
+	if j.Requester.BasicAuth != nil {
+		req.SetBasicAuth(j.Requester.BasicAuth.Username, j.Requester.BasicAuth.Password)
+	}
+	response, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return "", errors.New(strconv.Itoa(response.StatusCode))
+	}
+	return response.Header.Get("X-Jenkins"), nil
+}
+
 func (j *Jenkins) Poll() (int, error) {
 	resp, err := j.Requester.GetJSON("/", j.Raw, nil)
 	if err != nil {
@@ -527,7 +555,7 @@ func (j *Jenkins) Poll() (int, error) {
 // Create a ssh credentials
 // return credentials id
 func (j *Jenkins) CreateSshCredential(id, username, passphrase, privateKey, description string) (*string, error) {
-	requestStruct := NewCreateSshCredentialRequest(id, username, passphrase, privateKey, description)
+	requestStruct := NewCreateSshCredentialRequest(id, username, passphrase, privateKey, description, GLOBALScope)
 	param := map[string]string{"json": makeJson(requestStruct)}
 	responseString := ""
 	response, err := j.Requester.Post("/credentials/store/system/domain/_/createCredentials",
@@ -542,7 +570,7 @@ func (j *Jenkins) CreateSshCredential(id, username, passphrase, privateKey, desc
 }
 
 func (j *Jenkins) CreateUsernamePasswordCredential(id, username, password, description string) (*string, error) {
-	requestStruct := NewCreateUsernamePasswordRequest(id, username, password, description)
+	requestStruct := NewCreateUsernamePasswordRequest(id, username, password, description, GLOBALScope)
 	param := map[string]string{"json": makeJson(requestStruct)}
 	responseString := ""
 	response, err := j.Requester.Post("/credentials/store/system/domain/_/createCredentials",
@@ -556,8 +584,12 @@ func (j *Jenkins) CreateUsernamePasswordCredential(id, username, password, descr
 	return &requestStruct.Credentials.Id, nil
 }
 
-func (j *Jenkins) CreateSshCredentialInFolder(domain, id, username, passphrase, privateKey, description string, folders ...string) (*string, error) {
-	requestStruct := NewCreateSshCredentialRequest(id, username, passphrase, privateKey, description)
+func (j *Jenkins) CreateSshCredentialInFolder(domain, id, username, passphrase, privateKey, description, scope string, folders ...string) (*string, error) {
+	return j.CreateSshCredentialInFolderContext(context.Background(), domain, id, username, passphrase, privateKey, description, scope, folders...)
+}
+
+func (j *Jenkins) CreateSshCredentialInFolderContext(ctx context.Context, domain, id, username, passphrase, privateKey, description, scope string, folders ...string) (*string, error) {
+	requestStruct := NewCreateSshCredentialRequest(id, username, passphrase, privateKey, description, scope)
 	param := map[string]string{"json": makeJson(requestStruct)}
 	responseString := ""
 	prePath := ""
@@ -570,20 +602,25 @@ func (j *Jenkins) CreateSshCredentialInFolder(domain, id, username, passphrase,
 	for _, folder := range folders {
 		prePath = prePath + fmt.Sprintf("/job/%s", folder)
 	}
-	response, err := j.Requester.Post(prePath+
-		fmt.Sprintf("/credentials/store/folder/domain/%s/createCredentials", domain),
-		nil, &responseString, param)
+	err := j.retryCredentialCall(ctx, func() (*http.Response, error) {
+		return j.Requester.PostContext(ctx, prePath+
+			fmt.Sprintf("/credentials/store/folder/domain/%s/createCredentials", domain),
+			nil, &responseString, param)
+	}, func() bool {
+		return j.credentialExistsInFolder(ctx, domain, id, folders...)
+	})
 	if err != nil {
-		return nil, err
-	}
-	if response.StatusCode != http.StatusOK {
-		return nil, errors.New(strconv.Itoa(response.StatusCode))
+		return nil, createCredentialError(err)
 	}
 	return &requestStruct.Credentials.Id, nil
 }
 
-func (j *Jenkins) CreateUsernamePasswordCredentialInFolder(domain, id, username, password, description string, folders ...string) (*string, error) {
-	requestStruct := NewCreateUsernamePasswordRequest(id, username, password, description)
+func (j *Jenkins) CreateUsernamePasswordCredentialInFolder(domain, id, username, password, description, scope string, folders ...string) (*string, error) {
+	return j.CreateUsernamePasswordCredentialInFolderContext(context.Background(), domain, id, username, password, description, scope, folders...)
+}
+
+func (j *Jenkins) CreateUsernamePasswordCredentialInFolderContext(ctx context.Context, domain, id, username, password, description, scope string, folders ...string) (*string, error) {
+	requestStruct := NewCreateUsernamePasswordRequest(id, username, password, description, scope)
 	param := map[string]string{"json": makeJson(requestStruct)}
 	responseString := ""
 	prePath := ""
@@ -596,20 +633,25 @@ func (j *Jenkins) CreateUsernamePasswordCredentialInFolder(domain, id, username,
 	for _, folder := range folders {
 		prePath = prePath + fmt.Sprintf("/job/%s", folder)
 	}
-	response, err := j.Requester.Post(prePath+
-		fmt.Sprintf("/credentials/store/folder/domain/%s/createCredentials", domain),
-		nil, &responseString, param)
+	err := j.retryCredentialCall(ctx, func() (*http.Response, error) {
+		return j.Requester.PostContext(ctx, prePath+
+			fmt.Sprintf("/credentials/store/folder/domain/%s/createCredentials", domain),
+			nil, &responseString, param)
+	}, func() bool {
+		return j.credentialExistsInFolder(ctx, domain, id, folders...)
+	})
 	if err != nil {
-		return nil, err
-	}
-	if response.StatusCode != http.StatusOK {
-		return nil, errors.New(strconv.Itoa(response.StatusCode))
+		return nil, createCredentialError(err)
 	}
 	return &requestStruct.Credentials.Id, nil
 }
 
-func (j *Jenkins) CreateSecretTextCredentialInFolder(domain, id, secret, description string, folders ...string) (*string, error) {
-	requestStruct := NewCreateSecretTextCredentialRequest(id, secret, description)
+func (j *Jenkins) CreateSecretTextCredentialInFolder(domain, id, secret, description, scope string, folders ...string) (*string, error) {
+	return j.CreateSecretTextCredentialInFolderContext(context.Background(), domain, id, secret, description, scope, folders...)
+}
+
+func (j *Jenkins) CreateSecretTextCredentialInFolderContext(ctx context.Context, domain, id, secret, description, scope string, folders ...string) (*string, error) {
+	requestStruct := NewCreateSecretTextCredentialRequest(id, secret, description, scope)
 	param := map[string]string{"json": makeJson(requestStruct)}
 	responseString := ""
 	prePath := ""
@@ -622,20 +664,25 @@ func (j *Jenkins) CreateSecretTextCredentialInFolder(domain, id, secret, descrip
 	for _, folder := range folders {
 		prePath = prePath + fmt.Sprintf("/job/%s", folder)
 	}
-	response, err := j.Requester.Post(prePath+
-		fmt.Sprintf("/credentials/store/folder/domain/%s/createCredentials", domain),
-		nil, &responseString, param)
+	err := j.retryCredentialCall(ctx, func() (*http.Response, error) {
+		return j.Requester.PostContext(ctx, prePath+
+			fmt.Sprintf("/credentials/store/folder/domain/%s/createCredentials", domain),
+			nil, &responseString, param)
+	}, func() bool {
+		return j.credentialExistsInFolder(ctx, domain, id, folders...)
+	})
 	if err != nil {
-		return nil, err
-	}
-	if response.StatusCode != http.StatusOK {
-		return nil, errors.New(strconv.Itoa(response.StatusCode))
+		return nil, createCredentialError(err)
 	}
 	return &requestStruct.Credentials.Id, nil
 }
 
-func (j *Jenkins) CreateKubeconfigCredentialInFolder(domain, id, content, description string, folders ...string) (*string, error) {
-	requestStruct := NewCreateKubeconfigCredentialRequest(id, content, description)
+func (j *Jenkins) CreateKubeconfigCredentialInFolder(domain, id, content, description, scope string, folders ...string) (*string, error) {
+	return j.CreateKubeconfigCredentialInFolderContext(context.Background(), domain, id, content, description, scope, folders...)
+}
+
+func (j *Jenkins) CreateKubeconfigCredentialInFolderContext(ctx context.Context, domain, id, content, description, scope string, folders ...string) (*string, error) {
+	requestStruct := NewCreateKubeconfigCredentialRequest(id, content, description, scope)
 	param := map[string]string{"json": makeJson(requestStruct)}
 	responseString := ""
 	prePath := ""
@@ -648,21 +695,58 @@ func (j *Jenkins) CreateKubeconfigCredentialInFolder(domain, id, content, descri
 	for _, folder := range folders {
 		prePath = prePath + fmt.Sprintf("/job/%s", folder)
 	}
-	response, err := j.Requester.Post(prePath+
-		fmt.Sprintf("/credentials/store/folder/domain/%s/createCredentials", domain),
-		nil, &responseString, param)
+	err := j.retryCredentialCall(ctx, func() (*http.Response, error) {
+		return j.Requester.PostContext(ctx, prePath+
+			fmt.Sprintf("/credentials/store/folder/domain/%s/createCredentials", domain),
+			nil, &responseString, param)
+	}, func() bool {
+		return j.credentialExistsInFolder(ctx, domain, id, folders...)
+	})
 	if err != nil {
-		return nil, err
+		return nil, createCredentialError(err)
 	}
-	if response.StatusCode != http.StatusOK {
-		return nil, errors.New(strconv.Itoa(response.StatusCode))
+	return &requestStruct.Credentials.Id, nil
+}
+
+func (j *Jenkins) CreateCertificateCredentialInFolder(domain, id, keystore, password, description, scope string, folders ...string) (*string, error) {
+	return j.CreateCertificateCredentialInFolderContext(context.Background(), domain, id, keystore, password, description, scope, folders...)
+}
+
+func (j *Jenkins) CreateCertificateCredentialInFolderContext(ctx context.Context, domain, id, keystore, password, description, scope string, folders ...string) (*string, error) {
+	requestStruct := NewCreateCertificateCredentialRequest(id, keystore, password, description, scope)
+	param := map[string]string{"json": makeJson(requestStruct)}
+	responseString := ""
+	prePath := ""
+	if domain == "" {
+		domain = "_"
+	}
+	if len(folders) == 0 {
+		return nil, fmt.Errorf("folder name shoud not be nil")
+	}
+	for _, folder := range folders {
+		prePath = prePath + fmt.Sprintf("/job/%s", folder)
+	}
+	err := j.retryCredentialCall(ctx, func() (*http.Response, error) {
+		return j.Requester.PostContext(ctx, prePath+
+			fmt.Sprintf("/credentials/store/folder/domain/%s/createCredentials", domain),
+			nil, &responseString, param)
+	}, func() bool {
+		return j.credentialExistsInFolder(ctx, domain, id, folders...)
+	})
+	if err != nil {
+		return nil, createCredentialError(err)
 	}
 	return &requestStruct.Credentials.Id, nil
 }
 
-func (j *Jenkins) UpdateSshCredentialInFolder(domain, id, username, passphrase, privateKey, description string, folders ...string) (*string, error) {
-	requestStruct := NewSshCredential(id, username, passphrase, privateKey, description)
+func (j *Jenkins) CreateAWSCredentialInFolder(domain, id, accessKey, secretKey, iamRoleArn, description, scope string, folders ...string) (*string, error) {
+	return j.CreateAWSCredentialInFolderContext(context.Background(), domain, id, accessKey, secretKey, iamRoleArn, description, scope, folders...)
+}
+
+func (j *Jenkins) CreateAWSCredentialInFolderContext(ctx context.Context, domain, id, accessKey, secretKey, iamRoleArn, description, scope string, folders ...string) (*string, error) {
+	requestStruct := NewCreateAWSCredentialRequest(id, accessKey, secretKey, iamRoleArn, description, scope)
 	param := map[string]string{"json": makeJson(requestStruct)}
+	responseString := ""
 	prePath := ""
 	if domain == "" {
 		domain = "_"
@@ -673,20 +757,53 @@ func (j *Jenkins) UpdateSshCredentialInFolder(domain, id, username, passphrase,
 	for _, folder := range folders {
 		prePath = prePath + fmt.Sprintf("/job/%s", folder)
 	}
-	response, err := j.Requester.Post(prePath+
-		fmt.Sprintf("/credentials/store/folder/domain/%s/credential/%s/updateSubmit", domain, id),
-		nil, nil, param)
+	err := j.retryCredentialCall(ctx, func() (*http.Response, error) {
+		return j.Requester.PostContext(ctx, prePath+
+			fmt.Sprintf("/credentials/store/folder/domain/%s/createCredentials", domain),
+			nil, &responseString, param)
+	}, func() bool {
+		return j.credentialExistsInFolder(ctx, domain, id, folders...)
+	})
 	if err != nil {
-		return nil, err
+		return nil, createCredentialError(err)
 	}
-	if response.StatusCode != http.StatusOK {
-		return nil, errors.New(strconv.Itoa(response.StatusCode))
+	return &requestStruct.Credentials.Id, nil
+}
+
+func (j *Jenkins) UpdateSshCredentialInFolder(domain, id, username, passphrase, privateKey, description, scope string, folders ...string) (*string, error) {
+	return j.UpdateSshCredentialInFolderContext(context.Background(), domain, id, username, passphrase, privateKey, description, scope, folders...)
+}
+
+func (j *Jenkins) UpdateSshCredentialInFolderContext(ctx context.Context, domain, id, username, passphrase, privateKey, description, scope string, folders ...string) (*string, error) {
+	requestStruct := NewSshCredential(id, username, passphrase, privateKey, description, scope)
+	param := map[string]string{"json": makeJson(requestStruct)}
+	prePath := ""
+	if domain == "" {
+		domain = "_"
+	}
+	if len(folders) == 0 {
+		return nil, fmt.Errorf("folder name shoud not be nil")
+	}
+	for _, folder := range folders {
+		prePath = prePath + fmt.Sprintf("/job/%s", folder)
+	}
+	err := j.retryCredentialCall(ctx, func() (*http.Response, error) {
+		return j.Requester.PostContext(ctx, prePath+
+			fmt.Sprintf("/credentials/store/folder/domain/%s/credential/%s/updateSubmit", domain, id),
+			nil, nil, param)
+	}, nil)
+	if err != nil {
+		return nil, credentialError(err)
 	}
 	return &id, nil
 }
 
-func (j *Jenkins) UpdateUsernamePasswordCredentialInFolder(domain, id, username, password, description string, folders ...string) (*string, error) {
-	requestStruct := NewUsernamePasswordCredential(id, username, password, description)
+func (j *Jenkins) UpdateUsernamePasswordCredentialInFolder(domain, id, username, password, description, scope string, folders ...string) (*string, error) {
+	return j.UpdateUsernamePasswordCredentialInFolderContext(context.Background(), domain, id, username, password, description, scope, folders...)
+}
+
+func (j *Jenkins) UpdateUsernamePasswordCredentialInFolderContext(ctx context.Context, domain, id, username, password, description, scope string, folders ...string) (*string, error) {
+	requestStruct := NewUsernamePasswordCredential(id, username, password, description, scope)
 	param := map[string]string{"json": makeJson(requestStruct)}
 	prePath := ""
 	if domain == "" {
@@ -698,20 +815,23 @@ func (j *Jenkins) UpdateUsernamePasswordCredentialInFolder(domain, id, username,
 	for _, folder := range folders {
 		prePath = prePath + fmt.Sprintf("/job/%s", folder)
 	}
-	response, err := j.Requester.Post(prePath+
-		fmt.Sprintf("/credentials/store/folder/domain/%s/credential/%s/updateSubmit", domain, id),
-		nil, nil, param)
+	err := j.retryCredentialCall(ctx, func() (*http.Response, error) {
+		return j.Requester.PostContext(ctx, prePath+
+			fmt.Sprintf("/credentials/store/folder/domain/%s/credential/%s/updateSubmit", domain, id),
+			nil, nil, param)
+	}, nil)
 	if err != nil {
-		return nil, err
-	}
-	if response.StatusCode != http.StatusOK {
-		return nil, errors.New(strconv.Itoa(response.StatusCode))
+		return nil, credentialError(err)
 	}
 	return &id, nil
 }
 
-func (j *Jenkins) UpdateSecretTextCredentialInFolder(domain, id, secret, description string, folders ...string) (*string, error) {
-	requestStruct := NewSecretTextCredential(id, secret, description)
+func (j *Jenkins) UpdateSecretTextCredentialInFolder(domain, id, secret, description, scope string, folders ...string) (*string, error) {
+	return j.UpdateSecretTextCredentialInFolderContext(context.Background(), domain, id, secret, description, scope, folders...)
+}
+
+func (j *Jenkins) UpdateSecretTextCredentialInFolderContext(ctx context.Context, domain, id, secret, description, scope string, folders ...string) (*string, error) {
+	requestStruct := NewSecretTextCredential(id, secret, description, scope)
 	param := map[string]string{"json": makeJson(requestStruct)}
 	prePath := ""
 	if domain == "" {
@@ -723,20 +843,51 @@ func (j *Jenkins) UpdateSecretTextCredentialInFolder(domain, id, secret, descrip
 	for _, folder := range folders {
 		prePath = prePath + fmt.Sprintf("/job/%s", folder)
 	}
-	response, err := j.Requester.Post(prePath+
-		fmt.Sprintf("/credentials/store/folder/domain/%s/credential/%s/updateSubmit", domain, id),
-		nil, nil, param)
+	err := j.retryCredentialCall(ctx, func() (*http.Response, error) {
+		return j.Requester.PostContext(ctx, prePath+
+			fmt.Sprintf("/credentials/store/folder/domain/%s/credential/%s/updateSubmit", domain, id),
+			nil, nil, param)
+	}, nil)
 	if err != nil {
-		return nil, err
+		return nil, credentialError(err)
 	}
-	if response.StatusCode != http.StatusOK {
-		return nil, errors.New(strconv.Itoa(response.StatusCode))
+	return &id, nil
+}
+
+func (j *Jenkins) UpdateKubeconfigCredentialInFolder(domain, id, content, description, scope string, folders ...string) (*string, error) {
+	return j.UpdateKubeconfigCredentialInFolderContext(context.Background(), domain, id, content, description, scope, folders...)
+}
+
+func (j *Jenkins) UpdateKubeconfigCredentialInFolderContext(ctx context.Context, domain, id, content, description, scope string, folders ...string) (*string, error) {
+	requestStruct := NewKubeconfigCredential(id, content, description, scope)
+	param := map[string]string{"json": makeJson(requestStruct)}
+	prePath := ""
+	if domain == "" {
+		domain = "_"
+	}
+	if len(folders) == 0 {
+		return nil, fmt.Errorf("folder name shoud not be nil")
+	}
+	for _, folder := range folders {
+		prePath = prePath + fmt.Sprintf("/job/%s", folder)
+	}
+	err := j.retryCredentialCall(ctx, func() (*http.Response, error) {
+		return j.Requester.PostContext(ctx, prePath+
+			fmt.Sprintf("/credentials/store/folder/domain/%s/credential/%s/updateSubmit", domain, id),
+			nil, nil, param)
+	}, nil)
+	if err != nil {
+		return nil, credentialError(err)
 	}
 	return &id, nil
 }
 
-func (j *Jenkins) UpdateKubeconfigCredentialInFolder(domain, id, content, description string, folders ...string) (*string, error) {
-	requestStruct := NewKubeconfigCredential(id, content, description)
+func (j *Jenkins) UpdateCertificateCredentialInFolder(domain, id, keystore, password, description, scope string, folders ...string) (*string, error) {
+	return j.UpdateCertificateCredentialInFolderContext(context.Background(), domain, id, keystore, password, description, scope, folders...)
+}
+
+func (j *Jenkins) UpdateCertificateCredentialInFolderContext(ctx context.Context, domain, id, keystore, password, description, scope string, folders ...string) (*string, error) {
+	requestStruct := NewCertificateCredential(id, keystore, password, description, scope)
 	param := map[string]string{"json": makeJson(requestStruct)}
 	prePath := ""
 	if domain == "" {
@@ -748,19 +899,50 @@ func (j *Jenkins) UpdateKubeconfigCredentialInFolder(domain, id, content, descri
 	for _, folder := range folders {
 		prePath = prePath + fmt.Sprintf("/job/%s", folder)
 	}
-	response, err := j.Requester.Post(prePath+
-		fmt.Sprintf("/credentials/store/folder/domain/%s/credential/%s/updateSubmit", domain, id),
-		nil, nil, param)
+	err := j.retryCredentialCall(ctx, func() (*http.Response, error) {
+		return j.Requester.PostContext(ctx, prePath+
+			fmt.Sprintf("/credentials/store/folder/domain/%s/credential/%s/updateSubmit", domain, id),
+			nil, nil, param)
+	}, nil)
 	if err != nil {
-		return nil, err
+		return nil, credentialError(err)
 	}
-	if response.StatusCode != http.StatusOK {
-		return nil, errors.New(strconv.Itoa(response.StatusCode))
+	return &id, nil
+}
+
+func (j *Jenkins) UpdateAWSCredentialInFolder(domain, id, accessKey, secretKey, iamRoleArn, description, scope string, folders ...string) (*string, error) {
+	return j.UpdateAWSCredentialInFolderContext(context.Background(), domain, id, accessKey, secretKey, iamRoleArn, description, scope, folders...)
+}
+
+func (j *Jenkins) UpdateAWSCredentialInFolderContext(ctx context.Context, domain, id, accessKey, secretKey, iamRoleArn, description, scope string, folders ...string) (*string, error) {
+	requestStruct := NewAWSCredential(id, accessKey, secretKey, iamRoleArn, description, scope)
+	param := map[string]string{"json": makeJson(requestStruct)}
+	prePath := ""
+	if domain == "" {
+		domain = "_"
+	}
+	if len(folders) == 0 {
+		return nil, fmt.Errorf("folder name shoud not be nil")
+	}
+	for _, folder := range folders {
+		prePath = prePath + fmt.Sprintf("/job/%s", folder)
+	}
+	err := j.retryCredentialCall(ctx, func() (*http.Response, error) {
+		return j.Requester.PostContext(ctx, prePath+
+			fmt.Sprintf("/credentials/store/folder/domain/%s/credential/%s/updateSubmit", domain, id),
+			nil, nil, param)
+	}, nil)
+	if err != nil {
+		return nil, credentialError(err)
 	}
 	return &id, nil
 }
 
 func (j *Jenkins) GetCredentialInFolder(domain, id string, folders ...string) (*CredentialResponse, error) {
+	return j.GetCredentialInFolderContext(context.Background(), domain, id, folders...)
+}
+
+func (j *Jenkins) GetCredentialInFolderContext(ctx context.Context, domain, id string, folders ...string) (*CredentialResponse, error) {
 	responseStruct := &CredentialResponse{}
 	prePath := ""
 	if domain == "" {
@@ -772,22 +954,26 @@ func (j *Jenkins) GetCredentialInFolder(domain, id string, folders ...string) (*
 	for _, folder := range folders {
 		prePath = prePath + fmt.Sprintf("/job/%s", folder)
 	}
-	response, err := j.Requester.GetJSON(prePath+
+	response, err := j.Requester.GetJSONContext(ctx, prePath+
 		fmt.Sprintf("/credentials/store/folder/domain/%s/credential/%s", domain, id),
 		responseStruct, map[string]string{
 			"depth": "2",
 		})
 	if err != nil {
-		return nil, err
+		return nil, credentialError(err)
 	}
 	if response.StatusCode != http.StatusOK {
-		return nil, errors.New(strconv.Itoa(response.StatusCode))
+		return nil, credentialError(errors.New(strconv.Itoa(response.StatusCode)))
 	}
 	responseStruct.Domain = domain
 	return responseStruct, nil
 }
 
 func (j *Jenkins) GetCredentialContentInFolder(domain, id string, folders ...string) (string, error) {
+	return j.GetCredentialContentInFolderContext(context.Background(), domain, id, folders...)
+}
+
+func (j *Jenkins) GetCredentialContentInFolderContext(ctx context.Context, domain, id string, folders ...string) (string, error) {
 	responseStruct := ""
 	prePath := ""
 	if domain == "" {
@@ -799,9 +985,57 @@ func (j *Jenkins) GetCredentialContentInFolder(domain, id string, folders ...str
 	for _, folder := range folders {
 		prePath = prePath + fmt.Sprintf("/job/%s", folder)
 	}
-	response, err := j.Requester.GetHtml(prePath+
+	response, err := j.Requester.GetHtmlContext(ctx, prePath+
 		fmt.Sprintf("/credentials/store/folder/domain/%s/credential/%s/update", domain, id),
 		&responseStruct, nil)
+	if err != nil {
+		return "", credentialError(err)
+	}
+	if response.StatusCode != http.StatusOK {
+		return "", credentialError(errors.New(strconv.Itoa(response.StatusCode)))
+	}
+	return responseStruct, nil
+}
+
+func (j *Jenkins) GetCredentialConfigInFolder(domain, id string, folders ...string) (string, error) {
+	responseStruct := ""
+	prePath := ""
+	if domain == "" {
+		domain = "_"
+	}
+	if len(folders) == 0 {
+		return "", fmt.Errorf("folder name shoud not be nil")
+	}
+	for _, folder := range folders {
+		prePath = prePath + fmt.Sprintf("/job/%s", folder)
+	}
+	response, err := j.Requester.GetXML(prePath+
+		fmt.Sprintf("/credentials/store/folder/domain/%s/credential/%s/config.xml", domain, id),
+		&responseStruct, nil)
+	if err != nil {
+		return "", credentialError(err)
+	}
+	if response.StatusCode != http.StatusOK {
+		return "", credentialError(errors.New(strconv.Itoa(response.StatusCode)))
+	}
+	return responseStruct, nil
+}
+
+func (j *Jenkins) GetDomainConfigInFolder(domain string, folders ...string) (string, error) {
+	responseStruct := ""
+	prePath := ""
+	if domain == "" {
+		domain = "_"
+	}
+	if len(folders) == 0 {
+		return "", fmt.Errorf("folder name shoud not be nil")
+	}
+	for _, folder := range folders {
+		prePath = prePath + fmt.Sprintf("/job/%s", folder)
+	}
+	response, err := j.Requester.GetXML(prePath+
+		fmt.Sprintf("/credentials/store/folder/domain/%s/config.xml", domain),
+		&responseStruct, nil)
 	if err != nil {
 		return "", err
 	}
@@ -826,10 +1060,11 @@ func (j *Jenkins) GetCredentialsInFolder(domain string, folders ...string) ([]*C
 				Credentials []*CredentialResponse `json:"credentials"`
 			} `json:"domains"`
 		}{}
+		// credentials are one level deeper than in a single domain, depth 3 keeps their fingerprint
 		response, err := j.Requester.GetJSON(prePath+
 			"/credentials/store/folder/",
 			responseStruct, map[string]string{
-				"depth": "2",
+				"depth": "3",
 			})
 		if err != nil {
 			return nil, err
@@ -869,6 +1104,10 @@ func (j *Jenkins) GetCredentialsInFolder(domain string, folders ...string) ([]*C
 }
 
 func (j *Jenkins) DeleteCredentialInFolder(domain, id string, folders ...string) (*string, error) {
+	return j.DeleteCredentialInFolderContext(context.Background(), domain, id, folders...)
+}
+
+func (j *Jenkins) DeleteCredentialInFolderContext(ctx context.Context, domain, id string, folders ...string) (*string, error) {
 	prePath := ""
 	if domain == "" {
 		domain = "_"
@@ -879,8 +1118,88 @@ func (j *Jenkins) DeleteCredentialInFolder(domain, id string, folders ...string)
 	for _, folder := range folders {
 		prePath = prePath + fmt.Sprintf("/job/%s", folder)
 	}
+	err := j.retryCredentialCall(ctx, func() (*http.Response, error) {
+		return j.Requester.PostContext(ctx, prePath+
+			fmt.Sprintf("/credentials/store/folder/domain/%s/credential/%s/doDelete", domain, id),
+			nil, nil, nil)
+	}, func() bool {
+		return j.credentialDeletedInFolder(ctx, domain, id, folders...)
+	})
+	if err != nil {
+		return nil, credentialError(err)
+	}
+	return &id, nil
+}
+
+// GetCredentialDomainsInFolder returns the names of the credential domains in the folder store,
+// the default domain "_" first.
+func (j *Jenkins) GetCredentialDomainsInFolder(folders ...string) ([]string, error) {
+	prePath := ""
+	if len(folders) == 0 {
+		return nil, fmt.Errorf("folder name shoud not be nil")
+	}
+	for _, folder := range folders {
+		prePath = prePath + fmt.Sprintf("/job/%s", folder)
+	}
+	var responseStruct = &struct {
+		Domains map[string]interface{} `json:"domains"`
+	}{}
+	response, err := j.Requester.GetJSON(prePath+
+		"/credentials/store/folder/",
+		responseStruct, map[string]string{
+			"depth": "1",
+		})
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.New(strconv.Itoa(response.StatusCode))
+	}
+	domains := []string{"_"}
+	for domain := range responseStruct.Domains {
+		if domain != "_" {
+			domains = append(domains, domain)
+		}
+	}
+	sort.Strings(domains[1:])
+	return domains, nil
+}
+
+func (j *Jenkins) CreateCredentialDomainInFolder(domain, description, scope string, folders ...string) (*string, error) {
+	requestStruct := &CredentialDomain{Name: domain, Description: description}
+	xmlBytes, err := xml.Marshal(requestStruct)
+	if err != nil {
+		return nil, err
+	}
+	responseString := ""
+	prePath := ""
+	if len(folders) == 0 {
+		return nil, fmt.Errorf("folder name shoud not be nil")
+	}
+	for _, folder := range folders {
+		prePath = prePath + fmt.Sprintf("/job/%s", folder)
+	}
+	response, err := j.Requester.PostXML(prePath+"/credentials/store/folder/createDomain",
+		string(xmlBytes), &responseString, nil)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.New(strconv.Itoa(response.StatusCode))
+	}
+	return &domain, nil
+}
+
+func (j *Jenkins) DeleteCredentialDomainInFolder(domain string, folders ...string) (*string, error) {
+	prePath := ""
+	if len(folders) == 0 {
+		return nil, fmt.Errorf("folder name shoud not be nil")
+	}
+	for _, folder := range folders {
+		prePath = prePath + fmt.Sprintf("/job/%s", folder)
+	}
 	response, err := j.Requester.Post(prePath+
-		fmt.Sprintf("/credentials/store/folder/domain/%s/credential/%s/doDelete", domain, id),
+		fmt.Sprintf("/credentials/store/folder/domain/%s/doDelete", domain),
 		nil, nil, nil)
 	if err != nil {
 		return nil, err
@@ -888,7 +1207,7 @@ func (j *Jenkins) DeleteCredentialInFolder(domain, id string, folders ...string)
 	if response.StatusCode != http.StatusOK {
 		return nil, errors.New(strconv.Itoa(response.StatusCode))
 	}
-	return &id, nil
+	return &domain, nil
 }
 
 func (j *Jenkins) GetGlobalRole(roleName string) (*GlobalRole, error) {
@@ -0,0 +1,78 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gojenkins
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"kubesphere.io/devops/pkg/logger"
+	"kubesphere.io/devops/pkg/utils/metricsutils"
+)
+
+var jenkinsCallDuration = metricsutils.NewHistogramVec("jenkins_call_duration_seconds",
+	"Duration of the HTTP calls to Jenkins by HTTP method and API path.", nil, "method", "path")
+
+// the path segments following these name an item, a credential or a build, they are replaced in path labels
+var jenkinsPathNameSegments = map[string]string{
+	"job":         ":job",
+	"view":        ":view",
+	"domain":      ":domain",
+	"credential":  ":credential",
+	"user":        ":user",
+	"computer":    ":computer",
+	"fingerprint": ":fingerprint",
+	// blue ocean
+	"organizations": ":organization",
+	"pipelines":     ":pipeline",
+	"branches":      ":branch",
+}
+
+// jenkinsPathTemplate turns the path of a Jenkins API call into its template, the names of jobs, domains,
+// credentials and the like and build numbers are replaced, so the path label stays bounded.
+func jenkinsPathTemplate(path string) string {
+	segments := strings.Split(path, "/")
+	for i := 0; i < len(segments); i++ {
+		if placeholder, ok := jenkinsPathNameSegments[segments[i]]; ok && i+1 < len(segments) &&
+			segments[i+1] != "" {
+			segments[i+1] = placeholder
+			i++
+			continue
+		}
+		if segments[i] == "artifact" {
+			return strings.Join(segments[:i+1], "/") + "/:path"
+		}
+		if _, err := strconv.Atoi(segments[i]); err == nil {
+			segments[i] = ":number"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// send sends req to Jenkins and observes how long Jenkins took to answer, the request id carried by the
+// context of req is passed to Jenkins in the X-Request-ID header. The Jenkins version of the response is kept.
+func (r *Requester) send(req *http.Request) (*http.Response, error) {
+	if requestId := logger.GetRequestId(req.Context()); requestId != "" {
+		req.Header.Set(logger.RequestIdHeader, requestId)
+	}
+	start := time.Now()
+	response, err := r.Client.Do(req)
+	jenkinsCallDuration.Observe(time.Since(start).Seconds(), req.Method, jenkinsPathTemplate(req.URL.Path))
+	if err == nil {
+		r.recordVersion(response)
+	}
+	return response, err
+}
@@ -16,6 +16,7 @@ package gojenkins
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -27,6 +28,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 )
 
 // Request Methods
@@ -37,6 +39,8 @@ type APIRequest struct {
 	Payload  io.Reader
 	Headers  http.Header
 	Suffix   string
+	// Context cancels the request, no context lets it run until Jenkins answers
+	Context context.Context
 }
 
 func (ar *APIRequest) SetHeader(key string, value string) *APIRequest {
@@ -47,7 +51,7 @@ func (ar *APIRequest) SetHeader(key string, value string) *APIRequest {
 func NewAPIRequest(method string, endpoint string, payload io.Reader) *APIRequest {
 	var headers = http.Header{}
 	var suffix string
-	ar := &APIRequest{method, endpoint, payload, headers, suffix}
+	ar := &APIRequest{Method: method, Endpoint: endpoint, Payload: payload, Headers: headers, Suffix: suffix}
 	return ar
 }
 
@@ -58,11 +62,13 @@ type Requester struct {
 	CACert      []byte
 	SslVerify   bool
 	connControl chan struct{}
+	// version of Jenkins in its last response
+	version atomic.Value
 }
 
 func (r *Requester) SetCrumb(ar *APIRequest) error {
 	crumbData := map[string]string{}
-	response, err := r.GetJSON("/crumbIssuer/api/json", &crumbData, nil)
+	response, err := r.GetJSONContext(ar.Context, "/crumbIssuer/api/json", &crumbData, nil)
 	if err != nil {
 		jenkinsError, ok := err.(*ErrorResponse)
 		if ok && jenkinsError.Response.StatusCode == http.StatusNotFound {
@@ -88,7 +94,12 @@ func (r *Requester) PostJSON(endpoint string, payload io.Reader, responseStruct
 }
 
 func (r *Requester) Post(endpoint string, payload io.Reader, responseStruct interface{}, querystring map[string]string) (*http.Response, error) {
+	return r.PostContext(context.Background(), endpoint, payload, responseStruct, querystring)
+}
+
+func (r *Requester) PostContext(ctx context.Context, endpoint string, payload io.Reader, responseStruct interface{}, querystring map[string]string) (*http.Response, error) {
 	ar := NewAPIRequest("POST", endpoint, payload)
+	ar.Context = ctx
 	if err := r.SetCrumb(ar); err != nil {
 		return nil, err
 	}
@@ -126,7 +137,12 @@ func (r *Requester) PostXML(endpoint string, xml string, responseStruct interfac
 }
 
 func (r *Requester) GetJSON(endpoint string, responseStruct interface{}, query map[string]string) (*http.Response, error) {
+	return r.GetJSONContext(context.Background(), endpoint, responseStruct, query)
+}
+
+func (r *Requester) GetJSONContext(ctx context.Context, endpoint string, responseStruct interface{}, query map[string]string) (*http.Response, error) {
 	ar := NewAPIRequest("GET", endpoint, nil)
+	ar.Context = ctx
 	ar.SetHeader("Content-Type", "application/json")
 	ar.Suffix = "api/json"
 	return r.Do(ar, &responseStruct, query)
@@ -146,17 +162,36 @@ func (r *Requester) Get(endpoint string, responseStruct interface{}, querystring
 }
 
 func (r *Requester) GetHtml(endpoint string, responseStruct interface{}, querystring map[string]string) (*http.Response, error) {
+	return r.GetHtmlContext(context.Background(), endpoint, responseStruct, querystring)
+}
+
+func (r *Requester) GetHtmlContext(ctx context.Context, endpoint string, responseStruct interface{}, querystring map[string]string) (*http.Response, error) {
 	ar := NewAPIRequest("GET", endpoint, nil)
+	ar.Context = ctx
 	ar.Suffix = ""
 	return r.DoGet(ar, responseStruct, querystring)
 }
 
+// acquireConn waits for a free connection to Jenkins, or until ctx is done.
+func (r *Requester) acquireConn(ctx context.Context) error {
+	if ctx == nil {
+		r.connControl <- struct{}{}
+		return nil
+	}
+	select {
+	case r.connControl <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 func (r *Requester) SetClient(client *http.Client) *Requester {
 	r.Client = client
 	return r
 }
 
-//Add auth on redirect if required.
+// Add auth on redirect if required.
 func (r *Requester) redirectPolicyFunc(req *http.Request, via []*http.Request) error {
 	if r.BasicAuth != nil {
 		req.SetBasicAuth(r.BasicAuth.Username, r.BasicAuth.Password)
@@ -231,6 +266,9 @@ func (r *Requester) DoGet(ar *APIRequest, responseStruct interface{}, options ..
 		}
 	}
 
+	if ar.Context != nil {
+		req = req.WithContext(ar.Context)
+	}
 	if r.BasicAuth != nil {
 		req.SetBasicAuth(r.BasicAuth.Username, r.BasicAuth.Password)
 	}
@@ -239,15 +277,17 @@ func (r *Requester) DoGet(ar *APIRequest, responseStruct interface{}, options ..
 	for k := range ar.Headers {
 		req.Header.Add(k, ar.Headers.Get(k))
 	}
-	r.connControl <- struct{}{}
-	if response, err := r.Client.Do(req); err != nil {
+	if err := r.acquireConn(ar.Context); err != nil {
+		return nil, err
+	}
+	if response, err := r.send(req); err != nil {
 		<-r.connControl
 		return nil, err
 	} else {
 		<-r.connControl
 		errorText := response.Header.Get("X-Error")
 		if errorText != "" {
-			return nil, errors.New(errorText)
+			return nil, headerErrorResponse(response, errorText)
 		}
 		err := CheckResponse(response)
 		if err != nil {
@@ -335,6 +375,9 @@ func (r *Requester) Do(ar *APIRequest, responseStruct interface{}, options ...in
 		}
 	}
 
+	if ar.Context != nil {
+		req = req.WithContext(ar.Context)
+	}
 	if r.BasicAuth != nil {
 		req.SetBasicAuth(r.BasicAuth.Username, r.BasicAuth.Password)
 	}
@@ -343,15 +386,17 @@ func (r *Requester) Do(ar *APIRequest, responseStruct interface{}, options ...in
 	for k := range ar.Headers {
 		req.Header.Add(k, ar.Headers.Get(k))
 	}
-	r.connControl <- struct{}{}
-	if response, err := r.Client.Do(req); err != nil {
+	if err := r.acquireConn(ar.Context); err != nil {
+		return nil, err
+	}
+	if response, err := r.send(req); err != nil {
 		<-r.connControl
 		return nil, err
 	} else {
 		<-r.connControl
 		errorText := response.Header.Get("X-Error")
 		if errorText != "" {
-			return nil, errors.New(errorText)
+			return nil, headerErrorResponse(response, errorText)
 		}
 		err := CheckResponse(response)
 		if err != nil {
@@ -383,6 +428,9 @@ func (r *Requester) DoPostForm(ar *APIRequest, responseStruct interface{}, form
 		formValue.Set(k, v)
 	}
 	req, err := http.NewRequest("POST", URL.String(), strings.NewReader(formValue.Encode()))
+	if ar.Context != nil {
+		req = req.WithContext(ar.Context)
+	}
 	if r.BasicAuth != nil {
 		req.SetBasicAuth(r.BasicAuth.Username, r.BasicAuth.Password)
 	}
@@ -391,15 +439,17 @@ func (r *Requester) DoPostForm(ar *APIRequest, responseStruct interface{}, form
 	for k := range ar.Headers {
 		req.Header.Add(k, ar.Headers.Get(k))
 	}
-	r.connControl <- struct{}{}
-	if response, err := r.Client.Do(req); err != nil {
+	if err := r.acquireConn(ar.Context); err != nil {
+		return nil, err
+	}
+	if response, err := r.send(req); err != nil {
 		<-r.connControl
 		return nil, err
 	} else {
 		<-r.connControl
 		errorText := response.Header.Get("X-Error")
 		if errorText != "" {
-			return nil, errors.New(errorText)
+			return nil, headerErrorResponse(response, errorText)
 		}
 		err := CheckResponse(response)
 		if err != nil {
@@ -450,6 +500,17 @@ func (e *ErrorResponse) Error() string {
 	u := fmt.Sprintf("%s://%s%s", e.Response.Request.URL.Scheme, e.Response.Request.URL.Host, e.Response.Request.URL.RequestURI())
 	return fmt.Sprintf("%s %s: %d %s", e.Response.Request.Method, u, e.Response.StatusCode, e.Message)
 }
+
+// headerErrorResponse returns the error Jenkins reported in the X-Error header of response, it keeps the
+// status of a failed response so that a missing item is not taken for a failure of Jenkins.
+func headerErrorResponse(response *http.Response, errorText string) error {
+	defer response.Body.Close()
+	if response.StatusCode < http.StatusBadRequest {
+		return errors.New(errorText)
+	}
+	return &ErrorResponse{Response: response, Message: errorText}
+}
+
 func CheckResponse(r *http.Response) error {
 
 	switch r.StatusCode {
@@ -0,0 +1,86 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gojenkins
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy retries the credential calls failing with a 5xx status or a connection error,
+// the delay between two attempts doubles after every attempt.
+type RetryPolicy struct {
+	Attempts  int
+	BaseDelay time.Duration
+}
+
+func (j *Jenkins) SetRetryPolicy(attempts int, baseDelay time.Duration) *Jenkins {
+	j.Retry = RetryPolicy{Attempts: attempts, BaseDelay: baseDelay}
+	return j
+}
+
+func isTransientError(err error) bool {
+	if code, convErr := strconv.Atoi(err.Error()); convErr == nil {
+		return code >= http.StatusInternalServerError
+	}
+	switch e := err.(type) {
+	case *ErrorResponse:
+		return e.Response.StatusCode >= http.StatusInternalServerError
+	case *url.Error, net.Error:
+		return true
+	}
+	return false
+}
+
+// retryCredentialCall sends call until it succeeds, fails with an error that is not transient, or runs
+// out of attempts. done, when set, is checked before every retry: a call whose response was lost is
+// not sent again once its effect is visible, so a create is never sent twice for a created credential.
+// Retries stop when ctx is done.
+func (j *Jenkins) retryCredentialCall(ctx context.Context, call func() (*http.Response, error),
+	done func() bool) error {
+	delay := j.Retry.BaseDelay
+	for attempt := 1; ; attempt++ {
+		response, err := call()
+		if err == nil && response.StatusCode != http.StatusOK {
+			err = errors.New(strconv.Itoa(response.StatusCode))
+		}
+		if err == nil || attempt >= j.Retry.Attempts || !isTransientError(err) {
+			return err
+		}
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		delay *= 2
+		if done != nil && done() {
+			return nil
+		}
+	}
+}
+
+func (j *Jenkins) credentialExistsInFolder(ctx context.Context, domain, id string, folders ...string) bool {
+	_, err := j.GetCredentialInFolderContext(ctx, domain, id, folders...)
+	return err == nil
+}
+
+func (j *Jenkins) credentialDeletedInFolder(ctx context.Context, domain, id string, folders ...string) bool {
+	_, err := j.GetCredentialInFolderContext(ctx, domain, id, folders...)
+	return errors.Is(err, ErrCredentialNotFound)
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gojenkins
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// GetUserFullName returns the full name of the Jenkins user id.
+func (j *Jenkins) GetUserFullName(id string) (string, error) {
+	var responseStruct = &struct {
+		FullName string `json:"fullName"`
+	}{}
+	response, err := j.Requester.GetJSON("/user/"+url.PathEscape(id)+"/api/json", responseStruct,
+		map[string]string{
+			"tree": "fullName",
+		})
+	if err != nil {
+		return "", err
+	}
+	if response.StatusCode != http.StatusOK {
+		return "", errors.New(strconv.Itoa(response.StatusCode))
+	}
+	return responseStruct.FullName, nil
+}
+
+// GetUserFullNames returns the full names of all the users known to Jenkins by their id in one call.
+func (j *Jenkins) GetUserFullNames() (map[string]string, error) {
+	var responseStruct = &struct {
+		Users []struct {
+			User struct {
+				Id       string `json:"id"`
+				FullName string `json:"fullName"`
+			} `json:"user"`
+		} `json:"users"`
+	}{}
+	response, err := j.Requester.GetJSON("/asynchPeople/api/json", responseStruct, map[string]string{
+		"tree": "users[user[id,fullName]]",
+	})
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.New(strconv.Itoa(response.StatusCode))
+	}
+	fullNames := make(map[string]string, len(responseStruct.Users))
+	for _, user := range responseStruct.Users {
+		fullNames[user.User.Id] = user.User.FullName
+	}
+	return fullNames, nil
+}
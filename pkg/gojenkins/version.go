@@ -0,0 +1,38 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gojenkins
+
+import (
+	"net/http"
+)
+
+// JenkinsVersionHeader is set by Jenkins on its responses.
+const JenkinsVersionHeader = "X-Jenkins"
+
+func (r *Requester) recordVersion(response *http.Response) {
+	if version := response.Header.Get(JenkinsVersionHeader); version != "" {
+		r.version.Store(version)
+	}
+}
+
+// GetVersion returns the Jenkins version of the last response from Jenkins, Jenkins may be upgraded
+// while the client runs. It falls back to the version seen by Init.
+func (j *Jenkins) GetVersion() string {
+	if j.Requester != nil {
+		if version, ok := j.Requester.version.Load().(string); ok {
+			return version
+		}
+	}
+	return j.Version
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logger
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Fields are logged after the message as key=value pairs sorted by key, so that log aggregators can
+// query them. Values with spaces, quotes or equal signs are quoted.
+type Fields map[string]interface{}
+
+func (fields Fields) String() string {
+	if len(fields) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var builder strings.Builder
+	for _, key := range keys {
+		builder.WriteString(" ")
+		builder.WriteString(key)
+		builder.WriteString("=")
+		builder.WriteString(formatFieldValue(fields[key]))
+	}
+	return builder.String()
+}
+
+func formatFieldValue(value interface{}) string {
+	str := fmt.Sprint(value)
+	if str == "" || strings.ContainsAny(str, " =\"\t\r\n") {
+		return strconv.Quote(str)
+	}
+	return str
+}
+
+// Entry logs messages with fields, the printf-style functions log the same messages without fields.
+// Entries are not changed by WithField and WithFields, which return new entries.
+type Entry struct {
+	requestId string
+	fields    Fields
+}
+
+// WithFields returns an entry logging fields.
+func WithFields(fields Fields) *Entry {
+	return (&Entry{}).WithFields(fields)
+}
+
+// WithField returns an entry logging the field key.
+func WithField(key string, value interface{}) *Entry {
+	return (&Entry{}).WithField(key, value)
+}
+
+// FromContext returns an entry logging the request id and the fields carried by ctx.
+func FromContext(ctx context.Context) *Entry {
+	return &Entry{requestId: GetRequestId(ctx), fields: contextFields(ctx)}
+}
+
+func (entry *Entry) WithFields(fields Fields) *Entry {
+	merged := make(Fields, len(entry.fields)+len(fields))
+	for key, value := range entry.fields {
+		merged[key] = value
+	}
+	for key, value := range fields {
+		merged[key] = value
+	}
+	return &Entry{requestId: entry.requestId, fields: merged}
+}
+
+func (entry *Entry) WithField(key string, value interface{}) *Entry {
+	return entry.WithFields(Fields{key: value})
+}
+
+// WithError returns an entry logging err as the error field.
+func (entry *Entry) WithError(err error) *Entry {
+	return entry.WithField("error", fmt.Sprintf("%+v", err))
+}
+
+func (entry *Entry) log(level Level, format string, v []interface{}) {
+	if logger.level() < level {
+		return
+	}
+	message := fmt.Sprintf(format, v...)
+	if entry.requestId != "" {
+		message = "[" + entry.requestId + "] " + message
+	}
+	logger.logf(level, "%s%s", message, entry.fields.String())
+}
+
+func (entry *Entry) Debug(format string, v ...interface{}) {
+	entry.log(DebugLevel, format, v)
+}
+
+func (entry *Entry) Info(format string, v ...interface{}) {
+	entry.log(InfoLevel, format, v)
+}
+
+func (entry *Entry) Warn(format string, v ...interface{}) {
+	entry.log(WarnLevel, format, v)
+}
+
+func (entry *Entry) Error(format string, v ...interface{}) {
+	entry.log(ErrorLevel, format, v)
+}
+
+func (entry *Entry) Critical(format string, v ...interface{}) {
+	entry.log(CriticalLevel, format, v)
+}
+
+type fieldsKey struct{}
+
+// ContextWithFields returns a copy of ctx carrying fields along with the fields ctx already carries, they
+// are logged by FromContext entries and by the functions logging with a context.
+func ContextWithFields(ctx context.Context, fields Fields) context.Context {
+	merged := make(Fields, len(fields))
+	for key, value := range contextFields(ctx) {
+		merged[key] = value
+	}
+	for key, value := range fields {
+		merged[key] = value
+	}
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+func contextFields(ctx context.Context) Fields {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(fieldsKey{}).(Fields)
+	return fields
+}
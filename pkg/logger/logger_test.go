@@ -15,6 +15,8 @@ package logger
 
 import (
 	"bytes"
+	"context"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -59,3 +61,54 @@ func TestLogger(t *testing.T) {
 	assert.Regexp(t, " -WARNING- \\(prefix\\)log_content\\(suffix\\)", log)
 	t.Log(log)
 }
+
+func TestLoggerContext(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger = NewLogger().WithDepth(4)
+	SetOutput(buf)
+
+	ErrorContext(context.Background(), "no request id [%d]", 1)
+	log := readBuf(buf)
+	assert.Regexp(t, " -ERROR- no request id \\[1\\] \\(logger_test.go:\\d+\\)", log)
+
+	ctx := WithRequestId(context.Background(), "req-1")
+	assert.Equal(t, "req-1", GetRequestId(ctx))
+	WarnContext(ctx, "with request id [%d]", 2)
+	log = readBuf(buf)
+	assert.Regexp(t, " -WARNING- \\[req-1\\] with request id \\[2\\] \\(logger_test.go:\\d+\\)", log)
+	t.Log(log)
+}
+
+func TestLoggerFields(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger = NewLogger().WithDepth(4)
+	SetOutput(buf)
+	SetLevelByString("info")
+
+	WithFields(Fields{"project": "project-1", "action": "create"}).Error("failed [%d]", 1)
+	log := readBuf(buf)
+	assert.Regexp(t, " -ERROR- failed \\[1\\] action=create project=project-1 \\(logger_test.go:\\d+\\)", log)
+
+	entry := WithField("operator", "admin")
+	entry.WithError(fmt.Errorf("not found")).WithField("empty", "").Warn("quoted")
+	log = readBuf(buf)
+	assert.Regexp(t, " -WARNING- quoted empty=\"\" error=\"not found\" operator=admin \\(logger_test.go:\\d+\\)", log)
+	entry.Info("unchanged")
+	assert.Regexp(t, " -INFO- unchanged operator=admin \\(logger_test.go:\\d+\\)", readBuf(buf))
+
+	entry.Debug("debug log, should ignore by default")
+	assert.Empty(t, readBuf(buf))
+
+	ctx := ContextWithFields(WithRequestId(context.Background(), "req-1"), Fields{"project": "project-1"})
+	ctx = ContextWithFields(ctx, Fields{"credential_id": "github"})
+	ErrorContext(ctx, "with fields [%d]", 2)
+	log = readBuf(buf)
+	assert.Regexp(t, " -ERROR- \\[req-1\\] with fields \\[2\\] credential_id=github project=project-1 "+
+		"\\(logger_test.go:\\d+\\)", log)
+
+	FromContext(ctx).WithField("result", "error").Warn("done")
+	log = readBuf(buf)
+	assert.Regexp(t, " -WARNING- \\[req-1\\] done credential_id=github project=project-1 result=error "+
+		"\\(logger_test.go:\\d+\\)", log)
+	t.Log(log)
+}
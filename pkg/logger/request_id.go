@@ -0,0 +1,57 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logger
+
+import (
+	"context"
+)
+
+// RequestIdHeader is the header a request id is accepted from and sent with.
+const RequestIdHeader = "X-Request-ID"
+
+type requestIdKey struct{}
+
+// WithRequestId returns a copy of ctx carrying requestId.
+func WithRequestId(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIdKey{}, requestId)
+}
+
+// GetRequestId returns the request id carried by ctx, or "" if there is none.
+func GetRequestId(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestId, _ := ctx.Value(requestIdKey{}).(string)
+	return requestId
+}
+
+// WarnContext logs at warn level, prefixed with the request id and followed by the fields carried by ctx.
+func WarnContext(ctx context.Context, format string, v ...interface{}) {
+	format, v = withRequestId(ctx, format, v)
+	logger.Warn(format+"%s", append(v, contextFields(ctx).String())...)
+}
+
+// ErrorContext logs at error level, prefixed with the request id and followed by the fields carried by ctx.
+func ErrorContext(ctx context.Context, format string, v ...interface{}) {
+	format, v = withRequestId(ctx, format, v)
+	logger.Error(format+"%s", append(v, contextFields(ctx).String())...)
+}
+
+func withRequestId(ctx context.Context, format string, v []interface{}) (string, []interface{}) {
+	requestId := GetRequestId(ctx)
+	if requestId == "" {
+		return format, v
+	}
+	return "[%s] " + format, append([]interface{}{requestId}, v...)
+}
@@ -4,20 +4,55 @@ import (
 	"time"
 
 	"github.com/asaskevich/govalidator"
+
+	"kubesphere.io/devops/pkg/db"
+	"kubesphere.io/devops/pkg/utils/idutils"
 )
 
 const (
-	ProjectCredentialTableName    = "project_credential"
-	ProjectCredentialIdColumn     = "credential_id"
-	ProjectCredentialDomainColumn = "domain"
+	ProjectCredentialTableName           = "project_credential"
+	ProjectCredentialPrefix              = "credential-"
+	ProjectCredentialIdColumn            = "credential_id"
+	ProjectCredentialDomainColumn        = "domain"
+	ProjectCredentialExpiresAtColumn     = "expires_at"
+	ProjectCredentialUuidColumn          = "uuid"
+	ProjectCredentialRegistryColumn      = "registry_url"
+	ProjectCredentialModifiedByColumn    = "modified_by"
+	ProjectCredentialModifiedTimeColumn  = "modified_time"
+	ProjectCredentialStoreColumn         = "store"
+	ProjectCredentialIntendedJobsColumn  = "intended_jobs"
+	ProjectCredentialServerUrlColumn     = "server_url"
+	ProjectCredentialKeyAlgorithmColumn  = "key_algorithm"
+	ProjectCredentialKeyBitsColumn       = "key_bits"
+	ProjectCredentialStrengthScoreColumn = "strength_score"
+
+	// the Jenkins credential store a credential is created in
+	CredentialStoreFolder = "folder"
+	CredentialStoreGlobal = "global"
+
+	// columns encrypted at rest when encryption is enabled, with registry_url, server_url and modified_by
+	ProjectCredentialCreatorColumn        = "creator"
+	ProjectCredentialStrengthReasonColumn = "strength_reason"
 )
 
 type ProjectCredential struct {
-	ProjectId    string    `json:"project_id"`
-	CredentialId string    `json:"credential_id"`
-	Domain       string    `json:"domain"`
-	Creator      string    `json:"creator"`
-	CreateTime   time.Time `json:"create_time"`
+	ProjectId      string             `json:"project_id"`
+	CredentialId   string             `json:"credential_id"`
+	Domain         string             `json:"domain"`
+	Creator        db.EncryptedString `json:"creator"`
+	CreateTime     time.Time          `json:"create_time"`
+	ModifiedBy     db.EncryptedString `json:"modified_by"`
+	ModifiedTime   time.Time          `json:"modified_time"`
+	ExpiresAt      *time.Time         `json:"expires_at"`
+	Uuid           string             `json:"uuid"`
+	StrengthScore  *int               `json:"strength_score"`
+	StrengthReason db.EncryptedString `json:"strength_reason"`
+	RegistryUrl    db.EncryptedString `json:"registry_url"`
+	Store          string             `json:"store"`
+	IntendedJobs   string             `json:"intended_jobs"`
+	ServerUrl      db.EncryptedString `json:"server_url"`
+	KeyAlgorithm   string             `json:"key_algorithm"`
+	KeyBits        *int               `json:"key_bits"`
 }
 
 var ProjectCredentialColumns = GetColumnsFromStruct(&ProjectCredential{})
@@ -26,11 +61,16 @@ func NewProjectCredential(projectId, credentialId, domain, creator string) *Proj
 	if govalidator.IsNull(domain) {
 		domain = "_"
 	}
+	now := time.Now()
 	return &ProjectCredential{
 		ProjectId:    projectId,
 		CredentialId: credentialId,
 		Domain:       domain,
-		Creator:      creator,
-		CreateTime:   time.Now(),
+		Creator:      db.EncryptedString(creator),
+		CreateTime:   now,
+		ModifiedBy:   db.EncryptedString(creator),
+		ModifiedTime: now,
+		Uuid:         idutils.GetUuid(ProjectCredentialPrefix),
+		Store:        CredentialStoreFolder,
 	}
 }
@@ -0,0 +1,68 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/asaskevich/govalidator"
+
+	"kubesphere.io/devops/pkg/utils/idutils"
+)
+
+const (
+	CredentialAuditLogTableName        = "project_credential_audit_log"
+	CredentialAuditLogPrefix           = "audit-"
+	CredentialAuditLogIdColumn         = "audit_id"
+	CredentialAuditLogCreateTimeColumn = "create_time"
+)
+
+// the severities of audited operations, break-glass operations are critical
+const (
+	CredentialAuditSeverityInfo     = "info"
+	CredentialAuditSeverityCritical = "critical"
+)
+
+// CredentialAuditLog records who performed an operation on a credential, when, and the status it was answered with.
+// Reason is the justification given for break-glass operations.
+type CredentialAuditLog struct {
+	AuditId      string    `json:"audit_id"`
+	ProjectId    string    `json:"project_id"`
+	CredentialId string    `json:"credential_id"`
+	Domain       string    `json:"domain"`
+	Operator     string    `json:"operator"`
+	Action       string    `json:"action"`
+	Status       int       `json:"status"`
+	Severity     string    `json:"severity"`
+	Reason       string    `json:"reason,omitempty"`
+	CreateTime   time.Time `json:"create_time"`
+}
+
+var CredentialAuditLogColumns = GetColumnsFromStruct(&CredentialAuditLog{})
+
+func NewCredentialAuditLog(projectId, credentialId, domain, operator, action string) *CredentialAuditLog {
+	if govalidator.IsNull(domain) {
+		domain = "_"
+	}
+	return &CredentialAuditLog{
+		AuditId:      idutils.GetUuid(CredentialAuditLogPrefix),
+		ProjectId:    projectId,
+		CredentialId: credentialId,
+		Domain:       domain,
+		Operator:     operator,
+		Action:       action,
+		Severity:     CredentialAuditSeverityInfo,
+		CreateTime:   time.Now(),
+	}
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/asaskevich/govalidator"
+)
+
+const (
+	ProjectCredentialDeletionTableName = "project_credential_deletion"
+)
+
+// ProjectCredentialDeletion records the last deletion of a credential id in a domain.
+type ProjectCredentialDeletion struct {
+	ProjectId    string    `json:"project_id"`
+	CredentialId string    `json:"credential_id"`
+	Domain       string    `json:"domain"`
+	Operator     string    `json:"operator"`
+	DeleteTime   time.Time `json:"delete_time"`
+}
+
+var ProjectCredentialDeletionColumns = GetColumnsFromStruct(&ProjectCredentialDeletion{})
+
+func NewProjectCredentialDeletion(projectId, credentialId, domain, operator string) *ProjectCredentialDeletion {
+	if govalidator.IsNull(domain) {
+		domain = "_"
+	}
+	return &ProjectCredentialDeletion{
+		ProjectId:    projectId,
+		CredentialId: credentialId,
+		Domain:       domain,
+		Operator:     operator,
+		DeleteTime:   time.Now(),
+	}
+}
@@ -0,0 +1,37 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+const (
+	ProjectCredentialDescriptionTemplateTableName = "project_credential_description_template"
+	ProjectCredentialTypeColumn                   = "credential_type"
+)
+
+// ProjectCredentialDescriptionTemplate is the description given to credentials of a type
+// created without one.
+type ProjectCredentialDescriptionTemplate struct {
+	ProjectId      string `json:"project_id"`
+	CredentialType string `json:"credential_type"`
+	Template       string `json:"template"`
+}
+
+var ProjectCredentialDescriptionTemplateColumns = GetColumnsFromStruct(&ProjectCredentialDescriptionTemplate{})
+
+func NewProjectCredentialDescriptionTemplate(projectId, credentialType, template string) *ProjectCredentialDescriptionTemplate {
+	return &ProjectCredentialDescriptionTemplate{
+		ProjectId:      projectId,
+		CredentialType: credentialType,
+		Template:       template,
+	}
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/asaskevich/govalidator"
+)
+
+const (
+	ProjectCredentialIdempotencyKeyTableName        = "project_credential_idempotency_key"
+	ProjectCredentialIdempotencyKeyColumn           = "idempotency_key"
+	ProjectCredentialIdempotencyKeyExpireTimeColumn = "expire_time"
+)
+
+// ProjectCredentialIdempotencyKey remembers the credential created by a request with an idempotency key
+// until ExpireTime, so retries of the request get the credential instead of a conflict.
+// RequestHash is the hash of the request payload, a request reusing the key with another payload is rejected.
+type ProjectCredentialIdempotencyKey struct {
+	ProjectId      string    `json:"project_id"`
+	IdempotencyKey string    `json:"idempotency_key"`
+	CredentialId   string    `json:"credential_id"`
+	Domain         string    `json:"domain"`
+	RequestHash    string    `json:"request_hash"`
+	CreateTime     time.Time `json:"create_time"`
+	ExpireTime     time.Time `json:"expire_time"`
+}
+
+var ProjectCredentialIdempotencyKeyColumns = GetColumnsFromStruct(&ProjectCredentialIdempotencyKey{})
+
+func NewProjectCredentialIdempotencyKey(projectId, idempotencyKey, credentialId, domain, requestHash string,
+	window time.Duration) *ProjectCredentialIdempotencyKey {
+	if govalidator.IsNull(domain) {
+		domain = "_"
+	}
+	now := time.Now()
+	return &ProjectCredentialIdempotencyKey{
+		ProjectId:      projectId,
+		IdempotencyKey: idempotencyKey,
+		CredentialId:   credentialId,
+		Domain:         domain,
+		RequestHash:    requestHash,
+		CreateTime:     now,
+		ExpireTime:     now.Add(window),
+	}
+}
@@ -0,0 +1,36 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+const (
+	ProjectCredentialPolicyTableName = "project_credential_policy"
+)
+
+// ProjectCredentialPolicy holds the credential rules of a project. RecreateCooldown is the number of
+// seconds after a deletion during which the credential id can not be created again, 0 disables it.
+type ProjectCredentialPolicy struct {
+	ProjectId                string `json:"project_id"`
+	PreCreateWebhook         bool   `json:"pre_create_webhook"`
+	RequireVerification      bool   `json:"require_verification"`
+	ContentValidationWebhook bool   `json:"content_validation_webhook"`
+	RecreateCooldown         int    `json:"recreate_cooldown"`
+}
+
+var ProjectCredentialPolicyColumns = GetColumnsFromStruct(&ProjectCredentialPolicy{})
+
+func NewProjectCredentialPolicy(projectId string) *ProjectCredentialPolicy {
+	return &ProjectCredentialPolicy{
+		ProjectId: projectId,
+	}
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/asaskevich/govalidator"
+
+	"kubesphere.io/devops/pkg/utils/idutils"
+)
+
+const (
+	ProjectCredentialRotationTableName = "project_credential_rotation"
+	ProjectCredentialRotationPrefix    = "rotation-"
+	ProjectCredentialRotateTimeColumn  = "rotate_time"
+)
+
+// ProjectCredentialRotation records who rotated the secret of a credential and when.
+type ProjectCredentialRotation struct {
+	RotationId   string    `json:"rotation_id"`
+	ProjectId    string    `json:"project_id"`
+	CredentialId string    `json:"credential_id"`
+	Domain       string    `json:"domain"`
+	Operator     string    `json:"operator"`
+	RotateTime   time.Time `json:"rotate_time"`
+}
+
+var ProjectCredentialRotationColumns = GetColumnsFromStruct(&ProjectCredentialRotation{})
+
+func NewProjectCredentialRotation(projectId, credentialId, domain, operator string) *ProjectCredentialRotation {
+	if govalidator.IsNull(domain) {
+		domain = "_"
+	}
+	return &ProjectCredentialRotation{
+		RotationId:   idutils.GetUuid(ProjectCredentialRotationPrefix),
+		ProjectId:    projectId,
+		CredentialId: credentialId,
+		Domain:       domain,
+		Operator:     operator,
+		RotateTime:   time.Now(),
+	}
+}
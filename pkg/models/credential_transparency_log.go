@@ -0,0 +1,42 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"time"
+)
+
+const (
+	ProjectCredentialTransparencyLogTableName = "project_credential_transparency_log"
+	ProjectCredentialTransparencyLogSeqColumn = "seq"
+)
+
+// ProjectCredentialTransparencyLog is an entry of the hash chained log of credential mutations of a project.
+// Hash covers the entry and PrevHash, the hash of the previous entry, so altering or removing an entry
+// breaks the chain. Signature is the HMAC of Hash when a signing key is configured.
+type ProjectCredentialTransparencyLog struct {
+	ProjectId    string    `json:"project_id"`
+	Seq          int64     `json:"seq"`
+	CredentialId string    `json:"credential_id"`
+	Domain       string    `json:"domain"`
+	Operation    string    `json:"operation"`
+	Operator     string    `json:"operator"`
+	ContentHash  string    `json:"content_hash"`
+	CreateTime   time.Time `json:"create_time"`
+	PrevHash     string    `json:"prev_hash"`
+	Hash         string    `json:"hash"`
+	Signature    string    `json:"signature"`
+}
+
+var ProjectCredentialTransparencyLogColumns = GetColumnsFromStruct(&ProjectCredentialTransparencyLog{})
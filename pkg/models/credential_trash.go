@@ -0,0 +1,66 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/asaskevich/govalidator"
+
+	"kubesphere.io/devops/pkg/utils/idutils"
+)
+
+const (
+	ProjectCredentialTrashTableName        = "project_credential_trash"
+	ProjectCredentialTrashPrefix           = "trash-"
+	ProjectCredentialTrashIdColumn         = "trash_id"
+	ProjectCredentialTrashExpireTimeColumn = "expire_time"
+)
+
+// ProjectCredentialTrash keeps the content of a deleted credential, encrypted, until ExpireTime
+// so the credential can be restored.
+type ProjectCredentialTrash struct {
+	TrashId      string    `json:"trash_id"`
+	ProjectId    string    `json:"project_id"`
+	CredentialId string    `json:"credential_id"`
+	Domain       string    `json:"domain"`
+	Type         string    `json:"type"`
+	Store        string    `json:"store"`
+	Content      string    `json:"-"`
+	Operator     string    `json:"operator"`
+	DeleteTime   time.Time `json:"delete_time"`
+	ExpireTime   time.Time `json:"expire_time"`
+}
+
+var ProjectCredentialTrashColumns = GetColumnsFromStruct(&ProjectCredentialTrash{})
+
+func NewProjectCredentialTrash(projectId, credentialId, domain, credentialType, content, operator string,
+	retention time.Duration) *ProjectCredentialTrash {
+	if govalidator.IsNull(domain) {
+		domain = "_"
+	}
+	now := time.Now()
+	return &ProjectCredentialTrash{
+		TrashId:      idutils.GetUuid(ProjectCredentialTrashPrefix),
+		ProjectId:    projectId,
+		CredentialId: credentialId,
+		Domain:       domain,
+		Type:         credentialType,
+		Store:        CredentialStoreFolder,
+		Content:      content,
+		Operator:     operator,
+		DeleteTime:   now,
+		ExpireTime:   now.Add(retention),
+	}
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/asaskevich/govalidator"
+)
+
+const (
+	ProjectCredentialUsageThresholdTableName               = "project_credential_usage_threshold"
+	ProjectCredentialUsageThresholdLastUsageColumn         = "last_usage"
+	ProjectCredentialUsageThresholdLastDeltaColumn         = "last_delta"
+	ProjectCredentialUsageThresholdLastEvaluatedTimeColumn = "last_evaluated_time"
+)
+
+// ProjectCredentialUsageThreshold holds the usage alerting thresholds of a credential.
+// Usage is the number of builds recorded by the credential fingerprint, LastUsage and
+// LastDelta are the values seen by the previous evaluation.
+type ProjectCredentialUsageThreshold struct {
+	ProjectId         string     `json:"project_id"`
+	CredentialId      string     `json:"credential_id"`
+	Domain            string     `json:"domain"`
+	SpikeThreshold    int        `json:"spike_threshold"`
+	ZeroAlert         bool       `json:"zero_alert"`
+	LastUsage         *int       `json:"last_usage"`
+	LastDelta         *int       `json:"last_delta"`
+	LastEvaluatedTime *time.Time `json:"last_evaluated_time"`
+}
+
+var ProjectCredentialUsageThresholdColumns = GetColumnsFromStruct(&ProjectCredentialUsageThreshold{})
+
+func NewProjectCredentialUsageThreshold(projectId, credentialId, domain string) *ProjectCredentialUsageThreshold {
+	if govalidator.IsNull(domain) {
+		domain = "_"
+	}
+	return &ProjectCredentialUsageThreshold{
+		ProjectId:    projectId,
+		CredentialId: credentialId,
+		Domain:       domain,
+	}
+}
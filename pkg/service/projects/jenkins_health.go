@@ -0,0 +1,41 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"net/http"
+
+	"github.com/ant0ine/go-json-rest/rest"
+
+	"kubesphere.io/devops/pkg/logger"
+)
+
+type JenkinsHealthResponse struct {
+	Healthy bool   `json:"healthy"`
+	Version string `json:"version,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// JenkinsHealthHandler is a readiness probe checking Jenkins can be reached and authenticated to.
+func (s *ProjectService) JenkinsHealthHandler(w rest.ResponseWriter, r *rest.Request) {
+	version, err := s.Ds.Jenkins.Ping(s.Config.Jenkins.HealthTimeout)
+	if err != nil {
+		logger.WarnContext(r.Context(), "jenkins is not healthy: %+v", err)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.WriteJson(&JenkinsHealthResponse{Reason: err.Error()})
+		return
+	}
+	w.WriteJson(&JenkinsHealthResponse{Healthy: true, Version: version})
+	return
+}
@@ -29,35 +29,35 @@ func (s *ProjectService) GetPipelineSonarHandler(w rest.ResponseWriter, r *rest.
 	projectId := r.PathParams["id"]
 	pipelineId := r.PathParams["pid"]
 	operator := userutils.GetUserNameFromRequest(r)
-	err := s.checkProjectUserInRole(operator, projectId, AllRoleSlice)
+	err := s.checkProjectUserInRole(r, operator, projectId, AllRoleSlice)
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusForbidden)
 		return
 	}
 	job, err := s.Ds.Jenkins.GetJob(pipelineId, projectId)
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 		return
 	}
 	build, err := job.GetLastBuild()
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 		return
 	}
 
 	sonarStatus, err := s.getBuildSonarResults(build)
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	if len(sonarStatus) == 0 {
 		build, err := job.GetLastCompletedBuild()
 		if err != nil && stringutils.GetJenkinsStatusCode(err) != http.StatusNotFound {
-			logger.Error("%+v", err)
+			logger.ErrorContext(r.Context(), "%+v", err)
 			rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 			return
 		}
@@ -73,28 +73,28 @@ func (s *ProjectService) GetMultiBranchPipelineSonarHandler(w rest.ResponseWrite
 	pipelineId := r.PathParams["pid"]
 	branchName := r.PathParams["bid"]
 	operator := userutils.GetUserNameFromRequest(r)
-	err := s.checkProjectUserInRole(operator, projectId, AllRoleSlice)
+	err := s.checkProjectUserInRole(r, operator, projectId, AllRoleSlice)
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusForbidden)
 		return
 	}
 	job, err := s.Ds.Jenkins.GetJob(branchName, projectId, pipelineId)
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 		return
 	}
 	build, err := job.GetLastBuild()
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 		return
 	}
 
 	sonarStatus, err := s.getBuildSonarResults(build)
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -102,7 +102,7 @@ func (s *ProjectService) GetMultiBranchPipelineSonarHandler(w rest.ResponseWrite
 	if len(sonarStatus) == 0 {
 		build, err := job.GetLastCompletedBuild()
 		if err != nil && stringutils.GetJenkinsStatusCode(err) != http.StatusNotFound {
-			logger.Error("%+v", err)
+			logger.ErrorContext(r.Context(), "%+v", err)
 			rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 			return
 		}
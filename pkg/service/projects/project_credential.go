@@ -14,8 +14,24 @@ limitations under the License.
 package projects
 
 import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/asaskevich/govalidator"
+	"github.com/beevik/etree"
+	"github.com/gocraft/dbr"
+
+	"kubesphere.io/devops/pkg/db"
 	"kubesphere.io/devops/pkg/gojenkins"
+	"kubesphere.io/devops/pkg/logger"
 	"kubesphere.io/devops/pkg/models"
+	"kubesphere.io/devops/pkg/utils/reflectutils"
 )
 
 var CredentialTypeMap = map[string]string{
@@ -23,6 +39,243 @@ var CredentialTypeMap = map[string]string{
 	"Username with password":                CredentialTypeUsernamePassword,
 	"Secret text":                           CredentialTypeSecretText,
 	"Kubernetes configuration (kubeconfig)": CredentialTypeKubeConfig,
+	"Certificate":                           CredentialTypeCertificate,
+	"AWS Credentials":                       CredentialTypeAWS,
+	"Secret file":                           CredentialTypeSecretFile,
+
+	// token credential of the OpenShift client plugin
+	"OpenShift Token for OpenShift Client Plugin": CredentialTypeOpenShiftToken,
+}
+
+var CredentialScopes = []string{gojenkins.GLOBALScope, gojenkins.SYSTEMScope}
+
+// normalizeCredentialScope upper cases the optional scope of a credential content and checks it is
+// one of CredentialScopes, credentials without scope are created GLOBAL.
+func normalizeCredentialScope(content map[string]interface{}) error {
+	value, ok := content["scope"]
+	if !ok || value == nil {
+		return nil
+	}
+	scope, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("error scope should be one of %s", CredentialScopes)
+	}
+	scope = strings.ToUpper(strings.TrimSpace(scope))
+	if govalidator.IsNull(scope) {
+		delete(content, "scope")
+		return nil
+	}
+	if !reflectutils.In(scope, CredentialScopes) {
+		return fmt.Errorf("error scope [%s] not in %s", scope, CredentialScopes)
+	}
+	content["scope"] = scope
+	return nil
+}
+
+var xmlVersionReplacer = strings.NewReplacer("<?xml version='1.1'", "<?xml version='1.0'",
+	`<?xml version="1.1"`, `<?xml version="1.0"`)
+
+// readJenkinsXml parses a config.xml from Jenkins, which declares XML 1.1
+// that encoding/xml refuses although the content is valid XML 1.0.
+func readJenkinsXml(configXml string) (*etree.Document, error) {
+	doc := etree.NewDocument()
+	err := doc.ReadFromString(xmlVersionReplacer.Replace(configXml))
+	if err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// parseCredentialScope reads the scope from a credential config.xml,
+// credentials without scope element are treated as GLOBAL like Jenkins does.
+func parseCredentialScope(configXml string) (string, error) {
+	doc, err := readJenkinsXml(configXml)
+	if err != nil {
+		return "", err
+	}
+	if doc.Root() == nil {
+		return gojenkins.GLOBALScope, nil
+	}
+	scope := doc.Root().SelectElement("scope")
+	if scope == nil || strings.TrimSpace(scope.Text()) == "" {
+		return gojenkins.GLOBALScope, nil
+	}
+	return strings.TrimSpace(scope.Text()), nil
+}
+
+// config.xml of credentials read from Jenkins at once to fill their scope
+const credentialScopeConcurrency = 10
+
+// fillCredentialsScope fetches the scope of every credential from Jenkins, credentialScopeConcurrency at once.
+// Credentials whose config can not be read keep an empty scope, they are named by the returned warnings.
+func (s *ProjectService) fillCredentialsScope(projectId string, credentials []*CredentialResponse) []string {
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	unknown := make([]string, 0)
+	tokens := make(chan struct{}, credentialScopeConcurrency)
+	for _, credential := range credentials {
+		wg.Add(1)
+		tokens <- struct{}{}
+		go func(credential *CredentialResponse) {
+			defer func() {
+				<-tokens
+				wg.Done()
+			}()
+			configXml, err := s.credentialStore().GetCredentialConfigInFolder(credential.Domain, credential.Id, projectId)
+			if err == nil {
+				credential.Scope, err = parseCredentialScope(configXml)
+			}
+			if err != nil {
+				logger.Warn("failed to read scope of credential [%s]: %+v", credential.Id, err)
+				lock.Lock()
+				unknown = append(unknown, credential.Id)
+				lock.Unlock()
+			}
+		}(credential)
+	}
+	wg.Wait()
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+	return []string{fmt.Sprintf("scope of credentials %s can not be read, they are listed without scope", unknown)}
+}
+
+// listCredentials merges the credentials in the project folder with their metadata in db,
+// an empty domain means all domains.
+func (s *ProjectService) listCredentials(projectId, domain string) ([]*CredentialResponse, error) {
+	jenkinsCredentialResponses, err := s.getCredentialsInFolder(projectId, domain)
+	if err != nil {
+		return nil, err
+	}
+	projectCredentials, err := s.loadProjectCredentials(projectId, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	response := formatCredentialsResponse(jenkinsCredentialResponses, projectCredentials)
+	// the warnings are logged, callers do not list credentials by scope
+	s.fillCredentialsScope(projectId, response)
+	return response, nil
+}
+
+// setCredentialListWarnings adds the warnings of a degraded credential list as Warning headers with the
+// miscellaneous warning code 199, the body of the list keeps its shape.
+func setCredentialListWarnings(w rest.ResponseWriter, warnings []string) {
+	for _, warning := range warnings {
+		w.Header().Add("Warning", fmt.Sprintf("199 devops %q", warning))
+	}
+}
+
+const credentialMetadataUnavailableWarning = "credential metadata is unavailable, credentials are listed " +
+	"without their uuid, creator, modification, expiry and registry url"
+
+// loadCredentialsMetadata loads the records of the credentials of the project for a list, which only
+// lacks their metadata when the records can not be loaded: the records are nil with a warning then.
+func (s *ProjectService) loadCredentialsMetadata(projectId, domain string) ([]*models.ProjectCredential, []string) {
+	projectCredentials, err := s.loadProjectCredentials(projectId, domain)
+	if err != nil {
+		logger.Error("failed to load credential metadata of project [%s]: %+v", projectId, err)
+		return nil, []string{credentialMetadataUnavailableWarning}
+	}
+	return projectCredentials, nil
+}
+
+// listCredentialsWithWarnings lists the credentials like listCredentials, only failing to list them in
+// Jenkins is an error, credentials whose records can not be loaded are listed with warnings.
+func (s *ProjectService) listCredentialsWithWarnings(projectId, domain string) ([]*CredentialResponse, []string,
+	error) {
+	jenkinsCredentialResponses, err := s.getCredentialsInFolder(projectId, domain)
+	if err != nil {
+		return nil, nil, err
+	}
+	projectCredentials, warnings := s.loadCredentialsMetadata(projectId, domain)
+
+	response := formatCredentialsResponse(jenkinsCredentialResponses, projectCredentials)
+	warnings = append(warnings, s.fillCredentialsScope(projectId, response)...)
+	return response, warnings, nil
+}
+
+// loadProjectCredentials loads the records of the credentials of the project, an empty domain means all domains.
+func (s *ProjectService) loadProjectCredentials(projectId, domain string) ([]*models.ProjectCredential, error) {
+	selectCondition := db.Eq(models.ProjectIdColumn, projectId)
+	if !govalidator.IsNull(domain) {
+		selectCondition = db.And(selectCondition, db.Eq(models.ProjectCredentialDomainColumn, domain))
+	}
+	projectCredentials := make([]*models.ProjectCredential, 0)
+	_, err := s.Ds.Db.Select(models.ProjectCredentialColumns...).
+		From(models.ProjectCredentialTableName).Where(selectCondition).Load(&projectCredentials)
+	if err != nil {
+		return nil, err
+	}
+	return projectCredentials, nil
+}
+
+// parseCredentialTypes parses a comma separated list of credential types, the types of
+// CredentialTypeMap and the ones resolved from db records.
+func parseCredentialTypes(types string) ([]string, error) {
+	validTypes := []string{CredentialTypeDockerRegistry}
+	for _, credentialType := range CredentialTypeMap {
+		validTypes = append(validTypes, credentialType)
+	}
+	parsed := make([]string, 0)
+	for _, credentialType := range strings.Split(types, ",") {
+		credentialType = strings.TrimSpace(credentialType)
+		if govalidator.IsNull(credentialType) {
+			continue
+		}
+		if !reflectutils.In(credentialType, validTypes) {
+			sort.Strings(validTypes)
+			return nil, fmt.Errorf("error credential type [%s] not in %s", credentialType, validTypes)
+		}
+		parsed = append(parsed, credentialType)
+	}
+	return parsed, nil
+}
+
+func filterCredentialsByType(credentials []*CredentialResponse, types []string) []*CredentialResponse {
+	filtered := make([]*CredentialResponse, 0)
+	for _, credential := range credentials {
+		if reflectutils.In(credential.Type, types) {
+			filtered = append(filtered, credential)
+		}
+	}
+	return filtered
+}
+
+// filterCredentialsByScope keeps the credentials with scope, and those whose scope could not be read
+// which may have it.
+func filterCredentialsByScope(credentials []*CredentialResponse, scope string) []*CredentialResponse {
+	filtered := make([]*CredentialResponse, 0)
+	for _, credential := range credentials {
+		if credential.Scope == scope || credential.Scope == "" {
+			filtered = append(filtered, credential)
+		}
+	}
+	return filtered
+}
+
+// filterCredentialsByQuery keeps the credentials whose id or description contains q, ignoring case.
+// A q with * matches the whole id or description, * matching any characters, e.g. "git*" matches ids
+// starting with git.
+func filterCredentialsByQuery(credentials []*CredentialResponse, q string) []*CredentialResponse {
+	q = strings.ToLower(q)
+	match := func(value string) bool {
+		return strings.Contains(strings.ToLower(value), q)
+	}
+	if strings.Contains(q, "*") {
+		pattern := regexp.MustCompile("^(?s)" + strings.Replace(regexp.QuoteMeta(q), `\*`, ".*", -1) + "$")
+		match = func(value string) bool {
+			return pattern.MatchString(strings.ToLower(value))
+		}
+	}
+	filtered := make([]*CredentialResponse, 0)
+	for _, credential := range credentials {
+		if match(credential.Id) || match(credential.Description) {
+			filtered = append(filtered, credential)
+		}
+	}
+	return filtered
 }
 
 func formatCredentialResponse(
@@ -56,30 +309,436 @@ func formatCredentialResponse(
 
 	if dbCredentialResponse != nil {
 		response.CreateTime = &dbCredentialResponse.CreateTime
-		response.Creator = dbCredentialResponse.Creator
+		response.Creator = string(dbCredentialResponse.Creator)
+		response.ModifiedBy = string(dbCredentialResponse.ModifiedBy)
+		response.ModifiedTime = &dbCredentialResponse.ModifiedTime
+		response.ExpiresAt = dbCredentialResponse.ExpiresAt
+		response.Uuid = dbCredentialResponse.Uuid
+		response.IntendedJobs = parseIntendedJobs(dbCredentialResponse)
+		if dbCredentialResponse.StrengthScore != nil {
+			response.Strength = &CredentialStrength{
+				Score:  *dbCredentialResponse.StrengthScore,
+				Reason: string(dbCredentialResponse.StrengthReason),
+			}
+		}
+		if dbCredentialResponse.KeyBits != nil {
+			response.KeySize = &CredentialKeySize{
+				Algorithm: dbCredentialResponse.KeyAlgorithm,
+				Bits:      *dbCredentialResponse.KeyBits,
+			}
+		}
 	}
 
-	credentialType, ok := CredentialTypeMap[jenkinsCredentialResponse.TypeName]
-	if ok {
-		response.Type = credentialType
-		return response
-	}
-	response.Type = jenkinsCredentialResponse.TypeName
+	// credentials imported into Jenkins have no record and so no create time, this tells at least
+	// when they were first used
+	response.FirstUsedTime = jenkinsCredentialResponse.FirstUsedTime()
+	response.Type = resolveCredentialType(jenkinsCredentialResponse.TypeName, dbCredentialResponse)
 	return response
 }
 
+// resolveCredentialType maps the Jenkins type name of a credential to its type, docker registry
+// credentials are username password credentials in Jenkins and are told apart by their registry in db.
+// Unknown type names are returned as they are.
+func resolveCredentialType(typeName string, projectCredential *models.ProjectCredential) string {
+	credentialType, ok := CredentialTypeMap[typeName]
+	if !ok {
+		return typeName
+	}
+	if credentialType == CredentialTypeUsernamePassword &&
+		projectCredential != nil && !govalidator.IsNull(string(projectCredential.RegistryUrl)) {
+		return CredentialTypeDockerRegistry
+	}
+	return credentialType
+}
+
+// getProjectCredential returns the db record of the credential, nil if it has none.
+func (s *ProjectService) getProjectCredential(projectId, domain, credentialId string) (*models.ProjectCredential, error) {
+	if govalidator.IsNull(domain) {
+		domain = "_"
+	}
+	projectCredential := &models.ProjectCredential{}
+	err := s.Ds.Db.Select(models.ProjectCredentialColumns...).
+		From(models.ProjectCredentialTableName).
+		Where(db.And(db.Eq(models.ProjectIdColumn, projectId),
+			db.Eq(models.ProjectCredentialIdColumn, credentialId),
+			db.Eq(models.ProjectCredentialDomainColumn, domain))).
+		LoadOne(projectCredential)
+	if err == db.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return projectCredential, nil
+}
+
+// getCredentialType resolves the type of a credential read from Jenkins with its db record.
+func (s *ProjectService) getCredentialType(projectId string, credential *gojenkins.CredentialResponse) (string, error) {
+	projectCredential, err := s.getProjectCredential(projectId, credential.Domain, credential.Id)
+	if err != nil {
+		return "", err
+	}
+	return resolveCredentialType(credential.TypeName, projectCredential), nil
+}
+
+// markCredentialModified records operator as the last modifier of the credential, credentials created
+// outside of devops have no record to update.
+func (s *ProjectService) markCredentialModified(projectId, domain, credentialId, operator string) {
+	if govalidator.IsNull(domain) {
+		domain = "_"
+	}
+	_, err := s.Ds.Db.Update(models.ProjectCredentialTableName).
+		Set(models.ProjectCredentialModifiedByColumn, db.EncryptedString(operator)).
+		Set(models.ProjectCredentialModifiedTimeColumn, time.Now()).
+		Where(db.And(db.Eq(models.ProjectIdColumn, projectId),
+			db.Eq(models.ProjectCredentialIdColumn, credentialId),
+			db.Eq(models.ProjectCredentialDomainColumn, domain))).Exec()
+	if err != nil && err != db.ErrNotFound {
+		logger.Error("failed to record modification of credential [%s] by [%s]: %+v", credentialId, operator, err)
+	}
+}
+
+func validateRegistryUrl(registryUrl string) error {
+	if govalidator.IsNull(registryUrl) || !govalidator.IsURL(registryUrl) {
+		return fmt.Errorf("registry_url [%s] should be the url of a docker registry", registryUrl)
+	}
+	return nil
+}
+
+// updateRegistryUrl records the registry of a docker registry credential, an empty registry keeps the current one.
+func (s *ProjectService) updateRegistryUrl(projectId, domain, credentialId, registryUrl string) error {
+	if govalidator.IsNull(registryUrl) {
+		return nil
+	}
+	if govalidator.IsNull(domain) {
+		domain = "_"
+	}
+	_, err := s.Ds.Db.Update(models.ProjectCredentialTableName).
+		Set(models.ProjectCredentialRegistryColumn, db.EncryptedString(registryUrl)).
+		Where(db.And(db.Eq(models.ProjectIdColumn, projectId),
+			db.Eq(models.ProjectCredentialIdColumn, credentialId),
+			db.Eq(models.ProjectCredentialDomainColumn, domain))).Exec()
+	return err
+}
+
+// credentialKey identifies a credential within a project.
+type credentialKey struct {
+	id     string
+	domain string
+}
+
+// formatCredentialsResponse merges the Jenkins credentials with their db records, in the order of the
+// Jenkins credentials. The last record of a credential is used when it has several.
 func formatCredentialsResponse(jenkinsCredentialsResponse []*gojenkins.CredentialResponse,
 	projectCredentials []*models.ProjectCredential) []*CredentialResponse {
-	responseSlice := make([]*CredentialResponse, 0)
+	dbCredentials := indexProjectCredentials(projectCredentials)
+	responseSlice := make([]*CredentialResponse, 0, len(jenkinsCredentialsResponse))
 	for _, jenkinsCredential := range jenkinsCredentialsResponse {
-		var dbCredential *models.ProjectCredential = nil
-		for _, projectCredential := range projectCredentials {
-			if projectCredential.CredentialId == jenkinsCredential.Id &&
-				projectCredential.Domain == jenkinsCredential.Domain {
-				dbCredential = projectCredential
-			}
-		}
+		dbCredential := dbCredentials[credentialKey{id: jenkinsCredential.Id, domain: jenkinsCredential.Domain}]
 		responseSlice = append(responseSlice, formatCredentialResponse(jenkinsCredential, dbCredential))
 	}
 	return responseSlice
 }
+
+func indexProjectCredentials(projectCredentials []*models.ProjectCredential) map[credentialKey]*models.ProjectCredential {
+	dbCredentials := make(map[credentialKey]*models.ProjectCredential, len(projectCredentials))
+	for _, projectCredential := range projectCredentials {
+		dbCredentials[credentialKey{id: projectCredential.CredentialId, domain: projectCredential.Domain}] =
+			projectCredential
+	}
+	return dbCredentials
+}
+
+// getCredentialContent reads the current content of a credential, the description from the json api
+// and the other fields from its update form, it fails when the form lacks a field.
+// Secrets are kept in the encrypted form rendered by Jenkins, which Jenkins accepts back on update,
+// so the content can be merged with partial changes without knowing the plain secrets.
+func (s *ProjectService) getCredentialContent(projectId, domain, credentialId, credentialType string) (map[string]interface{}, error) {
+	content, form, projectCredential, err := s.getCredentialCommonContent(projectId, domain, credentialId)
+	if err != nil {
+		return nil, err
+	}
+	switch credentialType {
+	case CredentialTypeUsernamePassword:
+		content["username"] = form.field("username")
+		content["password"] = form.field("password")
+	case CredentialTypeDockerRegistry:
+		content["username"] = form.field("username")
+		content["password"] = form.field("password")
+		if projectCredential != nil {
+			content["registry_url"] = string(projectCredential.RegistryUrl)
+		}
+	case CredentialTypeSsh:
+		content["username"] = form.field("username")
+		content["passphrase"] = form.optionalField("passphrase")
+		content["private_key"] = form.field("privateKey")
+	case CredentialTypeSecretText:
+		content["secret"] = form.field("secret")
+	case CredentialTypeKubeConfig:
+		content["content"] = form.field("content")
+	case CredentialTypeAWS:
+		content["access_key_id"] = form.field("accessKey")
+		content["secret_access_key"] = form.field("secretKey")
+		content["iam_role_arn"] = form.optionalField("iamRoleArn")
+	case CredentialTypeCertificate:
+		return nil, fmt.Errorf("error keystore of %s credential [%s] can not be read from Jenkins",
+			credentialType, credentialId)
+	case CredentialTypeSecretFile:
+		return nil, fmt.Errorf("error file of %s credential [%s] can not be read from Jenkins",
+			credentialType, credentialId)
+	case CredentialTypeOpenShiftToken:
+		return nil, fmt.Errorf("error token of %s credential [%s] can not be read from Jenkins",
+			credentialType, credentialId)
+	default:
+		return nil, fmt.Errorf("error unsupport credential type %s", credentialType)
+	}
+	if err := form.err(s.credentialStore().GetVersion()); err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+// getCredentialCommonContent reads the fields of the content every type has, the id, the description and the
+// scope, along with the update form the other fields are read from and the db record of the credential.
+func (s *ProjectService) getCredentialCommonContent(projectId, domain, credentialId string) (map[string]interface{},
+	*credentialForm, *models.ProjectCredential, error) {
+	projectCredential, err := s.getProjectCredential(projectId, domain, credentialId)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	target := recordTarget(projectId, domain, projectCredential)
+	credential, err := target.get(context.Background(), s.credentialStore(), credentialId)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	stringBody, err := target.content(context.Background(), s.credentialStore(), credentialId)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	form, err := newCredentialForm(stringBody)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	content := map[string]interface{}{
+		"id":          credentialId,
+		"description": credential.Description,
+	}
+	if scope := form.scope(); scope != "" {
+		content["scope"] = scope
+	}
+	return content, form, projectCredential, nil
+}
+
+// mergeCredentialContent overrides the current content with the non nil fields of patch.
+func mergeCredentialContent(current, patch map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(current))
+	for key, value := range current {
+		merged[key] = value
+	}
+	for key, value := range patch {
+		if value != nil {
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
+// updateCredentialContent updates the credential in the store of target with the complete content of its type,
+// the record fields derived from the fields in changed, like the strength of a secret, are recomputed.
+func (s *ProjectService) updateCredentialContent(target credentialTarget, credentialType string,
+	content, changed map[string]interface{}) (*string, error) {
+	request, fields, err := s.prepareCredentialUpdate(credentialType, content, changed)
+	if err != nil {
+		return nil, err
+	}
+	credentialId, err := request.update(context.Background(), s.credentialStore(), target)
+	if err != nil {
+		return nil, err
+	}
+	return credentialId, s.updateCredentialRecordFields(target, *credentialId, fields)
+}
+
+// mergeUpdateContent merges the content of an update into the current content of the credential, fields
+// absent from the update keep their current values. A new ssh private key comes with its own passphrase,
+// the current one is not kept for it. The keystore of certificates can not be read back, it comes with the
+// update which is merged into the description and the scope. The content of secret files and the token of
+// OpenShift tokens can not be read back either, their update is used as is.
+func (s *ProjectService) mergeUpdateContent(projectId, domain, credentialId, credentialType string,
+	update map[string]interface{}) (map[string]interface{}, error) {
+	if credentialType == CredentialTypeSecretFile || credentialType == CredentialTypeOpenShiftToken {
+		return update, nil
+	}
+	var current map[string]interface{}
+	var err error
+	if credentialType == CredentialTypeCertificate {
+		current, _, _, err = s.getCredentialCommonContent(projectId, domain, credentialId)
+	} else {
+		current, err = s.getCredentialContent(projectId, domain, credentialId, credentialType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := update["private_key"]; ok {
+		delete(current, "passphrase")
+	}
+	content := mergeCredentialContent(current, update)
+	content["id"] = credentialId
+	return content, nil
+}
+
+// patchCredential merges patch into the current content of the credential and updates it,
+// fields absent from patch keep their current values, including secrets.
+func (s *ProjectService) patchCredential(projectId, domain, credentialId string,
+	patch map[string]interface{}) (*string, error) {
+	target, err := s.credentialTargetOf(projectId, domain, credentialId)
+	if err != nil {
+		return nil, err
+	}
+	jenkinsCredential, err := target.get(context.Background(), s.credentialStore(), credentialId)
+	if err != nil {
+		return nil, err
+	}
+	credentialType, err := s.getCredentialType(projectId, jenkinsCredential)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.getCredentialContent(projectId, domain, credentialId, credentialType)
+	if err != nil {
+		return nil, err
+	}
+	content := mergeCredentialContent(current, patch)
+	content["id"] = credentialId
+	return s.updateCredentialContent(target, credentialType, content, patch)
+}
+
+// detachedContext keeps the values of its parent but neither its deadline nor its cancellation.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (c detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (c detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (c detachedContext) Err() error {
+	return nil
+}
+
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
+
+// detachCredentialWrite returns a context for writing a credential to Jenkins and db which is not canceled
+// with ctx, a client disconnecting half way would leave them inconsistent. It has the write timeout instead.
+func (s *ProjectService) detachCredentialWrite(ctx context.Context) (context.Context, context.CancelFunc) {
+	detached := context.Context(detachedContext{parent: ctx})
+	if s.Config == nil || s.Config.Jenkins.WriteTimeout <= 0 {
+		return context.WithCancel(detached)
+	}
+	return context.WithTimeout(detached, s.Config.Jenkins.WriteTimeout)
+}
+
+// createCredentialWithRollback creates the credential in Jenkins with create and records it in db, both on
+// a context detached from ctx so a client disconnecting in between does not leave an unrecorded credential.
+// The idempotency key, if any, is recorded together with the credential.
+func (s *ProjectService) createCredentialWithRollback(ctx context.Context, projectCredential *models.ProjectCredential,
+	idempotencyKey *models.ProjectCredentialIdempotencyKey, create func(ctx context.Context) error) error {
+	ctx, cancel := s.detachCredentialWrite(ctx)
+	defer cancel()
+	err := create(ctx)
+	if err != nil {
+		return err
+	}
+	return s.saveCredentialOrRollback(projectCredential, idempotencyKey)
+}
+
+// saveCredentialOrRollback records a credential just created in Jenkins, the Jenkins credential is deleted
+// again if it can not be recorded so Jenkins and db stay consistent.
+func (s *ProjectService) saveCredentialOrRollback(projectCredential *models.ProjectCredential,
+	idempotencyKey *models.ProjectCredentialIdempotencyKey) error {
+	err := s.Ds.Db.WithTransaction(func(tx *dbr.Tx) error {
+		_, err := tx.InsertInto(models.ProjectCredentialTableName).Columns(models.ProjectCredentialColumns...).
+			Record(projectCredential).Exec()
+		if err != nil || idempotencyKey == nil {
+			return err
+		}
+		return saveIdempotencyKey(tx, idempotencyKey)
+	})
+	if err == nil {
+		return nil
+	}
+	var deleteErr error
+	if projectCredential.Store == models.CredentialStoreGlobal {
+		_, deleteErr = s.credentialStore().DeleteCredentialInSystem(projectCredential.Domain,
+			projectCredential.CredentialId)
+	} else {
+		_, deleteErr = s.credentialStore().DeleteCredentialInFolder(projectCredential.Domain,
+			projectCredential.CredentialId, projectCredential.ProjectId)
+	}
+	if deleteErr != nil {
+		logger.Error("failed to remove credential [%s] after db error, it needs manual repair: %+v",
+			projectCredential.CredentialId, deleteErr)
+	}
+	return err
+}
+
+// createCredentialContent creates the credential of request in target and records it in db, the Jenkins
+// credential is removed again if it can not be recorded. The store of request is ignored, callers creating
+// in the global store pass its target once checkGlobalStoreWriter let the operator in.
+func (s *ProjectService) createCredentialContent(ctx context.Context, target credentialTarget, operator string,
+	request *CredentialRequest) (*models.ProjectCredential, error) {
+	ctx, cancel := s.detachCredentialWrite(ctx)
+	defer cancel()
+	projectId := target.projectId
+	projectCredential, err := s.createJenkinsCredential(ctx, target, operator, request)
+	if err != nil {
+		return nil, err
+	}
+	err = s.saveCredentialOrRollback(projectCredential, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.recordCredentialMutation(projectId, operator, CredentialActionCreate, projectCredential.Domain,
+		projectCredential.CredentialId, request.Content)
+	return projectCredential, nil
+}
+
+// deleteCredentialContent deletes the credential from the store it is kept in and its record in db.
+func (s *ProjectService) deleteCredentialContent(projectId, domain, credentialId string) error {
+	if govalidator.IsNull(domain) {
+		domain = "_"
+	}
+	target, err := s.credentialTargetOf(projectId, domain, credentialId)
+	if err != nil {
+		return err
+	}
+	_, err = target.delete(context.Background(), s.credentialStore(), credentialId)
+	if err != nil {
+		return err
+	}
+	return s.Ds.Db.WithTransaction(func(tx *dbr.Tx) error {
+		return deleteCredentialRecordsTx(tx, projectId, domain, credentialId)
+	})
+}
+
+// deleteCredentialRecordsTx removes the record of a deleted credential along with its usage threshold.
+func deleteCredentialRecordsTx(tx *dbr.Tx, projectId, domain, credentialId string) error {
+	if govalidator.IsNull(domain) {
+		domain = "_"
+	}
+	for _, table := range []string{models.ProjectCredentialTableName, models.ProjectCredentialUsageThresholdTableName} {
+		_, err := tx.DeleteFrom(table).
+			Where(db.And(db.Eq(models.ProjectIdColumn, projectId),
+				db.Eq(models.ProjectCredentialIdColumn, credentialId),
+				db.Eq(models.ProjectCredentialDomainColumn, domain))).Exec()
+		if err != nil && err != db.ErrNotFound {
+			return err
+		}
+	}
+	return nil
+}
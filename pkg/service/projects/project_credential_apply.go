@@ -0,0 +1,335 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/asaskevich/govalidator"
+
+	"kubesphere.io/devops/pkg/gojenkins"
+	"kubesphere.io/devops/pkg/logger"
+	"kubesphere.io/devops/pkg/models"
+	"kubesphere.io/devops/pkg/utils/reflectutils"
+	"kubesphere.io/devops/pkg/utils/stringutils"
+	"kubesphere.io/devops/pkg/utils/userutils"
+)
+
+const (
+	CredentialApplyCreated        = "created"
+	CredentialApplyUpdated        = "updated"
+	CredentialApplyFailed         = "failed"
+	CredentialApplySkipped        = "skipped"
+	CredentialApplyRolledBack     = "rolled_back"
+	CredentialApplyRollbackFailed = "rollback_failed"
+)
+
+var applyCredentialTypes = []string{
+	CredentialTypeUsernamePassword,
+	CredentialTypeSsh,
+	CredentialTypeSecretText,
+	CredentialTypeKubeConfig,
+	CredentialTypeDockerRegistry,
+	CredentialTypeAWS,
+}
+
+type CredentialApplyResult struct {
+	Id      string `json:"id"`
+	Domain  string `json:"domain"`
+	Action  string `json:"action"`
+	Status  int    `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+type CredentialApplyResponse struct {
+	Atomic     bool                     `json:"atomic"`
+	RolledBack bool                     `json:"rolled_back"`
+	Results    []*CredentialApplyResult `json:"results"`
+}
+
+// validateApplyRequests checks the whole manifest before any Jenkins operation is staged.
+func validateApplyRequests(requests []*CredentialRequest, maxDescriptionLength int) error {
+	seen := make(map[string]bool)
+	for i, request := range requests {
+		if !reflectutils.In(request.Type, applyCredentialTypes) {
+			return fmt.Errorf("credential [%d] has unsupported type [%s]", i, request.Type)
+		}
+		credentialId, _ := request.Content["id"].(string)
+		if govalidator.IsNull(credentialId) {
+			return fmt.Errorf("credential [%d] has no id", i)
+		}
+		if err := validateCredentialId(credentialId); err != nil {
+			return fmt.Errorf("credential [%d]: %v", i, err)
+		}
+		if err := normalizeCredentialScope(request.Content); err != nil {
+			return fmt.Errorf("credential [%d]: %v", i, err)
+		}
+		if err := normalizeCredentialDescription(request.Content, maxDescriptionLength); err != nil {
+			return fmt.Errorf("credential [%d]: %v", i, err)
+		}
+		if err := normalizeSshPrivateKeys(request.Content); err != nil {
+			return fmt.Errorf("credential [%d]: %v", i, err)
+		}
+		if !govalidator.IsNull(request.Store) && request.Store != models.CredentialStoreFolder {
+			return fmt.Errorf("credential [%d] can not be applied to the [%s] store, applied credentials are "+
+				"kept in the project folder", i, request.Store)
+		}
+		if govalidator.IsNull(request.Domain) {
+			request.Domain = "_"
+		}
+		key := request.Domain + "/" + credentialId
+		if seen[key] {
+			return fmt.Errorf("credential [%s] appears more than once in domain [%s]", credentialId, request.Domain)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// checkCredentialCreate runs the checks a credential of a manifest must pass before it is created,
+// it returns the status to report with the failure.
+func (s *ProjectService) checkCredentialCreate(projectId, operator string, request *CredentialRequest,
+	credentialId string, overrideCooldown bool) (int, error) {
+	var err error
+	request.IntendedJobs, err = validateIntendedJobs(request.IntendedJobs)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+	remainingCooldown, err := s.checkRecreateCooldown(projectId, request.Domain, credentialId, overrideCooldown)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if remainingCooldown > 0 {
+		return http.StatusTooManyRequests, fmt.Errorf(
+			"credential [%s] was deleted recently, it can be created again in %ds", credentialId, remainingCooldown)
+	}
+	reason, err := s.checkPreCreateWebhook(projectId, operator, request)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if reason != nil {
+		return http.StatusForbidden, reason
+	}
+	verifyErr, err := s.checkCreateVerification(projectId, request)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if verifyErr != nil {
+		return http.StatusUnprocessableEntity, verifyErr
+	}
+	reason, err = s.checkContentValidationWebhook(projectId, operator, CredentialActionCreate, request.Type,
+		request.Domain, request.Content)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if reason != nil {
+		return http.StatusUnprocessableEntity, reason
+	}
+	return http.StatusOK, nil
+}
+
+// applyCredential creates the credential, or updates it with the given content merged into the
+// current one if it exists. It returns the operation that reverts the change.
+func (s *ProjectService) applyCredential(ctx context.Context, projectId, operator string,
+	request *CredentialRequest, result *CredentialApplyResult, overrideCooldown bool) (compensate func() error, err error) {
+	// applied credentials are kept in the project folder
+	target := newCredentialTarget(projectId, request.Domain, models.CredentialStoreFolder)
+	existing, err := target.get(ctx, s.credentialStore(), result.Id)
+	if err != nil && !errors.Is(err, gojenkins.ErrCredentialNotFound) {
+		result.Status = stringutils.GetJenkinsStatusCode(err)
+		return nil, err
+	}
+
+	if existing != nil {
+		credentialType, err := s.getCredentialType(projectId, existing)
+		if err != nil {
+			result.Status = http.StatusInternalServerError
+			return nil, err
+		}
+		if credentialType != request.Type {
+			result.Status = http.StatusConflict
+			return nil, fmt.Errorf("credential [%s] exists with type [%s]", result.Id, credentialType)
+		}
+		previous, err := s.getCredentialContent(projectId, request.Domain, result.Id, credentialType)
+		if err != nil {
+			result.Status = stringutils.GetJenkinsStatusCode(err)
+			return nil, err
+		}
+		validationContent := mergeCredentialContent(map[string]interface{}{}, request.Content)
+		validationContent["id"] = result.Id
+		reason, err := s.checkContentValidationWebhook(projectId, operator, CredentialActionUpdate, credentialType,
+			request.Domain, validationContent)
+		if err != nil {
+			result.Status = http.StatusInternalServerError
+			return nil, err
+		}
+		if reason != nil {
+			result.Status = http.StatusUnprocessableEntity
+			return nil, reason
+		}
+		content := mergeCredentialContent(previous, request.Content)
+		content["id"] = result.Id
+		_, err = s.updateCredentialContent(target, credentialType, content, request.Content)
+		if err != nil {
+			result.Status = stringutils.GetJenkinsStatusCode(err)
+			return nil, err
+		}
+		s.recordCredentialMutation(projectId, operator, CredentialActionUpdate, request.Domain, result.Id, content)
+		s.markCredentialModified(projectId, request.Domain, result.Id, operator)
+		result.Action = CredentialApplyUpdated
+		return func() error {
+			_, err := s.updateCredentialContent(target, credentialType, previous, nil)
+			if err != nil {
+				return err
+			}
+			s.recordCredentialMutation(projectId, operator, CredentialActionUpdate, request.Domain, result.Id, previous)
+			return nil
+		}, nil
+	}
+
+	status, err := s.checkCredentialCreate(projectId, operator, request, result.Id, overrideCooldown)
+	if err != nil {
+		result.Status = status
+		return nil, err
+	}
+	_, err = s.createCredentialContent(ctx, target, operator, request)
+	if err != nil {
+		result.Status = stringutils.GetJenkinsStatusCode(err)
+		return nil, err
+	}
+	result.Action = CredentialApplyCreated
+	return func() error {
+		err := s.deleteCredentialContent(projectId, request.Domain, result.Id)
+		if err != nil {
+			return err
+		}
+		s.recordCredentialMutation(projectId, operator, CredentialActionDelete, request.Domain, result.Id, nil)
+		return nil
+	}, nil
+}
+
+// ApplyCredentialsHandler creates or updates every credential of the manifest in the request body.
+//
+// With atomic=true the first failure stops the apply and the credentials applied before it are
+// compensated in reverse order: created ones are deleted and updated ones are updated back to the
+// content read before the change. Jenkins has no transactions, so atomicity is best effort:
+// other writers may observe the intermediate state, a compensation can fail itself (reported as
+// rollback_failed and needing manual repair), and a deleted credential loses its fingerprint usage.
+func (s *ProjectService) ApplyCredentialsHandler(w rest.ResponseWriter, r *rest.Request) {
+	requests := make([]*CredentialRequest, 0)
+	projectId := r.PathParams["id"]
+	defer s.invalidateCredentialList(projectId)
+	operator := userutils.GetUserNameFromRequest(r)
+
+	atomic := false
+	if atomicParam := r.URL.Query().Get("atomic"); !govalidator.IsNull(atomicParam) {
+		var err error
+		atomic, err = strconv.ParseBool(atomicParam)
+		if err != nil {
+			logger.ErrorContext(r.Context(), "%+v", err)
+			writeCredentialError(w, err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	overrideCooldown, err := parseCooldownOverride(r)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	err = r.DecodeJsonPayload(&requests)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	// applied credentials are created or updated, and deleted again when the apply is rolled back
+	for _, action := range []string{CredentialActionCreate, CredentialActionUpdate, CredentialActionDelete} {
+		err = s.checkProjectUserInRole(r, operator, projectId, s.credentialActionRoles(action))
+		if err != nil {
+			logger.ErrorContext(r.Context(), "%+v", err)
+			writeCredentialError(w, err, http.StatusForbidden)
+			return
+		}
+	}
+	err = checkCooldownOverride(operator, overrideCooldown)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
+		return
+	}
+	err = validateApplyRequests(requests, s.Config.Description.MaxLength)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	response := &CredentialApplyResponse{Atomic: atomic, Results: make([]*CredentialApplyResult, 0, len(requests))}
+	compensations := make([]func() error, 0, len(requests))
+	failedStatus := 0
+	for _, request := range requests {
+		credentialId, _ := request.Content["id"].(string)
+		result := &CredentialApplyResult{Id: credentialId, Domain: request.Domain}
+		response.Results = append(response.Results, result)
+		if atomic && failedStatus != 0 {
+			result.Action = CredentialApplySkipped
+			continue
+		}
+
+		compensate, err := s.applyCredential(r.Context(), projectId, operator, request, result, overrideCooldown)
+		if err != nil {
+			logger.ErrorContext(r.Context(), "failed to apply credential [%s] in project [%s]: %+v",
+				credentialId, projectId, err)
+			result.Action = CredentialApplyFailed
+			result.Message = cleanErrorMessage(err, result.Status)
+			failedStatus = result.Status
+			continue
+		}
+		result.Status = http.StatusOK
+		logger.Info("credential [%s] in project [%s] %s by [%s]", credentialId, projectId, result.Action, operator)
+		compensations = append(compensations, compensate)
+	}
+
+	if !atomic || failedStatus == 0 {
+		w.WriteJson(response)
+		return
+	}
+
+	logger.WarnContext(r.Context(), "rolling back %d applied credentials in project [%s]", len(compensations), projectId)
+	response.RolledBack = true
+	for i := len(compensations) - 1; i >= 0; i-- {
+		result := response.Results[i]
+		err := compensations[i]()
+		if err != nil {
+			logger.ErrorContext(r.Context(), "failed to roll back credential [%s] in project [%s], it needs manual repair: %+v",
+				result.Id, projectId, err)
+			result.Action = CredentialApplyRollbackFailed
+			result.Message = cleanErrorMessage(err, stringutils.GetJenkinsStatusCode(err))
+			continue
+		}
+		logger.Info("rolled back credential [%s] in project [%s]", result.Id, projectId)
+		result.Action = CredentialApplyRolledBack
+	}
+	w.WriteHeader(failedStatus)
+	w.WriteJson(response)
+	return
+}
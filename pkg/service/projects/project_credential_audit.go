@@ -0,0 +1,254 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/asaskevich/govalidator"
+	"github.com/gocraft/dbr"
+
+	"kubesphere.io/devops/pkg/db"
+	"kubesphere.io/devops/pkg/logger"
+	"kubesphere.io/devops/pkg/models"
+	"kubesphere.io/devops/pkg/utils/userutils"
+)
+
+// CredentialActionView is audited when the content of a credential is read.
+const CredentialActionView = "view"
+
+// the credential operations audited besides the credential actions
+const (
+	CredentialActionApply            = "apply"
+	CredentialActionBulkDescribe     = "bulk-describe"
+	CredentialActionBreakGlass       = "break-glass"
+	CredentialActionBreakGlassReveal = "break-glass-reveal"
+	CredentialActionSync             = "sync"
+	CredentialActionDeleteAll        = "delete-all"
+	CredentialActionRenameDomain     = "rename-domain"
+)
+
+// credentialReadActions are audited without notifying the change webhooks.
+var credentialReadActions = map[string]bool{
+	CredentialActionView:             true,
+	CredentialActionGet:              true,
+	CredentialActionList:             true,
+	CredentialActionBreakGlassReveal: true,
+}
+
+// the total number of entries of a paginated list is answered in this header, the body keeps its shape
+const totalCountHeader = "X-Total-Count"
+
+// credentialAudit wraps the response writer of a credential handler, so the status
+// the request was answered with can be written to the audit log.
+type credentialAudit struct {
+	rest.ResponseWriter
+	service *ProjectService
+	request *rest.Request
+	entry   *models.CredentialAuditLog
+	skipped bool
+}
+
+func (s *ProjectService) newCredentialAudit(w rest.ResponseWriter, r *rest.Request, action string) *credentialAudit {
+	entry := models.NewCredentialAuditLog(r.PathParams["id"], r.PathParams["cid"], r.URL.Query().Get("domain"),
+		userutils.GetUserNameFromRequest(r), action)
+	return &credentialAudit{ResponseWriter: w, service: s, request: r, entry: entry}
+}
+
+func (a *credentialAudit) WriteHeader(status int) {
+	if a.entry.Status == 0 {
+		a.entry.Status = status
+	}
+	a.ResponseWriter.WriteHeader(status)
+}
+
+func (a *credentialAudit) WriteJson(v interface{}) error {
+	if a.entry.Status == 0 {
+		a.entry.Status = http.StatusOK
+	}
+	return a.ResponseWriter.WriteJson(v)
+}
+
+// Write lets handlers answering raw bodies be audited.
+func (a *credentialAudit) Write(b []byte) (int, error) {
+	if a.entry.Status == 0 {
+		a.entry.Status = http.StatusOK
+	}
+	return a.ResponseWriter.(http.ResponseWriter).Write(b)
+}
+
+// Flush lets streamed responses be audited, they are flushed chunk by chunk.
+func (a *credentialAudit) Flush() {
+	if flusher, ok := a.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// setCredential sets the credential the request turned out to target, once the handler knows it.
+func (a *credentialAudit) setCredential(domain, credentialId string) {
+	if !govalidator.IsNull(domain) {
+		a.entry.Domain = domain
+	}
+	if !govalidator.IsNull(credentialId) {
+		a.entry.CredentialId = credentialId
+	}
+}
+
+// setBreakGlass audits the request as a critical break-glass operation justified by reason.
+func (a *credentialAudit) setBreakGlass(reason string) {
+	a.entry.Severity = models.CredentialAuditSeverityCritical
+	a.entry.Reason = reason
+}
+
+// skip leaves the request out of the audit log, for requests that turn out to change nothing.
+func (a *credentialAudit) skip() {
+	a.skipped = true
+}
+
+// record writes the audit entry and notifies the change webhooks of successful changes of a credential;
+// it is meant to be deferred by the handler.
+func (a *credentialAudit) record() {
+	if a.skipped {
+		return
+	}
+	if a.entry.Status == 0 {
+		a.entry.Status = http.StatusOK
+	}
+	_, err := a.service.Ds.Db.InsertInto(models.CredentialAuditLogTableName).
+		Columns(models.CredentialAuditLogColumns...).Record(a.entry).Exec()
+	if err != nil {
+		logger.Error("failed to audit %s of credential [%s] in project [%s]: %+v",
+			a.entry.Action, a.entry.CredentialId, a.entry.ProjectId, err)
+	}
+	if !credentialReadActions[a.entry.Action] && !govalidator.IsNull(a.entry.CredentialId) &&
+		a.entry.Status < http.StatusMultipleChoices {
+		credentialType, _ := a.request.Env[credentialOperationTypeEnvKey].(string)
+		a.service.notifyCredentialChange(a.entry, credentialType)
+	}
+}
+
+// AuditCredentialHandler writes the requests served by handler to the audit log as operations of action,
+// for the handlers that do not audit themselves.
+func (s *ProjectService) AuditCredentialHandler(action string, handler rest.HandlerFunc) rest.HandlerFunc {
+	return func(w rest.ResponseWriter, r *rest.Request) {
+		audit := s.newCredentialAudit(w, r, action)
+		defer audit.record()
+		handler(audit, r)
+	}
+}
+
+// setCredentialAuditAction changes the action the request answered with w is audited as, once the handler
+// knows it; requests not audited are left alone.
+func setCredentialAuditAction(w rest.ResponseWriter, action string) {
+	if audit, ok := w.(*credentialAudit); ok {
+		audit.entry.Action = action
+	}
+}
+
+// setCredentialAuditBreakGlass audits the request answered with w as a critical break-glass operation on the
+// credential, once the handler knows it; requests not audited are left alone.
+func setCredentialAuditBreakGlass(w rest.ResponseWriter, domain, credentialId, reason string) {
+	if audit, ok := w.(*credentialAudit); ok {
+		audit.setCredential(domain, credentialId)
+		audit.setBreakGlass(reason)
+	}
+}
+
+// parsePage parses the limit and offset of a paginated list, a missing limit selects the default page size.
+func parsePage(r *rest.Request) (limit, offset uint64, err error) {
+	for param, value := range map[string]*uint64{"limit": &limit, "offset": &offset} {
+		raw := r.URL.Query().Get(param)
+		if govalidator.IsNull(raw) {
+			continue
+		}
+		*value, err = strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("error %s [%s] is not a positive integer", param, raw)
+		}
+	}
+	return limit, offset, nil
+}
+
+func parseAuditTime(r *rest.Request, param string) (time.Time, error) {
+	value := r.URL.Query().Get(param)
+	if govalidator.IsNull(value) {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("error %s [%s] is not a RFC3339 time", param, value)
+	}
+	return t, nil
+}
+
+func (s *ProjectService) GetCredentialAuditHandler(w rest.ResponseWriter, r *rest.Request) {
+	projectId := r.PathParams["id"]
+	operator := userutils.GetUserNameFromRequest(r)
+	err := s.checkProjectUserInRole(r, operator, projectId, []string{ProjectOwner, ProjectMaintainer})
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
+		return
+	}
+	start, err := parseAuditTime(r, "start")
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	end, err := parseAuditTime(r, "end")
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	limit, offset, err := parsePage(r)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	conditions := []dbr.Builder{db.Eq(models.ProjectIdColumn, projectId)}
+	if !start.IsZero() {
+		conditions = append(conditions, db.Gte(models.CredentialAuditLogCreateTimeColumn, start))
+	}
+	if !end.IsZero() {
+		conditions = append(conditions, db.Lte(models.CredentialAuditLogCreateTimeColumn, end))
+	}
+	entries := []*models.CredentialAuditLog{}
+	query := s.Ds.Db.Select(models.CredentialAuditLogColumns...).
+		From(models.CredentialAuditLogTableName).
+		Where(db.And(conditions...))
+	total, err := db.Count(query)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	_, err = db.Paginate(query, limit, offset, "-"+models.CredentialAuditLogCreateTimeColumn,
+		models.CredentialAuditLogIdColumn).Load(&entries)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set(totalCountHeader, strconv.FormatUint(uint64(total), 10))
+	w.WriteJson(entries)
+	return
+}
@@ -0,0 +1,34 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"testing"
+)
+
+func TestParsePage(t *testing.T) {
+	limit, offset, err := parsePage(newFakeStoreRequest("admin", ProjectOwner, "/credentials/audit"))
+	if err != nil || limit != 0 || offset != 0 {
+		t.Fatalf("a request without page should select the default page, got %d, %d, %+v", limit, offset, err)
+	}
+	limit, offset, err = parsePage(newFakeStoreRequest("admin", ProjectOwner, "/credentials/audit?limit=20&offset=40"))
+	if err != nil || limit != 20 || offset != 40 {
+		t.Fatalf("expected limit 20 and offset 40, got %d, %d, %+v", limit, offset, err)
+	}
+	for _, invalid := range []string{"limit=-1", "offset=page"} {
+		if _, _, err := parsePage(newFakeStoreRequest("admin", ProjectOwner, "/credentials/audit?"+invalid)); err == nil {
+			t.Errorf("page [%s] should be rejected", invalid)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/gocraft/dbr"
+
+	"kubesphere.io/devops/pkg/gojenkins"
+	"kubesphere.io/devops/pkg/logger"
+	"kubesphere.io/devops/pkg/models"
+	"kubesphere.io/devops/pkg/utils/stringutils"
+	"kubesphere.io/devops/pkg/utils/userutils"
+)
+
+type CredentialBatchResult struct {
+	Id     string `json:"id"`
+	Domain string `json:"domain"`
+	Status int    `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// createBatchCredential creates one credential of a batch in Jenkins, its db record is returned to be
+// saved with the rest of the batch.
+func (s *ProjectService) createBatchCredential(ctx context.Context, projectId, operator string,
+	request *CredentialRequest, result *CredentialBatchResult) (*models.ProjectCredential, error) {
+	existing, err := s.credentialStore().GetCredentialInFolder(request.Domain, result.Id, projectId)
+	if existing != nil {
+		result.Status = http.StatusConflict
+		return nil, fmt.Errorf("credential id [%s] has been used", existing.Id)
+	}
+	if err != nil && !errors.Is(err, gojenkins.ErrCredentialNotFound) {
+		result.Status = stringutils.GetJenkinsStatusCode(err)
+		return nil, err
+	}
+	status, err := s.checkCredentialCreate(projectId, operator, request, result.Id, false)
+	if err != nil {
+		result.Status = status
+		return nil, err
+	}
+	target := newCredentialTarget(projectId, request.Domain, models.CredentialStoreFolder)
+	projectCredential, err := s.createJenkinsCredential(ctx, target, operator, request)
+	if err != nil {
+		result.Status = stringutils.GetJenkinsStatusCode(err)
+		return nil, err
+	}
+	return projectCredential, nil
+}
+
+// removeBatchCredentials deletes the Jenkins credentials created by a batch that can not be completed.
+func (s *ProjectService) removeBatchCredentials(projectId string, created []*models.ProjectCredential) {
+	for _, projectCredential := range created {
+		_, err := s.credentialStore().DeleteCredentialInFolder(projectCredential.Domain, projectCredential.CredentialId,
+			projectId)
+		if err != nil {
+			logger.Error("failed to remove credential [%s] of a failed batch in project [%s], "+
+				"it needs manual repair: %+v", projectCredential.CredentialId, projectId, err)
+		}
+	}
+}
+
+// saveBatchCredentials records the credentials created by a batch in a single transaction.
+func (s *ProjectService) saveBatchCredentials(created []*models.ProjectCredential) error {
+	return s.Ds.Db.WithTransaction(func(tx *dbr.Tx) error {
+		for _, projectCredential := range created {
+			_, err := tx.InsertInto(models.ProjectCredentialTableName).Columns(models.ProjectCredentialColumns...).
+				Record(projectCredential).Exec()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// CreateCredentialsBatchHandler creates every credential in the request body, or none of them.
+//
+// The first failure stops the batch and removes the Jenkins credentials created before it, the db
+// records of the batch are only saved, in one transaction, once every Jenkins credential exists.
+func (s *ProjectService) CreateCredentialsBatchHandler(w rest.ResponseWriter, r *rest.Request) {
+	requests := make([]*CredentialRequest, 0)
+	projectId := r.PathParams["id"]
+	defer s.invalidateCredentialList(projectId)
+	operator := userutils.GetUserNameFromRequest(r)
+	err := r.DecodeJsonPayload(&requests)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	err = s.checkProjectUserInRole(r, operator, projectId, s.credentialActionRoles(CredentialActionCreate))
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
+		return
+	}
+	for _, request := range requests {
+		err = s.fillGeneratedCredentialId(projectId, request)
+		if err != nil {
+			logCredentialError(r.Context(), err)
+			writeCredentialError(w, err, stringutils.GetJenkinsStatusCode(err))
+			return
+		}
+	}
+	err = validateApplyRequests(requests, s.Config.Description.MaxLength)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	for i, request := range requests {
+		err = validateCredentialFields(request.Type, request.Content)
+		if fieldsErr, ok := err.(*CredentialFieldsError); ok {
+			fieldsErr.Message = fmt.Sprintf("credential [%d]: %s", i, fieldsErr.Message)
+		}
+		if err != nil {
+			logger.WarnContext(r.Context(), "%+v", err)
+			writeCredentialError(w, err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	results := make([]*CredentialBatchResult, 0, len(requests))
+	created := make([]*models.ProjectCredential, 0, len(requests))
+	failedStatus := 0
+	for _, request := range requests {
+		credentialId, _ := request.Content["id"].(string)
+		result := &CredentialBatchResult{Id: credentialId, Domain: request.Domain}
+		results = append(results, result)
+		if failedStatus != 0 {
+			result.Error = "not created, the batch failed"
+			continue
+		}
+		projectCredential, err := s.createBatchCredential(r.Context(), projectId, operator, request, result)
+		if err != nil {
+			logger.ErrorContext(r.Context(), "failed to create credential [%s] in project [%s]: %+v",
+				credentialId, projectId, err)
+			result.Error = cleanErrorMessage(err, result.Status)
+			failedStatus = result.Status
+			continue
+		}
+		result.Status = http.StatusOK
+		created = append(created, projectCredential)
+	}
+
+	if failedStatus == 0 {
+		err = s.saveBatchCredentials(created)
+		if err != nil {
+			logger.ErrorContext(r.Context(), "failed to save credentials of the batch in project [%s]: %+v", projectId, err)
+			failedStatus = http.StatusInternalServerError
+			for _, result := range results {
+				result.Status = failedStatus
+				result.Error = cleanErrorMessage(err, failedStatus)
+			}
+		}
+	}
+	if failedStatus != 0 {
+		logger.WarnContext(r.Context(), "removing %d credentials of the failed batch in project [%s]",
+			len(created), projectId)
+		s.removeBatchCredentials(projectId, created)
+		for _, result := range results[:len(created)] {
+			if result.Status == http.StatusOK {
+				result.Status = failedStatus
+				result.Error = "removed, the batch failed"
+			}
+		}
+		w.WriteHeader(failedStatus)
+		w.WriteJson(results)
+		return
+	}
+
+	for i, projectCredential := range created {
+		s.recordCredentialMutation(projectId, operator, CredentialActionCreate, projectCredential.Domain,
+			projectCredential.CredentialId, requests[i].Content)
+	}
+	w.WriteJson(results)
+	return
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/asaskevich/govalidator"
+
+	"kubesphere.io/devops/pkg/db"
+	"kubesphere.io/devops/pkg/logger"
+	"kubesphere.io/devops/pkg/models"
+	"kubesphere.io/devops/pkg/utils/userutils"
+)
+
+const maxBatchGetCredentialIds = 200
+
+type BatchGetCredentialsRequest struct {
+	Ids []string `json:"ids"`
+}
+
+// BatchGetCredentialResult is the metadata of one requested credential,
+// ids which match no credential only have the id and the not_found marker.
+type BatchGetCredentialResult struct {
+	*CredentialResponse
+	NotFound bool `json:"not_found,omitempty"`
+}
+
+// BatchGetCredentialsHandler returns the metadata of the credentials of a domain with the requested ids
+// in the order of the request, the content of the credentials is never returned.
+func (s *ProjectService) BatchGetCredentialsHandler(w rest.ResponseWriter, r *rest.Request) {
+	request := &BatchGetCredentialsRequest{}
+	projectId := r.PathParams["id"]
+	domain := r.URL.Query().Get("domain")
+	operator := userutils.GetUserNameFromRequest(r)
+
+	err := r.DecodeJsonPayload(request)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	if len(request.Ids) > maxBatchGetCredentialIds {
+		err = fmt.Errorf("error %d credential ids requested, at most %d are allowed", len(request.Ids),
+			maxBatchGetCredentialIds)
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	err = s.checkProjectUserInRole(r, operator, projectId, s.credentialActionRoles(CredentialActionList))
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
+		return
+	}
+	if len(request.Ids) == 0 {
+		w.WriteJson([]*BatchGetCredentialResult{})
+		return
+	}
+
+	jenkinsCredentials, err := s.getCredentialsInFolder(projectId, domain)
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeJenkinsError(w, r, err)
+		return
+	}
+	projectCredentials, err := s.loadProjectCredentialsByIds(projectId, domain, request.Ids)
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeJenkinsError(w, r, err)
+		return
+	}
+	credentials := formatCredentialsResponse(jenkinsCredentials, projectCredentials)
+	results := batchGetCredentials(request.Ids, credentials)
+	found := make([]*CredentialResponse, 0, len(results))
+	for _, result := range results {
+		if !result.NotFound {
+			found = append(found, result.CredentialResponse)
+		}
+	}
+	setCredentialListWarnings(w, s.fillCredentialsScope(projectId, found))
+	s.fillCreatorDisplayNames(found)
+	w.WriteJson(results)
+	return
+}
+
+// loadProjectCredentialsByIds loads the records of the credentials with ids in one query,
+// an empty domain means all domains.
+func (s *ProjectService) loadProjectCredentialsByIds(projectId, domain string,
+	ids []string) ([]*models.ProjectCredential, error) {
+	selectCondition := db.And(db.Eq(models.ProjectIdColumn, projectId),
+		db.Eq(models.ProjectCredentialIdColumn, ids))
+	if !govalidator.IsNull(domain) {
+		selectCondition = db.And(selectCondition, db.Eq(models.ProjectCredentialDomainColumn, domain))
+	}
+	projectCredentials := make([]*models.ProjectCredential, 0)
+	_, err := s.Ds.Db.Select(models.ProjectCredentialColumns...).
+		From(models.ProjectCredentialTableName).Where(selectCondition).Load(&projectCredentials)
+	if err != nil {
+		return nil, err
+	}
+	return projectCredentials, nil
+}
+
+// batchGetCredentials picks the credentials with ids out of credentials, duplicated ids are returned once.
+func batchGetCredentials(ids []string, credentials []*CredentialResponse) []*BatchGetCredentialResult {
+	byId := make(map[string]*CredentialResponse, len(credentials))
+	for _, credential := range credentials {
+		byId[credential.Id] = credential
+	}
+	results := make([]*BatchGetCredentialResult, 0, len(ids))
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		credential, ok := byId[id]
+		if !ok {
+			results = append(results, &BatchGetCredentialResult{CredentialResponse: &CredentialResponse{Id: id},
+				NotFound: true})
+			continue
+		}
+		results = append(results, &BatchGetCredentialResult{CredentialResponse: credential})
+	}
+	return results
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestBatchGetCredentials(t *testing.T) {
+	credentials := []*CredentialResponse{
+		{Id: "github", Type: CredentialTypeUsernamePassword},
+		{Id: "kubeconfig", Type: CredentialTypeKubeConfig},
+	}
+	results := batchGetCredentials([]string{"kubeconfig", "missing", "github", "kubeconfig"}, credentials)
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	if results[0].Id != "kubeconfig" || results[0].NotFound || results[0].Type != CredentialTypeKubeConfig {
+		t.Fatalf("unexpected result %+v", results[0])
+	}
+	if results[1].Id != "missing" || !results[1].NotFound {
+		t.Fatalf("expected credential [missing] not to be found, got %+v", results[1])
+	}
+	if results[2].Id != "github" || results[2].NotFound {
+		t.Fatalf("unexpected result %+v", results[2])
+	}
+
+	body, err := json.Marshal(results[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := make(map[string]interface{})
+	json.Unmarshal(body, &decoded)
+	if decoded["id"] != "missing" || decoded["not_found"] != true {
+		t.Fatalf("unexpected json %s", body)
+	}
+}
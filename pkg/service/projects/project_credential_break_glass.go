@@ -0,0 +1,316 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/asaskevich/govalidator"
+
+	"kubesphere.io/devops/pkg/gojenkins"
+	"kubesphere.io/devops/pkg/logger"
+	"kubesphere.io/devops/pkg/models"
+	"kubesphere.io/devops/pkg/utils/reflectutils"
+	"kubesphere.io/devops/pkg/utils/stringutils"
+	"kubesphere.io/devops/pkg/utils/userutils"
+	"kubesphere.io/devops/pkg/utils/webhookutils"
+)
+
+// breakGlassReasonMaxLength is the length of the reasons the audit log keeps.
+const breakGlassReasonMaxLength = 1024
+
+// BreakGlassCredentialRequest is a credential request with the mandatory justification of the emergency.
+type BreakGlassCredentialRequest struct {
+	CredentialRequest
+	Reason string `json:"reason"`
+}
+
+// BreakGlassRevealRequest justifies reading the content of a credential in an emergency.
+type BreakGlassRevealRequest struct {
+	Domain string `json:"domain"`
+	Reason string `json:"reason"`
+}
+
+type BreakGlassRevealResponse struct {
+	Id         string                 `json:"id"`
+	Domain     string                 `json:"domain"`
+	Type       string                 `json:"type"`
+	BreakGlass bool                   `json:"break_glass"`
+	Content    map[string]interface{} `json:"content"`
+}
+
+// BreakGlassAlertEvent is the payload sent to the security alert webhook, it never carries secret values.
+type BreakGlassAlertEvent struct {
+	CredentialWebhookEvent
+	Action string `json:"action"`
+	Reason string `json:"reason"`
+}
+
+// sendBreakGlassAlert notifies security out of band that a break-glass operation is performed.
+func (s *ProjectService) sendBreakGlassAlert(event *BreakGlassAlertEvent) error {
+	alertUrl := s.Config.BreakGlass.AlertUrl
+	if govalidator.IsNull(alertUrl) {
+		return fmt.Errorf("break-glass alert url is not configured")
+	}
+	webhookConfig := s.Config.Webhook
+	statusCode, err := webhookutils.PostJSON(alertUrl, webhookConfig.Secret, webhookConfig.Timeout, event, nil)
+	if err == nil && (statusCode < 200 || statusCode > 299) {
+		err = fmt.Errorf("break-glass alert webhook returned %d", statusCode)
+	}
+	return err
+}
+
+// checkBreakGlassOperator lets the operator in when break-glass is enabled and the operator has one of
+// the break-glass roles in the project.
+func (s *ProjectService) checkBreakGlassOperator(r *rest.Request, operator, projectId string) error {
+	breakGlassRoles := s.Config.BreakGlass.Roles
+	if len(breakGlassRoles) == 0 {
+		return fmt.Errorf("break-glass is not enabled")
+	}
+	return s.checkProjectUserInRole(r, operator, projectId, breakGlassRoles)
+}
+
+func validateBreakGlassReason(reason string) error {
+	if govalidator.IsNull(reason) {
+		return fmt.Errorf("break-glass requires a reason")
+	}
+	if len(reason) > breakGlassReasonMaxLength {
+		return fmt.Errorf("break-glass reason is longer than %d characters", breakGlassReasonMaxLength)
+	}
+	return nil
+}
+
+// BreakGlassCreateCredentialHandler creates a credential immediately for incident response. It is only
+// open to the configured break-glass roles, requires a reason and skips the project credential policy:
+// neither the pre-create webhook nor the connectivity verification can hold the creation back.
+// Security is alerted before the creation, an unavailable alert webhook is logged but does not
+// block the emergency. The request is audited as critical with its reason.
+func (s *ProjectService) BreakGlassCreateCredentialHandler(w rest.ResponseWriter, r *rest.Request) {
+	request := &BreakGlassCredentialRequest{}
+	projectId := r.PathParams["id"]
+	defer s.invalidateCredentialList(projectId)
+	operator := userutils.GetUserNameFromRequest(r)
+
+	err := r.DecodeJsonPayload(request)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	request.Reason = strings.TrimSpace(request.Reason)
+	setCredentialAuditBreakGlass(w, request.Domain, "", request.Reason)
+	setCredentialOperationType(r, request.Type)
+	err = s.checkBreakGlassOperator(r, operator, projectId)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
+		return
+	}
+	err = validateBreakGlassReason(request.Reason)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	if !reflectutils.In(request.Type, applyCredentialTypes) {
+		err := fmt.Errorf("error unsupport credential type %s", request.Type)
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	err = s.fillGeneratedCredentialId(projectId, &request.CredentialRequest)
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeCredentialError(w, err, stringutils.GetJenkinsStatusCode(err))
+		return
+	}
+	credentialId, _ := request.Content["id"].(string)
+	setCredentialAuditBreakGlass(w, request.Domain, credentialId, request.Reason)
+	err = validateCredentialId(credentialId)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	err = normalizeCredentialScope(request.Content)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	err = normalizeCredentialDescription(request.Content, s.Config.Description.MaxLength)
+	if err != nil {
+		logger.WarnContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	if !govalidator.IsNull(request.Store) && request.Store != models.CredentialStoreFolder {
+		err := fmt.Errorf("break-glass credentials are created in the project folder, not the [%s] store",
+			request.Store)
+		logger.WarnContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	err = normalizeSshPrivateKeys(request.Content)
+	if err != nil {
+		logger.WarnContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	err = validateCredentialFields(request.Type, request.Content)
+	if err != nil {
+		logger.WarnContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	credential, err := s.credentialStore().GetCredentialInFolder(request.Domain, credentialId, projectId)
+	if credential != nil {
+		err := fmt.Errorf("credential id [%s] has been used", credential.Id)
+		logger.WarnContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusConflict)
+		return
+	}
+	if err != nil && !errors.Is(err, gojenkins.ErrCredentialNotFound) {
+		logCredentialError(r.Context(), err)
+		writeCredentialError(w, err, stringutils.GetJenkinsStatusCode(err))
+		return
+	}
+
+	logger.Critical("[break-glass] user [%s] creates credential [%s] in project [%s] bypassing the credential policy, "+
+		"reason: %s", operator, credentialId, projectId, request.Reason)
+	err = s.sendBreakGlassAlert(&BreakGlassAlertEvent{
+		CredentialWebhookEvent: CredentialWebhookEvent{
+			ProjectId:    projectId,
+			CredentialId: credentialId,
+			Type:         request.Type,
+			Domain:       request.Domain,
+			Operator:     operator,
+		},
+		Action: "create",
+		Reason: request.Reason,
+	})
+	if err != nil {
+		logger.Critical("[break-glass] failed to alert security of credential [%s] in project [%s]: %+v",
+			credentialId, projectId, err)
+	}
+
+	target := newCredentialTarget(projectId, request.Domain, models.CredentialStoreFolder)
+	projectCredential, err := s.createCredentialContent(r.Context(), target, operator, &request.CredentialRequest)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "[break-glass] %+v", err)
+		writeCredentialError(w, err, stringutils.GetJenkinsStatusCode(err))
+		return
+	}
+	logger.Critical("[break-glass] user [%s] created credential [%s] in project [%s]", operator, credentialId, projectId)
+	response := struct {
+		Id         string              `json:"id"`
+		BreakGlass bool                `json:"break_glass"`
+		Strength   *CredentialStrength `json:"strength,omitempty"`
+	}{Id: projectCredential.CredentialId, BreakGlass: true}
+	if projectCredential.StrengthScore != nil {
+		response.Strength = &CredentialStrength{
+			Score:  *projectCredential.StrengthScore,
+			Reason: string(projectCredential.StrengthReason),
+		}
+	}
+	w.WriteJson(response)
+	return
+}
+
+// BreakGlassRevealCredentialHandler answers the content of a credential immediately for incident response,
+// like reading it with content=true but open to the break-glass roles instead of the view roles and not
+// limited by the content read rate. It requires a reason, security is alerted before the content is read
+// and the request is audited as critical with its reason.
+func (s *ProjectService) BreakGlassRevealCredentialHandler(w rest.ResponseWriter, r *rest.Request) {
+	request := &BreakGlassRevealRequest{}
+	projectId := r.PathParams["id"]
+	credentialId := r.PathParams["cid"]
+	operator := userutils.GetUserNameFromRequest(r)
+
+	err := r.DecodeJsonPayload(request)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	if govalidator.IsNull(request.Domain) {
+		request.Domain = r.URL.Query().Get("domain")
+	}
+	request.Domain = normalizeCredentialDomain(request.Domain)
+	request.Reason = strings.TrimSpace(request.Reason)
+	setCredentialAuditBreakGlass(w, request.Domain, credentialId, request.Reason)
+	err = s.checkBreakGlassOperator(r, operator, projectId)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
+		return
+	}
+	err = validateBreakGlassReason(request.Reason)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	target, err := s.credentialTargetOf(projectId, request.Domain, credentialId)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	jenkinsCredential, err := target.get(r.Context(), s.credentialStore(), credentialId)
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeJenkinsError(w, r, err)
+		return
+	}
+	credentialType, err := s.getCredentialType(projectId, jenkinsCredential)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	setCredentialOperationType(r, credentialType)
+
+	logger.Critical("[break-glass] user [%s] reveals credential [%s] in project [%s] bypassing the credential policy, "+
+		"reason: %s", operator, credentialId, projectId, request.Reason)
+	err = s.sendBreakGlassAlert(&BreakGlassAlertEvent{
+		CredentialWebhookEvent: CredentialWebhookEvent{
+			ProjectId:    projectId,
+			CredentialId: credentialId,
+			Type:         credentialType,
+			Domain:       request.Domain,
+			Operator:     operator,
+		},
+		Action: "reveal",
+		Reason: request.Reason,
+	})
+	if err != nil {
+		logger.Critical("[break-glass] failed to alert security of credential [%s] in project [%s]: %+v",
+			credentialId, projectId, err)
+	}
+
+	content, err := s.getCredentialContent(projectId, request.Domain, credentialId, credentialType)
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeJenkinsError(w, r, err)
+		return
+	}
+	w.WriteJson(&BreakGlassRevealResponse{Id: credentialId, Domain: request.Domain, Type: credentialType,
+		BreakGlass: true, Content: content})
+	return
+}
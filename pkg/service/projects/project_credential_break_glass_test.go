@@ -0,0 +1,103 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"kubesphere.io/devops/pkg/config"
+	"kubesphere.io/devops/pkg/db"
+	"kubesphere.io/devops/pkg/models"
+)
+
+func TestBreakGlassAuditedAsCritical(t *testing.T) {
+	s := &ProjectService{Credentials: NewFakeCredentialStore(), Config: &config.Config{}}
+	s.Config.BreakGlass.Roles = []string{ProjectOwner}
+	for _, test := range []struct {
+		name         string
+		role         string
+		url          string
+		body         interface{}
+		credentialId string
+		status       int
+		handler      rest.HandlerFunc
+	}{
+		{name: "create by developer", role: ProjectDeveloper, url: "/projects/project/credentials/break-glass",
+			body: &BreakGlassCredentialRequest{Reason: "incident"}, status: http.StatusForbidden,
+			handler: s.BreakGlassCreateCredentialHandler},
+		{name: "create with a long reason", role: ProjectOwner, url: "/projects/project/credentials/break-glass",
+			body:   &BreakGlassCredentialRequest{Reason: strings.Repeat("incident ", 200)},
+			status: http.StatusBadRequest, handler: s.BreakGlassCreateCredentialHandler},
+		{name: "reveal by developer", role: ProjectDeveloper, url: "/projects/project/credentials/git/break-glass",
+			body: &BreakGlassRevealRequest{Reason: "incident"}, credentialId: "git", status: http.StatusForbidden,
+			handler: s.BreakGlassRevealCredentialHandler},
+		{name: "reveal without reason", role: ProjectOwner, url: "/projects/project/credentials/git/break-glass",
+			body: &BreakGlassRevealRequest{}, credentialId: "git", status: http.StatusBadRequest,
+			handler: s.BreakGlassRevealCredentialHandler},
+	} {
+		r := newFakeStoreJsonRequest("alice", test.role, "POST", test.url, test.body)
+		r.PathParams["cid"] = test.credentialId
+		w := httptest.NewRecorder()
+		audit := s.newCredentialAudit(&recorder{w}, r, CredentialActionBreakGlass)
+		test.handler(audit, r)
+		if w.Code != test.status {
+			t.Fatalf("%s should answer %d, got %d %s", test.name, test.status, w.Code, w.Body)
+		}
+		if audit.entry.Severity != models.CredentialAuditSeverityCritical {
+			t.Fatalf("%s should be audited as critical, got %+v", test.name, audit.entry)
+		}
+		if audit.entry.CredentialId != test.credentialId {
+			t.Fatalf("%s should be audited on credential [%s], got %+v", test.name, test.credentialId, audit.entry)
+		}
+	}
+}
+
+func TestBreakGlassRevealCredentialHandler(t *testing.T) {
+	s, store, projectIds, cleanup := newDbTestService(t, 1)
+	defer cleanup()
+	projectId := projectIds[0]
+	s.Config.BreakGlass.Roles = []string{ProjectMaintainer}
+	if _, err := store.CreateUsernamePasswordCredentialInFolder("_", "git", "admin", "password", "git", "",
+		projectId); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := s.AuditCredentialHandler(CredentialActionBreakGlassReveal, s.BreakGlassRevealCredentialHandler)
+	w := &recorder{httptest.NewRecorder()}
+	r := newProjectJsonRequest("alice", projectId, map[string]string{projectId: ProjectMaintainer}, "POST",
+		"/projects/"+projectId+"/credentials/git/break-glass", &BreakGlassRevealRequest{Reason: "incident 42"})
+	r.PathParams["cid"] = "git"
+	handler(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("break-glass operator should reveal the credential, got %d %s", w.Code, w.Body)
+	}
+	if !strings.Contains(w.Body.String(), `"username":"admin"`) {
+		t.Fatalf("content of the credential should be revealed, got %s", w.Body)
+	}
+
+	entries := []*models.CredentialAuditLog{}
+	_, err := s.Ds.Db.Select(models.CredentialAuditLogColumns...).From(models.CredentialAuditLogTableName).
+		Where(db.Eq(models.ProjectIdColumn, projectId)).Load(&entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Action != CredentialActionBreakGlassReveal || entries[0].CredentialId != "git" ||
+		entries[0].Severity != models.CredentialAuditSeverityCritical || entries[0].Reason != "incident 42" {
+		t.Fatalf("reveal should be audited as critical with its reason, got %+v", entries)
+	}
+}
@@ -0,0 +1,615 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/mitchellh/mapstructure"
+
+	"kubesphere.io/devops/pkg/db"
+	"kubesphere.io/devops/pkg/gojenkins"
+	"kubesphere.io/devops/pkg/models"
+)
+
+// credentialTarget is where a credential is kept in Jenkins, the folder of its project or, for the
+// credentials of the global store, the system store.
+type credentialTarget struct {
+	projectId string
+	domain    string
+	global    bool
+}
+
+func newCredentialTarget(projectId, domain, store string) credentialTarget {
+	if domain == "" {
+		domain = "_"
+	}
+	return credentialTarget{projectId: projectId, domain: domain, global: store == models.CredentialStoreGlobal}
+}
+
+// get reads the credential id from the store of the target.
+func (target credentialTarget) get(ctx context.Context, store CredentialStore,
+	id string) (*gojenkins.CredentialResponse, error) {
+	if target.global {
+		return store.GetCredentialInSystem(target.domain, id)
+	}
+	return store.GetCredentialInFolderContext(ctx, target.domain, id, target.projectId)
+}
+
+// content reads the html of the update page of the credential id from the store of the target.
+func (target credentialTarget) content(ctx context.Context, store CredentialStore, id string) (string, error) {
+	if target.global {
+		return store.GetCredentialContentInSystem(target.domain, id)
+	}
+	return store.GetCredentialContentInFolderContext(ctx, target.domain, id, target.projectId)
+}
+
+// config reads the config.xml of the credential id from the store of the target.
+func (target credentialTarget) config(store CredentialStore, id string) (string, error) {
+	if target.global {
+		return store.GetCredentialConfigInSystem(target.domain, id)
+	}
+	return store.GetCredentialConfigInFolder(target.domain, id, target.projectId)
+}
+
+// delete deletes the credential id from the store of the target.
+func (target credentialTarget) delete(ctx context.Context, store CredentialStore, id string) (*string, error) {
+	if target.global {
+		return store.DeleteCredentialInSystem(target.domain, id)
+	}
+	return store.DeleteCredentialInFolderContext(ctx, target.domain, id, target.projectId)
+}
+
+// recordTarget returns where the credential of projectCredential is kept, credentials without record
+// are in the folder of the project.
+func recordTarget(projectId, domain string, projectCredential *models.ProjectCredential) credentialTarget {
+	if projectCredential == nil {
+		return newCredentialTarget(projectId, domain, models.CredentialStoreFolder)
+	}
+	return newCredentialTarget(projectId, domain, projectCredential.Store)
+}
+
+// credentialTargetOf returns where the credential is kept according to its record.
+func (s *ProjectService) credentialTargetOf(projectId, domain, credentialId string) (credentialTarget, error) {
+	projectCredential, err := s.getProjectCredential(projectId, domain, credentialId)
+	if err != nil {
+		return credentialTarget{}, err
+	}
+	return recordTarget(projectId, domain, projectCredential), nil
+}
+
+// credentialRecordFields are the fields of the record of a credential derived from its content.
+type credentialRecordFields struct {
+	RegistryUrl string
+	ServerUrl   string
+	ExpiresAt   *time.Time
+	Strength    *CredentialStrength
+	KeySize     *CredentialKeySize
+}
+
+// credentialContentRequest is the content of a credential of one type. Every request of
+// credentialRequestFactories validates and creates its credential itself, so credentials of every type are
+// created the same way in project folders and in the system store.
+type credentialContentRequest interface {
+	credentialId() string
+	// prepare validates the request and derives the fields of the record of the credential from it
+	prepare(s *ProjectService) (*credentialRecordFields, error)
+	// create creates the credential in the store of target
+	create(ctx context.Context, store CredentialStore, target credentialTarget) (*string, error)
+	// prepareUpdate validates the complete content of an update, changed holds the fields given with the update
+	prepareUpdate(s *ProjectService, changed map[string]interface{}) (*credentialRecordFields, error)
+	// update updates the credential in the store of target
+	update(ctx context.Context, store CredentialStore, target credentialTarget) (*string, error)
+}
+
+// prepareCredentialRequest decodes content into the request of credentialType and prepares it.
+func (s *ProjectService) prepareCredentialRequest(credentialType string,
+	content map[string]interface{}) (credentialContentRequest, *credentialRecordFields, error) {
+	factory, ok := credentialRequestFactories[credentialType]
+	if !ok {
+		return nil, nil, fmt.Errorf("error unsupport credential type %s", credentialType)
+	}
+	request := factory()
+	err := mapstructure.Decode(content, request)
+	if err != nil {
+		return nil, nil, err
+	}
+	fields, err := request.prepare(s)
+	if err != nil {
+		return nil, nil, err
+	}
+	return request, fields, nil
+}
+
+// prepareCredentialUpdate decodes the complete content of an update of a credentialType credential and
+// prepares it, changed holds the fields given with the update.
+func (s *ProjectService) prepareCredentialUpdate(credentialType string, content,
+	changed map[string]interface{}) (credentialContentRequest, *credentialRecordFields, error) {
+	factory, ok := credentialRequestFactories[credentialType]
+	if !ok {
+		return nil, nil, fmt.Errorf("error unsupport credential type %s", credentialType)
+	}
+	request := factory()
+	err := mapstructure.Decode(content, request)
+	if err != nil {
+		return nil, nil, err
+	}
+	fields, err := request.prepareUpdate(s, changed)
+	if err != nil {
+		return nil, nil, err
+	}
+	return request, fields, nil
+}
+
+// updateCredentialRecordFields records the fields derived from the content of an updated credential,
+// empty fields keep their current values.
+func (s *ProjectService) updateCredentialRecordFields(target credentialTarget, id string,
+	fields *credentialRecordFields) error {
+	err := s.updateRegistryUrl(target.projectId, target.domain, id, fields.RegistryUrl)
+	if err != nil {
+		return err
+	}
+	err = s.updateServerUrl(target.projectId, target.domain, id, fields.ServerUrl)
+	if err != nil {
+		return err
+	}
+	if fields.Strength != nil {
+		err = s.updateCredentialStrength(target.projectId, target.domain, id, fields.Strength)
+		if err != nil {
+			return err
+		}
+	}
+	if fields.KeySize != nil {
+		err = s.updateCredentialKeySize(target.projectId, target.domain, id, fields.KeySize)
+		if err != nil {
+			return err
+		}
+	}
+	if fields.ExpiresAt == nil {
+		return nil
+	}
+	return s.updateCredentialExpiresAt(target.projectId, target.domain, id, fields.ExpiresAt)
+}
+
+// newCredentialRecord returns the record of the credential id created in target for request.
+func newCredentialRecord(target credentialTarget, operator, id string, request *CredentialRequest,
+	fields *credentialRecordFields) *models.ProjectCredential {
+	projectCredential := models.NewProjectCredential(target.projectId, id, target.domain, operator)
+	if target.global {
+		projectCredential.Store = models.CredentialStoreGlobal
+	}
+	projectCredential.RegistryUrl = db.EncryptedString(fields.RegistryUrl)
+	projectCredential.ServerUrl = db.EncryptedString(fields.ServerUrl)
+	projectCredential.ExpiresAt = fields.ExpiresAt
+	setRequestExpiresAt(projectCredential, request.ExpiresAt)
+	setRequestIntendedJobs(projectCredential, request.IntendedJobs)
+	setCredentialStrength(projectCredential, fields.Strength)
+	setCredentialKeySize(projectCredential, fields.KeySize)
+	return projectCredential
+}
+
+// createJenkinsCredential creates the credential of request in target and returns the db record to save for it.
+func (s *ProjectService) createJenkinsCredential(ctx context.Context, target credentialTarget, operator string,
+	request *CredentialRequest) (*models.ProjectCredential, error) {
+	err := s.fillDefaultDescription(target.projectId, operator, target.domain, request.Type, request.Content)
+	if err != nil {
+		return nil, err
+	}
+	if !target.global {
+		err = s.ensureCredentialDomain(target.projectId, target.domain)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var credentialRequest credentialContentRequest
+	var fields *credentialRecordFields
+	if request.jenkinsContent {
+		// like an update changing no field, the secrets are passed back to Jenkins as it encrypted them
+		credentialRequest, fields, err = s.prepareCredentialUpdate(request.Type, request.Content, nil)
+	} else {
+		credentialRequest, fields, err = s.prepareCredentialRequest(request.Type, request.Content)
+	}
+	if err != nil {
+		return nil, err
+	}
+	credentialId, err := credentialRequest.create(ctx, s.credentialStore(), target)
+	if err != nil {
+		return nil, err
+	}
+	projectCredential := newCredentialRecord(target, operator, *credentialId, request, fields)
+	carryCredentialAssessment(projectCredential, request.sourceRecord)
+	return projectCredential, nil
+}
+
+// carryCredentialAssessment sets the strength and key size of a credential created from the content of source,
+// they can not be assessed again from the secrets encrypted by Jenkins. source may be nil.
+func carryCredentialAssessment(projectCredential, source *models.ProjectCredential) {
+	if source == nil {
+		return
+	}
+	projectCredential.StrengthScore = source.StrengthScore
+	projectCredential.StrengthReason = source.StrengthReason
+	projectCredential.KeyAlgorithm = source.KeyAlgorithm
+	projectCredential.KeyBits = source.KeyBits
+}
+
+func (request *UsernamePasswordCredentialRequest) credentialId() string {
+	return request.Id
+}
+
+func (request *UsernamePasswordCredentialRequest) prepare(s *ProjectService) (*credentialRecordFields, error) {
+	return &credentialRecordFields{Strength: s.scoreSecret(request.Password)}, nil
+}
+
+func (request *UsernamePasswordCredentialRequest) create(ctx context.Context, store CredentialStore,
+	target credentialTarget) (*string, error) {
+	if target.global {
+		return store.CreateUsernamePasswordCredentialInSystem(target.domain, request.Id, request.Username,
+			request.Password, request.Description, request.Scope)
+	}
+	return store.CreateUsernamePasswordCredentialInFolderContext(ctx, target.domain, request.Id, request.Username,
+		request.Password, request.Description, request.Scope, target.projectId)
+}
+
+// prepareUpdate scores a new password, the current one is kept in the form encrypted by Jenkins.
+func (request *UsernamePasswordCredentialRequest) prepareUpdate(s *ProjectService,
+	changed map[string]interface{}) (*credentialRecordFields, error) {
+	if _, ok := changed["password"]; !ok {
+		return &credentialRecordFields{}, nil
+	}
+	return &credentialRecordFields{Strength: s.scoreSecret(request.Password)}, nil
+}
+
+func (request *UsernamePasswordCredentialRequest) update(ctx context.Context, store CredentialStore,
+	target credentialTarget) (*string, error) {
+	if target.global {
+		return store.UpdateUsernamePasswordCredentialInSystem(target.domain, request.Id, request.Username,
+			request.Password, request.Description, request.Scope)
+	}
+	return store.UpdateUsernamePasswordCredentialInFolderContext(ctx, target.domain, request.Id, request.Username,
+		request.Password, request.Description, request.Scope, target.projectId)
+}
+
+func (request *DockerRegistryCredentialRequest) credentialId() string {
+	return request.Id
+}
+
+func (request *DockerRegistryCredentialRequest) prepare(s *ProjectService) (*credentialRecordFields, error) {
+	err := validateRegistryUrl(request.RegistryUrl)
+	if err != nil {
+		return nil, err
+	}
+	return &credentialRecordFields{RegistryUrl: request.RegistryUrl, Strength: s.scoreSecret(request.Password)}, nil
+}
+
+// create creates a username with password credential, the registry url is only kept in the record.
+func (request *DockerRegistryCredentialRequest) create(ctx context.Context, store CredentialStore,
+	target credentialTarget) (*string, error) {
+	if target.global {
+		return store.CreateUsernamePasswordCredentialInSystem(target.domain, request.Id, request.Username,
+			request.Password, request.Description, request.Scope)
+	}
+	return store.CreateUsernamePasswordCredentialInFolderContext(ctx, target.domain, request.Id, request.Username,
+		request.Password, request.Description, request.Scope, target.projectId)
+}
+
+func (request *DockerRegistryCredentialRequest) prepareUpdate(s *ProjectService,
+	changed map[string]interface{}) (*credentialRecordFields, error) {
+	if !govalidator.IsNull(request.RegistryUrl) {
+		err := validateRegistryUrl(request.RegistryUrl)
+		if err != nil {
+			return nil, err
+		}
+	}
+	fields := &credentialRecordFields{RegistryUrl: request.RegistryUrl}
+	if _, ok := changed["password"]; ok {
+		fields.Strength = s.scoreSecret(request.Password)
+	}
+	return fields, nil
+}
+
+func (request *DockerRegistryCredentialRequest) update(ctx context.Context, store CredentialStore,
+	target credentialTarget) (*string, error) {
+	if target.global {
+		return store.UpdateUsernamePasswordCredentialInSystem(target.domain, request.Id, request.Username,
+			request.Password, request.Description, request.Scope)
+	}
+	return store.UpdateUsernamePasswordCredentialInFolderContext(ctx, target.domain, request.Id, request.Username,
+		request.Password, request.Description, request.Scope, target.projectId)
+}
+
+func (request *SshCredentialRequest) credentialId() string {
+	return request.Id
+}
+
+func (request *SshCredentialRequest) prepare(s *ProjectService) (*credentialRecordFields, error) {
+	key, err := validateSshPrivateKeys(request.PrivateKey, request.Passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return &credentialRecordFields{KeySize: key}, nil
+}
+
+func (request *SshCredentialRequest) create(ctx context.Context, store CredentialStore,
+	target credentialTarget) (*string, error) {
+	if target.global {
+		return store.CreateSshCredentialInSystem(target.domain, request.Id, request.Username, request.Passphrase,
+			request.PrivateKey, request.Description, request.Scope)
+	}
+	return store.CreateSshCredentialInFolderContext(ctx, target.domain, request.Id, request.Username,
+		request.Passphrase, request.PrivateKey, request.Description, request.Scope, target.projectId)
+}
+
+// prepareUpdate only validates a new private key, the current one is kept in the form encrypted by Jenkins.
+func (request *SshCredentialRequest) prepareUpdate(s *ProjectService,
+	changed map[string]interface{}) (*credentialRecordFields, error) {
+	if _, ok := changed["private_key"]; !ok {
+		return &credentialRecordFields{}, nil
+	}
+	key, err := validateSshPrivateKeys(request.PrivateKey, request.Passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return &credentialRecordFields{KeySize: key}, nil
+}
+
+func (request *SshCredentialRequest) update(ctx context.Context, store CredentialStore,
+	target credentialTarget) (*string, error) {
+	if target.global {
+		return store.UpdateSshCredentialInSystem(target.domain, request.Id, request.Username, request.Passphrase,
+			request.PrivateKey, request.Description, request.Scope)
+	}
+	return store.UpdateSshCredentialInFolderContext(ctx, target.domain, request.Id, request.Username,
+		request.Passphrase, request.PrivateKey, request.Description, request.Scope, target.projectId)
+}
+
+func (request *SecretTextCredentialRequest) credentialId() string {
+	return request.Id
+}
+
+func (request *SecretTextCredentialRequest) prepare(s *ProjectService) (*credentialRecordFields, error) {
+	return &credentialRecordFields{Strength: s.scoreSecret(request.Secret)}, nil
+}
+
+func (request *SecretTextCredentialRequest) create(ctx context.Context, store CredentialStore,
+	target credentialTarget) (*string, error) {
+	if target.global {
+		return store.CreateSecretTextCredentialInSystem(target.domain, request.Id, request.Secret,
+			request.Description, request.Scope)
+	}
+	return store.CreateSecretTextCredentialInFolderContext(ctx, target.domain, request.Id, request.Secret,
+		request.Description, request.Scope, target.projectId)
+}
+
+// prepareUpdate scores a new secret, the current one is kept in the form encrypted by Jenkins.
+func (request *SecretTextCredentialRequest) prepareUpdate(s *ProjectService,
+	changed map[string]interface{}) (*credentialRecordFields, error) {
+	if _, ok := changed["secret"]; !ok {
+		return &credentialRecordFields{}, nil
+	}
+	return &credentialRecordFields{Strength: s.scoreSecret(request.Secret)}, nil
+}
+
+func (request *SecretTextCredentialRequest) update(ctx context.Context, store CredentialStore,
+	target credentialTarget) (*string, error) {
+	if target.global {
+		return store.UpdateSecretTextCredentialInSystem(target.domain, request.Id, request.Secret,
+			request.Description, request.Scope)
+	}
+	return store.UpdateSecretTextCredentialInFolderContext(ctx, target.domain, request.Id, request.Secret,
+		request.Description, request.Scope, target.projectId)
+}
+
+func (request *KubeconfigCredentialRequest) credentialId() string {
+	return request.Id
+}
+
+func (request *KubeconfigCredentialRequest) prepare(s *ProjectService) (*credentialRecordFields, error) {
+	err := validateKubeconfig(request.Content)
+	if err != nil {
+		return nil, err
+	}
+	return &credentialRecordFields{}, nil
+}
+
+func (request *KubeconfigCredentialRequest) create(ctx context.Context, store CredentialStore,
+	target credentialTarget) (*string, error) {
+	if target.global {
+		return store.CreateKubeconfigCredentialInSystem(target.domain, request.Id, request.Content,
+			request.Description, request.Scope)
+	}
+	return store.CreateKubeconfigCredentialInFolderContext(ctx, target.domain, request.Id, request.Content,
+		request.Description, request.Scope, target.projectId)
+}
+
+func (request *KubeconfigCredentialRequest) prepareUpdate(s *ProjectService,
+	changed map[string]interface{}) (*credentialRecordFields, error) {
+	if !govalidator.IsNull(request.Content) {
+		err := validateKubeconfig(request.Content)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &credentialRecordFields{}, nil
+}
+
+func (request *KubeconfigCredentialRequest) update(ctx context.Context, store CredentialStore,
+	target credentialTarget) (*string, error) {
+	if target.global {
+		return store.UpdateKubeconfigCredentialInSystem(target.domain, request.Id, request.Content,
+			request.Description, request.Scope)
+	}
+	return store.UpdateKubeconfigCredentialInFolderContext(ctx, target.domain, request.Id, request.Content,
+		request.Description, request.Scope, target.projectId)
+}
+
+func (request *AWSCredentialRequest) credentialId() string {
+	return request.Id
+}
+
+func (request *AWSCredentialRequest) prepare(s *ProjectService) (*credentialRecordFields, error) {
+	return &credentialRecordFields{}, nil
+}
+
+func (request *AWSCredentialRequest) create(ctx context.Context, store CredentialStore,
+	target credentialTarget) (*string, error) {
+	if target.global {
+		return store.CreateAWSCredentialInSystem(target.domain, request.Id, request.AccessKeyId,
+			request.SecretAccessKey, request.IamRoleArn, request.Description, request.Scope)
+	}
+	return store.CreateAWSCredentialInFolderContext(ctx, target.domain, request.Id, request.AccessKeyId,
+		request.SecretAccessKey, request.IamRoleArn, request.Description, request.Scope, target.projectId)
+}
+
+func (request *AWSCredentialRequest) prepareUpdate(s *ProjectService,
+	changed map[string]interface{}) (*credentialRecordFields, error) {
+	return &credentialRecordFields{}, nil
+}
+
+func (request *AWSCredentialRequest) update(ctx context.Context, store CredentialStore,
+	target credentialTarget) (*string, error) {
+	if target.global {
+		return store.UpdateAWSCredentialInSystem(target.domain, request.Id, request.AccessKeyId,
+			request.SecretAccessKey, request.IamRoleArn, request.Description, request.Scope)
+	}
+	return store.UpdateAWSCredentialInFolderContext(ctx, target.domain, request.Id, request.AccessKeyId,
+		request.SecretAccessKey, request.IamRoleArn, request.Description, request.Scope, target.projectId)
+}
+
+func (request *CertificateCredentialRequest) credentialId() string {
+	return request.Id
+}
+
+// prepare records when the certificate of the keystore expires.
+func (request *CertificateCredentialRequest) prepare(s *ProjectService) (*credentialRecordFields, error) {
+	expiresAt, key, err := validateCertificateKeystore(request.Keystore, request.Password)
+	if err != nil {
+		return nil, err
+	}
+	return &credentialRecordFields{ExpiresAt: expiresAt, KeySize: key}, nil
+}
+
+func (request *CertificateCredentialRequest) create(ctx context.Context, store CredentialStore,
+	target credentialTarget) (*string, error) {
+	if target.global {
+		return store.CreateCertificateCredentialInSystem(target.domain, request.Id, request.Keystore,
+			request.Password, request.Description, request.Scope)
+	}
+	return store.CreateCertificateCredentialInFolderContext(ctx, target.domain, request.Id, request.Keystore,
+		request.Password, request.Description, request.Scope, target.projectId)
+}
+
+// prepareUpdate validates the keystore like on create, it can not be read back from Jenkins.
+func (request *CertificateCredentialRequest) prepareUpdate(s *ProjectService,
+	changed map[string]interface{}) (*credentialRecordFields, error) {
+	return request.prepare(s)
+}
+
+func (request *CertificateCredentialRequest) update(ctx context.Context, store CredentialStore,
+	target credentialTarget) (*string, error) {
+	if target.global {
+		return store.UpdateCertificateCredentialInSystem(target.domain, request.Id, request.Keystore,
+			request.Password, request.Description, request.Scope)
+	}
+	return store.UpdateCertificateCredentialInFolderContext(ctx, target.domain, request.Id, request.Keystore,
+		request.Password, request.Description, request.Scope, target.projectId)
+}
+
+func (request *SecretFileCredentialRequest) credentialId() string {
+	return request.Id
+}
+
+func (request *SecretFileCredentialRequest) prepare(s *ProjectService) (*credentialRecordFields, error) {
+	err := validateSecretFile(request.FileName, request.Content)
+	if err != nil {
+		return nil, err
+	}
+	return &credentialRecordFields{}, nil
+}
+
+func (request *SecretFileCredentialRequest) create(ctx context.Context, store CredentialStore,
+	target credentialTarget) (*string, error) {
+	if target.global {
+		return store.CreateSecretFileCredentialInSystem(target.domain, request.Id, request.FileName,
+			request.Content, request.Description, request.Scope)
+	}
+	return store.CreateSecretFileCredentialInFolderContext(ctx, target.domain, request.Id, request.FileName,
+		request.Content, request.Description, request.Scope, target.projectId)
+}
+
+// prepareUpdate validates the file like on create, it can not be read back from Jenkins.
+func (request *SecretFileCredentialRequest) prepareUpdate(s *ProjectService,
+	changed map[string]interface{}) (*credentialRecordFields, error) {
+	return request.prepare(s)
+}
+
+func (request *SecretFileCredentialRequest) update(ctx context.Context, store CredentialStore,
+	target credentialTarget) (*string, error) {
+	if target.global {
+		return store.UpdateSecretFileCredentialInSystem(target.domain, request.Id, request.FileName,
+			request.Content, request.Description, request.Scope)
+	}
+	return store.UpdateSecretFileCredentialInFolderContext(ctx, target.domain, request.Id, request.FileName,
+		request.Content, request.Description, request.Scope, target.projectId)
+}
+
+func (request *OpenShiftTokenCredentialRequest) credentialId() string {
+	return request.Id
+}
+
+func (request *OpenShiftTokenCredentialRequest) prepare(s *ProjectService) (*credentialRecordFields, error) {
+	err := validateOpenShiftServerUrl(request.ServerUrl)
+	if err != nil {
+		return nil, err
+	}
+	return &credentialRecordFields{ServerUrl: request.ServerUrl}, nil
+}
+
+func (request *OpenShiftTokenCredentialRequest) create(ctx context.Context, store CredentialStore,
+	target credentialTarget) (*string, error) {
+	if target.global {
+		return store.CreateOpenShiftTokenCredentialInSystem(target.domain, request.Id, request.Token,
+			request.Description, request.Scope)
+	}
+	return store.CreateOpenShiftTokenCredentialInFolderContext(ctx, target.domain, request.Id, request.Token,
+		request.Description, request.Scope, target.projectId)
+}
+
+func (request *OpenShiftTokenCredentialRequest) prepareUpdate(s *ProjectService,
+	changed map[string]interface{}) (*credentialRecordFields, error) {
+	if govalidator.IsNull(request.Token) {
+		return nil, fmt.Errorf("token of %s credential [%s] can not be read back, it should be updated",
+			CredentialTypeOpenShiftToken, request.Id)
+	}
+	if !govalidator.IsNull(request.ServerUrl) {
+		err := validateOpenShiftServerUrl(request.ServerUrl)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &credentialRecordFields{ServerUrl: request.ServerUrl}, nil
+}
+
+func (request *OpenShiftTokenCredentialRequest) update(ctx context.Context, store CredentialStore,
+	target credentialTarget) (*string, error) {
+	if target.global {
+		return store.UpdateOpenShiftTokenCredentialInSystem(target.domain, request.Id, request.Token,
+			request.Description, request.Scope)
+	}
+	return store.UpdateOpenShiftTokenCredentialInFolderContext(ctx, target.domain, request.Id, request.Token,
+		request.Description, request.Scope, target.projectId)
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"kubesphere.io/devops/pkg/config"
+	"kubesphere.io/devops/pkg/models"
+)
+
+func TestCreateJenkinsCredentialInFolder(t *testing.T) {
+	store := NewFakeCredentialStore()
+	s := &ProjectService{Credentials: store, Config: &config.Config{}}
+	expiresAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	request := &CredentialRequest{
+		Type: CredentialTypeUsernamePassword,
+		Content: map[string]interface{}{
+			"id": "deploy", "username": "admin", "password": "secret", "description": "deploy",
+		},
+		ExpiresAt:    &expiresAt,
+		IntendedJobs: []string{"pipeline"},
+	}
+
+	projectCredential, err := s.createJenkinsCredential(context.Background(),
+		newCredentialTarget("project", "", ""), "admin", request)
+	if err != nil {
+		t.Fatalf("failed to create credential: %+v", err)
+	}
+	if projectCredential.Store == "global" || projectCredential.Domain != "_" {
+		t.Fatalf("unexpected record %+v", projectCredential)
+	}
+	if projectCredential.ExpiresAt == nil || !projectCredential.ExpiresAt.Equal(expiresAt) {
+		t.Fatalf("expected the request expiry to be recorded, got %v", projectCredential.ExpiresAt)
+	}
+	intendedJobs := parseIntendedJobs(projectCredential)
+	if len(intendedJobs) != 1 || intendedJobs[0] != "pipeline" {
+		t.Fatalf("expected the intended jobs of the request to be recorded, got %v", intendedJobs)
+	}
+	if _, err := store.GetCredentialInFolder("_", "deploy", "project"); err != nil {
+		t.Fatalf("credential should be in the project folder: %+v", err)
+	}
+}
+
+func TestCreateJenkinsCredentialFromJenkinsContent(t *testing.T) {
+	store := NewFakeCredentialStore()
+	s := &ProjectService{Credentials: store, Config: &config.Config{Strength: config.StrengthConfig{
+		Enabled: true, WeakBits: 28, FairBits: 36, StrongBits: 60, VeryStrongBits: 80,
+	}}}
+	key := readTestKey(t, "ssh_rsa.pem")
+	newRequest := func(jenkinsContent bool) *CredentialRequest {
+		return &CredentialRequest{
+			Type: CredentialTypeSsh,
+			Content: map[string]interface{}{
+				"id": "deploy", "username": "git", "private_key": fakeEncryptSecret(key), "description": "deploy",
+			},
+			jenkinsContent: jenkinsContent,
+		}
+	}
+	// the private key read back from Jenkins is encrypted, it is not a key to validate
+	if _, err := s.createJenkinsCredential(context.Background(), newCredentialTarget("project", "", ""), "admin",
+		newRequest(false)); err == nil {
+		t.Fatal("the encrypted private key of a new credential should be rejected")
+	}
+	if _, err := s.createJenkinsCredential(context.Background(), newCredentialTarget("project", "", ""), "admin",
+		newRequest(true)); err != nil {
+		t.Fatalf("credential read back from Jenkins should be created: %+v", err)
+	}
+	if privateKey := fakeCredentialField(t, store, "project", "_", "deploy", "privateKey"); privateKey != key {
+		t.Fatalf("Jenkins should decrypt the private key, got [%s]", privateKey)
+	}
+
+	projectCredential, err := s.createJenkinsCredential(context.Background(),
+		newCredentialTarget("project", "", ""), "admin", &CredentialRequest{
+			Type: CredentialTypeUsernamePassword,
+			Content: map[string]interface{}{
+				"id": "admin", "username": "admin", "password": fakeEncryptSecret("T7#kq9!Zm2@wVx4$"),
+				"description": "admin",
+			},
+			jenkinsContent: true,
+		})
+	if err != nil {
+		t.Fatalf("credential read back from Jenkins should be created: %+v", err)
+	}
+	if projectCredential.StrengthScore != nil {
+		t.Fatalf("the password encrypted by Jenkins should not be scored, got %d", *projectCredential.StrengthScore)
+	}
+
+	score := 3
+	projectCredential, err = s.createJenkinsCredential(context.Background(),
+		newCredentialTarget("other", "", ""), "admin", &CredentialRequest{
+			Type: CredentialTypeUsernamePassword,
+			Content: map[string]interface{}{
+				"id": "admin", "username": "admin", "password": fakeEncryptSecret("T7#kq9!Zm2@wVx4$"),
+				"description": "admin",
+			},
+			jenkinsContent: true,
+			sourceRecord:   &models.ProjectCredential{StrengthScore: &score, StrengthReason: "long"},
+		})
+	if err != nil {
+		t.Fatalf("credential read back from Jenkins should be created: %+v", err)
+	}
+	if projectCredential.StrengthScore == nil || *projectCredential.StrengthScore != score ||
+		projectCredential.StrengthReason != "long" {
+		t.Fatalf("the strength of the source credential should be carried over, got %+v", projectCredential)
+	}
+}
+
+func TestPrepareCredentialRequest(t *testing.T) {
+	s := &ProjectService{Config: &config.Config{}}
+	if _, _, err := s.prepareCredentialRequest("unknown", map[string]interface{}{}); err == nil {
+		t.Fatal("an unknown credential type should be rejected")
+	}
+	for credentialType := range credentialRequestFactories {
+		request, _, err := s.prepareCredentialRequest(credentialType, map[string]interface{}{"id": "id"})
+		if err != nil {
+			continue
+		}
+		if request.credentialId() != "id" {
+			t.Errorf("type [%s] decoded id [%s]", credentialType, request.credentialId())
+		}
+	}
+}
+
+func TestPrepareCredentialUpdateScoresChangedSecret(t *testing.T) {
+	s := &ProjectService{Config: &config.Config{Strength: config.StrengthConfig{
+		Enabled: true, WeakBits: 28, FairBits: 36, StrongBits: 60, VeryStrongBits: 80,
+	}}}
+	content := map[string]interface{}{"id": "token", "secret": "T7#kq9!Zm2@wVx4$"}
+	_, fields, err := s.prepareCredentialUpdate(CredentialTypeSecretText, content,
+		map[string]interface{}{"secret": "T7#kq9!Zm2@wVx4$"})
+	if err != nil {
+		t.Fatalf("should not get error %+v", err)
+	}
+	if fields.Strength == nil || fields.Strength.Score != 4 {
+		t.Fatalf("a changed secret should be scored, got %+v", fields.Strength)
+	}
+	// the current secret is the form encrypted by Jenkins, it must not be scored
+	_, fields, err = s.prepareCredentialUpdate(CredentialTypeSecretText, content,
+		map[string]interface{}{"description": "token"})
+	if err != nil {
+		t.Fatalf("should not get error %+v", err)
+	}
+	if fields.Strength != nil {
+		t.Fatalf("a kept secret should not be scored, got %+v", fields.Strength)
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/ant0ine/go-json-rest/rest"
+
+	"kubesphere.io/devops/pkg/logger"
+	"kubesphere.io/devops/pkg/utils/stringutils"
+	"kubesphere.io/devops/pkg/utils/userutils"
+)
+
+type CredentialBulkResult struct {
+	Id      string `json:"id"`
+	Status  int    `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// BulkDescribeCredentialsHandler updates the descriptions of many credentials in a domain,
+// the request body maps credential id to its new description.
+func (s *ProjectService) BulkDescribeCredentialsHandler(w rest.ResponseWriter, r *rest.Request) {
+	descriptions := make(map[string]string)
+	projectId := r.PathParams["id"]
+	defer s.invalidateCredentialList(projectId)
+	domain := r.URL.Query().Get("domain")
+	operator := userutils.GetUserNameFromRequest(r)
+
+	err := r.DecodeJsonPayload(&descriptions)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	err = s.checkProjectUserInRole(r, operator, projectId, s.credentialActionRoles(CredentialActionUpdate))
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
+		return
+	}
+
+	credentialIds := make([]string, 0, len(descriptions))
+	for credentialId := range descriptions {
+		credentialIds = append(credentialIds, credentialId)
+	}
+	sort.Strings(credentialIds)
+
+	results := make([]*CredentialBulkResult, 0, len(credentialIds))
+	for _, credentialId := range credentialIds {
+		result := &CredentialBulkResult{Id: credentialId, Status: http.StatusOK}
+		patch := map[string]interface{}{"description": descriptions[credentialId]}
+		_, err := s.patchCredential(projectId, domain, credentialId, patch)
+		if err != nil {
+			logger.ErrorContext(r.Context(), "failed to describe credential [%s]: %+v", credentialId, err)
+			result.Status = stringutils.GetJenkinsStatusCode(err)
+			result.Message = cleanErrorMessage(err, result.Status)
+		} else {
+			s.recordCredentialMutation(projectId, operator, CredentialActionUpdate, domain, credentialId, patch)
+			s.markCredentialModified(projectId, domain, credentialId, operator)
+		}
+		results = append(results, result)
+	}
+	w.WriteJson(results)
+	return
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/asaskevich/govalidator"
+
+	"kubesphere.io/devops/pkg/logger"
+	"kubesphere.io/devops/pkg/utils/icsutils"
+	"kubesphere.io/devops/pkg/utils/stringutils"
+	"kubesphere.io/devops/pkg/utils/userutils"
+)
+
+const (
+	credentialCalendarProdId = "-//KubeSphere//DevOps Credentials//EN"
+	credentialReminderBefore = 7 * 24 * time.Hour
+)
+
+// credentialCalendarEvents returns the expiry and rotation due events of the credential,
+// events after until are left out unless until is nil. Rotation is due when the credential
+// has not been changed for the stale days of lint, see credentialChangeTime.
+func (s *ProjectService) credentialCalendarEvents(projectId string, credential *CredentialResponse,
+	changeTime, until *time.Time) []*icsutils.Event {
+	uid := credential.Uuid
+	if govalidator.IsNull(uid) {
+		uid = fmt.Sprintf("%s.%s.%s", projectId, credential.Domain, credential.Id)
+	}
+
+	inRange := func(t time.Time) bool {
+		return until == nil || !t.After(*until)
+	}
+	events := make([]*icsutils.Event, 0)
+	if credential.ExpiresAt != nil && inRange(*credential.ExpiresAt) {
+		events = append(events, &icsutils.Event{
+			Uid:         uid + "-expires@devops.kubesphere.io",
+			Summary:     fmt.Sprintf("Credential %s expires", credential.Id),
+			Description: fmt.Sprintf("Credential [%s] in domain [%s] of project [%s] expires.", credential.Id, credential.Domain, projectId),
+			Date:        *credential.ExpiresAt,
+			AlarmBefore: credentialReminderBefore,
+		})
+	}
+	if changeTime != nil && s.Config.Lint.StaleDays > 0 {
+		rotateAt := changeTime.AddDate(0, 0, s.Config.Lint.StaleDays)
+		if inRange(rotateAt) {
+			events = append(events, &icsutils.Event{
+				Uid:         uid + "-rotation@devops.kubesphere.io",
+				Summary:     fmt.Sprintf("Credential %s rotation due", credential.Id),
+				Description: fmt.Sprintf("Credential [%s] in domain [%s] of project [%s] should be rotated.", credential.Id, credential.Domain, projectId),
+				Date:        rotateAt,
+				AlarmBefore: credentialReminderBefore,
+			})
+		}
+	}
+	return events
+}
+
+// GetCredentialsCalendarHandler serves the rotation deadlines of the project credentials as iCalendar,
+// within limits the events to the given number of days from now.
+func (s *ProjectService) GetCredentialsCalendarHandler(w rest.ResponseWriter, r *rest.Request) {
+	projectId := r.PathParams["id"]
+	operator := userutils.GetUserNameFromRequest(r)
+	within := r.URL.Query().Get("within")
+	err := s.checkProjectUserInRole(r, operator, projectId, []string{ProjectOwner, ProjectMaintainer})
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
+		return
+	}
+
+	var until *time.Time
+	if !govalidator.IsNull(within) {
+		days, err := strconv.Atoi(within)
+		if err != nil || days < 0 {
+			err := fmt.Errorf("error within [%s] should be a non-negative number of days", within)
+			logger.ErrorContext(r.Context(), "%+v", err)
+			writeCredentialError(w, err, http.StatusBadRequest)
+			return
+		}
+		untilTime := time.Now().AddDate(0, 0, days)
+		until = &untilTime
+	}
+
+	credentials, err := s.listCredentials(projectId, "")
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeCredentialError(w, err, stringutils.GetJenkinsStatusCode(err))
+		return
+	}
+	lastRotations, err := s.lastCredentialRotations(projectId)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	calendar := &icsutils.Calendar{
+		ProdId: credentialCalendarProdId,
+		Name:   fmt.Sprintf("%s credential rotation", projectId),
+	}
+	for _, credential := range credentials {
+		changeTime := credentialChangeTime(credential, lastRotations)
+		calendar.Events = append(calendar.Events, s.credentialCalendarEvents(projectId, credential, changeTime, until)...)
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, err = w.(http.ResponseWriter).Write(calendar.Bytes())
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+	}
+	return
+}
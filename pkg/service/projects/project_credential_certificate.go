@@ -0,0 +1,77 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/asaskevich/govalidator"
+
+	"kubesphere.io/devops/pkg/db"
+	"kubesphere.io/devops/pkg/models"
+	"kubesphere.io/devops/pkg/utils/certutils"
+)
+
+// a keystore holds a key and a few certificates, anything bigger is not meant for a credential
+const maxCertificateKeystoreSize = 512 * 1024
+
+// validateCertificateKeystore checks keystore is a base64 encoded PKCS#12 keystore opened by password
+// which holds a private key, it returns the earliest expiry of its certificates and the size of the key.
+func validateCertificateKeystore(keystore, password string) (*time.Time, *CredentialKeySize, error) {
+	if govalidator.IsNull(keystore) {
+		return nil, nil, fmt.Errorf("keystore should not be empty")
+	}
+	if base64.StdEncoding.DecodedLen(len(keystore)) > maxCertificateKeystoreSize+2 {
+		return nil, nil, fmt.Errorf("keystore should not be larger than %d bytes", maxCertificateKeystoreSize)
+	}
+	data, err := base64.StdEncoding.DecodeString(keystore)
+	if err != nil {
+		return nil, nil, fmt.Errorf("keystore should be base64 encoded: %v", err)
+	}
+	if len(data) > maxCertificateKeystoreSize {
+		return nil, nil, fmt.Errorf("keystore should not be larger than %d bytes", maxCertificateKeystoreSize)
+	}
+	notAfter, err := certutils.GetKeystoreNotAfter(data, password)
+	if err == certutils.ErrNotPkcs12 || err == certutils.ErrNoPrivateKey {
+		return nil, nil, fmt.Errorf("keystore is invalid: %v", err)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("keystore can not be read with the password: %v", err)
+	}
+	algorithm, bits, err := certutils.GetKeystoreKeySize(data, password)
+	if err != nil {
+		return nil, nil, fmt.Errorf("keystore is invalid: %v", err)
+	}
+	if govalidator.IsNull(algorithm) {
+		return notAfter, nil, nil
+	}
+	return notAfter, &CredentialKeySize{Algorithm: algorithm, Bits: bits}, nil
+}
+
+func (s *ProjectService) updateCredentialExpiresAt(projectId, domain, credentialId string, expiresAt *time.Time) error {
+	if govalidator.IsNull(domain) {
+		domain = "_"
+	}
+	_, err := s.Ds.Db.Update(models.ProjectCredentialTableName).
+		Set(models.ProjectCredentialExpiresAtColumn, expiresAt).
+		Where(db.And(db.Eq(models.ProjectIdColumn, projectId),
+			db.Eq(models.ProjectCredentialIdColumn, credentialId),
+			db.Eq(models.ProjectCredentialDomainColumn, domain))).Exec()
+	if err != nil && err != db.ErrNotFound {
+		return err
+	}
+	return nil
+}
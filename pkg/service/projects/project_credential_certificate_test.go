@@ -0,0 +1,66 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readTestKeystore reads a keystore of testdata base64 encoded, certificate.p12 holds the key and the certificate
+// of certificate.pem and certificate_no_key.p12 only the certificate, both with the password "secret".
+func readTestKeystore(t *testing.T, name string) string {
+	keystore, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("should not get error %+v", err)
+	}
+	return base64.StdEncoding.EncodeToString(keystore)
+}
+
+func Test_ValidateCertificateKeystore(t *testing.T) {
+	notAfter := time.Date(2036, 10, 13, 19, 4, 29, 0, time.UTC)
+	expiresAt, key, err := validateCertificateKeystore(readTestKeystore(t, "certificate.p12"), "secret")
+	if err != nil {
+		t.Fatalf("should not get error %+v", err)
+	}
+	if !expiresAt.Equal(notAfter) {
+		t.Fatalf("expires at [%s] should equal [%s]", expiresAt, notAfter)
+	}
+	if key == nil || *key != (CredentialKeySize{Algorithm: "ecdsa", Bits: 256}) {
+		t.Fatalf("key should be a 256 bits ecdsa key, got %+v", key)
+	}
+
+	for _, test := range []struct {
+		name     string
+		keystore string
+		password string
+	}{
+		{name: "empty"},
+		{name: "not base64", keystore: "not base64!"},
+		{name: "not a keystore", keystore: base64.StdEncoding.EncodeToString([]byte("not a keystore"))},
+		{name: "too large", keystore: strings.Repeat("A", maxCertificateKeystoreSize*2)},
+		{name: "wrong password", keystore: readTestKeystore(t, "certificate.p12"), password: "other"},
+		{name: "pem certificate", keystore: readTestKeystore(t, "certificate.pem"), password: "secret"},
+		{name: "no private key", keystore: readTestKeystore(t, "certificate_no_key.p12"), password: "secret"},
+	} {
+		_, _, err := validateCertificateKeystore(test.keystore, test.password)
+		if err == nil {
+			t.Fatalf("%s: keystore should be rejected", test.name)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ant0ine/go-json-rest/rest"
+
+	"kubesphere.io/devops/pkg/config"
+	"kubesphere.io/devops/pkg/logger"
+	"kubesphere.io/devops/pkg/models"
+	"kubesphere.io/devops/pkg/utils/webhookutils"
+)
+
+// CredentialChangeEvent tells a change webhook that a credential was created, updated or deleted,
+// it never carries secret values.
+type CredentialChangeEvent struct {
+	CredentialWebhookEvent
+	Action    string    `json:"action"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// CredentialChangeDispatcher delivers credential change events, Dispatch must not block the caller.
+type CredentialChangeDispatcher interface {
+	Dispatch(event *CredentialChangeEvent)
+}
+
+// WebhookChangeDispatcher posts every event to each of its urls in the background,
+// failed deliveries are retried with an exponential backoff.
+type WebhookChangeDispatcher struct {
+	Urls          []string
+	Secret        string
+	Timeout       time.Duration
+	Retries       int
+	RetryInterval time.Duration
+}
+
+func NewWebhookChangeDispatcher(webhookConfig *config.WebhookConfig) *WebhookChangeDispatcher {
+	return &WebhookChangeDispatcher{
+		Urls:          webhookConfig.ChangeUrls,
+		Secret:        webhookConfig.Secret,
+		Timeout:       webhookConfig.Timeout,
+		Retries:       webhookConfig.ChangeRetries,
+		RetryInterval: webhookConfig.ChangeRetryInterval,
+	}
+}
+
+func (d *WebhookChangeDispatcher) Dispatch(event *CredentialChangeEvent) {
+	for _, url := range d.Urls {
+		go d.deliver(url, event)
+	}
+}
+
+// deliver posts event to url until it is accepted or the retries are used up,
+// only unreachable urls, 429 and 5xx answers are retried.
+func (d *WebhookChangeDispatcher) deliver(url string, event *CredentialChangeEvent) bool {
+	interval := d.RetryInterval
+	for attempt := 0; ; attempt++ {
+		statusCode, err := webhookutils.PostJSON(url, d.Secret, d.Timeout, event, nil)
+		if statusCode >= 200 && statusCode <= 299 {
+			return true
+		}
+		retryable := statusCode == 0 || statusCode == http.StatusTooManyRequests ||
+			statusCode >= http.StatusInternalServerError
+		if !retryable || attempt >= d.Retries {
+			logger.Error("failed to notify [%s] of %s of credential [%s] in project [%s], status %d: %+v",
+				url, event.Action, event.CredentialId, event.ProjectId, statusCode, err)
+			return false
+		}
+		time.Sleep(interval)
+		interval *= 2
+	}
+}
+
+// notifyCredentialChange dispatches the change audited by entry, the type is the one the handler
+// named for its credential operation.
+func (s *ProjectService) notifyCredentialChange(entry *models.CredentialAuditLog, credentialType string) {
+	if s.ChangeDispatcher == nil {
+		return
+	}
+	s.ChangeDispatcher.Dispatch(&CredentialChangeEvent{
+		CredentialWebhookEvent: CredentialWebhookEvent{
+			ProjectId:    entry.ProjectId,
+			CredentialId: entry.CredentialId,
+			Type:         credentialType,
+			Domain:       entry.Domain,
+			Operator:     entry.Operator,
+		},
+		Action:    entry.Action,
+		Timestamp: time.Now(),
+	})
+}
+
+// setDeletedCredentialType names the type of the credential r is about to delete, the change
+// webhooks could not tell it once the credential is gone.
+func (s *ProjectService) setDeletedCredentialType(r *rest.Request, projectId, domain, credentialId string) {
+	if s.ChangeDispatcher == nil {
+		return
+	}
+	credential, err := s.credentialStore().GetCredentialInFolderContext(r.Context(), domain, credentialId, projectId)
+	if err != nil {
+		logger.WarnContext(r.Context(), "failed to get type of credential [%s]: %+v", credentialId, err)
+		return
+	}
+	credentialType, err := s.getCredentialType(projectId, credential)
+	if err != nil {
+		logger.WarnContext(r.Context(), "failed to get type of credential [%s]: %+v", credentialId, err)
+		return
+	}
+	setCredentialOperationType(r, credentialType)
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"kubesphere.io/devops/pkg/utils/webhookutils"
+)
+
+func TestWebhookChangeDispatcherDeliver(t *testing.T) {
+	attempts := 0
+	var received *CredentialChangeEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.Header.Get(webhookutils.SignatureHeader) != webhookutils.Sign("secret", body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		received = &CredentialChangeEvent{}
+		json.Unmarshal(body, received)
+	}))
+	defer server.Close()
+
+	dispatcher := &WebhookChangeDispatcher{Secret: "secret", Timeout: time.Second, Retries: 3,
+		RetryInterval: time.Millisecond}
+	event := &CredentialChangeEvent{
+		CredentialWebhookEvent: CredentialWebhookEvent{ProjectId: "project", CredentialId: "github",
+			Type: CredentialTypeUsernamePassword, Operator: "admin"},
+		Action:    CredentialActionCreate,
+		Timestamp: time.Now(),
+	}
+	if !dispatcher.deliver(server.URL, event) {
+		t.Fatalf("expected the event to be delivered")
+	}
+	if attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", attempts)
+	}
+	if received == nil || received.CredentialId != "github" || received.Action != CredentialActionCreate {
+		t.Fatalf("unexpected event %+v", received)
+	}
+
+	dispatcher.Secret = "other"
+	attempts = 2
+	if dispatcher.deliver(server.URL, event) {
+		t.Fatalf("expected a rejected event not to be delivered")
+	}
+	if attempts != 3 {
+		t.Fatalf("expected a 401 not to be retried, got %d attempts", attempts)
+	}
+}
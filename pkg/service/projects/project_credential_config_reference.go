@@ -0,0 +1,81 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"strings"
+
+	"github.com/beevik/etree"
+)
+
+const (
+	CredentialConfigReferenceSharedLibrary = "shared_library"
+	CredentialConfigReferenceFolder        = "folder"
+
+	libraryConfigurationTag = "org.jenkinsci.plugins.workflow.libs.LibraryConfiguration"
+)
+
+// parseConfigCredentialReferences finds the credentialsId elements of a folder config.xml holding credentialId.
+// References inside a library configuration are reported as shared libraries named after the library,
+// other references as folder configuration.
+func parseConfigCredentialReferences(configXml, credentialId string) ([]*CredentialConfigReference, error) {
+	doc, err := readJenkinsXml(configXml)
+	if err != nil {
+		return nil, err
+	}
+	references := make([]*CredentialConfigReference, 0)
+	if doc.Root() == nil {
+		return references, nil
+	}
+	for _, element := range doc.Root().FindElements("//credentialsId") {
+		if strings.TrimSpace(element.Text()) != credentialId {
+			continue
+		}
+		tags := make([]string, 0)
+		var library *etree.Element
+		for current := element; current != nil && current.Tag != ""; current = current.Parent() {
+			tags = append([]string{current.Tag}, tags...)
+			if library == nil && current.Tag == libraryConfigurationTag {
+				library = current
+			}
+		}
+		reference := &CredentialConfigReference{
+			Kind: CredentialConfigReferenceFolder,
+			Path: strings.Join(tags, "/"),
+		}
+		if library != nil {
+			reference.Kind = CredentialConfigReferenceSharedLibrary
+			if name := library.SelectElement("name"); name != nil {
+				reference.Name = strings.TrimSpace(name.Text())
+			}
+		}
+		references = append(references, reference)
+	}
+	return references, nil
+}
+
+// getCredentialConfigReferences scans the project folder configuration, where folder level shared libraries
+// are defined. Credentials of the project folder are not visible to the global configuration, so global
+// libraries and tools can not reference them and are not scanned.
+func (s *ProjectService) getCredentialConfigReferences(projectId, credentialId string) ([]*CredentialConfigReference, error) {
+	folder, err := s.Ds.Jenkins.GetFolder(projectId)
+	if err != nil {
+		return nil, err
+	}
+	configXml, err := folder.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	return parseConfigCredentialReferences(configXml, credentialId)
+}
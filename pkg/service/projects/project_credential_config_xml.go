@@ -0,0 +1,96 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/ant0ine/go-json-rest/rest"
+
+	"kubesphere.io/devops/pkg/logger"
+	"kubesphere.io/devops/pkg/utils/userutils"
+)
+
+// CredentialFormatXml returns the credential config.xml of Jenkins as it is, with secrets masked.
+const CredentialFormatXml = "xml"
+
+const maskedCredentialSecret = "********"
+
+// credentialSecretElements are the config.xml elements holding secrets, Jenkins writes them encrypted
+// or redacted but any plaintext value left is masked.
+var credentialSecretElements = []*regexp.Regexp{
+	secretElementPattern("password"),
+	secretElementPattern("secret"),
+	secretElementPattern("secretKey"),
+	secretElementPattern("secretBytes"),
+	secretElementPattern("passphrase"),
+	secretElementPattern("privateKey"),
+	secretElementPattern("uploadedKeystoreBytes"),
+	secretElementPattern("content"),
+}
+
+// jenkinsEncryptedSecret matches the values Jenkins encrypted with its own key.
+var jenkinsEncryptedSecret = regexp.MustCompile(`^\s*\{[A-Za-z0-9+/=]+\}\s*$`)
+
+func secretElementPattern(name string) *regexp.Regexp {
+	return regexp.MustCompile(fmt.Sprintf(`(?s)(<%s>)(.*?)(</%s>)`, name, name))
+}
+
+func maskCredentialConfigXml(configXml string) string {
+	for _, pattern := range credentialSecretElements {
+		configXml = pattern.ReplaceAllStringFunc(configXml, func(element string) string {
+			parts := pattern.FindStringSubmatch(element)
+			if parts[2] == "" || jenkinsEncryptedSecret.MatchString(parts[2]) {
+				return element
+			}
+			return parts[1] + maskedCredentialSecret + parts[3]
+		})
+	}
+	return configXml
+}
+
+// writeCredentialConfigXml answers the credential config.xml to project owners, for debugging and for
+// migrating credentials between Jenkins instances.
+func (s *ProjectService) writeCredentialConfigXml(w rest.ResponseWriter, r *rest.Request, format string) {
+	projectId := r.PathParams["id"]
+	credentialId := r.PathParams["cid"]
+	operator := userutils.GetUserNameFromRequest(r)
+	if format != CredentialFormatXml {
+		err := fmt.Errorf("error format [%s] not in [%s]", format, CredentialFormatXml)
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	err := s.checkProjectUserInRole(r, operator, projectId, []string{ProjectOwner})
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
+		return
+	}
+	configXml, err := s.credentialStore().GetCredentialConfigInFolder(r.URL.Query().Get("domain"), credentialId, projectId)
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeJenkinsError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, err = w.(http.ResponseWriter).Write([]byte(maskCredentialConfigXml(configXml)))
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+	}
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"testing"
+)
+
+func TestMaskCredentialConfigXml(t *testing.T) {
+	configXml := `<com.cloudbees.plugins.credentials.impl.UsernamePasswordCredentialsImpl>
+  <scope>GLOBAL</scope>
+  <id>git</id>
+  <username>admin</username>
+  <password>{AQAAABAAAAAQ1234+/=}</password>
+  <content>apiVersion: v1
+kind: Config</content>
+  <privateKey></privateKey>
+</com.cloudbees.plugins.credentials.impl.UsernamePasswordCredentialsImpl>`
+	expected := `<com.cloudbees.plugins.credentials.impl.UsernamePasswordCredentialsImpl>
+  <scope>GLOBAL</scope>
+  <id>git</id>
+  <username>admin</username>
+  <password>{AQAAABAAAAAQ1234+/=}</password>
+  <content>********</content>
+  <privateKey></privateKey>
+</com.cloudbees.plugins.credentials.impl.UsernamePasswordCredentialsImpl>`
+	if masked := maskCredentialConfigXml(configXml); masked != expected {
+		t.Fatalf("expected\n%s\ngot\n%s", expected, masked)
+	}
+}
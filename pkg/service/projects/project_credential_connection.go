@@ -0,0 +1,181 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/asaskevich/govalidator"
+
+	"kubesphere.io/devops/pkg/logger"
+	"kubesphere.io/devops/pkg/utils/reflectutils"
+	"kubesphere.io/devops/pkg/utils/userutils"
+)
+
+// credential types Jenkins can test against a git repository, over http or ssh
+var testCredentialTypes = []string{CredentialTypeUsernamePassword, CredentialTypeSsh}
+
+// credential types that can be tested before they are saved, see verifyCredentialRequest
+var verifiableCredentialTypes = []string{CredentialTypeUsernamePassword, CredentialTypeSsh, CredentialTypeKubeConfig,
+	CredentialTypeOpenShiftToken}
+
+// git remote schemes credentials are tested against, local paths and file urls are not remotes
+var gitRepositoryUrlSchemes = []string{"http", "https", "ssh", "git"}
+
+// scpLikeGitUrl is the [user@]host:path form of ssh remotes
+var scpLikeGitUrl = regexp.MustCompile(`^(?:[^@/:]+@)?([A-Za-z0-9][A-Za-z0-9.-]*|\[[0-9A-Fa-f:.]+\]):[^/].*$`)
+
+// validateGitRepositoryUrl checks repoUrl is the url of a remote git repository and returns its host.
+func validateGitRepositoryUrl(repoUrl string) (string, error) {
+	if !strings.Contains(repoUrl, "://") {
+		match := scpLikeGitUrl.FindStringSubmatch(repoUrl)
+		if match == nil {
+			return "", fmt.Errorf("url should be the url of a remote git repository")
+		}
+		return strings.Trim(match[1], "[]"), nil
+	}
+	u, err := url.Parse(repoUrl)
+	if err != nil || !reflectutils.In(u.Scheme, gitRepositoryUrlSchemes) || u.Hostname() == "" {
+		return "", fmt.Errorf("url should be the url of a remote git repository, with scheme %s",
+			gitRepositoryUrlSchemes)
+	}
+	return u.Hostname(), nil
+}
+
+type TestCredentialRequest struct {
+	Domain string `json:"domain"`
+	// url of the git repository the credential is tested against
+	Url string `json:"url"`
+}
+
+type TestCredentialResponse struct {
+	Success bool   `json:"success"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// TestCredentialHandler asks Jenkins whether the credential can access a git repository. Ssh credentials
+// are tested with a handshake against the repository host, username password ones over http.
+func (s *ProjectService) TestCredentialHandler(w rest.ResponseWriter, r *rest.Request) {
+	request := &TestCredentialRequest{}
+	projectId := r.PathParams["id"]
+	credentialId := r.PathParams["cid"]
+	ctx := r.Context()
+	operator := userutils.GetUserNameFromRequest(r)
+	err := r.DecodeJsonPayload(request)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	if govalidator.IsNull(request.Domain) {
+		request.Domain = r.URL.Query().Get("domain")
+	}
+	host, err := validateGitRepositoryUrl(request.Url)
+	if err != nil {
+		logger.WarnContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	err = s.checkProjectUserInRole(r, operator, projectId, []string{ProjectOwner, ProjectMaintainer})
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
+		return
+	}
+	// Jenkins connects to the repository, the host is only checked once here
+	verifier, err := s.verifier(s.Config.Verify.Timeout)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	err = verifier.CheckHost(host)
+	if err != nil {
+		logger.WarnContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	jenkinsCredential, err := s.credentialStore().GetCredentialInFolderContext(ctx, request.Domain, credentialId,
+		projectId)
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeJenkinsError(w, r, err)
+		return
+	}
+	credentialType, err := s.getCredentialType(projectId, jenkinsCredential)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if !reflectutils.In(credentialType, testCredentialTypes) {
+		err := fmt.Errorf("%s credentials can not be tested, only %s can", credentialType, testCredentialTypes)
+		logger.WarnContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusUnprocessableEntity)
+		return
+	}
+	success, reason, err := s.Ds.Jenkins.VerifyGitCredentialInFolderContext(ctx, credentialId, request.Url,
+		projectId)
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeJenkinsError(w, r, err)
+		return
+	}
+	response := &TestCredentialResponse{Success: success}
+	if !success {
+		response.Reason = reason
+	}
+	w.WriteJson(response)
+	return
+}
+
+// TestNewCredentialHandler connects with a credential before it is created, the same way a project requiring
+// verification does on create. The credential is not saved and Jenkins is not involved.
+func (s *ProjectService) TestNewCredentialHandler(w rest.ResponseWriter, r *rest.Request) {
+	request := &CredentialRequest{}
+	projectId := r.PathParams["id"]
+	operator := userutils.GetUserNameFromRequest(r)
+	err := r.DecodeJsonPayload(request)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	err = s.checkProjectUserInRole(r, operator, projectId, s.credentialActionRoles(CredentialActionCreate))
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
+		return
+	}
+	if !reflectutils.In(request.Type, verifiableCredentialTypes) {
+		err := fmt.Errorf("%s credentials can not be tested, only %s can", request.Type, verifiableCredentialTypes)
+		logger.WarnContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusUnprocessableEntity)
+		return
+	}
+	response := &TestCredentialResponse{Success: true}
+	err = s.verifyCredentialRequest(request)
+	if err != nil {
+		response.Success = false
+		response.Reason = err.Error()
+	}
+	w.WriteJson(response)
+	return
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"testing"
+)
+
+func TestValidateGitRepositoryUrl(t *testing.T) {
+	for _, test := range []struct {
+		url  string
+		host string
+	}{
+		{url: "https://github.com/kubesphere/devops.git", host: "github.com"},
+		{url: "http://10.0.0.2:8080/scm/devops.git", host: "10.0.0.2"},
+		{url: "ssh://git@github.com:22/kubesphere/devops.git", host: "github.com"},
+		{url: "git://github.com/kubesphere/devops.git", host: "github.com"},
+		{url: "git@github.com:kubesphere/devops.git", host: "github.com"},
+		{url: "github.com:kubesphere/devops.git", host: "github.com"},
+		{url: "git@[::1]:devops.git", host: "::1"},
+		{url: "file:///var/jenkins_home/secrets"},
+		{url: "/var/jenkins_home/secrets"},
+		{url: "./devops.git"},
+		{url: "ftp://github.com/devops.git"},
+		{url: "https:///devops.git"},
+		{url: "git@github.com:/"},
+		{url: ""},
+	} {
+		host, err := validateGitRepositoryUrl(test.url)
+		if test.host == "" && err == nil {
+			t.Fatalf("url [%s] should be invalid, got host [%s]", test.url, host)
+		}
+		if test.host != "" && (err != nil || host != test.host) {
+			t.Fatalf("url [%s] should have host [%s], got [%s] and %v", test.url, test.host, host, err)
+		}
+	}
+}
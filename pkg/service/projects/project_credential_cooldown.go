@@ -0,0 +1,122 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/asaskevich/govalidator"
+
+	"kubesphere.io/devops/pkg/constants"
+	"kubesphere.io/devops/pkg/db"
+	"kubesphere.io/devops/pkg/logger"
+	"kubesphere.io/devops/pkg/models"
+)
+
+// recordCredentialDeletion keeps the last deletion of the credential id for the recreate cooldown.
+func (s *ProjectService) recordCredentialDeletion(projectId, domain, credentialId, operator string) error {
+	deletion := models.NewProjectCredentialDeletion(projectId, credentialId, domain, operator)
+	_, err := s.Ds.Db.DeleteFrom(models.ProjectCredentialDeletionTableName).
+		Where(db.And(db.Eq(models.ProjectIdColumn, projectId),
+			db.Eq(models.ProjectCredentialIdColumn, credentialId),
+			db.Eq(models.ProjectCredentialDomainColumn, deletion.Domain))).Exec()
+	if err != nil && err != db.ErrNotFound {
+		return err
+	}
+	_, err = s.Ds.Db.InsertInto(models.ProjectCredentialDeletionTableName).
+		Columns(models.ProjectCredentialDeletionColumns...).
+		Record(deletion).Exec()
+	return err
+}
+
+// getRecreateCooldown returns how long the credential id still can not be created again,
+// 0 if the project has no cooldown or the id was not deleted recently.
+func (s *ProjectService) getRecreateCooldown(projectId, domain, credentialId string) (time.Duration, error) {
+	policy, err := s.getCredentialPolicy(projectId)
+	if err != nil {
+		return 0, err
+	}
+	if policy.RecreateCooldown <= 0 {
+		return 0, nil
+	}
+	if govalidator.IsNull(domain) {
+		domain = "_"
+	}
+	deletion := &models.ProjectCredentialDeletion{}
+	err = s.Ds.Db.Select(models.ProjectCredentialDeletionColumns...).
+		From(models.ProjectCredentialDeletionTableName).
+		Where(db.And(db.Eq(models.ProjectIdColumn, projectId),
+			db.Eq(models.ProjectCredentialIdColumn, credentialId),
+			db.Eq(models.ProjectCredentialDomainColumn, domain))).
+		LoadOne(deletion)
+	if err == db.ErrNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	remaining := deletion.DeleteTime.Add(time.Duration(policy.RecreateCooldown) * time.Second).Sub(time.Now())
+	if remaining < 0 {
+		return 0, nil
+	}
+	return remaining, nil
+}
+
+// parseCooldownOverride reads the override_cooldown query parameter.
+func parseCooldownOverride(r *rest.Request) (bool, error) {
+	overrideParam := r.URL.Query().Get("override_cooldown")
+	if govalidator.IsNull(overrideParam) {
+		return false, nil
+	}
+	return strconv.ParseBool(overrideParam)
+}
+
+// checkCooldownOverride only lets admin override the recreate cooldown.
+func checkCooldownOverride(operator string, override bool) error {
+	if override && operator != constants.KS_ADMIN {
+		return fmt.Errorf("user [%s] can not override the recreate cooldown", operator)
+	}
+	return nil
+}
+
+// writeCooldownError rejects a recreate within the cooldown with the remaining seconds.
+func writeCooldownError(w rest.ResponseWriter, credentialId string, remainingSeconds int) {
+	err := fmt.Errorf("credential [%s] was deleted recently, it can be created again in %ds, "+
+		"update or rotate credentials instead of recreating them", credentialId, remainingSeconds)
+	logger.Warn("%+v", err)
+	w.Header().Set("Retry-After", strconv.Itoa(remainingSeconds))
+	writeCredentialError(w, err, http.StatusTooManyRequests)
+}
+
+// checkRecreateCooldown returns the seconds, rounded up, the credential id still has to wait before it can be
+// created again. It is 0 when the creation is allowed or the cooldown is overridden.
+func (s *ProjectService) checkRecreateCooldown(projectId, domain, credentialId string,
+	override bool) (remainingSeconds int, err error) {
+	remaining, err := s.getRecreateCooldown(projectId, domain, credentialId)
+	if err != nil || remaining == 0 {
+		return 0, err
+	}
+	remainingSeconds = int(math.Ceil(remaining.Seconds()))
+	if override {
+		logger.Warn("recreate cooldown of credential [%s] in project [%s] overridden with %ds remaining",
+			credentialId, projectId, remainingSeconds)
+		return 0, nil
+	}
+	return remainingSeconds, nil
+}
@@ -0,0 +1,266 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/asaskevich/govalidator"
+
+	"kubesphere.io/devops/pkg/gojenkins"
+	"kubesphere.io/devops/pkg/logger"
+	"kubesphere.io/devops/pkg/models"
+	"kubesphere.io/devops/pkg/utils/stringutils"
+	"kubesphere.io/devops/pkg/utils/userutils"
+)
+
+// content field holding the secret of each type, a credential is only copied when it could be read back
+var copyCredentialSecretFields = map[string]string{
+	CredentialTypeUsernamePassword: "password",
+	CredentialTypeDockerRegistry:   "password",
+	CredentialTypeSsh:              "private_key",
+	CredentialTypeSecretText:       "secret",
+	CredentialTypeKubeConfig:       "content",
+	CredentialTypeAWS:              "secret_access_key",
+}
+
+type CopyCredentialRequest struct {
+	Domain          string `json:"domain"`
+	TargetProjectId string `json:"target_project_id"`
+}
+
+type CopyCredentialResponse struct {
+	Id        string `json:"id"`
+	Domain    string `json:"domain"`
+	ProjectId string `json:"project_id"`
+}
+
+// CopyCredentialHandler creates the credential of the project with the same id, domain and content in
+// the target project. Secrets are read back from the Jenkins form of the credential, which holds them
+// encrypted by the Jenkins instance both folders belong to.
+func (s *ProjectService) CopyCredentialHandler(w rest.ResponseWriter, r *rest.Request) {
+	request := &CopyCredentialRequest{}
+	projectId := r.PathParams["id"]
+	credentialId := r.PathParams["cid"]
+	operator := userutils.GetUserNameFromRequest(r)
+	err := r.DecodeJsonPayload(request)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	if govalidator.IsNull(request.Domain) {
+		request.Domain = r.URL.Query().Get("domain")
+	}
+	if govalidator.IsNull(request.Domain) {
+		request.Domain = "_"
+	}
+	if govalidator.IsNull(request.TargetProjectId) || request.TargetProjectId == projectId {
+		err := fmt.Errorf("target_project_id should be another project")
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	defer s.invalidateCredentialList(request.TargetProjectId)
+	// the content of the credential is read in its project and created in the target project
+	for _, check := range []struct{ projectId, action string }{
+		{projectId: projectId, action: CredentialActionView},
+		{projectId: request.TargetProjectId, action: CredentialActionCreate},
+	} {
+		err = s.checkProjectUserInRole(r, operator, check.projectId, s.credentialActionRoles(check.action))
+		if err != nil {
+			logger.ErrorContext(r.Context(), "%+v", err)
+			writeCredentialError(w, err, http.StatusForbidden)
+			return
+		}
+	}
+
+	jenkinsCredential, err := s.credentialStore().GetCredentialInFolder(request.Domain, credentialId, projectId)
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeCredentialError(w, err, stringutils.GetJenkinsStatusCode(err))
+		return
+	}
+	credentialType, err := s.getCredentialType(projectId, jenkinsCredential)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	secretField, ok := copyCredentialSecretFields[credentialType]
+	if !ok {
+		err := fmt.Errorf("%s credentials can not be copied, their secret can not be read back from Jenkins",
+			credentialType)
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusUnprocessableEntity)
+		return
+	}
+	content, err := s.getCredentialContent(projectId, request.Domain, credentialId, credentialType)
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeCredentialError(w, err, stringutils.GetJenkinsStatusCode(err))
+		return
+	}
+	if secret, _ := content[secretField].(string); govalidator.IsNull(secret) {
+		err := fmt.Errorf("%s of credential [%s] can not be read back from Jenkins, "+
+			"create the credential in the target project instead", secretField, credentialId)
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusUnprocessableEntity)
+		return
+	}
+
+	existing, err := s.credentialStore().GetCredentialInFolder(request.Domain, credentialId, request.TargetProjectId)
+	if existing != nil {
+		err := fmt.Errorf("credential id [%s] has been used in project [%s]", credentialId, request.TargetProjectId)
+		logger.WarnContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusConflict)
+		return
+	}
+	if err != nil && !errors.Is(err, gojenkins.ErrCredentialNotFound) {
+		logCredentialError(r.Context(), err)
+		writeCredentialError(w, err, stringutils.GetJenkinsStatusCode(err))
+		return
+	}
+	sourceRecord, err := s.getProjectCredential(projectId, request.Domain, credentialId)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	createRequest := &CredentialRequest{Type: credentialType, Domain: request.Domain, Content: content,
+		jenkinsContent: true, sourceRecord: sourceRecord}
+	status, err := s.checkCredentialCreate(request.TargetProjectId, operator, createRequest, credentialId, false)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, status)
+		return
+	}
+	_, err = s.createCredentialContent(r.Context(),
+		newCredentialTarget(request.TargetProjectId, request.Domain, models.CredentialStoreFolder), operator, createRequest)
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeCredentialError(w, err, stringutils.GetJenkinsStatusCode(err))
+		return
+	}
+	logger.Info("credential [%s] copied from project [%s] to [%s] by [%s]", credentialId, projectId,
+		request.TargetProjectId, operator)
+	w.WriteJson(&CopyCredentialResponse{Id: credentialId, Domain: request.Domain, ProjectId: request.TargetProjectId})
+	return
+}
+
+// CopySshCredentialHandler creates a copy of an ssh credential of the project under another id in the same
+// domain, so that its keys can be rotated with an overlap. The copy is created from the config.xml of the
+// credential, which holds its keys encrypted by Jenkins, and is recorded like a created credential.
+func (s *ProjectService) CopySshCredentialHandler(w rest.ResponseWriter, r *rest.Request) {
+	request := &CopySshCredentialRequest{}
+	projectId := r.PathParams["id"]
+	defer s.invalidateCredentialList(projectId)
+	credentialId := r.PathParams["cid"]
+	operator := userutils.GetUserNameFromRequest(r)
+	setCredentialOperationType(r, CredentialTypeSsh)
+	err := r.DecodeJsonPayload(request)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	if govalidator.IsNull(request.Domain) {
+		request.Domain = r.URL.Query().Get("domain")
+	}
+	if govalidator.IsNull(request.Domain) {
+		request.Domain = "_"
+	}
+	if govalidator.IsNull(request.Id) || request.Id == credentialId {
+		err := fmt.Errorf("id of the copy should be another id")
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	err = validateCredentialId(request.Id)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	err = s.checkProjectUserInRole(r, operator, projectId, s.credentialActionRoles(CredentialActionCreate))
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
+		return
+	}
+
+	jenkinsCredential, err := s.credentialStore().GetCredentialInFolder(request.Domain, credentialId, projectId)
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeJenkinsError(w, r, err)
+		return
+	}
+	credentialType, err := s.getCredentialType(projectId, jenkinsCredential)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if credentialType != CredentialTypeSsh {
+		err := fmt.Errorf("credential [%s] is a %s credential, only ssh credentials can be copied in a project",
+			credentialId, credentialType)
+		logger.WarnContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusUnprocessableEntity)
+		return
+	}
+	content, err := s.getCredentialContent(projectId, request.Domain, credentialId, credentialType)
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeJenkinsError(w, r, err)
+		return
+	}
+	content["id"] = request.Id
+	createRequest := &CredentialRequest{Type: credentialType, Domain: request.Domain, Content: content,
+		jenkinsContent: true}
+	status, err := s.checkCredentialCreate(projectId, operator, createRequest, request.Id, false)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, status)
+		return
+	}
+	sourceRecord, err := s.getProjectCredential(projectId, request.Domain, credentialId)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	_, err = s.credentialStore().CopySshCredentialInFolder(request.Domain, credentialId, request.Id, projectId)
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeJenkinsError(w, r, err)
+		return
+	}
+	projectCredential := models.NewProjectCredential(projectId, request.Id, request.Domain, operator)
+	carryCredentialAssessment(projectCredential, sourceRecord)
+	err = s.saveCredentialOrRollback(projectCredential, nil)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	s.recordCredentialMutation(projectId, operator, CredentialActionCreate, request.Domain, request.Id, content)
+	logger.Info("ssh credential [%s] of project [%s] copied to [%s] by [%s]", credentialId, projectId, request.Id,
+		operator)
+	w.WriteJson(&CopyCredentialResponse{Id: request.Id, Domain: request.Domain, ProjectId: projectId})
+	return
+}
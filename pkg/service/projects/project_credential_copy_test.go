@@ -0,0 +1,58 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"kubesphere.io/devops/pkg/models"
+	"kubesphere.io/devops/pkg/utils/certutils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCopyCredentialHandlerSsh(t *testing.T) {
+	s, store, projectIds, cleanup := newDbTestService(t, 2)
+	defer cleanup()
+	source, target := projectIds[0], projectIds[1]
+	key := readTestKey(t, "ssh_rsa.pem")
+	if _, err := store.CreateSshCredentialInFolder("_", "deploy", "git", "", key, "deploy key", "",
+		source); err != nil {
+		t.Fatal(err)
+	}
+	keyBits := 2048
+	sourceRecord := models.NewProjectCredential(source, "deploy", "_", "alice")
+	sourceRecord.KeyAlgorithm, sourceRecord.KeyBits = certutils.KeyAlgorithmRsa, &keyBits
+	insertTestCredentialRecord(t, s, sourceRecord)
+
+	w := &recorder{httptest.NewRecorder()}
+	r := newProjectJsonRequest("alice", source, map[string]string{source: ProjectOwner, target: ProjectOwner},
+		"POST", "/projects/"+source+"/credentials/deploy/copy", &CopyCredentialRequest{TargetProjectId: target})
+	r.PathParams["cid"] = "deploy"
+	s.CopyCredentialHandler(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("ssh credential should be copied, got %d %s", w.Code, w.Body)
+	}
+	if copied := fakeCredentialField(t, store, target, "_", "deploy", "privateKey"); copied != key {
+		t.Fatalf("copy should have the private key of the credential, got [%s]", copied)
+	}
+	copiedRecord, err := s.getProjectCredential(target, "_", "deploy")
+	if err != nil || copiedRecord == nil {
+		t.Fatalf("copy should be recorded, got %v", err)
+	}
+	if copiedRecord.KeyAlgorithm != certutils.KeyAlgorithmRsa || copiedRecord.KeyBits == nil ||
+		*copiedRecord.KeyBits != keyBits {
+		t.Fatalf("copy should keep the key size of the credential, got %s %v", copiedRecord.KeyAlgorithm,
+			copiedRecord.KeyBits)
+	}
+}
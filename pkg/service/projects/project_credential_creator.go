@@ -0,0 +1,121 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"sync"
+	"time"
+
+	"github.com/asaskevich/govalidator"
+
+	"kubesphere.io/devops/pkg/logger"
+)
+
+type displayNameEntry struct {
+	displayName string
+	expireAt    time.Time
+}
+
+// displayNameCache keeps the display names of Jenkins users, so the creators of the credentials listed
+// are not looked up in Jenkins by every list. Users without display name are cached as well.
+type displayNameCache struct {
+	sync.Mutex
+	entries map[string]*displayNameEntry
+}
+
+var creatorDisplayNameCache = &displayNameCache{entries: make(map[string]*displayNameEntry)}
+
+// get returns the display names cached for users, and the users whose display name is not cached.
+func (c *displayNameCache) get(users map[string]string) (map[string]string, []string) {
+	c.Lock()
+	defer c.Unlock()
+	displayNames := make(map[string]string, len(users))
+	missing := make([]string, 0)
+	now := time.Now()
+	for user := range users {
+		entry, ok := c.entries[user]
+		if !ok || now.After(entry.expireAt) {
+			missing = append(missing, user)
+			continue
+		}
+		displayNames[user] = entry.displayName
+	}
+	return displayNames, missing
+}
+
+// set caches the display names of the users and drops the expired entries, so users removed from Jenkins
+// do not stay in the cache.
+func (c *displayNameCache) set(displayNames map[string]string, ttl time.Duration) {
+	c.Lock()
+	defer c.Unlock()
+	now := time.Now()
+	for user, entry := range c.entries {
+		if now.After(entry.expireAt) {
+			delete(c.entries, user)
+		}
+	}
+	for user, displayName := range displayNames {
+		c.entries[user] = &displayNameEntry{displayName: displayName, expireAt: now.Add(ttl)}
+	}
+}
+
+// fillCreatorDisplayNames sets the display name of the creator of the credentials from the Jenkins users,
+// through creatorDisplayNameCache. A single creator missing from the cache is looked up alone and several
+// with one call listing the users. Creators that can not be resolved keep their username as display name,
+// a failed lookup is not cached.
+func (s *ProjectService) fillCreatorDisplayNames(credentials []*CredentialResponse) {
+	creators := make(map[string]string)
+	for _, credential := range credentials {
+		if !govalidator.IsNull(credential.Creator) {
+			creators[credential.Creator] = ""
+		}
+	}
+	if len(creators) == 0 {
+		return
+	}
+	displayNames, missing := creatorDisplayNameCache.get(creators)
+	resolved := make(map[string]string, len(missing))
+	switch len(missing) {
+	case 0:
+	case 1:
+		fullName, err := s.Ds.Jenkins.GetUserFullName(missing[0])
+		if err != nil {
+			logger.Debug("failed to get display name of user [%s]: %+v", missing[0], err)
+			break
+		}
+		resolved[missing[0]] = fullName
+	default:
+		fullNames, err := s.Ds.Jenkins.GetUserFullNames()
+		if err != nil {
+			logger.Warn("failed to get display names of users: %+v", err)
+			break
+		}
+		for _, creator := range missing {
+			resolved[creator] = fullNames[creator]
+		}
+	}
+	creatorDisplayNameCache.set(resolved, s.Config.Creator.CacheTtl)
+	for creator, displayName := range resolved {
+		displayNames[creator] = displayName
+	}
+	for _, credential := range credentials {
+		if govalidator.IsNull(credential.Creator) {
+			continue
+		}
+		credential.CreatorDisplayName = displayNames[credential.Creator]
+		if govalidator.IsNull(credential.CreatorDisplayName) {
+			credential.CreatorDisplayName = credential.Creator
+		}
+	}
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"testing"
+	"time"
+
+	"kubesphere.io/devops/pkg/config"
+)
+
+func TestFillCreatorDisplayNamesCached(t *testing.T) {
+	creatorDisplayNameCache.set(map[string]string{"alice": "Alice", "bob": ""}, time.Minute)
+	creatorDisplayNameCache.set(map[string]string{"carol": "Carol"}, -time.Minute)
+	defer func() {
+		creatorDisplayNameCache.entries = make(map[string]*displayNameEntry)
+	}()
+
+	// no Jenkins is set, cached creators must not be looked up
+	s := &ProjectService{Config: &config.Config{}}
+	credentials := []*CredentialResponse{{Creator: "alice"}, {Creator: "bob"}, {Creator: ""}}
+	s.fillCreatorDisplayNames(credentials)
+	if credentials[0].CreatorDisplayName != "Alice" || credentials[1].CreatorDisplayName != "bob" ||
+		credentials[2].CreatorDisplayName != "" {
+		t.Fatalf("unexpected display names %q, %q, %q", credentials[0].CreatorDisplayName,
+			credentials[1].CreatorDisplayName, credentials[2].CreatorDisplayName)
+	}
+
+	_, missing := creatorDisplayNameCache.get(map[string]string{"alice": "", "carol": ""})
+	if len(missing) != 1 || missing[0] != "carol" {
+		t.Fatalf("the expired display name should be missing, got %v", missing)
+	}
+	if _, ok := creatorDisplayNameCache.entries["carol"]; ok {
+		t.Fatalf("the expired entry should be dropped by the next set")
+	}
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/gocraft/dbr"
+
+	"kubesphere.io/devops/pkg/gojenkins"
+	"kubesphere.io/devops/pkg/logger"
+	"kubesphere.io/devops/pkg/models"
+	"kubesphere.io/devops/pkg/utils/stringutils"
+	"kubesphere.io/devops/pkg/utils/userutils"
+)
+
+type CredentialDeleteFailure struct {
+	Id     string `json:"id"`
+	Domain string `json:"domain"`
+	Status int    `json:"status"`
+	Error  string `json:"error"`
+}
+
+type DeleteAllCredentialsResponse struct {
+	Deleted  int                        `json:"deleted"`
+	Failures []*CredentialDeleteFailure `json:"failures"`
+}
+
+// DeleteAllCredentialsHandler deletes every credential of the project folder, and those the project created
+// in the global store, for the teardown of the project. A credential Jenkins fails to delete is reported and
+// the others are still deleted, the db records of the deleted credentials are removed in one transaction.
+// Credentials go to the trash like single deletes, those that could not be restored are only deleted with force.
+func (s *ProjectService) DeleteAllCredentialsHandler(w rest.ResponseWriter, r *rest.Request) {
+	projectId := r.PathParams["id"]
+	defer s.invalidateCredentialList(projectId)
+	ctx := r.Context()
+	operator := userutils.GetUserNameFromRequest(r)
+	force, err := parseForceDelete(r)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	err = s.checkProjectUserInRole(r, operator, projectId, s.credentialActionRoles(CredentialActionDeleteAll))
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
+		return
+	}
+	credentials, err := s.credentialStore().GetCredentialsInFolder("", projectId)
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeJenkinsError(w, r, err)
+		return
+	}
+	globalCredentials, err := s.loadGlobalProjectCredentials(projectId)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	response := &DeleteAllCredentialsResponse{Failures: make([]*CredentialDeleteFailure, 0)}
+	deleted := make([]*gojenkins.CredentialResponse, 0, len(credentials))
+	fail := func(credentialId, domain string, err error) {
+		logger.ErrorContext(r.Context(), "failed to delete credential [%s] in domain [%s] of project [%s]: %+v",
+			credentialId, domain, projectId, err)
+		status := stringutils.GetJenkinsStatusCode(err)
+		if _, ok := err.(*CredentialNotRestorableError); ok {
+			status = http.StatusConflict
+		}
+		response.Failures = append(response.Failures, &CredentialDeleteFailure{
+			Id:     credentialId,
+			Domain: domain,
+			Status: status,
+			Error:  cleanErrorMessage(err, status),
+		})
+	}
+	targets := make([]credentialTarget, 0, len(credentials)+len(globalCredentials))
+	ids := make([]string, 0, len(credentials)+len(globalCredentials))
+	for _, credential := range credentials {
+		targets = append(targets, newCredentialTarget(projectId, credential.Domain, models.CredentialStoreFolder))
+		ids = append(ids, credential.Id)
+	}
+	for _, projectCredential := range globalCredentials {
+		targets = append(targets, recordTarget(projectId, projectCredential.Domain, projectCredential))
+		ids = append(ids, projectCredential.CredentialId)
+	}
+	for i, target := range targets {
+		trash, err := s.trashBeforeDelete(target, operator, ids[i], force)
+		// the record of a credential already removed from the global store is removed with the others
+		if err != nil && !(target.global && errors.Is(err, gojenkins.ErrCredentialNotFound)) {
+			fail(ids[i], target.domain, err)
+			continue
+		}
+		_, err = target.delete(ctx, s.credentialStore(), ids[i])
+		if err != nil && !(target.global && errors.Is(err, gojenkins.ErrCredentialNotFound)) {
+			s.untrashCredential(r.Context(), trash)
+			fail(ids[i], target.domain, err)
+			continue
+		}
+		deleted = append(deleted, &gojenkins.CredentialResponse{Id: ids[i], Domain: target.domain})
+	}
+
+	err = s.Ds.Db.WithTransaction(func(tx *dbr.Tx) error {
+		for _, credential := range deleted {
+			err := deleteCredentialRecordsTx(tx, projectId, credential.Domain, credential.Id)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logger.ErrorContext(r.Context(), "failed to remove the records of %d deleted credentials of project [%s]: %+v",
+			len(deleted), projectId, err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	for _, credential := range deleted {
+		err = s.recordCredentialDeletion(projectId, credential.Domain, credential.Id, operator)
+		if err != nil {
+			logger.WarnContext(r.Context(), "failed to record deletion of credential [%s]: %+v", credential.Id, err)
+		}
+		s.recordCredentialMutation(projectId, operator, CredentialActionDelete, credential.Domain, credential.Id, nil)
+	}
+	response.Deleted = len(deleted)
+	w.WriteJson(response)
+	return
+}
@@ -0,0 +1,235 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/asaskevich/govalidator"
+
+	"kubesphere.io/devops/pkg/db"
+	"kubesphere.io/devops/pkg/logger"
+	"kubesphere.io/devops/pkg/models"
+	"kubesphere.io/devops/pkg/utils/reflectutils"
+	"kubesphere.io/devops/pkg/utils/userutils"
+)
+
+type UpdateCredentialDescriptionTemplateRequest struct {
+	Template string `json:"template"`
+}
+
+// DescriptionTemplateContext holds the values available to description templates as
+// {project}, {operator}, {type}, {id} and {domain}. Other placeholders are kept as they are.
+type DescriptionTemplateContext struct {
+	Project  string
+	Operator string
+	Type     string
+	Id       string
+	Domain   string
+}
+
+func renderDescriptionTemplate(template string, context *DescriptionTemplateContext) string {
+	return strings.NewReplacer(
+		"{project}", context.Project,
+		"{operator}", context.Operator,
+		"{type}", context.Type,
+		"{id}", context.Id,
+		"{domain}", context.Domain,
+	).Replace(template)
+}
+
+// applyDescriptionTemplate sets the rendered template as the description of content,
+// an explicit description in content is kept.
+func applyDescriptionTemplate(content map[string]interface{}, template string, context *DescriptionTemplateContext) {
+	if govalidator.IsNull(template) {
+		return
+	}
+	if description, _ := content["description"].(string); !govalidator.IsNull(strings.TrimSpace(description)) {
+		return
+	}
+	content["description"] = renderDescriptionTemplate(template, context)
+}
+
+// normalizeCredentialDescription strips the control characters of the optional description of a
+// credential content, which keeps descriptions from forging log lines, and checks the description
+// is at most maxLength characters long, 0 disables the check.
+func normalizeCredentialDescription(content map[string]interface{}, maxLength int) error {
+	value, ok := content["description"]
+	if !ok || value == nil {
+		return nil
+	}
+	description, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("error description should be a string")
+	}
+	description = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, description)
+	if length := utf8.RuneCountInString(description); maxLength > 0 && length > maxLength {
+		return fmt.Errorf("error description is %d characters long, it should be at most %d", length, maxLength)
+	}
+	content["description"] = description
+	return nil
+}
+
+func (s *ProjectService) getCredentialDescriptionTemplates(projectId string) ([]*models.ProjectCredentialDescriptionTemplate, error) {
+	templates := make([]*models.ProjectCredentialDescriptionTemplate, 0)
+	_, err := s.Ds.Db.Select(models.ProjectCredentialDescriptionTemplateColumns...).
+		From(models.ProjectCredentialDescriptionTemplateTableName).
+		Where(db.Eq(models.ProjectIdColumn, projectId)).
+		OrderDir(models.ProjectCredentialTypeColumn, true).
+		Load(&templates)
+	if err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// fillDefaultDescription applies the description template of the credential type of the project
+// to request content created without a description.
+func (s *ProjectService) fillDefaultDescription(projectId, operator, domain, credentialType string,
+	content map[string]interface{}) error {
+	if content == nil {
+		return nil
+	}
+	if description, _ := content["description"].(string); !govalidator.IsNull(strings.TrimSpace(description)) {
+		return nil
+	}
+	template := &models.ProjectCredentialDescriptionTemplate{}
+	err := s.Ds.Db.Select(models.ProjectCredentialDescriptionTemplateColumns...).
+		From(models.ProjectCredentialDescriptionTemplateTableName).
+		Where(db.And(db.Eq(models.ProjectIdColumn, projectId),
+			db.Eq(models.ProjectCredentialTypeColumn, credentialType))).
+		LoadOne(template)
+	if err == db.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if govalidator.IsNull(domain) {
+		domain = "_"
+	}
+	credentialId, _ := content["id"].(string)
+	applyDescriptionTemplate(content, template.Template, &DescriptionTemplateContext{
+		Project:  projectId,
+		Operator: operator,
+		Type:     credentialType,
+		Id:       credentialId,
+		Domain:   domain,
+	})
+	return nil
+}
+
+func (s *ProjectService) GetCredentialDescriptionTemplatesHandler(w rest.ResponseWriter, r *rest.Request) {
+	projectId := r.PathParams["id"]
+	operator := userutils.GetUserNameFromRequest(r)
+	err := s.checkProjectUserInRole(r, operator, projectId, []string{ProjectOwner, ProjectMaintainer})
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
+		return
+	}
+	templates, err := s.getCredentialDescriptionTemplates(projectId)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	w.WriteJson(templates)
+	return
+}
+
+func (s *ProjectService) UpdateCredentialDescriptionTemplateHandler(w rest.ResponseWriter, r *rest.Request) {
+	request := &UpdateCredentialDescriptionTemplateRequest{}
+	projectId := r.PathParams["id"]
+	credentialType := r.PathParams["type"]
+	operator := userutils.GetUserNameFromRequest(r)
+	err := r.DecodeJsonPayload(request)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	if !reflectutils.In(credentialType, applyCredentialTypes) {
+		err := fmt.Errorf("error unsupport credential type %s", credentialType)
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	if govalidator.IsNull(strings.TrimSpace(request.Template)) {
+		err := fmt.Errorf("template should not be empty")
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	err = s.checkProjectUserInRole(r, operator, projectId, []string{ProjectOwner})
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
+		return
+	}
+
+	_, err = s.Ds.Db.DeleteFrom(models.ProjectCredentialDescriptionTemplateTableName).
+		Where(db.And(db.Eq(models.ProjectIdColumn, projectId),
+			db.Eq(models.ProjectCredentialTypeColumn, credentialType))).Exec()
+	if err != nil && err != db.ErrNotFound {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	template := models.NewProjectCredentialDescriptionTemplate(projectId, credentialType, request.Template)
+	_, err = s.Ds.Db.InsertInto(models.ProjectCredentialDescriptionTemplateTableName).
+		Columns(models.ProjectCredentialDescriptionTemplateColumns...).
+		Record(template).Exec()
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	w.WriteJson(template)
+	return
+}
+
+func (s *ProjectService) DeleteCredentialDescriptionTemplateHandler(w rest.ResponseWriter, r *rest.Request) {
+	projectId := r.PathParams["id"]
+	credentialType := r.PathParams["type"]
+	operator := userutils.GetUserNameFromRequest(r)
+	err := s.checkProjectUserInRole(r, operator, projectId, []string{ProjectOwner})
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
+		return
+	}
+	_, err = s.Ds.Db.DeleteFrom(models.ProjectCredentialDescriptionTemplateTableName).
+		Where(db.And(db.Eq(models.ProjectIdColumn, projectId),
+			db.Eq(models.ProjectCredentialTypeColumn, credentialType))).Exec()
+	if err != nil && err != db.ErrNotFound {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	w.WriteJson(struct {
+		Type string `json:"type"`
+	}{Type: credentialType})
+	return
+}
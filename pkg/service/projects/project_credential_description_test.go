@@ -0,0 +1,115 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_RenderDescriptionTemplate(t *testing.T) {
+	context := &DescriptionTemplateContext{
+		Project:  "project-1",
+		Operator: "admin",
+		Type:     CredentialTypeSsh,
+		Id:       "deploy-key",
+		Domain:   "_",
+	}
+	for _, test := range []struct {
+		template string
+		expected string
+	}{
+		{template: "Managed by {operator} — {type} credential", expected: "Managed by admin — ssh credential"},
+		{template: "{id} of {project} in {domain}", expected: "deploy-key of project-1 in _"},
+		{template: "{type}/{type}", expected: "ssh/ssh"},
+		{template: "Managed by {team}", expected: "Managed by {team}"},
+		{template: "no placeholder", expected: "no placeholder"},
+	} {
+		output := renderDescriptionTemplate(test.template, context)
+		if output != test.expected {
+			t.Fatalf("template [%s] output [%s] should equal [%s]", test.template, output, test.expected)
+		}
+	}
+}
+
+func Test_ApplyDescriptionTemplate(t *testing.T) {
+	context := &DescriptionTemplateContext{Operator: "admin", Type: CredentialTypeSecretText}
+	template := "{type} by {operator}"
+	for _, test := range []struct {
+		name     string
+		content  map[string]interface{}
+		template string
+		expected interface{}
+	}{
+		{
+			name:     "missing description",
+			content:  map[string]interface{}{"id": "token"},
+			template: template,
+			expected: "secret_text by admin",
+		},
+		{
+			name:     "empty description",
+			content:  map[string]interface{}{"id": "token", "description": " "},
+			template: template,
+			expected: "secret_text by admin",
+		},
+		{
+			name:     "explicit description",
+			content:  map[string]interface{}{"id": "token", "description": "sonar token"},
+			template: template,
+			expected: "sonar token",
+		},
+		{
+			name:     "no template",
+			content:  map[string]interface{}{"id": "token"},
+			template: "",
+			expected: nil,
+		},
+	} {
+		applyDescriptionTemplate(test.content, test.template, context)
+		if test.content["description"] != test.expected {
+			t.Fatalf("%s: description [%v] should equal [%v]", test.name, test.content["description"], test.expected)
+		}
+	}
+}
+
+func Test_NormalizeCredentialDescription(t *testing.T) {
+	for _, test := range []struct {
+		description interface{}
+		maxLength   int
+		expected    interface{}
+		valid       bool
+	}{
+		{description: nil, maxLength: 10, expected: nil, valid: true},
+		{description: "git", maxLength: 10, expected: "git", valid: true},
+		{description: "git\n[ERROR] forged\x1b[0m", maxLength: 0, expected: "git[ERROR] forged[0m", valid: true},
+		{description: "凭证描述", maxLength: 4, expected: "凭证描述", valid: true},
+		{description: strings.Repeat("a", 11), maxLength: 10, valid: false},
+		{description: strings.Repeat("a", 10) + "\r\n", maxLength: 10, expected: strings.Repeat("a", 10), valid: true},
+		{description: 1, maxLength: 10, valid: false},
+	} {
+		content := map[string]interface{}{"id": "git"}
+		if test.description != nil {
+			content["description"] = test.description
+		}
+		err := normalizeCredentialDescription(content, test.maxLength)
+		if test.valid != (err == nil) {
+			t.Fatalf("description [%v] valid should be %t, got error %v", test.description, test.valid, err)
+		}
+		if test.valid && content["description"] != test.expected {
+			t.Fatalf("description [%v] should be normalized to %q, got %q", test.description, test.expected,
+				content["description"])
+		}
+	}
+}
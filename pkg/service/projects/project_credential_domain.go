@@ -0,0 +1,239 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/asaskevich/govalidator"
+	"github.com/gocraft/dbr"
+
+	"kubesphere.io/devops/pkg/db"
+	"kubesphere.io/devops/pkg/logger"
+	"kubesphere.io/devops/pkg/models"
+	"kubesphere.io/devops/pkg/utils/reflectutils"
+	"kubesphere.io/devops/pkg/utils/stringutils"
+	"kubesphere.io/devops/pkg/utils/userutils"
+)
+
+// the domain every folder store has, it can not be created or deleted
+const defaultCredentialDomain = "_"
+
+func isDefaultCredentialDomain(domain string) bool {
+	return govalidator.IsNull(domain) || domain == defaultCredentialDomain
+}
+
+// normalizeCredentialDomain returns the domain a credential is stored in, the default domain when it is empty.
+func normalizeCredentialDomain(domain string) string {
+	if isDefaultCredentialDomain(domain) {
+		return defaultCredentialDomain
+	}
+	return domain
+}
+
+// ensureCredentialDomain creates the domain in the project folder when it does not exist yet.
+func (s *ProjectService) ensureCredentialDomain(projectId, domain string) error {
+	if isDefaultCredentialDomain(domain) {
+		return nil
+	}
+	_, err := s.credentialStore().GetCredentialsInFolder(domain, projectId)
+	if err == nil {
+		return nil
+	}
+	if stringutils.GetJenkinsStatusCode(err) != http.StatusNotFound {
+		return err
+	}
+	_, err = s.credentialStore().CreateCredentialDomainInFolder(domain, "", "", projectId)
+	if err != nil {
+		return err
+	}
+	logger.Info("credential domain [%s] created in project [%s]", domain, projectId)
+	return nil
+}
+
+// removeEmptyCredentialDomain deletes the domain from the project folder when it holds no credential.
+func (s *ProjectService) removeEmptyCredentialDomain(projectId, domain string) error {
+	if isDefaultCredentialDomain(domain) {
+		return nil
+	}
+	credentials, err := s.credentialStore().GetCredentialsInFolder(domain, projectId)
+	if err != nil {
+		return err
+	}
+	if len(credentials) > 0 {
+		return nil
+	}
+	_, err = s.credentialStore().DeleteCredentialDomainInFolder(domain, projectId)
+	if err != nil {
+		return err
+	}
+	logger.Info("empty credential domain [%s] removed from project [%s]", domain, projectId)
+	return nil
+}
+
+func (s *ProjectService) GetCredentialDomainsHandler(w rest.ResponseWriter, r *rest.Request) {
+	projectId := r.PathParams["id"]
+	operator := userutils.GetUserNameFromRequest(r)
+	err := s.checkProjectUserInRole(r, operator, projectId, []string{ProjectOwner, ProjectMaintainer})
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
+		return
+	}
+	domains, err := s.credentialStore().GetCredentialDomainsInFolder(projectId)
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeJenkinsError(w, r, err)
+		return
+	}
+	w.WriteJson(domains)
+	return
+}
+
+type RenameCredentialDomainRequest struct {
+	Name string `json:"name"`
+	// move the credentials into the domain when it exists already
+	Merge bool `json:"merge,omitempty"`
+}
+
+type RenameCredentialDomainResponse struct {
+	Domain      string   `json:"domain"`
+	Credentials []string `json:"credentials"`
+}
+
+// RenameCredentialDomainHandler moves the credentials of a domain to the domain of the new name and updates
+// their records. A rename into an existing domain merges the domains only on request.
+func (s *ProjectService) RenameCredentialDomainHandler(w rest.ResponseWriter, r *rest.Request) {
+	request := &RenameCredentialDomainRequest{}
+	projectId := r.PathParams["id"]
+	defer s.invalidateCredentialList(projectId)
+	domain := normalizeCredentialDomain(r.PathParams["domain"])
+	operator := userutils.GetUserNameFromRequest(r)
+	err := r.DecodeJsonPayload(request)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	if govalidator.IsNull(request.Name) || normalizeCredentialDomain(request.Name) == domain {
+		err := fmt.Errorf("name should be another domain than [%s]", domain)
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	newDomain := normalizeCredentialDomain(request.Name)
+	err = s.checkProjectUserInRole(r, operator, projectId, s.credentialActionRoles(CredentialActionRenameDomain))
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
+		return
+	}
+
+	domains, err := s.credentialStore().GetCredentialDomainsInFolder(projectId)
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeJenkinsError(w, r, err)
+		return
+	}
+	if !reflectutils.In(domain, domains) {
+		err := fmt.Errorf("credential domain [%s] not found", domain)
+		logger.WarnContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusNotFound)
+		return
+	}
+	if reflectutils.In(newDomain, domains) && !request.Merge {
+		err := fmt.Errorf("credential domain [%s] exists, set merge to move the credentials into it", newDomain)
+		logger.WarnContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusConflict)
+		return
+	}
+
+	moved, renameErr := s.credentialStore().RenameCredentialDomainInFolder(domain, newDomain, projectId)
+	// the credentials moved in Jenkins are recorded in their domain, also when the rename fails part way
+	err = s.moveCredentialRecords(projectId, operator, domain, newDomain, moved)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "credentials %v of project [%s] are moved to domain [%s] in Jenkins "+
+			"but their records are not, they need manual repair: %+v", moved, projectId, newDomain, err)
+	}
+	if renameErr != nil {
+		logCredentialError(r.Context(), renameErr)
+		writeJenkinsError(w, r, renameErr)
+		return
+	}
+	if err != nil {
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	logger.Info("credential domain [%s] of project [%s] renamed to [%s] by [%s], %d credentials moved", domain,
+		projectId, newDomain, operator, len(moved))
+	w.WriteJson(&RenameCredentialDomainResponse{Domain: newDomain, Credentials: moved})
+	return
+}
+
+// moveCredentialRecords moves the records of credentials moved from domain to newDomain with their
+// rotations, usage threshold, deletion and trash, and logs the moves in the same transaction. Threshold
+// and deletion rows of the ids left in newDomain belong to former credentials and are dropped.
+func (s *ProjectService) moveCredentialRecords(projectId, operator, domain, newDomain string,
+	credentialIds []string) error {
+	if len(credentialIds) == 0 {
+		return nil
+	}
+	transparencyLogLock.Lock()
+	defer transparencyLogLock.Unlock()
+	return s.Ds.Db.WithTransaction(func(tx *dbr.Tx) error {
+		_, err := tx.Update(models.ProjectCredentialTableName).
+			Set(models.ProjectCredentialDomainColumn, newDomain).
+			Set(models.ProjectCredentialModifiedByColumn, db.EncryptedString(operator)).
+			Set(models.ProjectCredentialModifiedTimeColumn, time.Now()).
+			Where(db.And(db.Eq(models.ProjectIdColumn, projectId),
+				db.Eq(models.ProjectCredentialIdColumn, credentialIds),
+				db.Eq(models.ProjectCredentialDomainColumn, domain))).Exec()
+		if err != nil {
+			return err
+		}
+		for _, table := range []string{models.ProjectCredentialUsageThresholdTableName,
+			models.ProjectCredentialDeletionTableName} {
+			_, err = tx.DeleteFrom(table).
+				Where(db.And(db.Eq(models.ProjectIdColumn, projectId),
+					db.Eq(models.ProjectCredentialIdColumn, credentialIds),
+					db.Eq(models.ProjectCredentialDomainColumn, newDomain))).Exec()
+			if err != nil {
+				return err
+			}
+		}
+		for _, table := range []string{models.ProjectCredentialUsageThresholdTableName,
+			models.ProjectCredentialDeletionTableName, models.ProjectCredentialRotationTableName,
+			models.ProjectCredentialTrashTableName} {
+			_, err = tx.Update(table).
+				Set(models.ProjectCredentialDomainColumn, newDomain).
+				Where(db.And(db.Eq(models.ProjectIdColumn, projectId),
+					db.Eq(models.ProjectCredentialIdColumn, credentialIds),
+					db.Eq(models.ProjectCredentialDomainColumn, domain))).Exec()
+			if err != nil {
+				return err
+			}
+		}
+		for _, credentialId := range credentialIds {
+			err = s.appendTransparencyLogTx(tx, projectId, operator, CredentialActionMove, newDomain, credentialId,
+				map[string]interface{}{"from_domain": domain})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gocraft/dbr"
+	"github.com/gocraft/dbr/dialect"
+
+	"kubesphere.io/devops/pkg/config"
+	"kubesphere.io/devops/pkg/db"
+	"kubesphere.io/devops/pkg/ds"
+)
+
+// unavailableConnector opens no connection, every query of its database fails.
+type unavailableConnector struct{}
+
+func (unavailableConnector) Connect(context.Context) (driver.Conn, error) {
+	return nil, errors.New("database unavailable")
+}
+func (unavailableConnector) Driver() driver.Driver { return nil }
+
+func newUnavailableDatabase() *db.Database {
+	conn := &dbr.Connection{DB: sql.OpenDB(unavailableConnector{}), Dialect: dialect.MySQL,
+		EventReceiver: &dbr.NullEventReceiver{}}
+	return &db.Database{Session: conn.NewSession(nil)}
+}
+
+func TestEnsureCredentialDomain(t *testing.T) {
+	store := NewFakeCredentialStore()
+	s := &ProjectService{Credentials: store, Config: &config.Config{}}
+	if err := s.ensureCredentialDomain("project", "github"); err != nil {
+		t.Fatalf("failed to create the domain: %+v", err)
+	}
+	if err := s.ensureCredentialDomain("project", "github"); err != nil {
+		t.Fatalf("an existing domain should be kept: %+v", err)
+	}
+	domains, err := store.GetCredentialDomainsInFolder("project")
+	if err != nil || !reflect.DeepEqual(domains, []string{"_", "github"}) {
+		t.Fatalf("the domain should be created in the project folder, got %v %v", domains, err)
+	}
+	if err := s.removeEmptyCredentialDomain("project", "github"); err != nil {
+		t.Fatalf("failed to remove the empty domain: %+v", err)
+	}
+	if domains, _ := store.GetCredentialDomainsInFolder("project"); !reflect.DeepEqual(domains, []string{"_"}) {
+		t.Fatalf("the empty domain should be removed, got %v", domains)
+	}
+}
+
+func TestRenameCredentialDomainHandlerRecordsNotMoved(t *testing.T) {
+	store := NewFakeCredentialStore()
+	for _, id := range []string{"git", "registry"} {
+		if _, err := store.CreateSecretTextCredentialInFolder("github", id, "secret", "", "", "project"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := &ProjectService{Ds: &ds.Ds{Db: newUnavailableDatabase()}, Credentials: store, Config: &config.Config{}}
+
+	w := &recorder{httptest.NewRecorder()}
+	r := newFakeStoreJsonRequest("admin", ProjectOwner, "POST", "/projects/project/credentials/domains/github/rename",
+		&RenameCredentialDomainRequest{Name: "gitlab"})
+	r.PathParams["domain"] = "github"
+	s.RenameCredentialDomainHandler(w, r)
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("records failing to move should fail the rename, got %d %s", w.Code, w.Body)
+	}
+	// the credentials stay moved in Jenkins, their records need manual repair
+	moved, err := store.GetCredentialsInFolder("gitlab", "project")
+	if err != nil || len(moved) != 2 {
+		t.Fatalf("credentials should be moved to the new domain, got %v %v", moved, err)
+	}
+	if domains, _ := store.GetCredentialDomainsInFolder("project"); !reflect.DeepEqual(domains, []string{"_", "gitlab"}) {
+		t.Fatalf("the renamed domain should be removed, got %v", domains)
+	}
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"strconv"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/asaskevich/govalidator"
+)
+
+// CredentialDryRunResponse answers a create or update run with ?dry_run=true, which passed every check
+// but changed neither Jenkins nor the db.
+type CredentialDryRunResponse struct {
+	WouldCreate bool   `json:"would_create,omitempty"`
+	WouldUpdate bool   `json:"would_update,omitempty"`
+	Id          string `json:"id"`
+}
+
+func parseDryRun(r *rest.Request) (bool, error) {
+	dryRunParam := r.URL.Query().Get("dry_run")
+	if govalidator.IsNull(dryRunParam) {
+		return false, nil
+	}
+	return strconv.ParseBool(dryRunParam)
+}
+
+func writeCreateDryRun(w rest.ResponseWriter, credentialId string) {
+	w.WriteJson(&CredentialDryRunResponse{WouldCreate: true, Id: credentialId})
+}
+
+func writeUpdateDryRun(w rest.ResponseWriter, credentialId string) {
+	w.WriteJson(&CredentialDryRunResponse{WouldUpdate: true, Id: credentialId})
+}
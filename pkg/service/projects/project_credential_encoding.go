@@ -0,0 +1,108 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	CredentialEncodingRaw    = "raw"
+	CredentialEncodingBase64 = "base64"
+	CredentialEncodingUrl    = "url"
+)
+
+// CredentialContentEncoders transform the secret fields of revealed credential content,
+// new encodings only need to be registered here.
+var CredentialContentEncoders = map[string]func(value string) string{
+	CredentialEncodingRaw: func(value string) string {
+		return value
+	},
+	CredentialEncodingBase64: func(value string) string {
+		return base64.StdEncoding.EncodeToString([]byte(value))
+	},
+	CredentialEncodingUrl: func(value string) string {
+		return url.QueryEscape(value)
+	},
+}
+
+// credentialSecretFields are the content fields holding secrets, other fields are never encoded nor sent
+// to webhooks. Every credential request declares its secret fields by tagging them `secret:"true"`.
+var credentialSecretFields = secretFieldsOf(credentialRequestFactories)
+
+// secretFieldsOf returns the json names of the fields tagged as secret in the requests of factories, sorted.
+func secretFieldsOf(factories map[string]func() credentialContentRequest) []string {
+	names := make(map[string]bool)
+	for _, factory := range factories {
+		requestType := reflect.TypeOf(factory()).Elem()
+		for i := 0; i < requestType.NumField(); i++ {
+			field := requestType.Field(i)
+			if field.Tag.Get("secret") != "true" {
+				continue
+			}
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if name == "" {
+				name = field.Name
+			}
+			names[name] = true
+		}
+	}
+	fields := make([]string, 0, len(names))
+	for name := range names {
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func credentialEncodings() []string {
+	encodings := make([]string, 0, len(CredentialContentEncoders))
+	for encoding := range CredentialContentEncoders {
+		encodings = append(encodings, encoding)
+	}
+	sort.Strings(encodings)
+	return encodings
+}
+
+// encodeCredentialContent applies the encoding to the non empty secret fields of content,
+// an empty encoding means raw.
+func encodeCredentialContent(content map[string]interface{}, encoding string) error {
+	if encoding == "" {
+		encoding = CredentialEncodingRaw
+	}
+	encoder, ok := CredentialContentEncoders[encoding]
+	if !ok {
+		return fmt.Errorf("error encoding [%s] not in %s", encoding, credentialEncodings())
+	}
+	for _, field := range credentialSecretFields {
+		switch value := content[field].(type) {
+		case string:
+			if value != "" {
+				content[field] = encoder(value)
+			}
+		case []interface{}:
+			for i, item := range value {
+				if item, ok := item.(string); ok && item != "" {
+					value[i] = encoder(item)
+				}
+			}
+		}
+	}
+	return nil
+}
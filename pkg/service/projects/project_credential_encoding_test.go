@@ -0,0 +1,94 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_EncodeCredentialContent(t *testing.T) {
+	for _, test := range []struct {
+		encoding string
+		input    map[string]interface{}
+		expected map[string]interface{}
+	}{
+		{
+			encoding: "",
+			input:    map[string]interface{}{"id": "a b", "private_key": "key+/="},
+			expected: map[string]interface{}{"id": "a b", "private_key": "key+/="},
+		},
+		{
+			encoding: CredentialEncodingRaw,
+			input:    map[string]interface{}{"id": "a b", "content": "apiVersion: v1"},
+			expected: map[string]interface{}{"id": "a b", "content": "apiVersion: v1"},
+		},
+		{
+			encoding: CredentialEncodingBase64,
+			input:    map[string]interface{}{"id": "a b", "username": "admin", "password": "p@ss word"},
+			expected: map[string]interface{}{"id": "a b", "username": "admin", "password": "cEBzcyB3b3Jk"},
+		},
+		{
+			encoding: CredentialEncodingBase64,
+			input:    map[string]interface{}{"id": "a b", "private_key": "", "passphrase": "key"},
+			expected: map[string]interface{}{"id": "a b", "private_key": "", "passphrase": "a2V5"},
+		},
+		{
+			encoding: CredentialEncodingBase64,
+			input:    map[string]interface{}{"id": "a b", "private_keys": []interface{}{"key", ""}},
+			expected: map[string]interface{}{"id": "a b", "private_keys": []interface{}{"a2V5", ""}},
+		},
+		{
+			encoding: CredentialEncodingUrl,
+			input:    map[string]interface{}{"id": "a b", "secret": "a&b=c d/é"},
+			expected: map[string]interface{}{"id": "a b", "secret": "a%26b%3Dc+d%2F%C3%A9"},
+		},
+	} {
+		err := encodeCredentialContent(test.input, test.encoding)
+		if err != nil {
+			t.Fatalf("should not get error %+v", err)
+		}
+		if !reflect.DeepEqual(test.input, test.expected) {
+			t.Fatalf("encoding [%s] output [%+v] should equal [%+v]", test.encoding, test.input, test.expected)
+		}
+	}
+}
+
+func Test_EncodeCredentialContentUnknownEncoding(t *testing.T) {
+	content := map[string]interface{}{"secret": "value"}
+	err := encodeCredentialContent(content, "rot13")
+	if err == nil {
+		t.Fatalf("should get error for unknown encoding")
+	}
+	if content["secret"] != "value" {
+		t.Fatalf("content [%+v] should not be changed", content)
+	}
+}
+
+func Test_CredentialSecretFields(t *testing.T) {
+	expected := []string{"content", "keystore", "passphrase", "password", "private_key", "private_keys", "secret",
+		"secret_access_key", "token"}
+	if !reflect.DeepEqual(credentialSecretFields, expected) {
+		t.Fatalf("secret fields %v should equal %v", credentialSecretFields, expected)
+	}
+
+	metadata, hashes := hashCredentialContent(map[string]interface{}{"id": "openshift",
+		"server_url": "https://api.example.com:6443", "token": "sha256~token"})
+	if _, ok := metadata["token"]; ok {
+		t.Fatalf("the token should not be sent as metadata %v", metadata)
+	}
+	if hashes["token"] == "" || metadata["server_url"] == nil {
+		t.Fatalf("the token should be hashed, got metadata %v hashes %v", metadata, hashes)
+	}
+}
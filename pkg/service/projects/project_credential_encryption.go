@@ -0,0 +1,85 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"kubesphere.io/devops/pkg/db"
+	"kubesphere.io/devops/pkg/logger"
+	"kubesphere.io/devops/pkg/models"
+)
+
+// plainCredentialRecord reads the encrypted columns of a project credential as stored.
+type plainCredentialRecord struct {
+	Uuid           string
+	Creator        string
+	ModifiedBy     string
+	StrengthReason string
+	RegistryUrl    string
+	ServerUrl      string
+}
+
+func (r *plainCredentialRecord) encrypted() bool {
+	for _, value := range []string{r.Creator, r.ModifiedBy, r.StrengthReason, r.RegistryUrl, r.ServerUrl} {
+		if value != "" && !db.IsEncrypted(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// EncryptCredentialRecords encrypts the project credentials written before encryption was enabled,
+// it does nothing when encryption is disabled.
+func (s *ProjectService) EncryptCredentialRecords() {
+	if !db.EncryptionEnabled() {
+		return
+	}
+	var records []*plainCredentialRecord
+	_, err := s.Ds.Db.Select(models.ProjectCredentialUuidColumn, models.ProjectCredentialCreatorColumn,
+		models.ProjectCredentialModifiedByColumn, models.ProjectCredentialStrengthReasonColumn,
+		models.ProjectCredentialRegistryColumn, models.ProjectCredentialServerUrlColumn).
+		From(models.ProjectCredentialTableName).Load(&records)
+	if err != nil {
+		logger.Error("failed to list credentials to encrypt: %+v", err)
+		return
+	}
+	encrypted := 0
+	for _, record := range records {
+		if record.encrypted() {
+			continue
+		}
+		// encrypted values are decrypted back on scan, so values already encrypted are kept as they are
+		_, err := s.Ds.Db.Update(models.ProjectCredentialTableName).
+			Set(models.ProjectCredentialCreatorColumn, encryptedColumnValue(record.Creator)).
+			Set(models.ProjectCredentialModifiedByColumn, encryptedColumnValue(record.ModifiedBy)).
+			Set(models.ProjectCredentialStrengthReasonColumn, encryptedColumnValue(record.StrengthReason)).
+			Set(models.ProjectCredentialRegistryColumn, encryptedColumnValue(record.RegistryUrl)).
+			Set(models.ProjectCredentialServerUrlColumn, encryptedColumnValue(record.ServerUrl)).
+			Where(db.Eq(models.ProjectCredentialUuidColumn, record.Uuid)).Exec()
+		if err != nil {
+			logger.Error("failed to encrypt credential [%s]: %+v", record.Uuid, err)
+			continue
+		}
+		encrypted++
+	}
+	if encrypted > 0 {
+		logger.Info("encrypted %d credential records", encrypted)
+	}
+}
+
+func encryptedColumnValue(value string) interface{} {
+	if db.IsEncrypted(value) {
+		return value
+	}
+	return db.EncryptedString(value)
+}
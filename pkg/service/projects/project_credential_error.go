@@ -0,0 +1,181 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ant0ine/go-json-rest/rest"
+
+	"kubesphere.io/devops/pkg/gojenkins"
+	"kubesphere.io/devops/pkg/logger"
+	"kubesphere.io/devops/pkg/utils/stringutils"
+)
+
+const (
+	ErrorCodeInvalidRequest     = "INVALID_REQUEST"
+	ErrorCodeForbidden          = "FORBIDDEN"
+	ErrorCodeNotProjectMember   = "NOT_PROJECT_MEMBER"
+	ErrorCodeInsufficientRole   = "INSUFFICIENT_ROLE"
+	ErrorCodeNotFound           = "NOT_FOUND"
+	ErrorCodeCredentialConflict = "CREDENTIAL_CONFLICT"
+	ErrorCodeCredentialInUse    = "CREDENTIAL_IN_USE"
+	ErrorCodePreconditionFailed = "PRECONDITION_FAILED"
+	ErrorCodeValidationFailed   = "VALIDATION_FAILED"
+	ErrorCodeTooManyRequests    = "TOO_MANY_REQUESTS"
+	ErrorCodeInternal           = "INTERNAL_ERROR"
+	ErrorCodeJenkinsUnavailable = "JENKINS_UNAVAILABLE"
+	ErrorCodeRequestCanceled    = "REQUEST_CANCELED"
+	ErrorCodeRequestTimeout     = "REQUEST_TIMEOUT"
+)
+
+// StatusClientClosedRequest answers requests whose client went away before they were answered.
+const StatusClientClosedRequest = 499
+
+// CredentialError is the body of failed credential requests, Code is stable for clients to match on.
+type CredentialError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *CredentialError) Error() string {
+	return e.Message
+}
+
+// CredentialInUseError rejects the deletion of a credential with the references still using it.
+type CredentialInUseError struct {
+	CredentialError
+	Usage *CredentialUsageResponse `json:"usage"`
+}
+
+// CredentialFieldsError rejects a request with the message of every invalid field of its content.
+type CredentialFieldsError struct {
+	CredentialError
+	Fields map[string]string `json:"fields"`
+}
+
+func newCredentialError(code, message string) *CredentialError {
+	return &CredentialError{Code: code, Message: message}
+}
+
+var errorCodes = map[int]string{
+	http.StatusBadRequest:          ErrorCodeInvalidRequest,
+	http.StatusForbidden:           ErrorCodeForbidden,
+	http.StatusNotFound:            ErrorCodeNotFound,
+	http.StatusConflict:            ErrorCodeCredentialConflict,
+	http.StatusPreconditionFailed:  ErrorCodePreconditionFailed,
+	http.StatusUnprocessableEntity: ErrorCodeValidationFailed,
+	http.StatusTooManyRequests:     ErrorCodeTooManyRequests,
+	http.StatusInternalServerError: ErrorCodeInternal,
+	http.StatusBadGateway:          ErrorCodeJenkinsUnavailable,
+	http.StatusServiceUnavailable:  ErrorCodeJenkinsUnavailable,
+	http.StatusGatewayTimeout:      ErrorCodeJenkinsUnavailable,
+}
+
+// isUnavailableError tells whether err means Jenkins could not be reached.
+func isUnavailableError(err error) bool {
+	switch err.(type) {
+	case *url.Error, net.Error:
+		return true
+	}
+	return false
+}
+
+// cleanErrorMessage returns a message fit for clients. Bare Jenkins status codes, Jenkins error responses
+// and html pages are replaced with the status text, their details are only logged.
+func cleanErrorMessage(err error, status int) string {
+	if isUnavailableError(err) {
+		return "jenkins is unavailable"
+	}
+	message := strings.TrimSpace(err.Error())
+	if _, ok := err.(*gojenkins.ErrorResponse); ok {
+		return http.StatusText(status)
+	}
+	if _, convErr := strconv.Atoi(message); convErr == nil {
+		return http.StatusText(status)
+	}
+	lower := strings.ToLower(message)
+	if message == "" || strings.Contains(lower, "<html") || strings.Contains(lower, "<!doctype") {
+		return http.StatusText(status)
+	}
+	return message
+}
+
+// toCredentialError derives the code of err from the response status unless err is a CredentialError
+// or tells why the operator was not let in the project.
+func toCredentialError(err error, status int) *CredentialError {
+	switch typedErr := err.(type) {
+	case *CredentialError:
+		return typedErr
+	case *NotProjectMemberError:
+		return newCredentialError(ErrorCodeNotProjectMember, typedErr.Error())
+	case *ProjectRoleError:
+		return newCredentialError(ErrorCodeInsufficientRole, typedErr.Error())
+	}
+	code, ok := errorCodes[status]
+	switch {
+	case isUnavailableError(err):
+		code = ErrorCodeJenkinsUnavailable
+	case !ok && status >= 500:
+		code = ErrorCodeInternal
+	case !ok:
+		code = ErrorCodeInvalidRequest
+	}
+	return newCredentialError(code, cleanErrorMessage(err, status))
+}
+
+// logCredentialError logs err at debug level when Jenkins answered 404, which is expected when a
+// credential is probed or looked up, other errors are logged at error level. Both carry the request id
+// and fields of ctx.
+func logCredentialError(ctx context.Context, err error) {
+	if stringutils.GetJenkinsStatusCode(err) == http.StatusNotFound {
+		logger.FromContext(ctx).Debug("%+v", err)
+		return
+	}
+	logger.ErrorContext(ctx, "%+v", err)
+}
+
+// writeCredentialError writes err as a {code, message} body with status.
+func writeCredentialError(w rest.ResponseWriter, err error, status int) {
+	w.WriteHeader(status)
+	if inUseErr, ok := err.(*CredentialInUseError); ok {
+		w.WriteJson(inUseErr)
+		return
+	}
+	if fieldsErr, ok := err.(*CredentialFieldsError); ok {
+		w.WriteJson(fieldsErr)
+		return
+	}
+	w.WriteJson(toCredentialError(err, status))
+}
+
+// writeJenkinsError writes the error of a Jenkins call made for r. A call that failed because the
+// client went away is answered with 499, one that ran out of time with 408.
+func writeJenkinsError(w rest.ResponseWriter, r *rest.Request, err error) {
+	switch r.Context().Err() {
+	case context.Canceled:
+		writeCredentialError(w, newCredentialError(ErrorCodeRequestCanceled, "request canceled"),
+			StatusClientClosedRequest)
+	case context.DeadlineExceeded:
+		writeCredentialError(w, newCredentialError(ErrorCodeRequestTimeout, "request timed out"),
+			http.StatusRequestTimeout)
+	default:
+		writeCredentialError(w, err, stringutils.GetJenkinsStatusCode(err))
+	}
+}
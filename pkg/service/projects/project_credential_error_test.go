@@ -0,0 +1,65 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func Test_ToCredentialError(t *testing.T) {
+	for _, test := range []struct {
+		err      error
+		status   int
+		expected CredentialError
+	}{
+		{
+			err:      errors.New("404"),
+			status:   http.StatusNotFound,
+			expected: CredentialError{Code: ErrorCodeNotFound, Message: "Not Found"},
+		},
+		{
+			err:      errors.New("credential id [git] has been used"),
+			status:   http.StatusConflict,
+			expected: CredentialError{Code: ErrorCodeCredentialConflict, Message: "credential id [git] has been used"},
+		},
+		{
+			err:      errors.New("<html><body>java.lang.NullPointerException</body></html>"),
+			status:   http.StatusInternalServerError,
+			expected: CredentialError{Code: ErrorCodeInternal, Message: "Internal Server Error"},
+		},
+		{
+			err:      &url.Error{Op: "Get", URL: "http://jenkins/", Err: errors.New("connection refused")},
+			status:   http.StatusInternalServerError,
+			expected: CredentialError{Code: ErrorCodeJenkinsUnavailable, Message: "jenkins is unavailable"},
+		},
+		{
+			err:      errors.New("418"),
+			status:   http.StatusTeapot,
+			expected: CredentialError{Code: ErrorCodeInvalidRequest, Message: "I'm a teapot"},
+		},
+		{
+			err:      newCredentialError(ErrorCodeValidationFailed, "bad content"),
+			status:   http.StatusBadRequest,
+			expected: CredentialError{Code: ErrorCodeValidationFailed, Message: "bad content"},
+		},
+	} {
+		output := toCredentialError(test.err, test.status)
+		if *output != test.expected {
+			t.Fatalf("error [%v] with status %d output %+v should equal %+v", test.err, test.status, *output, test.expected)
+		}
+	}
+}
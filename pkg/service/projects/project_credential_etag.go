@@ -0,0 +1,72 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/asaskevich/govalidator"
+)
+
+// ErrorCredentialModified rejects an update whose If-Match header no longer matches the credential.
+var ErrorCredentialModified = newCredentialError(ErrorCodePreconditionFailed,
+	"credential was modified since it was read, read it again before updating it")
+
+// credentialETag derives the ETag of a credential from its Jenkins config.xml, so that it changes
+// whenever the credential changes in Jenkins, whoever changed it.
+func credentialETag(configXml string) string {
+	hash := sha256.Sum256([]byte(configXml))
+	return fmt.Sprintf(`"%s"`, hex.EncodeToString(hash[:]))
+}
+
+func (s *ProjectService) getCredentialETag(target credentialTarget, credentialId string) (string, error) {
+	configXml, err := target.config(s.credentialStore(), credentialId)
+	if err != nil {
+		return "", err
+	}
+	return credentialETag(configXml), nil
+}
+
+// matchETag tells whether etag is one of the ETags of an If-Match header, * matches any ETag.
+// Weak ETags never match as If-Match uses the strong comparison.
+func matchETag(ifMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// checkCredentialIfMatch returns ErrorCredentialModified when the request has an If-Match header not
+// matching the current ETag of the credential, requests without If-Match are not checked.
+func (s *ProjectService) checkCredentialIfMatch(r *rest.Request, target credentialTarget, credentialId string) error {
+	ifMatch := r.Header.Get("If-Match")
+	if govalidator.IsNull(ifMatch) {
+		return nil
+	}
+	etag, err := s.getCredentialETag(target, credentialId)
+	if err != nil {
+		return err
+	}
+	if !matchETag(ifMatch, etag) {
+		return ErrorCredentialModified
+	}
+	return nil
+}
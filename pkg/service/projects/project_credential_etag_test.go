@@ -0,0 +1,45 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"testing"
+)
+
+func TestCheckCredentialIfMatch(t *testing.T) {
+	store := NewFakeCredentialStore()
+	store.CreateSecretTextCredentialInFolder("", "git", "secret", "first", "", "project")
+	s := &ProjectService{Credentials: store}
+	etag, err := s.getCredentialETag(newCredentialTarget("project", "", ""), "git")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ifMatch := range []string{"", "*", etag, `"other", ` + etag} {
+		r := newFakeStoreRequest("alice", ProjectMaintainer, "/projects/project/credentials/git")
+		r.Header.Set("If-Match", ifMatch)
+		if err := s.checkCredentialIfMatch(r, newCredentialTarget("project", "", ""), "git"); err != nil {
+			t.Fatalf("If-Match [%s] should match [%s], got %v", ifMatch, etag, err)
+		}
+	}
+
+	store.UpdateSecretTextCredentialInFolder("", "git", "secret", "second", "", "project")
+	for _, ifMatch := range []string{etag, "W/" + etag} {
+		r := newFakeStoreRequest("alice", ProjectMaintainer, "/projects/project/credentials/git")
+		r.Header.Set("If-Match", ifMatch)
+		if err := s.checkCredentialIfMatch(r, newCredentialTarget("project", "", ""), "git"); err != ErrorCredentialModified {
+			t.Fatalf("If-Match [%s] of a modified credential should fail, got %v", ifMatch, err)
+		}
+	}
+}
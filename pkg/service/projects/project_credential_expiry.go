@@ -0,0 +1,195 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/asaskevich/govalidator"
+
+	"kubesphere.io/devops/pkg/db"
+	"kubesphere.io/devops/pkg/logger"
+	"kubesphere.io/devops/pkg/models"
+	"kubesphere.io/devops/pkg/utils/userutils"
+	"kubesphere.io/devops/pkg/utils/webhookutils"
+)
+
+// CredentialExpiryAlert is the alert of the notification webhook events of expiring credentials.
+const CredentialExpiryAlert = "expiry"
+
+type ExpiringCredentialResponse struct {
+	Id        string    `json:"id"`
+	Domain    string    `json:"domain"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Expired   bool      `json:"expired"`
+	// whole days left before the expiry, negative once expired
+	DaysLeft int `json:"days_left"`
+}
+
+// CredentialExpiryEvent is the payload sent to the notification webhook for a credential about to expire.
+type CredentialExpiryEvent struct {
+	ProjectId    string    `json:"project_id"`
+	CredentialId string    `json:"credential_id"`
+	Domain       string    `json:"domain"`
+	Alert        string    `json:"alert"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	DaysLeft     int       `json:"days_left"`
+}
+
+// earlierExpiresAt returns the earlier of two expiries, nil when both are nil.
+func earlierExpiresAt(expiresAt, other *time.Time) *time.Time {
+	if expiresAt == nil || (other != nil && other.Before(*expiresAt)) {
+		return other
+	}
+	return expiresAt
+}
+
+// setRequestExpiresAt records the expiry given with a create request, the expiry read from the content of
+// the credential, as the NotAfter of a certificate, is kept when it is earlier.
+func setRequestExpiresAt(projectCredential *models.ProjectCredential, expiresAt *time.Time) {
+	projectCredential.ExpiresAt = earlierExpiresAt(projectCredential.ExpiresAt, expiresAt)
+}
+
+// updateRequestExpiresAt records the expiry given with an update request unless contentExpiresAt, read
+// from the content of the credential, is earlier. The expiry is kept without one.
+func (s *ProjectService) updateRequestExpiresAt(projectId, domain, credentialId string, expiresAt,
+	contentExpiresAt *time.Time) error {
+	if expiresAt == nil {
+		return nil
+	}
+	return s.updateCredentialExpiresAt(projectId, domain, credentialId, earlierExpiresAt(expiresAt, contentExpiresAt))
+}
+
+// expiringCredentials returns the credentials expiring before until, the soonest first.
+func expiringCredentials(projectCredentials []*models.ProjectCredential, now,
+	until time.Time) []*ExpiringCredentialResponse {
+	expiring := make([]*ExpiringCredentialResponse, 0)
+	for _, projectCredential := range projectCredentials {
+		if projectCredential.ExpiresAt == nil || projectCredential.ExpiresAt.After(until) {
+			continue
+		}
+		expiresAt := *projectCredential.ExpiresAt
+		expiring = append(expiring, &ExpiringCredentialResponse{
+			Id:        projectCredential.CredentialId,
+			Domain:    projectCredential.Domain,
+			ExpiresAt: expiresAt,
+			Expired:   !expiresAt.After(now),
+			DaysLeft:  int(math.Floor(expiresAt.Sub(now).Hours() / 24)),
+		})
+	}
+	sort.SliceStable(expiring, func(i, j int) bool {
+		return expiring[i].ExpiresAt.Before(expiring[j].ExpiresAt)
+	})
+	return expiring
+}
+
+// loadExpiringProjectCredentials loads the records of the credentials expiring before until, in every
+// project when projectId is empty.
+func (s *ProjectService) loadExpiringProjectCredentials(projectId string,
+	until time.Time) ([]*models.ProjectCredential, error) {
+	selectCondition := db.Lte(models.ProjectCredentialExpiresAtColumn, until)
+	if !govalidator.IsNull(projectId) {
+		selectCondition = db.And(db.Eq(models.ProjectIdColumn, projectId), selectCondition)
+	}
+	projectCredentials := make([]*models.ProjectCredential, 0)
+	_, err := s.Ds.Db.Select(models.ProjectCredentialColumns...).
+		From(models.ProjectCredentialTableName).Where(selectCondition).Load(&projectCredentials)
+	if err != nil {
+		return nil, err
+	}
+	return projectCredentials, nil
+}
+
+// GetExpiringCredentialsHandler lists the credentials of a project expiring within the given number of
+// days, the expired ones included.
+func (s *ProjectService) GetExpiringCredentialsHandler(w rest.ResponseWriter, r *rest.Request) {
+	projectId := r.PathParams["id"]
+	operator := userutils.GetUserNameFromRequest(r)
+	err := s.checkProjectUserInRole(r, operator, projectId, s.credentialActionRoles(CredentialActionList))
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
+		return
+	}
+
+	days := s.Config.Expiry.WarningDays
+	if daysParam := r.URL.Query().Get("days"); !govalidator.IsNull(daysParam) {
+		days, err = strconv.Atoi(daysParam)
+		if err != nil || days < 0 {
+			err := fmt.Errorf("error days [%s] should be a non-negative number of days", daysParam)
+			logger.WarnContext(r.Context(), "%+v", err)
+			writeCredentialError(w, err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	now := time.Now()
+	until := now.AddDate(0, 0, days)
+	projectCredentials, err := s.loadExpiringProjectCredentials(projectId, until)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	w.WriteJson(expiringCredentials(projectCredentials, now, until))
+	return
+}
+
+// WarnExpiringCredentials logs the credentials of every project expiring within the warning days, and
+// sends them to the notification webhook when it is configured.
+func (s *ProjectService) WarnExpiringCredentials() {
+	now := time.Now()
+	until := now.AddDate(0, 0, s.Config.Expiry.WarningDays)
+	projectCredentials, err := s.loadExpiringProjectCredentials("", until)
+	if err != nil {
+		logger.Error("failed to load expiring credentials: %+v", err)
+		return
+	}
+	webhookConfig := s.Config.Webhook
+	for _, projectCredential := range projectCredentials {
+		expiring := expiringCredentials([]*models.ProjectCredential{projectCredential}, now, until)
+		if len(expiring) == 0 {
+			continue
+		}
+		logger.Warn("credential [%s] in domain [%s] of project [%s] expires at %s",
+			projectCredential.CredentialId, projectCredential.Domain, projectCredential.ProjectId,
+			expiring[0].ExpiresAt.Format(time.RFC3339))
+		if govalidator.IsNull(webhookConfig.NotificationUrl) {
+			continue
+		}
+		event := &CredentialExpiryEvent{
+			ProjectId:    projectCredential.ProjectId,
+			CredentialId: projectCredential.CredentialId,
+			Domain:       projectCredential.Domain,
+			Alert:        CredentialExpiryAlert,
+			ExpiresAt:    expiring[0].ExpiresAt,
+			DaysLeft:     expiring[0].DaysLeft,
+		}
+		statusCode, err := webhookutils.PostJSON(webhookConfig.NotificationUrl, webhookConfig.Secret,
+			webhookConfig.Timeout, event, nil)
+		if err == nil && (statusCode < 200 || statusCode > 299) {
+			err = fmt.Errorf("notification webhook returned %d", statusCode)
+		}
+		if err != nil {
+			logger.Error("failed to send expiry alert of credential [%s] in project [%s]: %+v",
+				projectCredential.CredentialId, projectCredential.ProjectId, err)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"testing"
+	"time"
+
+	"kubesphere.io/devops/pkg/models"
+)
+
+func TestExpiringCredentials(t *testing.T) {
+	now := time.Date(2019, 3, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		expiresAt := now.Add(d)
+		return &expiresAt
+	}
+	projectCredentials := []*models.ProjectCredential{
+		{CredentialId: "later", Domain: "_", ExpiresAt: at(30 * 24 * time.Hour)},
+		{CredentialId: "soon", Domain: "_", ExpiresAt: at(36 * time.Hour)},
+		{CredentialId: "never", Domain: "_"},
+		{CredentialId: "expired", Domain: "_", ExpiresAt: at(-time.Hour)},
+	}
+
+	expiring := expiringCredentials(projectCredentials, now, now.AddDate(0, 0, 14))
+	if len(expiring) != 2 {
+		t.Fatalf("only the credentials expiring within 14 days should be listed, got %d", len(expiring))
+	}
+	if expiring[0].Id != "expired" || !expiring[0].Expired || expiring[0].DaysLeft != -1 {
+		t.Fatalf("the expired credential should be listed first, got %+v", expiring[0])
+	}
+	if expiring[1].Id != "soon" || expiring[1].Expired || expiring[1].DaysLeft != 1 {
+		t.Fatalf("the credential expiring in 36 hours should have 1 day left, got %+v", expiring[1])
+	}
+}
+
+func TestSetRequestExpiresAt(t *testing.T) {
+	notAfter := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+	earlier := notAfter.AddDate(0, -1, 0)
+	later := notAfter.AddDate(1, 0, 0)
+	for _, test := range []struct {
+		name      string
+		content   *time.Time
+		request   *time.Time
+		expiresAt *time.Time
+	}{
+		{name: "no expiry", content: nil, request: nil, expiresAt: nil},
+		{name: "content only", content: &notAfter, request: nil, expiresAt: &notAfter},
+		{name: "request only", content: nil, request: &later, expiresAt: &later},
+		{name: "earlier request", content: &notAfter, request: &earlier, expiresAt: &earlier},
+		{name: "later request", content: &notAfter, request: &later, expiresAt: &notAfter},
+	} {
+		projectCredential := &models.ProjectCredential{ExpiresAt: test.content}
+		setRequestExpiresAt(projectCredential, test.request)
+		if test.expiresAt == nil {
+			if projectCredential.ExpiresAt != nil {
+				t.Fatalf("%s: no expiry should be recorded, got %v", test.name, projectCredential.ExpiresAt)
+			}
+			continue
+		}
+		if projectCredential.ExpiresAt == nil || !projectCredential.ExpiresAt.Equal(*test.expiresAt) {
+			t.Fatalf("%s: expiry should be %v, got %v", test.name, test.expiresAt, projectCredential.ExpiresAt)
+		}
+	}
+}
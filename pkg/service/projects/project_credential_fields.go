@@ -0,0 +1,70 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/mitchellh/mapstructure"
+)
+
+var credentialRequestFactories = map[string]func() credentialContentRequest{
+	CredentialTypeUsernamePassword: func() credentialContentRequest { return &UsernamePasswordCredentialRequest{} },
+	CredentialTypeSsh:              func() credentialContentRequest { return &SshCredentialRequest{} },
+	CredentialTypeSecretText:       func() credentialContentRequest { return &SecretTextCredentialRequest{} },
+	CredentialTypeKubeConfig:       func() credentialContentRequest { return &KubeconfigCredentialRequest{} },
+	CredentialTypeDockerRegistry:   func() credentialContentRequest { return &DockerRegistryCredentialRequest{} },
+	CredentialTypeCertificate:      func() credentialContentRequest { return &CertificateCredentialRequest{} },
+	CredentialTypeAWS:              func() credentialContentRequest { return &AWSCredentialRequest{} },
+	CredentialTypeSecretFile:       func() credentialContentRequest { return &SecretFileCredentialRequest{} },
+	CredentialTypeOpenShiftToken:   func() credentialContentRequest { return &OpenShiftTokenCredentialRequest{} },
+}
+
+// validateCredentialFields checks the content of a credential to create against the `valid` tags of
+// the request of its type, every invalid field is reported at once in a CredentialFieldsError.
+// Content of unknown types is left to the type check of the handlers.
+func validateCredentialFields(credentialType string, content map[string]interface{}) error {
+	factory, ok := credentialRequestFactories[credentialType]
+	if !ok {
+		return nil
+	}
+	request := factory()
+	err := mapstructure.Decode(content, request)
+	if err != nil {
+		return err
+	}
+	_, err = govalidator.ValidateStruct(request)
+	if err == nil {
+		return nil
+	}
+	fields := govalidator.ErrorsByField(err)
+	if len(fields) == 0 {
+		return err
+	}
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return &CredentialFieldsError{
+		CredentialError: CredentialError{
+			Code:    ErrorCodeInvalidRequest,
+			Message: fmt.Sprintf("invalid fields [%s]", strings.Join(names, ", ")),
+		},
+		Fields: fields,
+	}
+}
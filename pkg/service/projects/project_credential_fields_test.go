@@ -0,0 +1,54 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"testing"
+)
+
+func Test_ValidateCredentialFields(t *testing.T) {
+	err := validateCredentialFields(CredentialTypeSsh, map[string]interface{}{
+		"id":          "git-ssh",
+		"description": "only the id is set",
+	})
+	fieldsErr, ok := err.(*CredentialFieldsError)
+	if !ok {
+		t.Fatalf("should get a fields error, got %v", err)
+	}
+	if len(fieldsErr.Fields) != 2 || fieldsErr.Fields["username"] == "" || fieldsErr.Fields["private_key"] == "" {
+		t.Fatalf("username and private_key should be reported, got %v", fieldsErr.Fields)
+	}
+	if fieldsErr.Message != "invalid fields [private_key, username]" {
+		t.Fatalf("unexpected message [%s]", fieldsErr.Message)
+	}
+
+	err = validateCredentialFields(CredentialTypeUsernamePassword, map[string]interface{}{
+		"id":       "git-up",
+		"username": "admin",
+	})
+	if err != nil {
+		t.Fatalf("username_password without password should be valid, got %v", err)
+	}
+
+	err = validateCredentialFields("unknown", map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("unknown types should be left to the handlers, got %v", err)
+	}
+
+	err = validateCredentialFields(CredentialTypeSecretText, map[string]interface{}{})
+	fieldsErr, ok = err.(*CredentialFieldsError)
+	if !ok || len(fieldsErr.Fields) != 2 {
+		t.Fatalf("id and secret should be reported, got %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"kubesphere.io/devops/pkg/gojenkins"
+	"kubesphere.io/devops/pkg/models"
+)
+
+const ErrorCodeCredentialContentUnparsable = "CREDENTIAL_CONTENT_UNPARSABLE"
+
+// credentialForm reads the fields of the update page of a credential, the only place Jenkins shows the
+// content of a credential. Jenkins changes the markup of the page across versions, so the fields the
+// page does not have are recorded instead of being read as blank.
+type credentialForm struct {
+	doc     *goquery.Document
+	missing []string
+}
+
+func newCredentialForm(page string) (*credentialForm, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(page))
+	if err != nil {
+		return nil, err
+	}
+	return &credentialForm{doc: doc}, nil
+}
+
+// field returns the value of the first input or text area whose name contains name.
+func (f *credentialForm) field(name string) string {
+	selection := f.doc.Find(fmt.Sprintf("input[name*=%s], textarea[name*=%s]", name, name)).First()
+	if selection.Length() == 0 {
+		f.missing = append(f.missing, name)
+		return ""
+	}
+	if goquery.NodeName(selection) == "textarea" {
+		return selection.Text()
+	}
+	value, _ := selection.Attr("value")
+	return value
+}
+
+// optionalField is field for the fields Jenkins only renders when they are set.
+func (f *credentialForm) optionalField(name string) string {
+	missing := len(f.missing)
+	value := f.field(name)
+	f.missing = f.missing[:missing]
+	return value
+}
+
+// scope returns the selected scope, empty when the page has no scope.
+func (f *credentialForm) scope() string {
+	scope, _ := f.doc.Find("select[name*=scope] option[selected]").First().Attr("value")
+	return scope
+}
+
+// err returns the error of the fields missing from the page, nil when every field was found.
+func (f *credentialForm) err(jenkinsVersion string) error {
+	if len(f.missing) == 0 {
+		return nil
+	}
+	if jenkinsVersion == "" {
+		jenkinsVersion = "unknown"
+	}
+	return newCredentialError(ErrorCodeCredentialContentUnparsable,
+		fmt.Sprintf("unable to parse credential content for Jenkins version %s, fields %s not found",
+			jenkinsVersion, strings.Join(f.missing, ", ")))
+}
+
+// formCredentialContent returns the content shown for a credential of credentialType, the id and the
+// description come from the json api of Jenkins, only the other fields are read from the form.
+// Secrets and the bytes of secret files are not shown, it returns nil for the types without content to show.
+func formCredentialContent(form *credentialForm, credentialType string, credential *gojenkins.CredentialResponse,
+	projectCredential *models.ProjectCredential) interface{} {
+	id, description := credential.Id, credential.Description
+	switch credentialType {
+	case CredentialTypeDockerRegistry:
+		return &DockerRegistryCredentialRequest{Id: id, Description: description,
+			RegistryUrl: string(projectCredential.RegistryUrl), Username: form.field("username")}
+	case CredentialTypeAWS:
+		return &AWSCredentialRequest{Id: id, Description: description,
+			AccessKeyId: form.field("accessKey"), IamRoleArn: form.optionalField("iamRoleArn")}
+	case CredentialTypeCertificate:
+		return &CertificateCredentialRequest{Id: id, Description: description}
+	case CredentialTypeSecretFile:
+		return &SecretFileCredentialRequest{Id: id, Description: description, FileName: form.field("fileName")}
+	case CredentialTypeOpenShiftToken:
+		// the token is never read from the form, only the server recorded in db is shown
+		request := &OpenShiftTokenCredentialRequest{Id: id, Description: description}
+		if projectCredential != nil {
+			request.ServerUrl = string(projectCredential.ServerUrl)
+		}
+		return request
+	case CredentialTypeKubeConfig:
+		return &KubeconfigCredentialRequest{Id: id, Description: description, Content: form.field("content")}
+	case CredentialTypeUsernamePassword:
+		return &UsernamePasswordCredentialRequest{Id: id, Description: description,
+			Username: form.field("username")}
+	case CredentialTypeSsh:
+		privateKey := form.field("privateKey")
+		return &SshCredentialRequest{Id: id, Description: description, Username: form.field("username"),
+			PrivateKey: privateKey, PrivateKeys: gojenkins.SplitPrivateKeys(privateKey)}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"strings"
+	"testing"
+
+	"kubesphere.io/devops/pkg/gojenkins"
+	"kubesphere.io/devops/pkg/models"
+)
+
+func TestFormCredentialContent(t *testing.T) {
+	credential := &gojenkins.CredentialResponse{Id: "git", Description: "deploy key"}
+	// newer Jenkins renders multi-line fields as text areas
+	form, err := newCredentialForm(`<form><textarea name="_.username">admin</textarea>` +
+		`<input name="_.password" type="password" value="{AQAAABAAAAAQ}"/></form>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := formCredentialContent(form, CredentialTypeUsernamePassword, credential, &models.ProjectCredential{})
+	if err := form.err("2.150.1"); err != nil {
+		t.Fatal(err)
+	}
+	usernamePassword := content.(*UsernamePasswordCredentialRequest)
+	if usernamePassword.Id != "git" || usernamePassword.Description != "deploy key" ||
+		usernamePassword.Username != "admin" || usernamePassword.Password != "" {
+		t.Fatalf("unexpected content %+v", usernamePassword)
+	}
+
+	form, err = newCredentialForm(`<form><input name="_.user" type="text" value="admin"/></form>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	formCredentialContent(form, CredentialTypeSsh, credential, &models.ProjectCredential{})
+	err = form.err("2.150.1")
+	credentialErr, ok := err.(*CredentialError)
+	if !ok || credentialErr.Code != ErrorCodeCredentialContentUnparsable ||
+		!strings.Contains(credentialErr.Message, "Jenkins version 2.150.1") ||
+		!strings.Contains(credentialErr.Message, "privateKey, username") {
+		t.Fatalf("missing fields should fail the form, got %v", err)
+	}
+}
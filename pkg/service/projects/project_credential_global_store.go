@@ -0,0 +1,136 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/asaskevich/govalidator"
+
+	"kubesphere.io/devops/pkg/constants"
+	"kubesphere.io/devops/pkg/db"
+	"kubesphere.io/devops/pkg/gojenkins"
+	"kubesphere.io/devops/pkg/models"
+)
+
+var CredentialStores = []string{models.CredentialStoreFolder, models.CredentialStoreGlobal}
+
+// validateCredentialStore checks the store of a request, the global store only has the global domain.
+func validateCredentialStore(store, domain string) error {
+	if govalidator.IsNull(store) || store == models.CredentialStoreFolder {
+		return nil
+	}
+	if store != models.CredentialStoreGlobal {
+		return fmt.Errorf("error credential store [%s] not in %s", store, CredentialStores)
+	}
+	if !govalidator.IsNull(domain) && domain != "_" {
+		return fmt.Errorf("error credentials of the global store are in the global domain, not [%s]", domain)
+	}
+	return nil
+}
+
+// checkGlobalStoreWriter only lets the admin owning the project write to the global store,
+// its credentials are visible to the jobs of every project.
+func (s *ProjectService) checkGlobalStoreWriter(r *rest.Request, operator, projectId string) error {
+	err := s.checkProjectUserInRole(r, operator, projectId, []string{ProjectOwner})
+	if err != nil {
+		return err
+	}
+	if operator != constants.KS_ADMIN {
+		return fmt.Errorf("user [%s] can not write credentials to the global store", operator)
+	}
+	return nil
+}
+
+// getGlobalProjectCredential returns the record of a credential the project created in the global store,
+// the credentials of the other projects in the store are not found.
+func (s *ProjectService) getGlobalProjectCredential(projectId, credentialId string) (*models.ProjectCredential, error) {
+	projectCredential, err := s.getProjectCredential(projectId, "_", credentialId)
+	if err != nil {
+		return nil, err
+	}
+	if projectCredential == nil || projectCredential.Store != models.CredentialStoreGlobal {
+		return nil, fmt.Errorf("credential [%s] not found in the global store: %w", credentialId,
+			gojenkins.ErrCredentialNotFound)
+	}
+	return projectCredential, nil
+}
+
+// getCredentialInStore reads the credential from the store it was created in.
+func (s *ProjectService) getCredentialInStore(ctx context.Context, store, projectId, domain,
+	credentialId string) (*gojenkins.CredentialResponse, error) {
+	if store != models.CredentialStoreGlobal {
+		return s.credentialStore().GetCredentialInFolderContext(ctx, domain, credentialId, projectId)
+	}
+	_, err := s.getGlobalProjectCredential(projectId, credentialId)
+	if err != nil {
+		return nil, err
+	}
+	return s.credentialStore().GetCredentialInSystem("_", credentialId)
+}
+
+func (s *ProjectService) getCredentialContentInStore(ctx context.Context, store, projectId, domain,
+	credentialId string) (string, error) {
+	if store != models.CredentialStoreGlobal {
+		return s.credentialStore().GetCredentialContentInFolderContext(ctx, domain, credentialId, projectId)
+	}
+	return s.credentialStore().GetCredentialContentInSystem("_", credentialId)
+}
+
+// loadGlobalProjectCredentials loads the records of the credentials the project created in the global store.
+func (s *ProjectService) loadGlobalProjectCredentials(projectId string) ([]*models.ProjectCredential, error) {
+	projectCredentials := make([]*models.ProjectCredential, 0)
+	_, err := s.Ds.Db.Select(models.ProjectCredentialColumns...).
+		From(models.ProjectCredentialTableName).
+		Where(db.And(db.Eq(models.ProjectIdColumn, projectId),
+			db.Eq(models.ProjectCredentialStoreColumn, models.CredentialStoreGlobal))).
+		Load(&projectCredentials)
+	if err != nil {
+		return nil, err
+	}
+	return projectCredentials, nil
+}
+
+// listGlobalCredentials lists the credentials the project created in the global store.
+func (s *ProjectService) listGlobalCredentials(projectId string) ([]*CredentialResponse, error) {
+	projectCredentials, err := s.loadGlobalProjectCredentials(projectId)
+	if err != nil {
+		return nil, err
+	}
+	response := make([]*CredentialResponse, 0)
+	if len(projectCredentials) == 0 {
+		return response, nil
+	}
+	jenkinsCredentials, err := s.credentialStore().GetCredentialsInSystem("_")
+	if err != nil {
+		return nil, err
+	}
+	recorded := make(map[string]bool, len(projectCredentials))
+	for _, projectCredential := range projectCredentials {
+		recorded[projectCredential.CredentialId] = true
+	}
+	projectJenkinsCredentials := make([]*gojenkins.CredentialResponse, 0)
+	for _, jenkinsCredential := range jenkinsCredentials {
+		if recorded[jenkinsCredential.Id] {
+			projectJenkinsCredentials = append(projectJenkinsCredentials, jenkinsCredential)
+		}
+	}
+	response = formatCredentialsResponse(projectJenkinsCredentials, projectCredentials)
+	for _, credential := range response {
+		credential.Store = models.CredentialStoreGlobal
+	}
+	return response, nil
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"kubesphere.io/devops/pkg/config"
+	"kubesphere.io/devops/pkg/constants"
+	"kubesphere.io/devops/pkg/gojenkins"
+	"kubesphere.io/devops/pkg/models"
+)
+
+func TestValidateCredentialStore(t *testing.T) {
+	for _, valid := range [][2]string{{"", "domain"}, {"folder", "domain"}, {"global", ""}, {"global", "_"}} {
+		if err := validateCredentialStore(valid[0], valid[1]); err != nil {
+			t.Errorf("store [%s] in domain [%s] should be valid: %+v", valid[0], valid[1], err)
+		}
+	}
+	for _, invalid := range [][2]string{{"system", ""}, {"global", "domain"}} {
+		if err := validateCredentialStore(invalid[0], invalid[1]); err == nil {
+			t.Errorf("store [%s] in domain [%s] should be invalid", invalid[0], invalid[1])
+		}
+	}
+}
+
+func TestApplyAndBreakGlassStayInFolder(t *testing.T) {
+	requests := []*CredentialRequest{{Type: CredentialTypeSecretText, Store: models.CredentialStoreGlobal,
+		Content: map[string]interface{}{"id": "token", "secret": "secret"}}}
+	if err := validateApplyRequests(requests, 0); err == nil {
+		t.Fatalf("applied credentials should not be created in the global store")
+	}
+
+	store := NewFakeCredentialStore()
+	s := &ProjectService{Credentials: store, Config: &config.Config{}}
+	s.Config.BreakGlass.Roles = []string{ProjectMaintainer}
+	w := &recorder{httptest.NewRecorder()}
+	r := newFakeStoreJsonRequest("alice", ProjectMaintainer, "POST", "/projects/project/credentials/break-glass",
+		&BreakGlassCredentialRequest{CredentialRequest: *requests[0], Reason: "incident"})
+	s.BreakGlassCreateCredentialHandler(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("break-glass credentials should not be created in the global store, got %d %s", w.Code, w.Body)
+	}
+	if credentials, _ := store.GetCredentialsInSystem("_"); len(credentials) != 0 {
+		t.Fatalf("global store should be left untouched, got %v", credentials)
+	}
+}
+
+func TestCreateGlobalJenkinsCredential(t *testing.T) {
+	store := NewFakeCredentialStore()
+	s := &ProjectService{Credentials: store, Config: &config.Config{}}
+	content := map[string]interface{}{"id": "token", "secret": "secret", "description": "deploy token"}
+	request := &CredentialRequest{Type: CredentialTypeSecretText, Content: content}
+
+	projectCredential, err := s.createJenkinsCredential(context.Background(),
+		newCredentialTarget("project", "", models.CredentialStoreGlobal), "admin", request)
+	if err != nil {
+		t.Fatalf("failed to create global credential: %+v", err)
+	}
+	if projectCredential.Store != models.CredentialStoreGlobal || projectCredential.Domain != "_" {
+		t.Fatalf("unexpected record %+v", projectCredential)
+	}
+	if _, err := store.GetCredentialInSystem("_", "token"); err != nil {
+		t.Fatalf("credential should be in the system store: %+v", err)
+	}
+	if _, err := store.GetCredentialInFolder("_", "token", "project"); !errors.Is(err, gojenkins.ErrCredentialNotFound) {
+		t.Fatalf("credential should not be in the project folder, got %+v", err)
+	}
+
+	_, err = s.createJenkinsCredential(context.Background(),
+		newCredentialTarget("other", "", models.CredentialStoreGlobal), "admin", request)
+	if !errors.Is(err, gojenkins.ErrCredentialExists) {
+		t.Fatalf("expected the id to be used in the global store, got %+v", err)
+	}
+}
+
+func TestUpdateAndDeleteGlobalCredential(t *testing.T) {
+	store := NewFakeCredentialStore()
+	s := &ProjectService{Credentials: store, Config: &config.Config{}}
+	target := newCredentialTarget("project", "", models.CredentialStoreGlobal)
+	if _, err := store.CreateSecretTextCredentialInSystem("_", "token", "secret", "", ""); err != nil {
+		t.Fatalf("failed to create global credential: %+v", err)
+	}
+	if _, err := store.CreateSecretTextCredentialInFolder("_", "token", "folder", "", "", "project"); err != nil {
+		t.Fatalf("failed to create folder credential: %+v", err)
+	}
+
+	_, err := s.updateCredentialContent(target, CredentialTypeSecretText,
+		map[string]interface{}{"id": "token", "secret": "rotated", "description": "rotated"}, nil)
+	if err != nil {
+		t.Fatalf("failed to update global credential: %+v", err)
+	}
+	credential, err := store.GetCredentialInSystem("_", "token")
+	if err != nil || credential.Description != "rotated" {
+		t.Fatalf("global credential should be updated, got %+v, %+v", credential, err)
+	}
+	credential, _ = store.GetCredentialInFolder("_", "token", "project")
+	if credential.Description != "" {
+		t.Fatalf("folder credential should be kept, got %+v", credential)
+	}
+	if _, err := s.getCredentialETag(target, "token"); err != nil {
+		t.Fatalf("global credential should have an ETag: %+v", err)
+	}
+
+	if _, err := target.delete(context.Background(), store, "token"); err != nil {
+		t.Fatalf("failed to delete global credential: %+v", err)
+	}
+	if _, err := store.GetCredentialInSystem("_", "token"); !errors.Is(err, gojenkins.ErrCredentialNotFound) {
+		t.Fatalf("credential should be deleted from the global store, got %+v", err)
+	}
+	if _, err := store.GetCredentialInFolder("_", "token", "project"); err != nil {
+		t.Fatalf("folder credential should be kept: %+v", err)
+	}
+}
+
+// newGlobalCredentialTestService returns a service on the test database with the secret text credential
+// token of its project in the global store, and a folder credential token in another domain of the project.
+func newGlobalCredentialTestService(t *testing.T) (*ProjectService, *FakeCredentialStore, string, func()) {
+	s, store, projectIds, cleanup := newDbTestService(t, 1)
+	projectId := projectIds[0]
+	if _, err := store.CreateSecretTextCredentialInSystem("_", "token", "secret", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.CreateSecretTextCredentialInFolder("github", "token", "folder", "", "",
+		projectId); err != nil {
+		t.Fatal(err)
+	}
+	projectCredential := models.NewProjectCredential(projectId, "token", "_", constants.KS_ADMIN)
+	projectCredential.Store = models.CredentialStoreGlobal
+	insertTestCredentialRecord(t, s, projectCredential)
+	return s, store, projectId, cleanup
+}
+
+func TestDeleteGlobalCredentialHandler(t *testing.T) {
+	s, store, projectId, cleanup := newGlobalCredentialTestService(t)
+	defer cleanup()
+
+	// the usage of the credential is read from the global store before it is deleted
+	w := &recorder{httptest.NewRecorder()}
+	r := newProjectJsonRequest(constants.KS_ADMIN, projectId, map[string]string{projectId: ProjectOwner},
+		"DELETE", "/projects/"+projectId+"/credentials/token", nil)
+	r.PathParams["cid"] = "token"
+	s.DeleteCredentialHandler(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("global credential should be deleted, got %d %s", w.Code, w.Body)
+	}
+	if _, err := store.GetCredentialInSystem("_", "token"); !errors.Is(err, gojenkins.ErrCredentialNotFound) {
+		t.Fatalf("credential should be deleted from the global store, got %+v", err)
+	}
+	if _, err := store.GetCredentialInFolder("github", "token", projectId); err != nil {
+		t.Fatalf("folder credential should be kept: %+v", err)
+	}
+}
+
+func TestRotateGlobalCredentialHandler(t *testing.T) {
+	s, store, projectId, cleanup := newGlobalCredentialTestService(t)
+	defer cleanup()
+
+	for _, test := range []struct {
+		username string
+		status   int
+		secret   string
+	}{
+		{username: "alice", status: http.StatusForbidden, secret: "secret"},
+		{username: constants.KS_ADMIN, status: http.StatusOK, secret: "rotated"},
+	} {
+		w := &recorder{httptest.NewRecorder()}
+		r := newProjectJsonRequest(test.username, projectId, map[string]string{projectId: ProjectOwner}, "POST",
+			"/projects/"+projectId+"/credentials/token/rotate", &RotateCredentialRequest{Secret: "rotated"})
+		r.PathParams["cid"] = "token"
+		s.RotateCredentialHandler(w, r)
+		if w.Code != test.status {
+			t.Fatalf("rotation of global credential by [%s] should answer %d, got %d %s", test.username,
+				test.status, w.Code, w.Body)
+		}
+		credential, err := store.getInFolder(fakeSystemFolder, "_", "token")
+		if err != nil || credential.fields[0][1] != test.secret {
+			t.Fatalf("global credential should have secret [%s], got %+v %v", test.secret, credential, err)
+		}
+	}
+	if secret := fakeCredentialField(t, store, projectId, "github", "token", "secret"); secret != "folder" {
+		t.Fatalf("folder credential should be kept, got [%s]", secret)
+	}
+}
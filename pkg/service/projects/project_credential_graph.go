@@ -0,0 +1,181 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ant0ine/go-json-rest/rest"
+
+	"kubesphere.io/devops/pkg/logger"
+	"kubesphere.io/devops/pkg/utils/stringutils"
+	"kubesphere.io/devops/pkg/utils/userutils"
+)
+
+const (
+	DependencyNodeCredential = "credential"
+	DependencyNodeJob        = "job"
+)
+
+type DependencyNode struct {
+	Id     string `json:"id"`
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Domain string `json:"domain,omitempty"`
+	Type   string `json:"type,omitempty"`
+	Status string `json:"status,omitempty"`
+}
+
+// DependencyEdge links a credential node to a job node that used it in Builds builds.
+type DependencyEdge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Builds int    `json:"builds"`
+}
+
+type DependencyGraphResponse struct {
+	Nodes       []*DependencyNode `json:"nodes"`
+	Edges       []*DependencyEdge `json:"edges"`
+	GeneratedAt time.Time         `json:"generated_at"`
+}
+
+type dependencyGraphEntry struct {
+	graph    *DependencyGraphResponse
+	expireAt time.Time
+}
+
+type dependencyGraphCache struct {
+	sync.Mutex
+	entries map[string]*dependencyGraphEntry
+}
+
+var credentialDependencyGraphCache = &dependencyGraphCache{entries: make(map[string]*dependencyGraphEntry)}
+
+func (c *dependencyGraphCache) get(projectId string) *DependencyGraphResponse {
+	c.Lock()
+	defer c.Unlock()
+	entry, ok := c.entries[projectId]
+	if !ok || time.Now().After(entry.expireAt) {
+		return nil
+	}
+	return entry.graph
+}
+
+func (c *dependencyGraphCache) set(projectId string, graph *DependencyGraphResponse, ttl time.Duration) {
+	c.Lock()
+	defer c.Unlock()
+	c.entries[projectId] = &dependencyGraphEntry{graph: graph, expireAt: time.Now().Add(ttl)}
+}
+
+func credentialNodeId(domain, credentialId string) string {
+	return DependencyNodeCredential + ":" + domain + "/" + credentialId
+}
+
+func jobNodeId(fullName string) string {
+	return DependencyNodeJob + ":" + fullName
+}
+
+// getCredentialDependencyGraph builds the graph from the fingerprints of all credentials in the project,
+// which Jenkins returns with the credential list in a single request.
+func (s *ProjectService) getCredentialDependencyGraph(projectId string) (*DependencyGraphResponse, error) {
+	if graph := credentialDependencyGraphCache.get(projectId); graph != nil {
+		return graph, nil
+	}
+	credentials, err := s.credentialStore().GetCredentialsInFolder("", projectId)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(credentials, func(i, j int) bool {
+		if credentials[i].Domain != credentials[j].Domain {
+			return credentials[i].Domain < credentials[j].Domain
+		}
+		return credentials[i].Id < credentials[j].Id
+	})
+
+	graph := &DependencyGraphResponse{
+		Nodes:       make([]*DependencyNode, 0),
+		Edges:       make([]*DependencyEdge, 0),
+		GeneratedAt: time.Now(),
+	}
+	jobs := make(map[string]*DependencyNode)
+	tree := newJobTree(s.credentialStore())
+	for _, credential := range credentials {
+		credentialType, ok := CredentialTypeMap[credential.TypeName]
+		if !ok {
+			credentialType = credential.TypeName
+		}
+		credentialNode := &DependencyNode{
+			Id:     credentialNodeId(credential.Domain, credential.Id),
+			Kind:   DependencyNodeCredential,
+			Name:   credential.Id,
+			Domain: credential.Domain,
+			Type:   credentialType,
+		}
+		graph.Nodes = append(graph.Nodes, credentialNode)
+		if credential.Fingerprint == nil {
+			continue
+		}
+		for _, usage := range credential.Fingerprint.Usage {
+			jobNode, ok := jobs[usage.Name]
+			if !ok {
+				status, err := tree.getStatus(usage.Name)
+				if err != nil {
+					return nil, err
+				}
+				jobNode = &DependencyNode{Id: jobNodeId(usage.Name), Kind: DependencyNodeJob, Name: usage.Name, Status: status}
+				jobs[usage.Name] = jobNode
+			}
+			edge := &DependencyEdge{Source: credentialNode.Id, Target: jobNode.Id}
+			for _, buildRange := range usage.Ranges.Ranges {
+				edge.Builds += buildRange.End - buildRange.Start
+			}
+			graph.Edges = append(graph.Edges, edge)
+		}
+	}
+
+	jobNames := make([]string, 0, len(jobs))
+	for name := range jobs {
+		jobNames = append(jobNames, name)
+	}
+	sort.Strings(jobNames)
+	for _, name := range jobNames {
+		graph.Nodes = append(graph.Nodes, jobs[name])
+	}
+	credentialDependencyGraphCache.set(projectId, graph, s.Config.Graph.CacheTtl)
+	return graph, nil
+}
+
+// GetCredentialDependencyGraphHandler returns the credentials of the project and the jobs referencing them,
+// the graph is cached for a short time so it can lag behind recent builds.
+func (s *ProjectService) GetCredentialDependencyGraphHandler(w rest.ResponseWriter, r *rest.Request) {
+	projectId := r.PathParams["id"]
+	operator := userutils.GetUserNameFromRequest(r)
+	err := s.checkProjectUserInRole(r, operator, projectId, []string{ProjectOwner, ProjectMaintainer})
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
+		return
+	}
+	graph, err := s.getCredentialDependencyGraph(projectId)
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeCredentialError(w, err, stringutils.GetJenkinsStatusCode(err))
+		return
+	}
+	w.WriteJson(graph)
+	return
+}
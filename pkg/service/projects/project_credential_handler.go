@@ -14,21 +14,25 @@ limitations under the License.
 package projects
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
-	"github.com/PuerkitoBio/goquery"
 	"github.com/ant0ine/go-json-rest/rest"
 	"github.com/asaskevich/govalidator"
 	"github.com/gocraft/dbr"
-	"github.com/mitchellh/mapstructure"
 
 	"kubesphere.io/devops/pkg/db"
+	"kubesphere.io/devops/pkg/gojenkins"
 	"kubesphere.io/devops/pkg/logger"
 	"kubesphere.io/devops/pkg/models"
+	"kubesphere.io/devops/pkg/utils/kubeconfigutils"
+	"kubesphere.io/devops/pkg/utils/reflectutils"
 	"kubesphere.io/devops/pkg/utils/stringutils"
 	"kubesphere.io/devops/pkg/utils/userutils"
 )
@@ -38,51 +42,143 @@ const (
 	CredentialTypeSsh              = "ssh"
 	CredentialTypeSecretText       = "secret_text"
 	CredentialTypeKubeConfig       = "kubeconfig"
+	CredentialTypeDockerRegistry   = "docker_registry"
+	CredentialTypeCertificate      = "certificate"
+	CredentialTypeAWS              = "aws"
+	CredentialTypeSecretFile       = "secret_file"
+	CredentialTypeOpenShiftToken   = "openshift_token"
 )
 
 type CredentialRequest struct {
 	Type    string                 `json:"type"`
 	Domain  string                 `json:"domain"`
 	Content map[string]interface{} `json:"content"`
+	// git repository or ssh server the credential is verified against
+	VerifyUrl string `json:"verify_url,omitempty"`
+	// generate the id of a credential created without id
+	GenerateId bool `json:"generate_id,omitempty"`
+	// Jenkins store the credential is created in, folder by default
+	Store string `json:"store,omitempty"`
+	// when the credential expires, only recorded to remind of its rotation, Jenkins keeps using it
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// jobs the credential is meant for, informational and not enforced, kept as they are on update without them
+	IntendedJobs []string `json:"intended_jobs,omitempty"`
+	// content read back from Jenkins, its secrets are encrypted by Jenkins so they are neither validated nor scored
+	jenkinsContent bool
+	// record of the credential the content was read back from, its strength and key size are carried over
+	sourceRecord *models.ProjectCredential
 }
 
 type UsernamePasswordCredentialRequest struct {
-	Id          string `json:"id"`
-	Username    string `json:"username"`
-	Password    string `json:"password,omitempty"`
+	Id          string `json:"id" valid:"required"`
+	Username    string `json:"username" valid:"required"`
+	Password    string `json:"password,omitempty" secret:"true"`
 	Description string `json:"description"`
+	Scope       string `json:"scope,omitempty"`
 }
 
+// SshCredentialRequest may give several keys as private_keys instead of private_key, they are stored
+// concatenated in the single private key of the credential and whether Jenkins tries every one of them
+// depends on the plugin using it. Content responses hold both the private_key and its keys one by one.
 type SshCredentialRequest struct {
-	Id          string `json:"id"`
-	Username    string `json:"username"`
-	Passphrase  string `json:"passphrase"`
-	PrivateKey  string `json:"private_key" mapstructure:"private_key"`
-	Description string `json:"description"`
+	Id          string   `json:"id" valid:"required"`
+	Username    string   `json:"username" valid:"required"`
+	Passphrase  string   `json:"passphrase" secret:"true"`
+	PrivateKey  string   `json:"private_key" mapstructure:"private_key" valid:"required" secret:"true"`
+	PrivateKeys []string `json:"private_keys,omitempty" mapstructure:"-" secret:"true"`
+	Description string   `json:"description"`
+	Scope       string   `json:"scope,omitempty"`
 }
 
 type SecretTextCredentialRequest struct {
-	Id          string `json:"id"`
-	Secret      string `json:"secret"`
+	Id          string `json:"id" valid:"required"`
+	Secret      string `json:"secret" valid:"required" secret:"true"`
+	Description string `json:"description"`
+	Scope       string `json:"scope,omitempty"`
+}
+
+// SecretFileCredentialRequest holds a file, base64 encoded, the file itself can not be read back
+type SecretFileCredentialRequest struct {
+	Id          string `json:"id" valid:"required"`
+	FileName    string `json:"file_name" mapstructure:"file_name" valid:"required"`
+	Content     string `json:"content,omitempty" valid:"required" secret:"true"`
 	Description string `json:"description"`
+	Scope       string `json:"scope,omitempty"`
 }
 
 type KubeconfigCredentialRequest struct {
-	Id          string `json:"id"`
-	Content     string `json:"content"`
+	Id          string `json:"id" valid:"required"`
+	Content     string `json:"content" valid:"required" secret:"true"`
+	Description string `json:"description"`
+	Scope       string `json:"scope,omitempty"`
+}
+
+type DockerRegistryCredentialRequest struct {
+	Id          string `json:"id" valid:"required"`
+	RegistryUrl string `json:"registry_url" mapstructure:"registry_url" valid:"required"`
+	Username    string `json:"username" valid:"required"`
+	Password    string `json:"password,omitempty" secret:"true"`
+	Description string `json:"description"`
+	Scope       string `json:"scope,omitempty"`
+}
+
+// CertificateCredentialRequest holds a PKCS#12 keystore, base64 encoded
+type CertificateCredentialRequest struct {
+	Id          string `json:"id" valid:"required"`
+	Keystore    string `json:"keystore,omitempty" valid:"required" secret:"true"`
+	Password    string `json:"password,omitempty" secret:"true"`
+	Description string `json:"description"`
+	Scope       string `json:"scope,omitempty"`
+}
+
+// AWSCredentialRequest holds an AWS access key, optionally with the IAM role to assume
+type AWSCredentialRequest struct {
+	Id              string `json:"id" valid:"required"`
+	AccessKeyId     string `json:"access_key_id" mapstructure:"access_key_id" valid:"required"`
+	SecretAccessKey string `json:"secret_access_key,omitempty" mapstructure:"secret_access_key" valid:"required" secret:"true"`
+	IamRoleArn      string `json:"iam_role_arn,omitempty" mapstructure:"iam_role_arn"`
+	Description     string `json:"description"`
+	Scope           string `json:"scope,omitempty"`
+}
+
+// OpenShiftTokenCredentialRequest holds a bearer token of the cluster at ServerUrl, the token can not be read back
+type OpenShiftTokenCredentialRequest struct {
+	Id          string `json:"id" valid:"required"`
+	ServerUrl   string `json:"server_url" mapstructure:"server_url" valid:"required"`
+	Token       string `json:"token,omitempty" valid:"required" secret:"true"`
 	Description string `json:"description"`
+	Scope       string `json:"scope,omitempty"`
+}
+
+// CreateCredentialResponse answers a create, the strength is only given for secrets which are scored and the
+// clusters for kubeconfig credentials whose connectivity was validated.
+type CreateCredentialResponse struct {
+	Id       string                 `json:"id"`
+	Store    string                 `json:"store,omitempty"`
+	Strength *CredentialStrength    `json:"strength,omitempty"`
+	Clusters []*ClusterReachability `json:"clusters,omitempty"`
 }
 
 type DeleteCredentialRequest struct {
 	Domain string `json:"domain"`
 }
 
-type CopySshCredentialRequest struct {
+type DeleteCredentialResponse struct {
 	Id string `json:"id"`
+	// set when the credential can be restored from trash
+	TrashId         string     `json:"trash_id,omitempty"`
+	RestorableUntil *time.Time `json:"restorable_until,omitempty"`
+}
+
+// CopySshCredentialRequest gives the id of the copy of an ssh credential, in the domain of the credential.
+type CopySshCredentialRequest struct {
+	Id     string `json:"id"`
+	Domain string `json:"domain"`
 }
 
 type CredentialResponse struct {
 	Id          string `json:"id"`
+	Uuid        string `json:"uuid,omitempty"`
 	Type        string `json:"type"`
 	DisplayName string `json:"display_name"`
 	Fingerprint *struct {
@@ -98,523 +194,938 @@ type CredentialResponse struct {
 			} `json:"ranges"`
 		} `json:"usage,omitempty"`
 	} `json:"fingerprint,omitempty"`
-	Description string                 `json:"description"`
-	Domain      string                 `json:"domain"`
-	CreateTime  *time.Time             `json:"create_time,omitempty"`
-	Creator     string                 `json:"creator,omitempty"`
-	Content     map[string]interface{} `json:"content"`
+	Description        string              `json:"description"`
+	Domain             string              `json:"domain"`
+	Scope              string              `json:"scope"`
+	Store              string              `json:"store,omitempty"`
+	CreateTime         *time.Time          `json:"create_time,omitempty"`
+	Creator            string              `json:"creator,omitempty"`
+	CreatorDisplayName string              `json:"creator_display_name,omitempty"`
+	ModifiedBy         string              `json:"modified_by,omitempty"`
+	ModifiedTime       *time.Time          `json:"modified_time,omitempty"`
+	ExpiresAt          *time.Time          `json:"expires_at,omitempty"`
+	Strength           *CredentialStrength `json:"strength,omitempty"`
+	// key of ssh and certificate credentials, nil for credentials created before it was recorded
+	KeySize *CredentialKeySize `json:"key_size,omitempty"`
+	// hosts of the domain and whether they are reachable, only filled on request
+	Reachability []*HostReachability `json:"reachability,omitempty"`
+	// jobs the credential is meant for, informational and not enforced, nil when it is meant for any job
+	IntendedJobs []string `json:"intended_jobs,omitempty"`
+	// when Jenkins first fingerprinted the credential on its first use, nil before
+	FirstUsedTime *time.Time `json:"first_used_time,omitempty"`
+	// references from the project folder configuration, only filled when config scan is enabled
+	ConfigReferences []*CredentialConfigReference `json:"config_references,omitempty"`
+	Content          map[string]interface{}       `json:"content"`
 }
 
 func (s *ProjectService) CreateCredentialHandler(w rest.ResponseWriter, r *rest.Request) {
+	audit := s.newCredentialAudit(w, r, CredentialActionCreate)
+	defer audit.record()
+	w = audit
 	request := &CredentialRequest{}
 	projectId := r.PathParams["id"]
+	defer s.invalidateCredentialList(projectId)
+	ctx := r.Context()
 	operator := userutils.GetUserNameFromRequest(r)
 
 	err := r.DecodeJsonPayload(request)
 	if err != nil {
-		logger.Error("%+v", err)
-		rest.Error(w, err.Error(), http.StatusBadRequest)
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
 		return
 	}
 
-	err = s.checkProjectUserInRole(operator, projectId, []string{ProjectOwner, ProjectMaintainer})
+	overrideCooldown, err := parseCooldownOverride(r)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	dryRun, err := parseDryRun(r)
 	if err != nil {
-		logger.Error("%+v", err)
-		rest.Error(w, err.Error(), http.StatusForbidden)
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
 		return
 	}
+	if dryRun {
+		audit.skip()
+	}
 
-	switch request.Type {
-	case CredentialTypeUsernamePassword:
-		UPRequest := &UsernamePasswordCredentialRequest{}
-		err := mapstructure.Decode(request.Content, UPRequest)
+	err = s.checkProjectUserInRole(r, operator, projectId, s.credentialActionRoles(CredentialActionCreate))
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
+		return
+	}
+	err = checkCooldownOverride(operator, overrideCooldown)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
+		return
+	}
+	// retries of a create which succeeded get the created credential instead of a conflict
+	idempotencyKey := r.Header.Get(IdempotencyKeyHeader)
+	var requestHash string
+	if !govalidator.IsNull(idempotencyKey) && !dryRun {
+		err = validateIdempotencyKey(idempotencyKey)
 		if err != nil {
-			logger.Error("%+v", err)
-			rest.Error(w, err.Error(), http.StatusBadRequest)
+			logger.WarnContext(r.Context(), "%+v", err)
+			writeCredentialError(w, err, http.StatusBadRequest)
 			return
 		}
-
-		credential, err := s.Ds.Jenkins.GetCredentialInFolder(request.Domain, UPRequest.Id, projectId)
-		if credential != nil {
-			err := fmt.Errorf("credential id [%s] has been used", credential.Id)
-			logger.Warn(err.Error())
-			rest.Error(w, err.Error(), http.StatusConflict)
-			return
-		}
-		if err != nil && stringutils.GetJenkinsStatusCode(err) != http.StatusNotFound {
-			logger.Error("%+v", err)
-			rest.Error(w, err.Error(), http.StatusBadRequest)
+		requestHash, err = hashIdempotentRequest(request)
+		if err != nil {
+			logger.ErrorContext(r.Context(), "%+v", err)
+			writeCredentialError(w, err, http.StatusInternalServerError)
 			return
 		}
-		credentialId, err := s.Ds.Jenkins.CreateUsernamePasswordCredentialInFolder(request.Domain, UPRequest.Id,
-			UPRequest.Username, UPRequest.Password, UPRequest.Description, projectId)
+		replayed, err := s.replayIdempotentCreate(w, r, projectId, idempotencyKey, requestHash)
 		if err != nil {
-			logger.Error("%+v", err)
-			rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
+			logger.ErrorContext(r.Context(), "%+v", err)
+			writeCredentialError(w, err, http.StatusInternalServerError)
 			return
 		}
-
-		projectCredential := models.NewProjectCredential(projectId, UPRequest.Id, request.Domain, operator)
-		_, err = s.Ds.Db.InsertInto(models.ProjectCredentialTableName).Columns(models.ProjectCredentialColumns...).
-			Record(projectCredential).Exec()
-		if err != nil {
-			logger.Error("%+v", err)
-			rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
+		if replayed {
+			audit.skip()
 			return
 		}
-
-		w.WriteJson(struct {
-			Id string `json:"id"`
-		}{Id: *credentialId})
+	}
+	err = validateCredentialStore(request.Store, request.Domain)
+	if err != nil {
+		logger.WarnContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
 		return
-
-	case CredentialTypeSsh:
-		SshRequest := &SshCredentialRequest{}
-		err := mapstructure.Decode(request.Content, SshRequest)
+	}
+	if request.Store == models.CredentialStoreGlobal {
+		err = s.checkGlobalStoreWriter(r, operator, projectId)
 		if err != nil {
-			logger.Error("%+v", err)
-			rest.Error(w, err.Error(), http.StatusBadRequest)
+			logger.ErrorContext(r.Context(), "%+v", err)
+			writeCredentialError(w, err, http.StatusForbidden)
 			return
 		}
+	}
+	err = s.fillGeneratedCredentialId(projectId, request)
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeJenkinsError(w, r, err)
+		return
+	}
 
-		credential, err := s.Ds.Jenkins.GetCredentialInFolder(request.Domain, SshRequest.Id, projectId)
-		if credential != nil {
-			err := fmt.Errorf("credential id [%s] has been used", credential.Id)
-			logger.Warn(err.Error())
-			rest.Error(w, err.Error(), http.StatusConflict)
-			return
-		}
-		if err != nil && stringutils.GetJenkinsStatusCode(err) != http.StatusNotFound {
-			logger.Error("%+v", err)
-			rest.Error(w, err.Error(), http.StatusBadRequest)
-			return
-		}
+	requestCredentialId, _ := request.Content["id"].(string)
+	audit.setCredential(request.Domain, requestCredentialId)
+	setCredentialLogFields(r, logger.Fields{"credential_id": requestCredentialId})
+	err = validateCredentialId(requestCredentialId)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	err = normalizeCredentialScope(request.Content)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	err = normalizeCredentialDescription(request.Content, s.Config.Description.MaxLength)
+	if err != nil {
+		logger.WarnContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	err = normalizeSshPrivateKeys(request.Content)
+	if err != nil {
+		logger.WarnContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	setCredentialOperationType(r, request.Type)
+	err = validateCredentialFields(request.Type, request.Content)
+	if err != nil {
+		logger.WarnContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	request.IntendedJobs, err = validateIntendedJobs(request.IntendedJobs)
+	if err != nil {
+		logger.WarnContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	remainingCooldown, err := s.checkRecreateCooldown(projectId, request.Domain, requestCredentialId, overrideCooldown)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if remainingCooldown > 0 {
+		writeCooldownError(w, requestCredentialId, remainingCooldown)
+		return
+	}
+
+	reason, err := s.checkPreCreateWebhook(projectId, operator, request)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if reason != nil {
+		logger.WarnContext(r.Context(), "%+v", reason)
+		writeCredentialError(w, reason, http.StatusForbidden)
+		return
+	}
 
-		credentialId, err := s.Ds.Jenkins.CreateSshCredentialInFolder(request.Domain, SshRequest.Id,
-			SshRequest.Username, SshRequest.Passphrase, SshRequest.PrivateKey, SshRequest.Description, projectId)
+	verifyErr, err := s.checkCreateVerification(projectId, request)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if verifyErr != nil {
+		logger.WarnContext(r.Context(), "%+v", verifyErr)
+		writeCredentialError(w, verifyErr, http.StatusUnprocessableEntity)
+		return
+	}
+	err = s.fillDefaultDescription(projectId, operator, request.Domain, request.Type, request.Content)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	reason, err = s.checkContentValidationWebhook(projectId, operator, CredentialActionCreate, request.Type,
+		request.Domain, request.Content)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if reason != nil {
+		logger.WarnContext(r.Context(), "%+v", reason)
+		writeCredentialError(w, reason, http.StatusUnprocessableEntity)
+		return
+	}
+	credentialRequest, fields, err := s.prepareCredentialRequest(request.Type, request.Content)
+	if err != nil {
+		logger.WarnContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	var clusters []*ClusterReachability
+	if kubeconfigRequest, ok := credentialRequest.(*KubeconfigCredentialRequest); ok {
+		var status int
+		clusters, status, err = s.checkCreateKubeconfigClusters(r, kubeconfigRequest.Content)
 		if err != nil {
-			logger.Error("%+v", err)
-			rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
+			logger.WarnContext(r.Context(), "%+v", err)
+			writeCredentialError(w, err, status)
 			return
 		}
-
-		projectCredential := models.NewProjectCredential(projectId, SshRequest.Id, request.Domain, operator)
-		_, err = s.Ds.Db.InsertInto(models.ProjectCredentialTableName).
-			Columns(models.ProjectCredentialColumns...).
-			Record(projectCredential).Exec()
+	}
+	target := newCredentialTarget(projectId, request.Domain, request.Store)
+	credential, err := target.get(ctx, s.credentialStore(), credentialRequest.credentialId())
+	if credential != nil {
+		err := fmt.Errorf("credential id [%s] has been used", credential.Id)
+		logger.WarnContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusConflict)
+		return
+	}
+	if err != nil && !errors.Is(err, gojenkins.ErrCredentialNotFound) {
+		logCredentialError(r.Context(), err)
+		writeJenkinsError(w, r, err)
+		return
+	}
+	if dryRun {
+		writeCreateDryRun(w, credentialRequest.credentialId())
+		return
+	}
+	if !target.global {
+		err = s.ensureCredentialDomain(projectId, request.Domain)
 		if err != nil {
-			logger.Error("%+v", err)
-			rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
+			logCredentialError(r.Context(), err)
+			writeJenkinsError(w, r, err)
 			return
 		}
+	}
 
-		w.WriteJson(struct {
-			Id string `json:"id"`
-		}{Id: *credentialId})
+	projectCredential := newCredentialRecord(target, operator, credentialRequest.credentialId(), request, fields)
+	var idempotencyRecord *models.ProjectCredentialIdempotencyKey
+	if requestHash != "" {
+		idempotencyRecord = models.NewProjectCredentialIdempotencyKey(projectId, idempotencyKey,
+			projectCredential.CredentialId, projectCredential.Domain, requestHash, s.Config.Idempotency.Window)
+	}
+	err = s.createCredentialWithRollback(ctx, projectCredential, idempotencyRecord, func(ctx context.Context) error {
+		_, err := credentialRequest.create(ctx, s.credentialStore(), target)
+		return err
+	})
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeJenkinsError(w, r, err)
 		return
+	}
 
-	case CredentialTypeSecretText:
-		TextRequest := &SecretTextCredentialRequest{}
-		err := mapstructure.Decode(request.Content, TextRequest)
-		if err != nil {
-			logger.Error("%+v", err)
-			rest.Error(w, err.Error(), http.StatusBadRequest)
-			return
-		}
+	s.recordCredentialMutation(projectId, operator, CredentialActionCreate, projectCredential.Domain,
+		projectCredential.CredentialId, request.Content)
+	response := &CreateCredentialResponse{Id: projectCredential.CredentialId, Strength: fields.Strength,
+		Clusters: clusters}
+	if target.global {
+		response.Store = models.CredentialStoreGlobal
+	}
+	w.WriteJson(response)
+	return
+}
 
-		credential, err := s.Ds.Jenkins.GetCredentialInFolder(request.Domain, TextRequest.Id, projectId)
-		if credential != nil {
-			err := fmt.Errorf("credential id [%s] has been used", credential.Id)
-			logger.Warn(err.Error())
-			rest.Error(w, err.Error(), http.StatusConflict)
-			return
-		}
-		if err != nil && stringutils.GetJenkinsStatusCode(err) != http.StatusNotFound {
-			logger.Error("%+v", err)
-			rest.Error(w, err.Error(), http.StatusBadRequest)
-			return
-		}
+// parseForceDelete reads the force query parameter of a delete, which skips the usage check and deletes
+// credentials that could not be restored from the trash permanently.
+func parseForceDelete(r *rest.Request) (bool, error) {
+	forceParam := r.URL.Query().Get("force")
+	if govalidator.IsNull(forceParam) {
+		return false, nil
+	}
+	return strconv.ParseBool(forceParam)
+}
 
-		credentialId, err := s.Ds.Jenkins.CreateSecretTextCredentialInFolder(request.Domain, TextRequest.Id,
-			TextRequest.Secret, TextRequest.Description, projectId)
-		if err != nil {
-			logger.Error("%+v", err)
-			rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
-			return
-		}
+// decodeDeleteCredentialRequest reads the domain of a delete from its body or, as DELETE requests often
+// have no body, from the domain query parameter. The domain is normalized the way credentials are stored.
+func decodeDeleteCredentialRequest(r *rest.Request) (*DeleteCredentialRequest, error) {
+	request := &DeleteCredentialRequest{}
+	err := r.DecodeJsonPayload(request)
+	if err != nil && err != rest.ErrJsonPayloadEmpty {
+		return nil, err
+	}
+	if govalidator.IsNull(request.Domain) {
+		request.Domain = r.URL.Query().Get("domain")
+	}
+	request.Domain = normalizeCredentialDomain(request.Domain)
+	return request, nil
+}
 
-		projectCredential := models.NewProjectCredential(projectId, TextRequest.Id, request.Domain, operator)
-		_, err = s.Ds.Db.
-			InsertInto(models.ProjectCredentialTableName).
-			Columns(models.ProjectCredentialColumns...).Record(projectCredential).Exec()
+func (s *ProjectService) DeleteCredentialHandler(w rest.ResponseWriter, r *rest.Request) {
+	audit := s.newCredentialAudit(w, r, CredentialActionDelete)
+	defer audit.record()
+	w = audit
+	projectId := r.PathParams["id"]
+	defer s.invalidateCredentialList(projectId)
+	ctx := r.Context()
+	credentialId := r.PathParams["cid"]
+	operator := userutils.GetUserNameFromRequest(r)
+	request, err := decodeDeleteCredentialRequest(r)
+	if err == nil {
+		err = checkUuidCredentialDomain(r, request.Domain)
+	}
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	audit.setCredential(request.Domain, "")
+	force, err := parseForceDelete(r)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	cleanupDomain := false
+	if cleanupParam := r.URL.Query().Get("cleanup_domain"); !govalidator.IsNull(cleanupParam) {
+		cleanupDomain, err = strconv.ParseBool(cleanupParam)
 		if err != nil {
-			logger.Error("%+v", err)
-			rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
+			logger.ErrorContext(r.Context(), "%+v", err)
+			writeCredentialError(w, err, http.StatusBadRequest)
 			return
 		}
-
-		w.WriteJson(struct {
-			Id string `json:"id"`
-		}{Id: *credentialId})
+	}
+	err = s.checkProjectUserInRole(r, operator, projectId, s.credentialActionRoles(CredentialActionDelete))
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
+		return
+	}
+	target, err := s.credentialTargetOf(projectId, request.Domain, credentialId)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
 		return
-	case CredentialTypeKubeConfig:
-		KubeconfigRequest := &KubeconfigCredentialRequest{}
-		err := mapstructure.Decode(request.Content, KubeconfigRequest)
+	}
+	if target.global {
+		err = s.checkGlobalStoreWriter(r, operator, projectId)
 		if err != nil {
-			logger.Error("%+v", err)
-			rest.Error(w, err.Error(), http.StatusBadRequest)
-			return
-		}
-
-		credential, err := s.Ds.Jenkins.GetCredentialInFolder(request.Domain, KubeconfigRequest.Id, projectId)
-		if credential != nil {
-			err := fmt.Errorf("credential id [%s] has been used", credential.Id)
-			logger.Warn(err.Error())
-			rest.Error(w, err.Error(), http.StatusConflict)
+			logger.ErrorContext(r.Context(), "%+v", err)
+			writeCredentialError(w, err, http.StatusForbidden)
 			return
 		}
-
-		if err != nil && stringutils.GetJenkinsStatusCode(err) != http.StatusNotFound {
-			logger.Error("%+v", err)
-			rest.Error(w, err.Error(), http.StatusBadRequest)
+	}
+	if !force {
+		err = s.checkCredentialNotInUse(projectId, request.Domain, credentialId)
+		if inUseErr, ok := err.(*CredentialInUseError); ok {
+			logger.WarnContext(r.Context(), "%+v", inUseErr)
+			writeCredentialError(w, inUseErr, http.StatusConflict)
 			return
 		}
-
-		credentialId, err := s.Ds.Jenkins.CreateKubeconfigCredentialInFolder(request.Domain, KubeconfigRequest.Id,
-			KubeconfigRequest.Content, KubeconfigRequest.Description, projectId)
 		if err != nil {
-			logger.Error("%+v", err)
-			rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
+			logCredentialError(r.Context(), err)
+			writeJenkinsError(w, r, err)
 			return
 		}
+	} else {
+		logger.WarnContext(r.Context(), "[%s] deletes credential [%s] in project [%s] without checking its usage",
+			operator, credentialId, projectId)
+	}
+	trash, err := s.trashBeforeDelete(target, operator, credentialId, force)
+	if notRestorableErr, ok := err.(*CredentialNotRestorableError); ok {
+		logger.WarnContext(r.Context(), "%+v", notRestorableErr)
+		writeCredentialError(w, notRestorableErr, http.StatusConflict)
+		return
+	}
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeJenkinsError(w, r, err)
+		return
+	}
+	if trash != nil {
+		setCredentialOperationType(r, trash.Type)
+	} else {
+		s.setDeletedCredentialType(r, projectId, request.Domain, credentialId)
+	}
+	id, err := target.delete(ctx, s.credentialStore(), credentialId)
+	if err != nil {
+		s.untrashCredential(r.Context(), trash)
+		logCredentialError(r.Context(), err)
+		writeJenkinsError(w, r, err)
+		return
+	}
 
-		projectCredential := models.NewProjectCredential(projectId, KubeconfigRequest.Id, request.Domain, operator)
-		_, err = s.Ds.Db.
-			InsertInto(models.ProjectCredentialTableName).
-			Columns(models.ProjectCredentialColumns...).Record(projectCredential).Exec()
+	err = s.Ds.Db.WithTransaction(func(tx *dbr.Tx) error {
+		return deleteCredentialRecordsTx(tx, projectId, request.Domain, credentialId)
+	})
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeJenkinsError(w, r, err)
+		return
+	}
+	err = s.recordCredentialDeletion(projectId, request.Domain, credentialId, operator)
+	if err != nil {
+		logger.WarnContext(r.Context(), "failed to record deletion of credential [%s]: %+v", credentialId, err)
+	}
+	s.recordCredentialMutation(projectId, operator, CredentialActionDelete, request.Domain, credentialId, nil)
+	if cleanupDomain {
+		err = s.removeEmptyCredentialDomain(projectId, request.Domain)
 		if err != nil {
-			logger.Error("%+v", err)
-			rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
-			return
+			logger.WarnContext(r.Context(), "failed to remove empty credential domain [%s]: %+v", request.Domain, err)
 		}
-
-		w.WriteJson(struct {
-			Id string `json:"id"`
-		}{Id: *credentialId})
-		return
-	default:
-		err := fmt.Errorf("error unsupport  credential type")
-		logger.Error("%+v", err)
-		rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
-		return
 	}
+	response := &DeleteCredentialResponse{Id: *id}
+	if trash != nil {
+		response.TrashId = trash.TrashId
+		response.RestorableUntil = &trash.ExpireTime
+	}
+	w.WriteJson(response)
+	return
 }
 
-func (s *ProjectService) DeleteCredentialHandler(w rest.ResponseWriter, r *rest.Request) {
-	request := &DeleteCredentialRequest{}
+func (s *ProjectService) UpdateCredentialHandler(w rest.ResponseWriter, r *rest.Request) {
+	audit := s.newCredentialAudit(w, r, CredentialActionUpdate)
+	defer audit.record()
+	w = audit
+	request := &CredentialRequest{}
 	projectId := r.PathParams["id"]
-	credentialId := r.PathParams["cid"]
+	defer s.invalidateCredentialList(projectId)
+	ctx := r.Context()
 	operator := userutils.GetUserNameFromRequest(r)
+	credentialId := r.PathParams["cid"]
 	err := r.DecodeJsonPayload(request)
 	if err != nil {
-		logger.Error("%+v", err)
-		rest.Error(w, err.Error(), http.StatusBadRequest)
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
 		return
 	}
-	err = s.checkProjectUserInRole(operator, projectId, []string{ProjectOwner, ProjectMaintainer})
+	dryRun, err := parseDryRun(r)
 	if err != nil {
-		logger.Error("%+v", err)
-		rest.Error(w, err.Error(), http.StatusForbidden)
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
 		return
 	}
-	id, err := s.Ds.Jenkins.DeleteCredentialInFolder(request.Domain, credentialId, projectId)
+	if dryRun {
+		audit.skip()
+	}
+	if govalidator.IsNull(request.Domain) {
+		request.Domain = r.URL.Query().Get("domain")
+	}
+	err = checkUuidCredentialDomain(r, request.Domain)
 	if err != nil {
-		logger.Error("%+v", err)
-		rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
+		logger.WarnContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
 		return
 	}
-
-	deleteConditions := append(make([]dbr.Builder, 0), db.Eq(models.ProjectIdColumn, projectId))
-	deleteConditions = append(deleteConditions, db.Eq(models.ProjectCredentialIdColumn, credentialId))
-	if !govalidator.IsNull(request.Domain) {
-		deleteConditions = append(deleteConditions, db.Eq(models.ProjectCredentialDomainColumn, request.Domain))
-	} else {
-		deleteConditions = append(deleteConditions, db.Eq(models.ProjectCredentialDomainColumn, "_"))
+	audit.setCredential(request.Domain, "")
+	err = normalizeCredentialScope(request.Content)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	request.IntendedJobs, err = validateIntendedJobs(request.IntendedJobs)
+	if err != nil {
+		logger.WarnContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	err = normalizeCredentialDescription(request.Content, s.Config.Description.MaxLength)
+	if err != nil {
+		logger.WarnContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	err = normalizeSshPrivateKeys(request.Content)
+	if err != nil {
+		logger.WarnContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
 	}
 
-	_, err = s.Ds.Db.DeleteFrom(models.ProjectCredentialTableName).
-		Where(db.And(deleteConditions...)).Exec()
-	if err != nil && err != db.ErrNotFound {
-		logger.Error("%+v", err)
-		rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
+	err = s.checkProjectUserInRole(r, operator, projectId, s.credentialActionRoles(CredentialActionUpdate))
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
+		return
+	}
+	target, err := s.credentialTargetOf(projectId, request.Domain, credentialId)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if target.global {
+		err = s.checkGlobalStoreWriter(r, operator, projectId)
+		if err != nil {
+			logger.ErrorContext(r.Context(), "%+v", err)
+			writeCredentialError(w, err, http.StatusForbidden)
+			return
+		}
+	}
+	jenkinsCredential, err := target.get(ctx, s.credentialStore(), credentialId)
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeJenkinsError(w, r, err)
 		return
 	}
+	credentialType, err := s.getCredentialType(projectId, jenkinsCredential)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	setCredentialOperationType(r, credentialType)
+	// the credential exists, Jenkins answered 404 above otherwise, but devops may not know how to update it
+	if _, ok := credentialRequestFactories[credentialType]; !ok {
+		err := fmt.Errorf("error credential [%s] has type [%s] which can not be updated", credentialId, credentialType)
+		logger.WarnContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusUnprocessableEntity)
+		return
+	}
+	err = s.checkCredentialIfMatch(r, target, credentialId)
+	if err == ErrorCredentialModified {
+		logger.WarnContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusPreconditionFailed)
+		return
+	}
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeJenkinsError(w, r, err)
+		return
+	}
+	validationContent := mergeCredentialContent(map[string]interface{}{}, request.Content)
+	validationContent["id"] = credentialId
+	reason, err := s.checkContentValidationWebhook(projectId, operator, CredentialActionUpdate, credentialType,
+		request.Domain, validationContent)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if reason != nil {
+		logger.WarnContext(r.Context(), "%+v", reason)
+		writeCredentialError(w, reason, http.StatusUnprocessableEntity)
+		return
+	}
+	content, err := s.mergeUpdateContent(projectId, request.Domain, credentialId, credentialType, request.Content)
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeJenkinsError(w, r, err)
+		return
+	}
+	content["id"] = credentialId
+	credentialRequest, fields, err := s.prepareCredentialUpdate(credentialType, content, request.Content)
+	if err != nil {
+		logger.WarnContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	if dryRun {
+		writeUpdateDryRun(w, credentialId)
+		return
+	}
+	id, err := credentialRequest.update(ctx, s.credentialStore(), target)
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeJenkinsError(w, r, err)
+		return
+	}
+	err = s.updateCredentialRecordFields(target, *id, fields)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	err = s.updateRequestExpiresAt(projectId, request.Domain, *id, request.ExpiresAt, fields.ExpiresAt)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	err = s.updateRequestIntendedJobs(projectId, request.Domain, *id, request.IntendedJobs)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	s.recordCredentialMutation(projectId, operator, CredentialActionUpdate, request.Domain, *id, validationContent)
+	s.markCredentialModified(projectId, request.Domain, *id, operator)
 	w.WriteJson(struct {
 		Id string `json:"id"`
 	}{Id: *id})
 	return
 }
 
-func (s *ProjectService) UpdateCredentialHandler(w rest.ResponseWriter, r *rest.Request) {
-	request := &CredentialRequest{}
+func (s *ProjectService) GetCredentialHandler(w rest.ResponseWriter, r *rest.Request) {
+	getContent := r.URL.Query().Get("content")
+	encoding := r.URL.Query().Get("encoding")
 	projectId := r.PathParams["id"]
+	ctx := r.Context()
 	operator := userutils.GetUserNameFromRequest(r)
 	credentialId := r.PathParams["cid"]
-	err := r.DecodeJsonPayload(request)
-	if err != nil {
-		logger.Error("%+v", err)
-		rest.Error(w, err.Error(), http.StatusBadRequest)
-		return
+	domain := r.URL.Query().Get("domain")
+	format := r.URL.Query().Get("format")
+	store := r.URL.Query().Get("store")
+	// the route audits reads of the metadata, reads of the content are audited as views
+	if !govalidator.IsNull(getContent) || format == CredentialFormatXml {
+		setCredentialAuditAction(w, CredentialActionView)
 	}
-
-	err = s.checkProjectUserInRole(operator, projectId, []string{ProjectOwner, ProjectMaintainer})
+	err := s.checkProjectUserInRole(r, operator, projectId, s.credentialActionRoles(CredentialActionList))
 	if err != nil {
-		logger.Error("%+v", err)
-		rest.Error(w, err.Error(), http.StatusForbidden)
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
 		return
 	}
-	jenkinsCredential, err := s.Ds.Jenkins.GetCredentialInFolder(request.Domain, credentialId, projectId)
-	if err != nil {
-		logger.Error("%+v", err)
-		rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
+	if !govalidator.IsNull(format) {
+		s.writeCredentialConfigXml(w, r, format)
 		return
 	}
-	credentialType := CredentialTypeMap[jenkinsCredential.TypeName]
-	switch credentialType {
-	case CredentialTypeUsernamePassword:
-		UPRequest := &UsernamePasswordCredentialRequest{}
-		UPRequest.Id = credentialId
-		err := mapstructure.Decode(request.Content, UPRequest)
+	// developers only see the metadata of credentials to reference them in pipelines
+	if !govalidator.IsNull(getContent) {
+		err = s.checkProjectUserInRole(r, operator, projectId, s.credentialActionRoles(CredentialActionView))
 		if err != nil {
-			logger.Error("%+v", err)
-			rest.Error(w, err.Error(), http.StatusBadRequest)
+			logger.ErrorContext(r.Context(), "%+v", err)
+			writeCredentialError(w, err, http.StatusForbidden)
 			return
 		}
-		credentialId, err := s.Ds.Jenkins.UpdateUsernamePasswordCredentialInFolder(request.Domain, UPRequest.Id,
-			UPRequest.Username, UPRequest.Password, UPRequest.Description, projectId)
+	}
+	if _, ok := CredentialContentEncoders[encoding]; !govalidator.IsNull(encoding) && !ok {
+		err := fmt.Errorf("error encoding [%s] not in %s", encoding, credentialEncodings())
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	if !govalidator.IsNull(getContent) && !s.checkContentReadLimit(w, operator, projectId) {
+		return
+	}
+	redact := false
+	if redactParam := r.URL.Query().Get("redact"); !govalidator.IsNull(redactParam) {
+		redact, err = strconv.ParseBool(redactParam)
 		if err != nil {
-			logger.Error("%+v", err)
-			rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
+			logger.ErrorContext(r.Context(), "%+v", err)
+			writeCredentialError(w, err, http.StatusBadRequest)
 			return
 		}
-		w.WriteJson(struct {
-			Id string `json:"id"`
-		}{Id: *credentialId})
+	}
+
+	err = validateCredentialStore(store, domain)
+	if err != nil {
+		logger.WarnContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	credentialResponse, err := s.getCredentialInStore(ctx, store, projectId, domain, credentialId)
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeJenkinsError(w, r, err)
 		return
 
-	case CredentialTypeSsh:
-		SshRequest := &SshCredentialRequest{}
-		SshRequest.Id = credentialId
-		err := mapstructure.Decode(request.Content, SshRequest)
+	}
+	projectCredential := &models.ProjectCredential{}
+	err = s.Ds.Db.Select(models.ProjectCredentialColumns...).
+		From(models.ProjectCredentialTableName).Where(
+		db.And(db.Eq(models.ProjectIdColumn, projectId),
+			db.Eq(models.ProjectCredentialIdColumn, credentialResponse.Id),
+			db.Eq(models.ProjectCredentialDomainColumn, credentialResponse.Domain))).LoadOne(projectCredential)
+	if err != nil && err != db.ErrNotFound {
+		logCredentialError(r.Context(), err)
+		writeJenkinsError(w, r, err)
+		return
+	}
+
+	response := formatCredentialResponse(credentialResponse, projectCredential)
+	if projectCredential.Store == models.CredentialStoreGlobal {
+		response.Store = models.CredentialStoreGlobal
+	}
+	etag, err := s.getCredentialETag(newCredentialTarget(projectId, domain, response.Store), credentialId)
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeJenkinsError(w, r, err)
+		return
+	}
+	w.Header().Set("ETag", etag)
+	setCredentialOperationType(r, response.Type)
+	setCredentialListWarnings(w, s.fillCredentialsScope(projectId, []*CredentialResponse{response}))
+	s.fillCreatorDisplayNames([]*CredentialResponse{response})
+	if s.Config.ConfigScan.Enabled {
+		response.ConfigReferences, err = s.getCredentialConfigReferences(projectId, credentialResponse.Id)
 		if err != nil {
-			logger.Error("%+v", err)
-			rest.Error(w, err.Error(), http.StatusBadRequest)
+			logCredentialError(r.Context(), err)
+			writeJenkinsError(w, r, err)
 			return
 		}
-		credentialId, err := s.Ds.Jenkins.UpdateSshCredentialInFolder(request.Domain, SshRequest.Id,
-			SshRequest.Username, SshRequest.Passphrase, SshRequest.PrivateKey, SshRequest.Description, projectId)
+	}
+	if getContent != "" {
+		stringBody, err := s.getCredentialContentInStore(ctx, store, projectId, domain, credentialId)
 		if err != nil {
-			logger.Error("%+v", err)
-			rest.Error(w, err.Error(), http.StatusInternalServerError)
+			logCredentialError(r.Context(), err)
+			writeJenkinsError(w, r, err)
 			return
 		}
-		w.WriteJson(struct {
-			Id string `json:"id"`
-		}{Id: *credentialId})
-		return
-
-	case CredentialTypeSecretText:
-		TextRequest := &SecretTextCredentialRequest{}
-		TextRequest.Id = credentialId
-		err := mapstructure.Decode(request.Content, TextRequest)
+		form, err := newCredentialForm(stringBody)
 		if err != nil {
-			logger.Error("%+v", err)
-			rest.Error(w, err.Error(), http.StatusBadRequest)
+			logCredentialError(r.Context(), err)
+			writeJenkinsError(w, r, err)
 			return
 		}
-		credentialId, err := s.Ds.Jenkins.UpdateSecretTextCredentialInFolder(request.Domain, TextRequest.Id,
-			TextRequest.Secret, TextRequest.Description, projectId)
+		content := formCredentialContent(form, response.Type, credentialResponse, projectCredential)
+		err = form.err(s.credentialStore().GetVersion())
 		if err != nil {
-			logger.Error("%+v", err)
-			rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
+			logger.ErrorContext(r.Context(), "%+v", err)
+			writeCredentialError(w, err, http.StatusInternalServerError)
 			return
 		}
-		w.WriteJson(struct {
-			Id string `json:"id"`
-		}{Id: *credentialId})
-		return
-	case CredentialTypeKubeConfig:
-		KubeconfigRequest := &KubeconfigCredentialRequest{}
-		KubeconfigRequest.Id = credentialId
-		err := mapstructure.Decode(request.Content, KubeconfigRequest)
-		if err != nil {
-			logger.Error("%+v", err)
-			rest.Error(w, err.Error(), http.StatusBadRequest)
-			return
+		if kubeconfig, ok := content.(*KubeconfigCredentialRequest); ok && redact {
+			kubeconfig.Content, err = kubeconfigutils.Redact(kubeconfig.Content)
+			if err != nil {
+				logger.ErrorContext(r.Context(), "%+v", err)
+				writeCredentialError(w, err, http.StatusUnprocessableEntity)
+				return
+			}
+		}
+		if content != nil {
+			jsonBytes, _ := json.Marshal(content)
+			json.Unmarshal(jsonBytes, &response.Content)
 		}
-		credentialId, err := s.Ds.Jenkins.UpdateKubeconfigCredentialInFolder(request.Domain, KubeconfigRequest.Id,
-			KubeconfigRequest.Content, KubeconfigRequest.Description, projectId)
+		err = encodeCredentialContent(response.Content, encoding)
 		if err != nil {
-			logger.Error("%+v", err)
-			rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
+			logger.ErrorContext(r.Context(), "%+v", err)
+			writeCredentialError(w, err, http.StatusBadRequest)
 			return
 		}
-		w.WriteJson(struct {
-			Id string `json:"id"`
-		}{Id: *credentialId})
-		return
-
-	default:
-		err := fmt.Errorf("error unsupport credential type %s", credentialType)
-		logger.Error("%+v", err)
-		rest.Error(w, err.Error(), http.StatusBadRequest)
-		return
 	}
+	w.WriteJson(response)
+	return
 }
 
-func (s *ProjectService) GetCredentialHandler(w rest.ResponseWriter, r *rest.Request) {
-	getContent := r.URL.Query().Get("content")
+// CredentialExistsHandler answers whether the credential id is taken with 200 or 404, without body.
+func (s *ProjectService) CredentialExistsHandler(w rest.ResponseWriter, r *rest.Request) {
 	projectId := r.PathParams["id"]
-	operator := userutils.GetUserNameFromRequest(r)
+	ctx := r.Context()
 	credentialId := r.PathParams["cid"]
 	domain := r.URL.Query().Get("domain")
-	err := s.checkProjectUserInRole(operator, projectId, []string{ProjectOwner, ProjectMaintainer})
+	operator := userutils.GetUserNameFromRequest(r)
+	err := s.checkProjectUserInRole(r, operator, projectId, []string{ProjectOwner, ProjectMaintainer})
 	if err != nil {
-		logger.Error("%+v", err)
-		rest.Error(w, err.Error(), http.StatusForbidden)
+		logger.ErrorContext(r.Context(), "%+v", err)
+		w.WriteHeader(http.StatusForbidden)
 		return
 	}
-
-	credentialResponse, err := s.Ds.Jenkins.GetCredentialInFolder(domain, credentialId, projectId)
+	_, err = s.credentialStore().GetCredentialInFolderContext(ctx, domain, credentialId, projectId)
 	if err != nil {
-		logger.Error("%+v", err)
-		rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
+		status := stringutils.GetJenkinsStatusCode(err)
+		if status != http.StatusNotFound {
+			logger.ErrorContext(r.Context(), "%+v", err)
+		}
+		w.WriteHeader(status)
 		return
+	}
+	w.WriteHeader(http.StatusOK)
+	return
+}
 
+func (s *ProjectService) GetCredentialsHandler(w rest.ResponseWriter, r *rest.Request) {
+	projectId := r.PathParams["id"]
+	operator := userutils.GetUserNameFromRequest(r)
+	domain := r.URL.Query().Get("domain")
+	scope := strings.ToUpper(r.URL.Query().Get("scope"))
+	err := s.checkProjectUserInRole(r, operator, projectId, s.credentialActionRoles(CredentialActionList))
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
+		return
 	}
-	projectCredential := &models.ProjectCredential{}
-	err = s.Ds.Db.Select(models.ProjectCredentialColumns...).
-		From(models.ProjectCredentialTableName).Where(
-		db.And(db.Eq(models.ProjectIdColumn, projectId),
-			db.Eq(models.ProjectCredentialIdColumn, credentialResponse.Id),
-			db.Eq(models.ProjectCredentialDomainColumn, credentialResponse.Domain))).LoadOne(projectCredential)
-	if err != nil && err != db.ErrNotFound {
-		logger.Error("%+v", err)
-		rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
+	if !govalidator.IsNull(scope) && !reflectutils.In(scope, CredentialScopes) {
+		err := fmt.Errorf("error scope [%s] not in %s", scope, CredentialScopes)
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
 		return
 	}
-
-	response := formatCredentialResponse(credentialResponse, projectCredential)
-	if getContent != "" {
-		stringBody, err := s.Ds.Jenkins.GetCredentialContentInFolder(domain, credentialId, projectId)
+	types, err := parseCredentialTypes(r.URL.Query().Get("type"))
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	withReachability := false
+	if reachability := r.URL.Query().Get("reachability"); !govalidator.IsNull(reachability) {
+		withReachability, err = strconv.ParseBool(reachability)
 		if err != nil {
-			logger.Error("%+v", err)
-			rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
+			logger.ErrorContext(r.Context(), "%+v", err)
+			writeCredentialError(w, err, http.StatusBadRequest)
+			return
+		}
+		if withReachability && !s.Config.Reachability.Enabled {
+			err := fmt.Errorf("error reachability is not enabled")
+			logger.ErrorContext(r.Context(), "%+v", err)
+			writeCredentialError(w, err, http.StatusBadRequest)
 			return
 		}
-		stringReader := strings.NewReader(stringBody)
-		doc, err := goquery.NewDocumentFromReader(stringReader)
+	}
+	store := r.URL.Query().Get("store")
+	err = validateCredentialStore(store, domain)
+	if err != nil {
+		logger.WarnContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	stream := false
+	if streamParam := r.URL.Query().Get("stream"); !govalidator.IsNull(streamParam) {
+		stream, err = strconv.ParseBool(streamParam)
 		if err != nil {
-			logger.Error("%+v", err)
-			rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
+			logger.ErrorContext(r.Context(), "%+v", err)
+			writeCredentialError(w, err, http.StatusBadRequest)
 			return
 		}
+	}
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	complete := func(response []*CredentialResponse) []*CredentialResponse {
+		if !govalidator.IsNull(scope) {
+			response = filterCredentialsByScope(response, scope)
+		}
+		if len(types) > 0 {
+			response = filterCredentialsByType(response, types)
+		}
+		// descriptions are read from Jenkins, so credentials are searched once merged with their records
+		if !govalidator.IsNull(q) {
+			response = filterCredentialsByQuery(response, q)
+		}
+		if withReachability {
+			s.fillCredentialsReachability(projectId, response)
+		}
+		s.fillCreatorDisplayNames(response)
+		return response
+	}
+	if stream && store != models.CredentialStoreGlobal {
+		s.streamCredentials(w, r, projectId, domain, complete)
+		return
+	}
 
-		switch response.Type {
-		case CredentialTypeKubeConfig:
-			content := &KubeconfigCredentialRequest{}
-			doc.Find("textarea[name*=content]").Each(func(i int, selection *goquery.Selection) {
-				value := selection.Text()
-				content.Content = value
-			})
-
-			doc.Find("input[name*=id][type=text]").Each(func(i int, selection *goquery.Selection) {
-				value, _ := selection.Attr("value")
-				content.Id = value
-			})
-			doc.Find("input[name*=description]").Each(func(i int, selection *goquery.Selection) {
-				value, _ := selection.Attr("value")
-				content.Description = value
-			})
-			jsonBytes, _ := json.Marshal(content)
-			json.Unmarshal(jsonBytes, &response.Content)
+	var response []*CredentialResponse
+	var warnings []string
+	if store == models.CredentialStoreGlobal {
+		response, err = s.listGlobalCredentials(projectId)
+	} else {
+		response, warnings, err = s.listCredentialsWithWarnings(projectId, domain)
+	}
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeJenkinsError(w, r, err)
+		return
+	}
+	setCredentialListWarnings(w, warnings)
+	w.WriteJson(complete(response))
+	return
+}
 
-		case CredentialTypeUsernamePassword:
-			content := &UsernamePasswordCredentialRequest{}
-			doc.Find("input[name*=username]").Each(func(i int, selection *goquery.Selection) {
-				value, _ := selection.Attr("value")
-				content.Username = value
-			})
-
-			doc.Find("input[name*=id][type=text]").Each(func(i int, selection *goquery.Selection) {
-				value, _ := selection.Attr("value")
-				content.Id = value
-			})
-			doc.Find("input[name*=description]").Each(func(i int, selection *goquery.Selection) {
-				value, _ := selection.Attr("value")
-				content.Description = value
-			})
-			jsonBytes, _ := json.Marshal(content)
-			json.Unmarshal(jsonBytes, &response.Content)
+func (s *ProjectService) GetCredentialByUuidHandler(w rest.ResponseWriter, r *rest.Request) {
+	s.serveCredentialByUuid(w, r, CredentialActionList, s.GetCredentialHandler)
+}
 
-		case CredentialTypeSsh:
-			content := &SshCredentialRequest{}
-			doc.Find("input[name*=username]").Each(func(i int, selection *goquery.Selection) {
-				value, _ := selection.Attr("value")
-				content.Username = value
-			})
-
-			doc.Find("input[name*=id][type=text]").Each(func(i int, selection *goquery.Selection) {
-				value, _ := selection.Attr("value")
-				content.Id = value
-			})
-			doc.Find("input[name*=description]").Each(func(i int, selection *goquery.Selection) {
-				value, _ := selection.Attr("value")
-				content.Description = value
-			})
-			doc.Find("textarea[name*=privateKey]").Each(func(i int, selection *goquery.Selection) {
-				value := selection.Text()
-				content.PrivateKey = value
-			})
-			jsonBytes, _ := json.Marshal(content)
-			json.Unmarshal(jsonBytes, &response.Content)
-		}
+func (s *ProjectService) UpdateCredentialByUuidHandler(w rest.ResponseWriter, r *rest.Request) {
+	s.serveCredentialByUuid(w, r, CredentialActionUpdate, s.UpdateCredentialHandler)
+}
+
+func (s *ProjectService) DeleteCredentialByUuidHandler(w rest.ResponseWriter, r *rest.Request) {
+	s.serveCredentialByUuid(w, r, CredentialActionDelete, s.DeleteCredentialHandler)
+}
+
+// credentialUuidDomainEnvKey is the Env key of the domain of the credential a uuid request resolved to.
+const credentialUuidDomainEnvKey = "CREDENTIAL_UUID_DOMAIN"
+
+// checkUuidCredentialDomain rejects a domain differing from the one of the credential the uuid of r
+// resolved to, the record of the uuid is authoritative. Requests not made by uuid are not checked.
+func checkUuidCredentialDomain(r *rest.Request, domain string) error {
+	uuidDomain, ok := r.Env[credentialUuidDomainEnvKey].(string)
+	if !ok {
+		return nil
 	}
-	w.WriteJson(response)
-	return
+	if normalizeCredentialDomain(domain) != normalizeCredentialDomain(uuidDomain) {
+		return fmt.Errorf("domain [%s] does not match the domain [%s] of credential uuid [%s]", domain,
+			uuidDomain, r.PathParams["uuid"])
+	}
+	return nil
 }
 
-func (s *ProjectService) GetCredentialsHandler(w rest.ResponseWriter, r *rest.Request) {
+// serveCredentialByUuid resolves the uuid in path to the credential id and domain,
+// then serves the request with the id based handler. The role of the operator for action is checked
+// first, so the uuids of a project are not revealed to users outside of it.
+func (s *ProjectService) serveCredentialByUuid(w rest.ResponseWriter, r *rest.Request, action string,
+	handler rest.HandlerFunc) {
 	projectId := r.PathParams["id"]
+	uuid := r.PathParams["uuid"]
 	operator := userutils.GetUserNameFromRequest(r)
-	domain := r.URL.Query().Get("domain")
-	err := s.checkProjectUserInRole(operator, projectId, []string{ProjectOwner, ProjectMaintainer})
+
+	err := s.checkProjectUserInRole(r, operator, projectId, s.credentialActionRoles(action))
 	if err != nil {
-		logger.Error("%+v", err)
-		rest.Error(w, err.Error(), http.StatusForbidden)
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
 		return
 	}
-	jenkinsCredentialResponses, err := s.Ds.Jenkins.GetCredentialsInFolder(domain, projectId)
-	if err != nil {
-		logger.Error("%+v", err)
-		rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
+	projectCredential := &models.ProjectCredential{}
+	err = s.Ds.Db.Select(models.ProjectCredentialColumns...).
+		From(models.ProjectCredentialTableName).Where(
+		db.And(db.Eq(models.ProjectIdColumn, projectId),
+			db.Eq(models.ProjectCredentialUuidColumn, uuid))).LoadOne(projectCredential)
+	if err == db.ErrNotFound {
+		err := fmt.Errorf("credential uuid [%s] not found", uuid)
+		logger.WarnContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusNotFound)
 		return
 	}
-	selectCondition := db.Eq(models.ProjectIdColumn, projectId)
-	if !govalidator.IsNull(domain) {
-		selectCondition = db.And(selectCondition, db.Eq(models.ProjectCredentialDomainColumn, domain))
-	}
-	projectCredentials := make([]*models.ProjectCredential, 0)
-	_, err = s.Ds.Db.Select(models.ProjectCredentialColumns...).
-		From(models.ProjectCredentialTableName).Where(selectCondition).Load(&projectCredentials)
-
 	if err != nil {
-		logger.Error("%+v", err)
-		rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
 		return
 	}
 
-	response := formatCredentialsResponse(jenkinsCredentialResponses, projectCredentials)
-	w.WriteJson(response)
-	return
+	if r.Env == nil {
+		r.Env = make(map[string]interface{})
+	}
+	r.Env[credentialUuidDomainEnvKey] = projectCredential.Domain
+	r.PathParams["cid"] = projectCredential.CredentialId
+	query := r.URL.Query()
+	query.Set("domain", projectCredential.Domain)
+	r.URL.RawQuery = query.Encode()
+	if audit, ok := w.(*credentialAudit); ok {
+		audit.setCredential(projectCredential.Domain, projectCredential.CredentialId)
+	}
+	handler(w, r)
 }
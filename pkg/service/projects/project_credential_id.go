@@ -0,0 +1,116 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/asaskevich/govalidator"
+
+	"kubesphere.io/devops/pkg/gojenkins"
+	"kubesphere.io/devops/pkg/utils/idutils"
+)
+
+const (
+	GeneratedCredentialIdPrefix = "credential-"
+	generateCredentialIdRetries = 5
+	// credential ids are stored in VARCHAR(255) columns
+	MaxCredentialIdLength = 255
+)
+
+// credentialIdPattern are the characters Jenkins accepts in credential ids.
+const credentialIdPattern = "^[a-zA-Z0-9_.-]+$"
+
+// reservedCredentialIds are the static routes under /projects/:id/credentials/, they are matched
+// before /projects/:id/credentials/:cid so a credential with one of these ids could not be reached.
+var reservedCredentialIds = map[string]bool{
+	"apply":                 true,
+	"audit":                 true,
+	"batch":                 true,
+	"batch-get":             true,
+	"break-glass":           true,
+	"bulk-describe":         true,
+	"dependency-graph":      true,
+	"domains":               true,
+	"expiring":              true,
+	"lint":                  true,
+	"rotation-calendar.ics": true,
+	"sync":                  true,
+	"test":                  true,
+	"transparency-log":      true,
+	"trash":                 true,
+	"unscoped":              true,
+	"uuid":                  true,
+}
+
+// validateCredentialId rejects the ids Jenkins would refuse, so they fail with 400 before any
+// Jenkins call: empty ids, ids with other characters than letters, digits, '-', '_' and '.',
+// ids starting with a dot and ids longer than MaxCredentialIdLength. The reservedCredentialIds
+// are rejected too.
+func validateCredentialId(credentialId string) error {
+	if govalidator.IsNull(credentialId) {
+		return fmt.Errorf("credential id should not be empty, set generate_id to have one generated")
+	}
+	if len(credentialId) > MaxCredentialIdLength {
+		return fmt.Errorf("credential id should not be longer than %d characters", MaxCredentialIdLength)
+	}
+	if !govalidator.Matches(credentialId, credentialIdPattern) {
+		return fmt.Errorf("credential id [%s] should only contain letters, digits, '-', '_' and '.'", credentialId)
+	}
+	if strings.HasPrefix(credentialId, ".") {
+		return fmt.Errorf("credential id [%s] should not start with '.'", credentialId)
+	}
+	if reservedCredentialIds[credentialId] {
+		return fmt.Errorf("credential id [%s] is reserved", credentialId)
+	}
+	return nil
+}
+
+// generateCredentialId returns a new credential id, the ids are unique per server and checked against
+// the credentials of the domain, another id is generated while the generated one is taken.
+func (s *ProjectService) generateCredentialId(projectId, domain string) (string, error) {
+	for i := 0; i < generateCredentialIdRetries; i++ {
+		credentialId := idutils.GetUuid36(GeneratedCredentialIdPrefix)
+		_, err := s.credentialStore().GetCredentialInFolder(domain, credentialId, projectId)
+		if err == nil {
+			continue
+		}
+		if errors.Is(err, gojenkins.ErrCredentialNotFound) {
+			return credentialId, nil
+		}
+		return "", err
+	}
+	return "", fmt.Errorf("error no unused credential id generated after %d retries", generateCredentialIdRetries)
+}
+
+// fillGeneratedCredentialId generates the id of a request with generate_id and without id.
+func (s *ProjectService) fillGeneratedCredentialId(projectId string, request *CredentialRequest) error {
+	if !request.GenerateId {
+		return nil
+	}
+	if credentialId, _ := request.Content["id"].(string); !govalidator.IsNull(credentialId) {
+		return nil
+	}
+	credentialId, err := s.generateCredentialId(projectId, request.Domain)
+	if err != nil {
+		return err
+	}
+	if request.Content == nil {
+		request.Content = make(map[string]interface{})
+	}
+	request.Content["id"] = credentialId
+	return nil
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_ValidateCredentialId(t *testing.T) {
+	for _, test := range []struct {
+		id    string
+		valid bool
+	}{
+		{id: "git-ssh", valid: true},
+		{id: "Docker_Hub.v2", valid: true},
+		{id: "a", valid: true},
+		{id: "9", valid: true},
+		{id: "credential-", valid: true},
+		{id: "_hidden", valid: true},
+		{id: "v1.0", valid: true},
+		{id: strings.Repeat("a", MaxCredentialIdLength), valid: true},
+		{id: strings.Repeat("a", MaxCredentialIdLength+1), valid: false},
+		{id: "", valid: false},
+		{id: "   ", valid: false},
+		{id: ".git", valid: false},
+		{id: ".", valid: false},
+		{id: "..", valid: false},
+		{id: "git ssh", valid: false},
+		{id: "git/ssh", valid: false},
+		{id: "../git", valid: false},
+		{id: "git?ssh", valid: false},
+		{id: "gít", valid: false},
+		{id: "trash", valid: false},
+		{id: "rotation-calendar.ics", valid: false},
+		{id: "break-glass", valid: false},
+		{id: "trash-v2", valid: true},
+	} {
+		err := validateCredentialId(test.id)
+		if test.valid && err != nil {
+			t.Fatalf("credential id [%s] should be valid, got %v", test.id, err)
+		}
+		if !test.valid && err == nil {
+			t.Fatalf("credential id [%s] should be invalid", test.id)
+		}
+	}
+}
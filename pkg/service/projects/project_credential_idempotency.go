@@ -0,0 +1,122 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+	"unicode"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/gocraft/dbr"
+
+	"kubesphere.io/devops/pkg/db"
+	"kubesphere.io/devops/pkg/logger"
+	"kubesphere.io/devops/pkg/models"
+)
+
+const (
+	IdempotencyKeyHeader    = "Idempotency-Key"
+	maxIdempotencyKeyLength = 255
+)
+
+func validateIdempotencyKey(key string) error {
+	if len(key) > maxIdempotencyKeyLength {
+		return fmt.Errorf("error idempotency key is longer than %d characters", maxIdempotencyKeyLength)
+	}
+	for _, c := range key {
+		if c > unicode.MaxASCII || !unicode.IsPrint(c) || unicode.IsSpace(c) {
+			return fmt.Errorf("error idempotency key [%s] has characters other than printable ascii", key)
+		}
+	}
+	return nil
+}
+
+// getIdempotencyKey returns the unexpired record of the key in the project, nil if it has none.
+func (s *ProjectService) getIdempotencyKey(projectId, key string) (*models.ProjectCredentialIdempotencyKey, error) {
+	record := &models.ProjectCredentialIdempotencyKey{}
+	err := s.Ds.Db.Select(models.ProjectCredentialIdempotencyKeyColumns...).
+		From(models.ProjectCredentialIdempotencyKeyTableName).
+		Where(db.And(db.Eq(models.ProjectIdColumn, projectId),
+			db.Eq(models.ProjectCredentialIdempotencyKeyColumn, key),
+			db.Gt(models.ProjectCredentialIdempotencyKeyExpireTimeColumn, time.Now()))).
+		LoadOne(record)
+	if err == db.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// saveIdempotencyKey remembers in tx that the key created the credential, an expired record of the key is replaced.
+func saveIdempotencyKey(tx *dbr.Tx, record *models.ProjectCredentialIdempotencyKey) error {
+	_, err := tx.DeleteFrom(models.ProjectCredentialIdempotencyKeyTableName).
+		Where(db.And(db.Eq(models.ProjectIdColumn, record.ProjectId),
+			db.Eq(models.ProjectCredentialIdempotencyKeyColumn, record.IdempotencyKey))).Exec()
+	if err != nil && err != db.ErrNotFound {
+		return err
+	}
+	_, err = tx.InsertInto(models.ProjectCredentialIdempotencyKeyTableName).
+		Columns(models.ProjectCredentialIdempotencyKeyColumns...).Record(record).Exec()
+	return err
+}
+
+// hashIdempotentRequest returns the hash of the create request as sent, before ids are generated
+// and the content is normalized.
+func hashIdempotentRequest(request *CredentialRequest) (string, error) {
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+	return sha256Hex(payload), nil
+}
+
+func (s *ProjectService) PurgeExpiredIdempotencyKeys() {
+	result, err := s.Ds.Db.DeleteFrom(models.ProjectCredentialIdempotencyKeyTableName).
+		Where(db.Lte(models.ProjectCredentialIdempotencyKeyExpireTimeColumn, time.Now())).Exec()
+	if err != nil {
+		logger.Error("failed to purge expired idempotency keys: %+v", err)
+		return
+	}
+	if purged, err := result.RowsAffected(); err == nil && purged > 0 {
+		logger.Info("purged %d expired idempotency keys", purged)
+	}
+}
+
+// replayIdempotentCreate answers a create request whose key already created a credential with that credential,
+// or rejects it when the key was used with another payload. It returns false when the key is unknown and the
+// request must be served.
+func (s *ProjectService) replayIdempotentCreate(w rest.ResponseWriter, r *rest.Request, projectId, key,
+	requestHash string) (bool, error) {
+	record, err := s.getIdempotencyKey(projectId, key)
+	if err != nil || record == nil {
+		return false, err
+	}
+	if record.RequestHash != "" && record.RequestHash != requestHash {
+		err := fmt.Errorf("idempotency key [%s] has been used by a different request", key)
+		logger.WarnContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusUnprocessableEntity)
+		return true, nil
+	}
+	logger.WarnContext(r.Context(), "credential [%s] in project [%s] has been created with idempotency key [%s]",
+		record.CredentialId, projectId, key)
+	w.WriteJson(struct {
+		Id string `json:"id"`
+	}{Id: record.CredentialId})
+	return true, nil
+}
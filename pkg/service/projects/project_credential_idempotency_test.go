@@ -0,0 +1,50 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateIdempotencyKey(t *testing.T) {
+	for _, key := range []string{"8e03978e-40d5-43e8-bc93-6894a57f9324", "retry-1"} {
+		if err := validateIdempotencyKey(key); err != nil {
+			t.Fatalf("expected idempotency key [%s] to be valid, got %v", key, err)
+		}
+	}
+	for _, key := range []string{"retry 1", "retry\n", "重试", strings.Repeat("k", maxIdempotencyKeyLength+1)} {
+		if err := validateIdempotencyKey(key); err == nil {
+			t.Fatalf("expected idempotency key [%s] to be invalid", key)
+		}
+	}
+}
+
+func TestHashIdempotentRequest(t *testing.T) {
+	request := func(secret string) *CredentialRequest {
+		return &CredentialRequest{Type: "secret_text", Content: map[string]interface{}{"id": "token", "secret": secret}}
+	}
+	first, err := hashIdempotentRequest(request("s1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	retry, _ := hashIdempotentRequest(request("s1"))
+	other, _ := hashIdempotentRequest(request("s2"))
+	if first != retry {
+		t.Fatalf("expected a retry to have the hash of the request, got %s and %s", first, retry)
+	}
+	if first == other {
+		t.Fatalf("expected another payload to have another hash")
+	}
+}
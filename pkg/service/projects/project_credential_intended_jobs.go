@@ -0,0 +1,126 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/asaskevich/govalidator"
+
+	"kubesphere.io/devops/pkg/db"
+	"kubesphere.io/devops/pkg/models"
+)
+
+const maxIntendedJobs = 50
+
+// Intended jobs name the jobs of the project a credential is meant for, by their path in the project like
+// "pipeline" or "folder/pipeline", covering the branches of multibranch pipelines. They are informational
+// only and NOT enforced: with the credentials and cloudbees-folder plugins every job of a folder can use the
+// credentials of the folder, there is no per-job binding. A credential which some jobs must not use has to
+// live in a folder of its own. References from other jobs are marked unintended in the usage of the
+// credential, which needs build fingerprints recorded by the credentials plugin.
+
+// validateIntendedJobs trims, sorts and deduplicates the intended jobs, nil when jobs is nil.
+func validateIntendedJobs(jobs []string) ([]string, error) {
+	if jobs == nil {
+		return nil, nil
+	}
+	intended := make([]string, 0, len(jobs))
+	seen := make(map[string]bool, len(jobs))
+	for _, job := range jobs {
+		job = strings.Trim(strings.TrimSpace(job), "/")
+		if govalidator.IsNull(job) {
+			return nil, fmt.Errorf("error intended job name is empty")
+		}
+		if seen[job] {
+			continue
+		}
+		seen[job] = true
+		intended = append(intended, job)
+	}
+	if len(intended) > maxIntendedJobs {
+		return nil, fmt.Errorf("error credential intended for %d jobs, at most %d are allowed", len(intended),
+			maxIntendedJobs)
+	}
+	sort.Strings(intended)
+	return intended, nil
+}
+
+func formatIntendedJobs(jobs []string) string {
+	if len(jobs) == 0 {
+		return ""
+	}
+	formatted, _ := json.Marshal(jobs)
+	return string(formatted)
+}
+
+// parseIntendedJobs returns the intended jobs of a credential record, nil when any job is intended.
+func parseIntendedJobs(projectCredential *models.ProjectCredential) []string {
+	if projectCredential == nil || govalidator.IsNull(projectCredential.IntendedJobs) {
+		return nil
+	}
+	var jobs []string
+	if json.Unmarshal([]byte(projectCredential.IntendedJobs), &jobs) != nil {
+		return nil
+	}
+	return jobs
+}
+
+func setRequestIntendedJobs(projectCredential *models.ProjectCredential, jobs []string) {
+	projectCredential.IntendedJobs = formatIntendedJobs(jobs)
+}
+
+// updateRequestIntendedJobs records the intended jobs given with an update request, they are kept without them.
+func (s *ProjectService) updateRequestIntendedJobs(projectId, domain, credentialId string, jobs []string) error {
+	if jobs == nil {
+		return nil
+	}
+	if govalidator.IsNull(domain) {
+		domain = "_"
+	}
+	_, err := s.Ds.Db.Update(models.ProjectCredentialTableName).
+		Set(models.ProjectCredentialIntendedJobsColumn, formatIntendedJobs(jobs)).
+		Where(db.And(db.Eq(models.ProjectIdColumn, projectId),
+			db.Eq(models.ProjectCredentialIdColumn, credentialId),
+			db.Eq(models.ProjectCredentialDomainColumn, domain))).Exec()
+	if err != nil && err != db.ErrNotFound {
+		return err
+	}
+	return nil
+}
+
+// jobIntended tells whether the job of the fingerprint usage, named by its full name, is one of the intended
+// jobs or a branch of one of them.
+func jobIntended(projectId, fullName string, jobs []string) bool {
+	for _, job := range jobs {
+		intended := projectId + "/" + job
+		if fullName == intended || strings.HasPrefix(fullName, intended+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// markUnintendedReferences marks the references of the usage by jobs the credential is not intended for.
+func markUnintendedReferences(projectId string, usage *CredentialUsageResponse, jobs []string) {
+	if jobs == nil {
+		return
+	}
+	for _, reference := range usage.References {
+		reference.Unintended = !jobIntended(projectId, reference.Name, jobs)
+	}
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"reflect"
+	"testing"
+
+	"kubesphere.io/devops/pkg/models"
+)
+
+func TestValidateIntendedJobs(t *testing.T) {
+	jobs, err := validateIntendedJobs([]string{" deploy ", "build/", "deploy"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(jobs, []string{"build", "deploy"}) {
+		t.Fatalf("unexpected intended jobs %v", jobs)
+	}
+	if _, err := validateIntendedJobs([]string{" "}); err == nil {
+		t.Fatalf("expected an empty job name to be rejected")
+	}
+
+	projectCredential := &models.ProjectCredential{IntendedJobs: formatIntendedJobs(jobs)}
+	if parsed := parseIntendedJobs(projectCredential); !reflect.DeepEqual(parsed, jobs) {
+		t.Fatalf("expected intended jobs %v, got %v", jobs, parsed)
+	}
+	projectCredential.IntendedJobs = formatIntendedJobs([]string{})
+	if parsed := parseIntendedJobs(projectCredential); parsed != nil {
+		t.Fatalf("expected no intended job to intend any job, got %v", parsed)
+	}
+}
+
+func TestMarkUnintendedReferences(t *testing.T) {
+	usage := &CredentialUsageResponse{References: []*CredentialReference{
+		{Name: "project/deploy"},
+		{Name: "project/build/master"},
+		{Name: "project/build-test"},
+		{Name: "project/release"},
+	}}
+	markUnintendedReferences("project", usage, []string{"build", "deploy"})
+	unintended := make([]bool, 0, len(usage.References))
+	for _, reference := range usage.References {
+		unintended = append(unintended, reference.Unintended)
+	}
+	if !reflect.DeepEqual(unintended, []bool{false, false, true, true}) {
+		t.Fatalf("unexpected unintended references %v", unintended)
+	}
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"github.com/asaskevich/govalidator"
+
+	"kubesphere.io/devops/pkg/db"
+	"kubesphere.io/devops/pkg/models"
+	"kubesphere.io/devops/pkg/utils/certutils"
+)
+
+// CredentialKeySize is the algorithm and the size of the private key of a ssh or certificate credential, read
+// when the key is submitted as Jenkins never gives it back.
+type CredentialKeySize struct {
+	Algorithm string `json:"algorithm"`
+	Bits      int    `json:"bits"`
+}
+
+// securityBits is the comparable strength of a key, following the equivalences of NIST SP 800-57 for
+// the finite field algorithms while elliptic curve keys have half of their size.
+func (key *CredentialKeySize) securityBits() int {
+	if key.Algorithm != certutils.KeyAlgorithmRsa && key.Algorithm != certutils.KeyAlgorithmDsa {
+		return key.Bits / 2
+	}
+	switch {
+	case key.Bits < 2048:
+		return 80
+	case key.Bits < 3072:
+		return 112
+	case key.Bits < 7680:
+		return 128
+	case key.Bits < 15360:
+		return 192
+	}
+	return 256
+}
+
+// weakerKeySize returns the weaker of two keys by their security bits.
+func weakerKeySize(key, other *CredentialKeySize) *CredentialKeySize {
+	if key == nil || (other != nil && other.securityBits() < key.securityBits()) {
+		return other
+	}
+	return key
+}
+
+func setCredentialKeySize(projectCredential *models.ProjectCredential, key *CredentialKeySize) {
+	if key == nil {
+		return
+	}
+	projectCredential.KeyAlgorithm = key.Algorithm
+	projectCredential.KeyBits = &key.Bits
+}
+
+func (s *ProjectService) updateCredentialKeySize(projectId, domain, credentialId string, key *CredentialKeySize) error {
+	if govalidator.IsNull(domain) {
+		domain = "_"
+	}
+	_, err := s.Ds.Db.Update(models.ProjectCredentialTableName).
+		Set(models.ProjectCredentialKeyAlgorithmColumn, key.Algorithm).
+		Set(models.ProjectCredentialKeyBitsColumn, key.Bits).
+		Where(db.And(db.Eq(models.ProjectIdColumn, projectId),
+			db.Eq(models.ProjectCredentialIdColumn, credentialId),
+			db.Eq(models.ProjectCredentialDomainColumn, domain))).Exec()
+	if err != nil && err != db.ErrNotFound {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/asaskevich/govalidator"
+
+	"kubesphere.io/devops/pkg/logger"
+	"kubesphere.io/devops/pkg/utils/kubeconfigutils"
+	"kubesphere.io/devops/pkg/utils/verifyutils"
+)
+
+const ErrorInvalidKubeconfig = "invalid kubeconfig"
+
+// validateKubeconfig checks the content of a kubeconfig credential can be loaded by the pipelines using it.
+func validateKubeconfig(content string) error {
+	err := kubeconfigutils.Validate(content)
+	if err != nil {
+		return fmt.Errorf("%s: %v", ErrorInvalidKubeconfig, err)
+	}
+	return nil
+}
+
+// ClusterReachability tells whether the api server of a kubeconfig cluster answered with the credentials
+// of the kubeconfig.
+type ClusterReachability struct {
+	Cluster   string `json:"cluster"`
+	Server    string `json:"server"`
+	Reachable bool   `json:"reachable"`
+	Error     string `json:"error,omitempty"`
+}
+
+// checkKubeconfigClusters gets the version of the api server of every cluster of the kubeconfig at once,
+// with the user its contexts use for the cluster.
+func checkKubeconfigClusters(verifier *verifyutils.Verifier, content string) ([]*ClusterReachability, error) {
+	kubeconfig, err := kubeconfigutils.Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", ErrorInvalidKubeconfig, err)
+	}
+	results := make([]*ClusterReachability, len(kubeconfig.Clusters))
+	var wg sync.WaitGroup
+	for i := range kubeconfig.Clusters {
+		cluster := kubeconfig.Clusters[i]
+		result := &ClusterReachability{Cluster: cluster.Name, Server: cluster.Cluster.Server}
+		results[i] = result
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := verifier.KubernetesVersion(&cluster.Cluster, kubeconfig.ClusterUser(cluster.Name))
+			if err != nil {
+				result.Error = err.Error()
+				return
+			}
+			result.Reachable = true
+		}()
+	}
+	wg.Wait()
+	return results, nil
+}
+
+// unreachableClustersError names the clusters which did not answer, nil if all did.
+func unreachableClustersError(results []*ClusterReachability) error {
+	unreachable := make([]string, 0)
+	for _, result := range results {
+		if !result.Reachable {
+			unreachable = append(unreachable, result.Cluster)
+		}
+	}
+	if len(unreachable) == 0 {
+		return nil
+	}
+	return fmt.Errorf("kubeconfig clusters [%s] are not reachable", strings.Join(unreachable, ", "))
+}
+
+func parseBoolParam(r *rest.Request, name string) (bool, error) {
+	param := r.URL.Query().Get(name)
+	if govalidator.IsNull(param) {
+		return false, nil
+	}
+	return strconv.ParseBool(param)
+}
+
+// checkCreateKubeconfigClusters probes the clusters of a kubeconfig to create when validate_connectivity is set,
+// unreachable clusters are logged and only fail the creation when strict is set.
+// It returns the status to answer with along with the error.
+func (s *ProjectService) checkCreateKubeconfigClusters(r *rest.Request, content string) ([]*ClusterReachability,
+	int, error) {
+	validate, err := parseBoolParam(r, "validate_connectivity")
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	strict, err := parseBoolParam(r, "strict")
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if !validate {
+		return nil, 0, nil
+	}
+	verifier, err := s.verifier(s.Config.Reachability.Timeout)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	clusters, err := checkKubeconfigClusters(verifier, content)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	err = unreachableClustersError(clusters)
+	if err == nil {
+		return clusters, 0, nil
+	}
+	if strict {
+		return clusters, http.StatusUnprocessableEntity, err
+	}
+	logger.WarnContext(r.Context(), "%+v", err)
+	return clusters, 0, nil
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"fmt"
+	"kubesphere.io/devops/pkg/utils/verifyutils"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+current-context: dev
+clusters:
+- name: dev
+  cluster:
+    server: https://10.0.0.1:6443
+    certificate-authority-data: Y2E=
+contexts:
+- name: dev
+  context:
+    cluster: dev
+    user: deployer
+users:
+- name: deployer
+  user:
+    token: secret
+`
+
+func replaceKubeconfig(old, new string) string {
+	return strings.Replace(testKubeconfig, old, new, 1)
+}
+
+func TestValidateKubeconfig(t *testing.T) {
+	err := validateKubeconfig(testKubeconfig)
+	if err != nil {
+		t.Fatalf("should not get error %+v", err)
+	}
+
+	tests := map[string]struct {
+		content string
+		field   string
+	}{
+		"empty":           {content: "", field: "kubeconfig is empty"},
+		"blank":           {content: " \n", field: "kubeconfig is empty"},
+		"malformed yaml":  {content: "clusters: [\n- name: dev", field: "not valid yaml"},
+		"not a mapping":   {content: "just a token", field: "not valid yaml"},
+		"no cluster":      {content: "contexts:\n- name: dev\n  context:\n    cluster: dev\n", field: "clusters"},
+		"no context":      {content: "clusters:\n- name: dev\n  cluster:\n    server: https://dev\n", field: "contexts"},
+		"no server":       {content: replaceKubeconfig("server:", "proxy-url:"), field: "clusters[0].cluster.server"},
+		"unknown cluster": {content: replaceKubeconfig("cluster: dev", "cluster: prod"), field: "context.cluster"},
+		"unknown user":    {content: replaceKubeconfig("user: deployer", "user: admin"), field: "contexts[0].context.user"},
+		"unknown current": {content: replaceKubeconfig("context: dev", "context: prod"), field: "current-context"},
+		"wrong kind":      {content: replaceKubeconfig("kind: Config", "kind: Pod"), field: "kind"},
+	}
+	for name, test := range tests {
+		err := validateKubeconfig(test.content)
+		if err == nil {
+			t.Errorf("%s: should get error", name)
+			continue
+		}
+		if !strings.HasPrefix(err.Error(), ErrorInvalidKubeconfig) || !strings.Contains(err.Error(), test.field) {
+			t.Errorf("%s: error [%v] should name [%s]", name, err, test.field)
+		}
+	}
+}
+
+func TestCheckKubeconfigClusters(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/version" || r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"gitVersion":"v1.18.0"}`))
+	}))
+	defer server.Close()
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	kubeconfig := fmt.Sprintf(`apiVersion: v1
+kind: Config
+current-context: dev
+clusters:
+- name: dev
+  cluster:
+    server: %s
+- name: prod
+  cluster:
+    server: %s
+contexts:
+- name: dev
+  context:
+    cluster: dev
+    user: deployer
+- name: prod
+  context:
+    cluster: prod
+    user: deployer
+users:
+- name: deployer
+  user:
+    token: secret
+`, server.URL, closed.URL)
+	loopback, _ := verifyutils.ParseNetworks([]string{"127.0.0.0/8", "::1/128"})
+	clusters, err := checkKubeconfigClusters(&verifyutils.Verifier{Timeout: time.Second, AllowedNetworks: loopback},
+		kubeconfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clusters) != 2 {
+		t.Fatalf("expected 2 clusters, got %d", len(clusters))
+	}
+	if !clusters[0].Reachable || clusters[0].Cluster != "dev" {
+		t.Fatalf("expected cluster [dev] to be reachable, got %+v", clusters[0])
+	}
+	if clusters[1].Reachable || clusters[1].Error == "" {
+		t.Fatalf("expected cluster [prod] not to be reachable, got %+v", clusters[1])
+	}
+	err = unreachableClustersError(clusters)
+	if err == nil || !strings.Contains(err.Error(), "[prod]") {
+		t.Fatalf("expected cluster [prod] to be reported unreachable, got %v", err)
+	}
+}
+
+func TestCheckKubeconfigClustersRefusesLoopback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"gitVersion":"v1.18.0"}`))
+	}))
+	defer server.Close()
+
+	kubeconfig := fmt.Sprintf(`apiVersion: v1
+kind: Config
+current-context: dev
+clusters:
+- name: dev
+  cluster:
+    server: %s
+contexts:
+- name: dev
+  context:
+    cluster: dev
+    user: deployer
+users:
+- name: deployer
+  user:
+    token: secret
+`, server.URL)
+	clusters, err := checkKubeconfigClusters(&verifyutils.Verifier{Timeout: time.Second}, kubeconfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clusters) != 1 || clusters[0].Reachable || !strings.Contains(clusters[0].Error, "not allowed") {
+		t.Fatalf("expected the loopback cluster to be refused, got %+v", clusters[0])
+	}
+}
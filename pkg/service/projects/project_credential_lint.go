@@ -0,0 +1,236 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/asaskevich/govalidator"
+
+	"kubesphere.io/devops/pkg/config"
+	"kubesphere.io/devops/pkg/gojenkins"
+	"kubesphere.io/devops/pkg/logger"
+	"kubesphere.io/devops/pkg/utils/certutils"
+	"kubesphere.io/devops/pkg/utils/stringutils"
+	"kubesphere.io/devops/pkg/utils/userutils"
+)
+
+const (
+	LintSeverityInfo     = "info"
+	LintSeverityWarning  = "warning"
+	LintSeverityCritical = "critical"
+)
+
+var lintSeverityLevels = map[string]int{
+	LintSeverityInfo:     0,
+	LintSeverityWarning:  1,
+	LintSeverityCritical: 2,
+}
+
+type CredentialLintFinding struct {
+	Check    string `json:"check"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+type CredentialLintResult struct {
+	Id       string                   `json:"id"`
+	Type     string                   `json:"type"`
+	Domain   string                   `json:"domain"`
+	Findings []*CredentialLintFinding `json:"findings"`
+}
+
+// lintedCredential is a credential with the history lint needs beside its metadata.
+type lintedCredential struct {
+	*CredentialResponse
+	// see credentialChangeTime
+	ChangeTime *time.Time
+}
+
+// credentialLintCheck inspects the metadata of one credential, it must never look at secret values.
+// Check returns an empty message when the credential passes.
+type credentialLintCheck struct {
+	Name     string
+	Severity string
+	Check    func(cfg *config.LintConfig, credential *lintedCredential) string
+}
+
+var CredentialLintChecks = []*credentialLintCheck{
+	{
+		Name:     "missing_description",
+		Severity: LintSeverityInfo,
+		Check: func(cfg *config.LintConfig, credential *lintedCredential) string {
+			if govalidator.IsNull(credential.Description) {
+				return "credential has no description"
+			}
+			return ""
+		},
+	},
+	{
+		Name:     "broad_scope",
+		Severity: LintSeverityWarning,
+		Check: func(cfg *config.LintConfig, credential *lintedCredential) string {
+			if credential.Scope == gojenkins.GLOBALScope && credential.Domain == "_" {
+				return "credential is usable by every job in the project and is not restricted to any host"
+			}
+			return ""
+		},
+	},
+	{
+		Name:     "stale",
+		Severity: LintSeverityWarning,
+		Check: func(cfg *config.LintConfig, credential *lintedCredential) string {
+			if credential.ChangeTime == nil || cfg.StaleDays <= 0 {
+				return ""
+			}
+			staleTime := time.Now().AddDate(0, 0, -cfg.StaleDays)
+			if credential.ChangeTime.Before(staleTime) {
+				return fmt.Sprintf("credential has not been changed for more than %d days", cfg.StaleDays)
+			}
+			return ""
+		},
+	},
+	{
+		Name:     "unscoped",
+		Severity: LintSeverityWarning,
+		Check: func(cfg *config.LintConfig, credential *lintedCredential) string {
+			if isCredentialUnscoped(cfg, credential.CredentialResponse) {
+				return fmt.Sprintf("%s credential is in the global domain, %s",
+					credential.Type, CredentialUnscopedRemediation)
+			}
+			return ""
+		},
+	},
+	{
+		Name:     "no_expiry",
+		Severity: LintSeverityInfo,
+		Check: func(cfg *config.LintConfig, credential *lintedCredential) string {
+			if credential.ExpiresAt == nil {
+				return "credential has no expiry, nothing reminds to rotate it"
+			}
+			return ""
+		},
+	},
+	{
+		Name:     "key_too_short",
+		Severity: LintSeverityCritical,
+		Check: func(cfg *config.LintConfig, credential *lintedCredential) string {
+			key := credential.KeySize
+			if key == nil {
+				return ""
+			}
+			minBits := cfg.MinEcKeyBits
+			if key.Algorithm == certutils.KeyAlgorithmRsa || key.Algorithm == certutils.KeyAlgorithmDsa {
+				minBits = cfg.MinRsaKeyBits
+			}
+			if key.Bits < minBits {
+				return fmt.Sprintf("%s key of %d bits is shorter than %d bits", key.Algorithm, key.Bits, minBits)
+			}
+			return ""
+		},
+	},
+	{
+		Name:     "weak_secret",
+		Severity: LintSeverityWarning,
+		Check: func(cfg *config.LintConfig, credential *lintedCredential) string {
+			if credential.Strength == nil || credential.Strength.Score >= cfg.MinSecretScore {
+				return ""
+			}
+			if govalidator.IsNull(credential.Strength.Reason) {
+				return fmt.Sprintf("secret strength score %d is lower than %d", credential.Strength.Score, cfg.MinSecretScore)
+			}
+			return fmt.Sprintf("secret strength score %d is lower than %d, secret %s",
+				credential.Strength.Score, cfg.MinSecretScore, credential.Strength.Reason)
+		},
+	},
+}
+
+func lintCredential(cfg *config.LintConfig, credential *lintedCredential, minSeverity string) []*CredentialLintFinding {
+	findings := make([]*CredentialLintFinding, 0)
+	for _, check := range CredentialLintChecks {
+		if stringutils.StringIn(check.Name, cfg.DisabledChecks) {
+			continue
+		}
+		if lintSeverityLevels[check.Severity] < lintSeverityLevels[minSeverity] {
+			continue
+		}
+		message := check.Check(cfg, credential)
+		if govalidator.IsNull(message) {
+			continue
+		}
+		findings = append(findings, &CredentialLintFinding{
+			Check:    check.Name,
+			Severity: check.Severity,
+			Message:  message,
+		})
+	}
+	return findings
+}
+
+func (s *ProjectService) LintCredentialsHandler(w rest.ResponseWriter, r *rest.Request) {
+	projectId := r.PathParams["id"]
+	operator := userutils.GetUserNameFromRequest(r)
+	domain := r.URL.Query().Get("domain")
+	minSeverity := r.URL.Query().Get("min_severity")
+	if govalidator.IsNull(minSeverity) {
+		minSeverity = LintSeverityInfo
+	}
+	err := s.checkProjectUserInRole(r, operator, projectId, []string{ProjectOwner, ProjectMaintainer})
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
+		return
+	}
+	if _, ok := lintSeverityLevels[minSeverity]; !ok {
+		err := fmt.Errorf("error min_severity [%s], should be one of info, warning, critical", minSeverity)
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	credentials, err := s.listCredentials(projectId, domain)
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeCredentialError(w, err, stringutils.GetJenkinsStatusCode(err))
+		return
+	}
+	lastRotations, err := s.lastCredentialRotations(projectId)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	results := make([]*CredentialLintResult, 0)
+	for _, credential := range credentials {
+		linted := &lintedCredential{
+			CredentialResponse: credential,
+			ChangeTime:         credentialChangeTime(credential, lastRotations),
+		}
+		findings := lintCredential(&s.Config.Lint, linted, minSeverity)
+		if len(findings) == 0 {
+			continue
+		}
+		results = append(results, &CredentialLintResult{
+			Id:       credential.Id,
+			Type:     credential.Type,
+			Domain:   credential.Domain,
+			Findings: findings,
+		})
+	}
+	w.WriteJson(results)
+	return
+}
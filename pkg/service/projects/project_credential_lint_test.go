@@ -0,0 +1,90 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"testing"
+	"time"
+
+	"kubesphere.io/devops/pkg/config"
+	"kubesphere.io/devops/pkg/gojenkins"
+)
+
+func lintFindingChecks(findings []*CredentialLintFinding) map[string]string {
+	checks := make(map[string]string, len(findings))
+	for _, finding := range findings {
+		checks[finding.Check] = finding.Severity
+	}
+	return checks
+}
+
+func Test_LintCredential(t *testing.T) {
+	cfg := &config.LintConfig{StaleDays: 180, MinSecretScore: 2, MinRsaKeyBits: 2048, MinEcKeyBits: 256}
+	createTime := time.Now().AddDate(-1, 0, 0)
+	expiresAt := time.Now().AddDate(1, 0, 0)
+	credential := &CredentialResponse{
+		Id:           "deploy",
+		Type:         CredentialTypeSsh,
+		Domain:       "github.com",
+		Scope:        gojenkins.GLOBALScope,
+		Description:  "deploy key",
+		CreateTime:   &createTime,
+		ModifiedTime: &createTime,
+		ExpiresAt:    &expiresAt,
+		KeySize:      &CredentialKeySize{Algorithm: "rsa", Bits: 1024},
+	}
+
+	rotateTime := time.Now().AddDate(0, -1, 0)
+	lastRotations := map[credentialKey]time.Time{{id: "deploy", domain: "github.com"}: rotateTime}
+	if changeTime := credentialChangeTime(credential, lastRotations); changeTime == nil || !changeTime.Equal(rotateTime) {
+		t.Fatalf("change time should be the last rotation [%s], got %v", rotateTime, changeTime)
+	}
+
+	checks := lintFindingChecks(lintCredential(cfg, &lintedCredential{
+		CredentialResponse: credential,
+		ChangeTime:         credentialChangeTime(credential, nil),
+	}, LintSeverityInfo))
+	if checks["stale"] != LintSeverityWarning {
+		t.Fatalf("a credential unchanged for a year should be stale, got %v", checks)
+	}
+	if checks["key_too_short"] != LintSeverityCritical {
+		t.Fatalf("a 1024 bits rsa key should be critical, got %v", checks)
+	}
+	if _, ok := checks["no_expiry"]; ok {
+		t.Fatalf("a credential with an expiry should not be reported, got %v", checks)
+	}
+
+	credential.ExpiresAt = nil
+	credential.KeySize = &CredentialKeySize{Algorithm: "ed25519", Bits: 256}
+	checks = lintFindingChecks(lintCredential(cfg, &lintedCredential{
+		CredentialResponse: credential,
+		ChangeTime:         credentialChangeTime(credential, lastRotations),
+	}, LintSeverityInfo))
+	if _, ok := checks["stale"]; ok {
+		t.Fatalf("a credential rotated last month should not be stale, got %v", checks)
+	}
+	if _, ok := checks["key_too_short"]; ok {
+		t.Fatalf("an ed25519 key should not be too short, got %v", checks)
+	}
+	if checks["no_expiry"] != LintSeverityInfo {
+		t.Fatalf("a credential without expiry should be reported, got %v", checks)
+	}
+
+	credential.KeySize = &CredentialKeySize{Algorithm: "rsa", Bits: 1024}
+	checks = lintFindingChecks(lintCredential(cfg, &lintedCredential{CredentialResponse: credential},
+		LintSeverityCritical))
+	if len(checks) != 1 || checks["key_too_short"] != LintSeverityCritical {
+		t.Fatalf("only critical findings should be kept, got %v", checks)
+	}
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"sync"
+	"time"
+
+	"kubesphere.io/devops/pkg/gojenkins"
+)
+
+type credentialListEntry struct {
+	credentials []*gojenkins.CredentialResponse
+	expireAt    time.Time
+}
+
+// CredentialListCache keeps the credentials listed in the folder of a project by domain for a short time,
+// so the lists polled by dashboards do not all reach Jenkins. The mutation handlers invalidate the lists of
+// their project once they are done, a list loaded while the project is mutated is not kept.
+type CredentialListCache struct {
+	sync.Mutex
+	ttl     time.Duration
+	entries map[string]map[string]*credentialListEntry
+	// generations counts the invalidations of each project while lists of the project are loaded
+	generations map[string]uint64
+	// loads counts the lists of each project being loaded, between a get missing them and their set
+	loads map[string]int
+}
+
+func NewCredentialListCache(ttl time.Duration) *CredentialListCache {
+	return &CredentialListCache{
+		ttl:         ttl,
+		entries:     make(map[string]map[string]*credentialListEntry),
+		generations: make(map[string]uint64),
+		loads:       make(map[string]int),
+	}
+}
+
+// get returns the credentials cached for the domain of the project. When they are not cached it returns nil
+// and starts a load, with the generation of the project to set the credentials loaded instead.
+func (c *CredentialListCache) get(projectId, domain string) ([]*gojenkins.CredentialResponse, uint64) {
+	c.Lock()
+	defer c.Unlock()
+	entry, ok := c.entries[projectId][domain]
+	if ok && time.Now().After(entry.expireAt) {
+		c.deleteEntry(projectId, domain)
+		ok = false
+	}
+	if !ok {
+		c.loads[projectId]++
+		return nil, c.generations[projectId]
+	}
+	return entry.credentials, c.generations[projectId]
+}
+
+// set ends a load started by get and caches the credentials loaded, unless they are nil or the project has
+// been invalidated since generation. The lists expired in any project are dropped.
+func (c *CredentialListCache) set(projectId, domain string, generation uint64,
+	credentials []*gojenkins.CredentialResponse) {
+	c.Lock()
+	defer c.Unlock()
+	current := c.generations[projectId]
+	// the generation only tells loads apart, it starts over once no list of the project is loaded
+	c.loads[projectId]--
+	if c.loads[projectId] <= 0 {
+		delete(c.loads, projectId)
+		delete(c.generations, projectId)
+	}
+	now := time.Now()
+	for id, domains := range c.entries {
+		for name, entry := range domains {
+			if now.After(entry.expireAt) {
+				c.deleteEntry(id, name)
+			}
+		}
+	}
+	if credentials == nil || current != generation {
+		return
+	}
+	domains, ok := c.entries[projectId]
+	if !ok {
+		domains = make(map[string]*credentialListEntry)
+		c.entries[projectId] = domains
+	}
+	domains[domain] = &credentialListEntry{credentials: credentials, expireAt: now.Add(c.ttl)}
+}
+
+// deleteEntry drops the list of the domain of the project, and the project once it has no list left.
+func (c *CredentialListCache) deleteEntry(projectId, domain string) {
+	delete(c.entries[projectId], domain)
+	if len(c.entries[projectId]) == 0 {
+		delete(c.entries, projectId)
+	}
+}
+
+// invalidate drops the lists of all domains of the project, the list of all domains includes any of them.
+// The lists of the project being loaded are not cached.
+func (c *CredentialListCache) invalidate(projectId string) {
+	c.Lock()
+	defer c.Unlock()
+	delete(c.entries, projectId)
+	if c.loads[projectId] > 0 {
+		c.generations[projectId]++
+	}
+}
+
+// getCredentialsInFolder lists the credentials of the domain of the project through the list cache,
+// an empty domain lists all domains.
+func (s *ProjectService) getCredentialsInFolder(projectId, domain string) ([]*gojenkins.CredentialResponse,
+	error) {
+	if s.ListCache == nil {
+		return s.credentialStore().GetCredentialsInFolder(domain, projectId)
+	}
+	credentials, generation := s.ListCache.get(projectId, domain)
+	if credentials != nil {
+		return credentials, nil
+	}
+	credentials, err := s.credentialStore().GetCredentialsInFolder(domain, projectId)
+	if err != nil {
+		s.ListCache.set(projectId, domain, generation, nil)
+		return nil, err
+	}
+	s.ListCache.set(projectId, domain, generation, credentials)
+	return credentials, nil
+}
+
+// invalidateCredentialList drops the cached credential lists of the project, the mutation handlers defer it
+// so that the lists served after a mutation are loaded from Jenkins again.
+func (s *ProjectService) invalidateCredentialList(projectId string) {
+	if s.ListCache != nil {
+		s.ListCache.invalidate(projectId)
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"kubesphere.io/devops/pkg/gojenkins"
+	"testing"
+	"time"
+)
+
+func TestGetCredentialsInFolderCached(t *testing.T) {
+	store := NewFakeCredentialStore()
+	s := &ProjectService{Credentials: store, ListCache: NewCredentialListCache(time.Minute)}
+	_, err := store.CreateSecretTextCredentialInFolder("_", "token", "secret", "", "", "project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	credentials, err := s.getCredentialsInFolder("project", "")
+	if err != nil || len(credentials) != 1 {
+		t.Fatalf("expected 1 credential, got %v, %+v", credentials, err)
+	}
+
+	// changed behind the cache, the list cached is served until it is invalidated
+	_, err = store.CreateSecretTextCredentialInFolder("_", "other", "secret", "", "", "project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	credentials, _ = s.getCredentialsInFolder("project", "")
+	if len(credentials) != 1 {
+		t.Fatalf("expected the cached credential, got %d", len(credentials))
+	}
+	credentials, _ = s.getCredentialsInFolder("project", "_")
+	if len(credentials) != 2 {
+		t.Fatalf("expected 2 credentials in a domain not cached yet, got %d", len(credentials))
+	}
+
+	s.invalidateCredentialList("project")
+	credentials, _ = s.getCredentialsInFolder("project", "")
+	if len(credentials) != 2 {
+		t.Fatalf("expected 2 credentials after invalidation, got %d", len(credentials))
+	}
+}
+
+func TestCredentialListCacheExpires(t *testing.T) {
+	cache := NewCredentialListCache(-time.Second)
+	credentials, generation := cache.get("project", "")
+	if credentials != nil {
+		t.Fatalf("expected nothing cached, got %v", credentials)
+	}
+	cache.set("project", "", generation, credentials)
+	if credentials, _ := cache.get("project", ""); credentials != nil {
+		t.Fatalf("expected the list to be expired, got %v", credentials)
+	}
+}
+
+func TestCredentialListCacheSkipsListsLoadedBeforeInvalidation(t *testing.T) {
+	store := NewFakeCredentialStore()
+	cache := NewCredentialListCache(time.Minute)
+	_, generation := cache.get("project", "")
+	credentials, _ := store.GetCredentialsInFolder("", "project")
+
+	cache.invalidate("project")
+	cache.set("project", "", generation, credentials)
+	if cached, _ := cache.get("project", ""); cached != nil {
+		t.Fatalf("expected the list loaded before the invalidation not to be cached, got %v", cached)
+	}
+
+	_, generation = cache.get("project", "")
+	cache.set("project", "", generation, credentials)
+	if cached, _ := cache.get("project", ""); cached == nil {
+		t.Fatal("expected the list to be cached")
+	}
+}
+
+func TestCredentialListCacheNotUsed(t *testing.T) {
+	store := NewFakeCredentialStore()
+	s := &ProjectService{Credentials: store}
+	credentials, err := s.getCredentialsInFolder("project", "")
+	if err != nil || len(credentials) != 0 {
+		t.Fatalf("expected no credential, got %v, %+v", credentials, err)
+	}
+	_, err = store.CreateSecretTextCredentialInFolder("_", "token", "secret", "", "", "project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.invalidateCredentialList("project")
+	credentials, _ = s.getCredentialsInFolder("project", "")
+	if len(credentials) != 1 {
+		t.Fatalf("expected 1 credential, got %d", len(credentials))
+	}
+}
+
+func TestCredentialListCacheDropsExpiredListsAndGenerations(t *testing.T) {
+	cache := NewCredentialListCache(time.Minute)
+	credentials := make([]*gojenkins.CredentialResponse, 0)
+	_, generation := cache.get("expired", "")
+	cache.set("expired", "", generation, credentials)
+	cache.entries["expired"][""].expireAt = time.Now().Add(-time.Second)
+
+	_, generation = cache.get("project", "")
+	cache.invalidate("project")
+	cache.set("project", "", generation, credentials)
+	_, generation = cache.get("project", "_")
+	cache.set("project", "_", generation, credentials)
+	if _, ok := cache.entries["expired"]; ok {
+		t.Fatal("expected the expired list to be dropped")
+	}
+	if len(cache.generations) != 0 || len(cache.loads) != 0 {
+		t.Fatalf("expected no generation once the lists are loaded, got %v and %v", cache.generations, cache.loads)
+	}
+	if cached, _ := cache.get("project", "_"); cached == nil {
+		t.Fatal("expected the list loaded after the invalidation to be cached")
+	}
+
+	cache.invalidate("project")
+	if len(cache.entries) != 0 || len(cache.generations) != 0 {
+		t.Fatalf("expected an invalidation without loads to keep nothing, got %v and %v", cache.entries,
+			cache.generations)
+	}
+}
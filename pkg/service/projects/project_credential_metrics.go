@@ -0,0 +1,137 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"net/http"
+
+	"github.com/ant0ine/go-json-rest/rest"
+
+	"kubesphere.io/devops/pkg/logger"
+	"kubesphere.io/devops/pkg/utils/metricsutils"
+	"kubesphere.io/devops/pkg/utils/userutils"
+)
+
+// the credential operations counted besides the credential actions
+const (
+	CredentialActionGet     = "get"
+	CredentialActionList    = "list"
+	CredentialActionCopy    = "copy"
+	CredentialActionTest    = "test"
+	CredentialActionRestore = "restore"
+	CredentialActionBatch   = "batch"
+)
+
+const (
+	credentialOperationTypeEnvKey = "CREDENTIAL_OPERATION_TYPE"
+	unknownCredentialType         = "unknown"
+	credentialOperationSuccess    = "success"
+	credentialOperationFailure    = "failure"
+)
+
+// the results credential operations are logged with
+const (
+	credentialLogResultSuccess = "success"
+	credentialLogResultError   = "error"
+)
+
+var credentialOperations = metricsutils.NewCounterVec("credential_operations_total",
+	"Credential operations served by credential type, action and result.", "type", "action", "result")
+
+// operationRecorder keeps the status of the response of a credential operation.
+type operationRecorder struct {
+	rest.ResponseWriter
+	status int
+}
+
+func (o *operationRecorder) WriteHeader(status int) {
+	if o.status == 0 {
+		o.status = status
+	}
+	o.ResponseWriter.WriteHeader(status)
+}
+
+func (o *operationRecorder) WriteJson(v interface{}) error {
+	if o.status == 0 {
+		o.status = http.StatusOK
+	}
+	return o.ResponseWriter.WriteJson(v)
+}
+
+func (o *operationRecorder) Write(b []byte) (int, error) {
+	if o.status == 0 {
+		o.status = http.StatusOK
+	}
+	return o.ResponseWriter.(http.ResponseWriter).Write(b)
+}
+
+func (o *operationRecorder) Flush() {
+	if flusher, ok := o.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// setCredentialOperationType names the type of the credential the operation served for r is about,
+// operations of no known type are counted as unknown.
+func setCredentialOperationType(r *rest.Request, credentialType string) {
+	if r.Env == nil {
+		r.Env = make(map[string]interface{})
+	}
+	r.Env[credentialOperationTypeEnvKey] = credentialType
+}
+
+// setCredentialLogFields adds fields to the request context of r, everything logged with it afterwards
+// carries them.
+func setCredentialLogFields(r *rest.Request, fields logger.Fields) {
+	r.Request = r.WithContext(logger.ContextWithFields(r.Context(), fields))
+}
+
+// InstrumentCredentialHandler counts the requests served by handler as credential operations of action,
+// responses with a status below 400 are counted as successes. The operator, project, credential id and
+// action are logged as fields of everything handler logs with the request context, and of the result
+// logged once the operation is served.
+func (s *ProjectService) InstrumentCredentialHandler(action string, handler rest.HandlerFunc) rest.HandlerFunc {
+	return func(w rest.ResponseWriter, r *rest.Request) {
+		fields := logger.Fields{
+			"operator": userutils.GetUserNameFromRequest(r),
+			"project":  r.PathParams["id"],
+			"action":   action,
+		}
+		if credentialId := r.PathParams["cid"]; credentialId != "" {
+			fields["credential_id"] = credentialId
+		}
+		setCredentialLogFields(r, fields)
+		recorder := &operationRecorder{ResponseWriter: w}
+		handler(recorder, r)
+		// the type comes from the request, only known types are used as label so the series stay bounded
+		credentialType, _ := r.Env[credentialOperationTypeEnvKey].(string)
+		if _, ok := credentialRequestFactories[credentialType]; !ok {
+			credentialType = unknownCredentialType
+		}
+		result := credentialOperationSuccess
+		if recorder.status >= http.StatusBadRequest {
+			result = credentialOperationFailure
+		}
+		credentialOperations.Inc(credentialType, action, result)
+		entry := logger.FromContext(r.Context()).WithFields(logger.Fields{
+			"type":   credentialType,
+			"status": recorder.status,
+		})
+		if result == credentialOperationFailure {
+			entry.WithField("result", credentialLogResultError).Warn("credential operation failed")
+			return
+		}
+		entry.WithField("result", credentialLogResultSuccess).Info("credential operation served")
+	}
+}
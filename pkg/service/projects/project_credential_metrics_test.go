@@ -0,0 +1,83 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ant0ine/go-json-rest/rest"
+
+	"kubesphere.io/devops/pkg/logger"
+)
+
+func TestInstrumentCredentialHandler(t *testing.T) {
+	s := &ProjectService{}
+	succeed := s.InstrumentCredentialHandler("metrics-test", func(w rest.ResponseWriter, r *rest.Request) {
+		setCredentialOperationType(r, CredentialTypeSsh)
+		w.WriteJson(map[string]string{"id": "ssh"})
+	})
+	fail := s.InstrumentCredentialHandler("metrics-test", func(w rest.ResponseWriter, r *rest.Request) {
+		writeCredentialError(w, errors.New("not found"), http.StatusNotFound)
+	})
+	bogus := s.InstrumentCredentialHandler("metrics-test", func(w rest.ResponseWriter, r *rest.Request) {
+		setCredentialOperationType(r, "bogus-type")
+		writeCredentialError(w, errors.New("error unsupport credential type"), http.StatusBadRequest)
+	})
+
+	succeed(&recorder{httptest.NewRecorder()}, newFakeStoreRequest("admin", "owner", "/projects/project/credentials"))
+	succeed(&recorder{httptest.NewRecorder()}, newFakeStoreRequest("admin", "owner", "/projects/project/credentials"))
+	fail(&recorder{httptest.NewRecorder()}, &rest.Request{Request: httptest.NewRequest("GET", "/", nil)})
+	bogus(&recorder{httptest.NewRecorder()}, newFakeStoreRequest("admin", "owner", "/projects/project/credentials"))
+
+	if n := credentialOperations.Value(CredentialTypeSsh, "metrics-test", credentialOperationSuccess); n != 2 {
+		t.Fatalf("expected 2 successful ssh operations, got %v", n)
+	}
+	if n := credentialOperations.Value(unknownCredentialType, "metrics-test", credentialOperationFailure); n != 2 {
+		t.Fatalf("expected 2 failed unknown operations, got %v", n)
+	}
+	if n := credentialOperations.Value("bogus-type", "metrics-test", credentialOperationFailure); n != 0 {
+		t.Fatalf("expected no series for a type given by the client, got %v", n)
+	}
+}
+
+func TestInstrumentCredentialHandlerLogFields(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger.SetOutput(buf)
+	defer logger.SetOutput(os.Stdout)
+
+	s := &ProjectService{}
+	fail := s.InstrumentCredentialHandler(CredentialActionDelete, func(w rest.ResponseWriter, r *rest.Request) {
+		logCredentialError(r.Context(), errors.New("jenkins is down"))
+		writeCredentialError(w, errors.New("jenkins is down"), http.StatusServiceUnavailable)
+	})
+	fail(&recorder{httptest.NewRecorder()}, newFakeStoreRequest("admin", "owner", "/projects/project/credentials/git"))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected the error and the result to be logged, got %q", buf.String())
+	}
+	fields := "action=delete credential_id=git operator=admin project=project"
+	if !strings.Contains(lines[0], "jenkins is down "+fields) {
+		t.Fatalf("expected the error to be logged with %q, got %q", fields, lines[0])
+	}
+	if !strings.Contains(lines[1], fields+" result=error status=503 type=unknown") {
+		t.Fatalf("expected the result to be logged as error, got %q", lines[1])
+	}
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/asaskevich/govalidator"
+
+	"kubesphere.io/devops/pkg/db"
+	"kubesphere.io/devops/pkg/gojenkins"
+	"kubesphere.io/devops/pkg/logger"
+	"kubesphere.io/devops/pkg/models"
+	"kubesphere.io/devops/pkg/utils/userutils"
+)
+
+const CredentialActionMove = "move"
+
+type MoveCredentialRequest struct {
+	Domain       string `json:"domain"`
+	TargetDomain string `json:"target_domain"`
+}
+
+type MoveCredentialResponse struct {
+	Id     string `json:"id"`
+	Domain string `json:"domain"`
+}
+
+// MoveCredentialHandler moves a credential to another domain of the project. The credential is created
+// in the target domain from its config.xml, which keeps its secrets encrypted by Jenkins, before it is deleted
+// from its domain, the target credential is deleted again when the move can not be completed. Its record keeps
+// the creator and create time. The former credential goes to the trash when it is deleted.
+func (s *ProjectService) MoveCredentialHandler(w rest.ResponseWriter, r *rest.Request) {
+	request := &MoveCredentialRequest{}
+	projectId := r.PathParams["id"]
+	defer s.invalidateCredentialList(projectId)
+	credentialId := r.PathParams["cid"]
+	operator := userutils.GetUserNameFromRequest(r)
+	err := r.DecodeJsonPayload(request)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	if govalidator.IsNull(request.Domain) {
+		request.Domain = r.URL.Query().Get("domain")
+	}
+	if govalidator.IsNull(request.Domain) {
+		request.Domain = "_"
+	}
+	if govalidator.IsNull(request.TargetDomain) || request.TargetDomain == request.Domain {
+		err := fmt.Errorf("target_domain should be another domain")
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	err = s.checkProjectUserInRole(r, operator, projectId, s.credentialActionRoles(CredentialActionUpdate))
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
+		return
+	}
+
+	config, err := s.credentialStore().GetCredentialConfigInFolder(request.Domain, credentialId, projectId)
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeJenkinsError(w, r, err)
+		return
+	}
+	existing, err := s.credentialStore().GetCredentialInFolder(request.TargetDomain, credentialId, projectId)
+	if existing != nil {
+		err := fmt.Errorf("credential id [%s] has been used in domain [%s]", credentialId, request.TargetDomain)
+		logger.WarnContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusConflict)
+		return
+	}
+	if err != nil && !errors.Is(err, gojenkins.ErrCredentialNotFound) {
+		logCredentialError(r.Context(), err)
+		writeJenkinsError(w, r, err)
+		return
+	}
+	projectCredential, err := s.getProjectCredential(projectId, request.Domain, credentialId)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	err = s.ensureCredentialDomain(projectId, request.TargetDomain)
+	if err == nil {
+		err = s.credentialStore().CreateCredentialFromConfigInFolder(request.TargetDomain, config, projectId)
+	}
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeJenkinsError(w, r, err)
+		return
+	}
+	movedCredential := movedCredentialRecord(projectCredential, projectId, request.TargetDomain, credentialId,
+		operator)
+	// the credential is trashed before it is deleted from its former domain, like any other delete, the ones
+	// that can not be restored are not trashed as the moved credential keeps their secret
+	trash, err := s.trashBeforeDelete(newCredentialTarget(projectId, request.Domain, models.CredentialStoreFolder),
+		operator, credentialId, true)
+	if err == nil {
+		err = s.moveCredential(projectCredential, movedCredential, request.Domain)
+		if err != nil {
+			if former, _ := s.credentialStore().GetCredentialInFolder(request.Domain, credentialId,
+				projectId); former != nil {
+				s.untrashCredential(r.Context(), trash)
+			}
+		}
+	} else {
+		s.removeMovedCredential(movedCredential)
+	}
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeJenkinsError(w, r, err)
+		return
+	}
+
+	s.recordCredentialMutation(projectId, operator, CredentialActionMove, request.TargetDomain, credentialId,
+		map[string]interface{}{"from_domain": request.Domain})
+	logger.Info("credential [%s] of project [%s] moved from domain [%s] to [%s] by [%s]", credentialId, projectId,
+		request.Domain, request.TargetDomain, operator)
+	w.WriteJson(&MoveCredentialResponse{Id: credentialId, Domain: request.TargetDomain})
+	return
+}
+
+// moveCredential moves the record of a credential created in its target domain and deletes the credential
+// from its former domain in Jenkins, the record is only moved once the credential is deleted. A credential
+// without record is recorded as the moved one. The created credential is deleted again when the move fails
+// before the former one is deleted.
+func (s *ProjectService) moveCredential(projectCredential, movedCredential *models.ProjectCredential,
+	domain string) (err error) {
+	deleted := false
+	defer func() {
+		if err == nil || deleted {
+			return
+		}
+		s.removeMovedCredential(movedCredential)
+	}()
+	tx, err := s.Ds.Db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.RollbackUnlessCommitted()
+	if projectCredential != nil {
+		_, err = tx.Update(models.ProjectCredentialTableName).
+			Set(models.ProjectCredentialDomainColumn, movedCredential.Domain).
+			Set(models.ProjectCredentialModifiedByColumn, movedCredential.ModifiedBy).
+			Set(models.ProjectCredentialModifiedTimeColumn, movedCredential.ModifiedTime).
+			Where(db.And(db.Eq(models.ProjectIdColumn, projectCredential.ProjectId),
+				db.Eq(models.ProjectCredentialIdColumn, projectCredential.CredentialId),
+				db.Eq(models.ProjectCredentialDomainColumn, domain))).Exec()
+	} else {
+		_, err = tx.InsertInto(models.ProjectCredentialTableName).Columns(models.ProjectCredentialColumns...).
+			Record(movedCredential).Exec()
+	}
+	if err != nil {
+		return err
+	}
+	_, err = tx.Update(models.ProjectCredentialUsageThresholdTableName).
+		Set(models.ProjectCredentialDomainColumn, movedCredential.Domain).
+		Where(db.And(db.Eq(models.ProjectIdColumn, movedCredential.ProjectId),
+			db.Eq(models.ProjectCredentialIdColumn, movedCredential.CredentialId),
+			db.Eq(models.ProjectCredentialDomainColumn, domain))).Exec()
+	if err != nil {
+		return err
+	}
+	_, err = s.credentialStore().DeleteCredentialInFolder(domain, movedCredential.CredentialId, movedCredential.ProjectId)
+	if err != nil {
+		return err
+	}
+	deleted = true
+	err = tx.Commit()
+	if err != nil {
+		logger.Error("credential [%s] of project [%s] is moved to domain [%s] in Jenkins but its record is not, "+
+			"it needs manual repair: %+v", movedCredential.CredentialId, movedCredential.ProjectId,
+			movedCredential.Domain, err)
+	}
+	return err
+}
+
+// movedCredentialRecord returns the record of a credential moved to domain, a copy of its former record that
+// keeps the creator and create time, or a new record for a credential without record.
+func movedCredentialRecord(projectCredential *models.ProjectCredential, projectId, domain, credentialId,
+	operator string) *models.ProjectCredential {
+	if projectCredential == nil {
+		return models.NewProjectCredential(projectId, credentialId, domain, operator)
+	}
+	movedCredential := *projectCredential
+	movedCredential.Domain = domain
+	movedCredential.ModifiedBy = db.EncryptedString(operator)
+	movedCredential.ModifiedTime = time.Now()
+	return &movedCredential
+}
+
+// removeMovedCredential deletes the credential created in the target domain of a move that failed.
+func (s *ProjectService) removeMovedCredential(movedCredential *models.ProjectCredential) {
+	_, err := s.credentialStore().DeleteCredentialInFolder(movedCredential.Domain, movedCredential.CredentialId,
+		movedCredential.ProjectId)
+	if err != nil {
+		logger.Error("failed to remove credential [%s] from domain [%s] after failed move, "+
+			"it needs manual repair: %+v", movedCredential.CredentialId, movedCredential.Domain, err)
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMoveCredentialHandlerSsh(t *testing.T) {
+	s, store, projectIds, cleanup := newDbTestService(t, 1)
+	defer cleanup()
+	projectId := projectIds[0]
+	key := readTestKey(t, "ssh_rsa_encrypted.pem")
+	if _, err := store.CreateSshCredentialInFolder("_", "deploy", "git", "secret", key, "deploy key", "",
+		projectId); err != nil {
+		t.Fatal(err)
+	}
+
+	w := &recorder{httptest.NewRecorder()}
+	r := newProjectJsonRequest("alice", projectId, map[string]string{projectId: ProjectOwner}, "POST",
+		"/projects/"+projectId+"/credentials/deploy/move", &MoveCredentialRequest{TargetDomain: "github"})
+	r.PathParams["cid"] = "deploy"
+	s.MoveCredentialHandler(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("ssh credential should be moved, got %d %s", w.Code, w.Body)
+	}
+	if moved := fakeCredentialField(t, store, projectId, "github", "deploy", "privateKey"); moved != key {
+		t.Fatalf("moved credential should keep its private key, got [%s]", moved)
+	}
+	if passphrase := fakeCredentialField(t, store, projectId, "github", "deploy", "passphrase"); passphrase != "secret" {
+		t.Fatalf("moved credential should keep its passphrase, got [%s]", passphrase)
+	}
+	if _, err := store.GetCredentialInFolder("_", "deploy", projectId); err == nil {
+		t.Fatal("credential should be deleted from its former domain")
+	}
+}
+
+func TestMoveCredentialHandlerOpenShiftToken(t *testing.T) {
+	s, store, projectIds, cleanup := newDbTestService(t, 1)
+	defer cleanup()
+	projectId := projectIds[0]
+	if _, err := store.CreateOpenShiftTokenCredentialInFolder("_", "cluster", "token", "cluster token", "",
+		projectId); err != nil {
+		t.Fatal(err)
+	}
+
+	// the token can not be read back from the update page, the config.xml keeps it encrypted
+	w := &recorder{httptest.NewRecorder()}
+	r := newProjectJsonRequest("alice", projectId, map[string]string{projectId: ProjectOwner}, "POST",
+		"/projects/"+projectId+"/credentials/cluster/move", &MoveCredentialRequest{TargetDomain: "openshift"})
+	r.PathParams["cid"] = "cluster"
+	s.MoveCredentialHandler(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("openshift token credential should be moved, got %d %s", w.Code, w.Body)
+	}
+	if token := fakeCredentialField(t, store, projectId, "openshift", "cluster", "secret"); token != "token" {
+		t.Fatalf("moved credential should keep its token, got [%s]", token)
+	}
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/asaskevich/govalidator"
+
+	"kubesphere.io/devops/pkg/db"
+	"kubesphere.io/devops/pkg/models"
+)
+
+// validateOpenShiftServerUrl checks serverUrl is the https url of an api server, tokens are bearer
+// tokens which are not sent in clear text.
+func validateOpenShiftServerUrl(serverUrl string) error {
+	parsed, err := url.Parse(serverUrl)
+	if err != nil || parsed.Scheme != "https" || parsed.Hostname() == "" || parsed.User != nil ||
+		parsed.RawQuery != "" || parsed.Fragment != "" {
+		return fmt.Errorf("server_url [%s] should be the https url of an api server", serverUrl)
+	}
+	return nil
+}
+
+// updateServerUrl records the api server of an OpenShift token credential, an empty server keeps the current one.
+func (s *ProjectService) updateServerUrl(projectId, domain, credentialId, serverUrl string) error {
+	if govalidator.IsNull(serverUrl) {
+		return nil
+	}
+	if govalidator.IsNull(domain) {
+		domain = "_"
+	}
+	_, err := s.Ds.Db.Update(models.ProjectCredentialTableName).
+		Set(models.ProjectCredentialServerUrlColumn, db.EncryptedString(serverUrl)).
+		Where(db.And(db.Eq(models.ProjectIdColumn, projectId),
+			db.Eq(models.ProjectCredentialIdColumn, credentialId),
+			db.Eq(models.ProjectCredentialDomainColumn, domain))).Exec()
+	return err
+}
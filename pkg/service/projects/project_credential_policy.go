@@ -0,0 +1,132 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/gocraft/dbr"
+
+	"kubesphere.io/devops/pkg/db"
+	"kubesphere.io/devops/pkg/logger"
+	"kubesphere.io/devops/pkg/models"
+	"kubesphere.io/devops/pkg/utils/userutils"
+)
+
+type UpdateCredentialPolicyRequest struct {
+	PreCreateWebhook         *bool `json:"pre_create_webhook"`
+	RequireVerification      *bool `json:"require_verification"`
+	ContentValidationWebhook *bool `json:"content_validation_webhook"`
+	RecreateCooldown         *int  `json:"recreate_cooldown"`
+}
+
+// getCredentialPolicy returns the credential policy of the project,
+// projects which never configured a policy get the default one.
+func (s *ProjectService) getCredentialPolicy(projectId string) (*models.ProjectCredentialPolicy, error) {
+	policy := &models.ProjectCredentialPolicy{}
+	err := s.Ds.Db.Select(models.ProjectCredentialPolicyColumns...).
+		From(models.ProjectCredentialPolicyTableName).
+		Where(db.Eq(models.ProjectIdColumn, projectId)).
+		LoadOne(policy)
+	if err == db.ErrNotFound {
+		return models.NewProjectCredentialPolicy(projectId), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (s *ProjectService) GetCredentialPolicyHandler(w rest.ResponseWriter, r *rest.Request) {
+	projectId := r.PathParams["id"]
+	operator := userutils.GetUserNameFromRequest(r)
+	err := s.checkProjectUserInRole(r, operator, projectId, []string{ProjectOwner, ProjectMaintainer})
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
+		return
+	}
+	policy, err := s.getCredentialPolicy(projectId)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	w.WriteJson(policy)
+	return
+}
+
+func (s *ProjectService) UpdateCredentialPolicyHandler(w rest.ResponseWriter, r *rest.Request) {
+	request := &UpdateCredentialPolicyRequest{}
+	projectId := r.PathParams["id"]
+	operator := userutils.GetUserNameFromRequest(r)
+	err := r.DecodeJsonPayload(request)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	if request.RecreateCooldown != nil && *request.RecreateCooldown < 0 {
+		err := fmt.Errorf("recreate_cooldown should not be negative")
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	err = s.checkProjectUserInRole(r, operator, projectId, []string{ProjectOwner})
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
+		return
+	}
+	policy, err := s.getCredentialPolicy(projectId)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if request.PreCreateWebhook != nil {
+		policy.PreCreateWebhook = *request.PreCreateWebhook
+	}
+	if request.RequireVerification != nil {
+		policy.RequireVerification = *request.RequireVerification
+	}
+	if request.ContentValidationWebhook != nil {
+		policy.ContentValidationWebhook = *request.ContentValidationWebhook
+	}
+	if request.RecreateCooldown != nil {
+		policy.RecreateCooldown = *request.RecreateCooldown
+	}
+
+	// replaced in one transaction, a failed insert must not leave the project without its policy
+	err = s.Ds.Db.WithTransaction(func(tx *dbr.Tx) error {
+		_, err := tx.DeleteFrom(models.ProjectCredentialPolicyTableName).
+			Where(db.Eq(models.ProjectIdColumn, projectId)).Exec()
+		if err != nil {
+			return err
+		}
+		_, err = tx.InsertInto(models.ProjectCredentialPolicyTableName).
+			Columns(models.ProjectCredentialPolicyColumns...).
+			Record(policy).Exec()
+		return err
+	})
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	w.WriteJson(policy)
+	return
+}
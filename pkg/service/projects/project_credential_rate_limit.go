@@ -0,0 +1,96 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ant0ine/go-json-rest/rest"
+
+	"kubesphere.io/devops/pkg/logger"
+)
+
+// maxIdleBuckets is the number of buckets kept before the ones refilled completely are dropped.
+const maxIdleBuckets = 10000
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// contentReadLimiter is a token bucket per user and project, each bucket holds up to perMinute
+// tokens and is refilled continuously at perMinute tokens per minute.
+type contentReadLimiter struct {
+	sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+var credentialContentReadLimiter = &contentReadLimiter{buckets: make(map[string]*tokenBucket)}
+
+// take consumes a token of the bucket of key, when the bucket is empty it returns false and the
+// time until a token is available.
+func (l *contentReadLimiter) take(key string, perMinute int, now time.Time) (bool, time.Duration) {
+	l.Lock()
+	defer l.Unlock()
+	capacity := float64(perMinute)
+	rate := capacity / time.Minute.Seconds()
+	bucket, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= maxIdleBuckets {
+			l.prune(capacity, rate, now)
+		}
+		bucket = &tokenBucket{tokens: capacity, last: now}
+		l.buckets[key] = bucket
+	}
+	bucket.tokens = math.Min(capacity, bucket.tokens+now.Sub(bucket.last).Seconds()*rate)
+	bucket.last = now
+	if bucket.tokens < 1 {
+		return false, time.Duration((1 - bucket.tokens) / rate * float64(time.Second))
+	}
+	bucket.tokens--
+	return true, 0
+}
+
+func (l *contentReadLimiter) prune(capacity, rate float64, now time.Time) {
+	for key, bucket := range l.buckets {
+		if bucket.tokens+now.Sub(bucket.last).Seconds()*rate >= capacity {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// checkContentReadLimit answers 429 and returns false when operator read the content of
+// credentials of the project too often.
+func (s *ProjectService) checkContentReadLimit(w rest.ResponseWriter, operator, projectId string) bool {
+	perMinute := s.Config.ContentRead.RateLimit
+	if perMinute <= 0 {
+		return true
+	}
+	allowed, wait := credentialContentReadLimiter.take(operator+"/"+projectId, perMinute, time.Now())
+	if allowed {
+		return true
+	}
+	retryAfter := int(math.Ceil(wait.Seconds()))
+	err := fmt.Errorf("user [%s] reads credential content of project [%s] too often, retry in %ds",
+		operator, projectId, retryAfter)
+	logger.Warn("%+v", err)
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	writeCredentialError(w, err, http.StatusTooManyRequests)
+	return false
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_ContentReadLimiter(t *testing.T) {
+	limiter := &contentReadLimiter{buckets: make(map[string]*tokenBucket)}
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		if allowed, _ := limiter.take("admin/project", 3, now); !allowed {
+			t.Fatalf("read %d should be allowed", i)
+		}
+	}
+	allowed, wait := limiter.take("admin/project", 3, now)
+	if allowed {
+		t.Fatalf("read over the limit should be rejected")
+	}
+	if wait != 20*time.Second {
+		t.Fatalf("should wait 20s for the next token, got %s", wait)
+	}
+	if allowed, _ := limiter.take("admin/other-project", 3, now); !allowed {
+		t.Fatalf("reads of another project should have their own bucket")
+	}
+	if allowed, _ := limiter.take("admin/project", 3, now.Add(20*time.Second)); !allowed {
+		t.Fatalf("read should be allowed once a token is refilled")
+	}
+	if allowed, _ := limiter.take("admin/project", 3, now.Add(20*time.Second)); allowed {
+		t.Fatalf("bucket should be empty again")
+	}
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"kubesphere.io/devops/pkg/logger"
+)
+
+// ports probed for hosts of hostname specifications, which have no port
+var defaultProbePorts = []string{"443", "22"}
+
+type HostReachability struct {
+	Host      string `json:"host"`
+	Reachable bool   `json:"reachable"`
+	Error     string `json:"error,omitempty"`
+}
+
+type reachabilityEntry struct {
+	reachability *HostReachability
+	expireAt     time.Time
+}
+
+type reachabilityCache struct {
+	sync.Mutex
+	entries map[string]*reachabilityEntry
+}
+
+var hostReachabilityCache = &reachabilityCache{entries: make(map[string]*reachabilityEntry)}
+
+func (c *reachabilityCache) get(host string) *HostReachability {
+	c.Lock()
+	defer c.Unlock()
+	entry, ok := c.entries[host]
+	if !ok || time.Now().After(entry.expireAt) {
+		return nil
+	}
+	return entry.reachability
+}
+
+func (c *reachabilityCache) set(host string, reachability *HostReachability, ttl time.Duration) {
+	c.Lock()
+	defer c.Unlock()
+	c.entries[host] = &reachabilityEntry{reachability: reachability, expireAt: time.Now().Add(ttl)}
+}
+
+// parseDomainHosts returns the hosts included by the hostname and hostname port
+// specifications of a domain config.xml, wildcard patterns can not be probed and are left out.
+func parseDomainHosts(configXml string) ([]string, error) {
+	doc, err := readJenkinsXml(configXml)
+	if err != nil {
+		return nil, err
+	}
+	hosts := make([]string, 0)
+	for _, specification := range doc.FindElements("//specifications/*") {
+		if !strings.HasSuffix(specification.Tag, "HostnameSpecification") &&
+			!strings.HasSuffix(specification.Tag, "HostnamePortSpecification") {
+			continue
+		}
+		includes := specification.SelectElement("includes")
+		if includes == nil {
+			continue
+		}
+		for _, host := range strings.Split(includes.Text(), ",") {
+			host = strings.TrimSpace(host)
+			if host == "" || strings.ContainsAny(host, "*?") {
+				continue
+			}
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts, nil
+}
+
+// probeHost connects to the host without sending anything, hosts without port
+// are reachable if any of the default probe ports accepts the connection.
+func (s *ProjectService) probeHost(host string) *HostReachability {
+	if cached := hostReachabilityCache.get(host); cached != nil {
+		return cached
+	}
+	addresses := make([]string, 0)
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		addresses = append(addresses, host)
+	} else {
+		for _, port := range defaultProbePorts {
+			addresses = append(addresses, net.JoinHostPort(host, port))
+		}
+	}
+
+	reachability := &HostReachability{Host: host}
+	verifier, err := s.verifier(s.Config.Reachability.Timeout)
+	if err != nil {
+		reachability.Error = err.Error()
+		return reachability
+	}
+	for _, address := range addresses {
+		err := verifier.Tcp(address)
+		if err == nil {
+			reachability.Reachable = true
+			reachability.Error = ""
+			break
+		}
+		reachability.Error = err.Error()
+	}
+	hostReachabilityCache.set(host, reachability, s.Config.Reachability.CacheTtl)
+	return reachability
+}
+
+// fillCredentialsReachability probes the hosts of the domain every credential is scoped to,
+// credentials in the global domain are not scoped to any host and are left alone.
+func (s *ProjectService) fillCredentialsReachability(projectId string, credentials []*CredentialResponse) {
+	domainHosts := make(map[string][]string)
+	for _, credential := range credentials {
+		if credential.Domain == "_" {
+			continue
+		}
+		if _, ok := domainHosts[credential.Domain]; ok {
+			continue
+		}
+		domainHosts[credential.Domain] = make([]string, 0)
+		configXml, err := s.Ds.Jenkins.GetDomainConfigInFolder(credential.Domain, projectId)
+		if err != nil {
+			logger.Warn("failed to get domain [%s] config: %+v", credential.Domain, err)
+			continue
+		}
+		hosts, err := parseDomainHosts(configXml)
+		if err != nil {
+			logger.Warn("failed to parse domain [%s] config: %+v", credential.Domain, err)
+			continue
+		}
+		domainHosts[credential.Domain] = hosts
+	}
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	reachabilities := make(map[string]*HostReachability)
+	for _, hosts := range domainHosts {
+		for _, host := range hosts {
+			mutex.Lock()
+			_, ok := reachabilities[host]
+			reachabilities[host] = nil
+			mutex.Unlock()
+			if ok {
+				continue
+			}
+			wg.Add(1)
+			go func(host string) {
+				defer wg.Done()
+				reachability := s.probeHost(host)
+				mutex.Lock()
+				reachabilities[host] = reachability
+				mutex.Unlock()
+			}(host)
+		}
+	}
+	wg.Wait()
+
+	for _, credential := range credentials {
+		for _, host := range domainHosts[credential.Domain] {
+			credential.Reachability = append(credential.Reachability, reachabilities[host])
+		}
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"fmt"
+	"strings"
+
+	"kubesphere.io/devops/pkg/config"
+	"kubesphere.io/devops/pkg/utils/reflectutils"
+)
+
+// CredentialRolePolicy maps a credential action to the project roles allowed to perform it.
+type CredentialRolePolicy map[string][]string
+
+// defaultCredentialRolePolicy lets developers list credentials and get their metadata to reference them
+// in pipelines, changing credentials and reading their content is kept to owners and maintainers, and
+// changing all the credentials of a domain or of the project at once to owners.
+var defaultCredentialRolePolicy = CredentialRolePolicy{
+	CredentialActionCreate:       {ProjectOwner, ProjectMaintainer},
+	CredentialActionUpdate:       {ProjectOwner, ProjectMaintainer},
+	CredentialActionDelete:       {ProjectOwner, ProjectMaintainer},
+	CredentialActionRotate:       {ProjectOwner, ProjectMaintainer},
+	CredentialActionView:         {ProjectOwner, ProjectMaintainer},
+	CredentialActionList:         {ProjectOwner, ProjectMaintainer, ProjectDeveloper},
+	CredentialActionRenameDomain: {ProjectOwner},
+	CredentialActionDeleteAll:    {ProjectOwner},
+	CredentialActionSync:         {ProjectOwner},
+}
+
+// NewCredentialRolePolicy builds the policy configured by rolesConfig, actions configured with
+// no role keep their default roles.
+func NewCredentialRolePolicy(rolesConfig *config.CredentialRolesConfig) (CredentialRolePolicy, error) {
+	configured := map[string][]string{
+		CredentialActionCreate:       rolesConfig.Create,
+		CredentialActionUpdate:       rolesConfig.Update,
+		CredentialActionDelete:       rolesConfig.Delete,
+		CredentialActionRotate:       rolesConfig.Rotate,
+		CredentialActionView:         rolesConfig.View,
+		CredentialActionList:         rolesConfig.List,
+		CredentialActionRenameDomain: rolesConfig.RenameDomain,
+		CredentialActionDeleteAll:    rolesConfig.DeleteAll,
+		CredentialActionSync:         rolesConfig.Sync,
+	}
+	policy := make(CredentialRolePolicy, len(configured))
+	for action, roles := range configured {
+		policyRoles := make([]string, 0, len(roles))
+		for _, role := range roles {
+			role = strings.TrimSpace(role)
+			if role == "" {
+				continue
+			}
+			if !reflectutils.In(role, AllRoleSlice) {
+				return nil, fmt.Errorf("error role [%s] of credential action [%s] not in %s", role, action,
+					AllRoleSlice)
+			}
+			policyRoles = append(policyRoles, role)
+		}
+		if len(policyRoles) == 0 {
+			policyRoles = defaultCredentialRolePolicy[action]
+		}
+		policy[action] = policyRoles
+	}
+	return policy, nil
+}
+
+// credentialActionRoles returns the roles allowed to perform the credential action, the default
+// policy applies when the service has none.
+func (s *ProjectService) credentialActionRoles(action string) []string {
+	if roles, ok := s.RolePolicy[action]; ok {
+		return roles
+	}
+	return defaultCredentialRolePolicy[action]
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"kubesphere.io/devops/pkg/config"
+)
+
+func TestNewCredentialRolePolicy(t *testing.T) {
+	policy, err := NewCredentialRolePolicy(&config.CredentialRolesConfig{
+		Create:    []string{ProjectOwner, " " + ProjectMaintainer},
+		Delete:    []string{ProjectOwner},
+		List:      []string{""},
+		DeleteAll: []string{ProjectOwner, ProjectMaintainer},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if roles := policy[CredentialActionCreate]; !reflect.DeepEqual(roles, []string{ProjectOwner, ProjectMaintainer}) {
+		t.Fatalf("unexpected create roles %v", roles)
+	}
+	if roles := policy[CredentialActionDelete]; !reflect.DeepEqual(roles, []string{ProjectOwner}) {
+		t.Fatalf("unexpected delete roles %v", roles)
+	}
+	roles := policy[CredentialActionList]
+	if !reflect.DeepEqual(roles, defaultCredentialRolePolicy[CredentialActionList]) {
+		t.Fatalf("expected list to keep its default roles, got %v", roles)
+	}
+	if roles := policy[CredentialActionDeleteAll]; !reflect.DeepEqual(roles, []string{ProjectOwner, ProjectMaintainer}) {
+		t.Fatalf("unexpected delete all roles %v", roles)
+	}
+	if roles := policy[CredentialActionSync]; !reflect.DeepEqual(roles, []string{ProjectOwner}) {
+		t.Fatalf("expected sync to keep its default roles, got %v", roles)
+	}
+
+	_, err = NewCredentialRolePolicy(&config.CredentialRolesConfig{Delete: []string{"admin"}})
+	if err == nil {
+		t.Fatalf("expected an unknown role to be rejected")
+	}
+}
+
+func TestCredentialActionRoles(t *testing.T) {
+	s := &ProjectService{Credentials: NewFakeCredentialStore()}
+	if roles := s.credentialActionRoles(CredentialActionList); !reflect.DeepEqual(roles,
+		[]string{ProjectOwner, ProjectMaintainer, ProjectDeveloper}) {
+		t.Fatalf("unexpected default list roles %v", roles)
+	}
+
+	s.RolePolicy = CredentialRolePolicy{CredentialActionList: {ProjectOwner}}
+	w := httptest.NewRecorder()
+	s.GetCredentialHandler(&recorder{w}, newFakeStoreRequest("alice", ProjectDeveloper,
+		"/projects/project/credentials/git"))
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected developers to be forbidden by the policy, got %d", w.Code)
+	}
+}
+
+func TestCredentialHandlerForbiddenCodes(t *testing.T) {
+	s := &ProjectService{Credentials: NewFakeCredentialStore()}
+	for _, test := range []struct {
+		role string
+		code string
+	}{
+		{role: "", code: ErrorCodeNotProjectMember},
+		{role: ProjectReporter, code: ErrorCodeInsufficientRole},
+	} {
+		w := httptest.NewRecorder()
+		s.GetCredentialHandler(&recorder{w}, newFakeStoreRequest("alice", test.role,
+			"/projects/project/credentials/git"))
+		if w.Code != http.StatusForbidden {
+			t.Fatalf("expected role [%s] to be forbidden, got %d", test.role, w.Code)
+		}
+		credentialErr := &CredentialError{}
+		if err := json.Unmarshal(w.Body.Bytes(), credentialErr); err != nil {
+			t.Fatal(err)
+		}
+		if credentialErr.Code != test.code {
+			t.Fatalf("expected role [%s] to be answered with %s, got %s", test.role, test.code, credentialErr.Code)
+		}
+	}
+}
+
+func TestCredentialHandlersFollowRolePolicy(t *testing.T) {
+	s := &ProjectService{Credentials: NewFakeCredentialStore(), Config: &config.Config{}}
+	s.RolePolicy = CredentialRolePolicy{}
+	for action := range defaultCredentialRolePolicy {
+		s.RolePolicy[action] = []string{ProjectMaintainer}
+	}
+	// owners are allowed every action by the default policy
+	roles := map[string]string{"project": ProjectOwner, "other": ProjectMaintainer}
+	for _, test := range []struct {
+		name    string
+		method  string
+		url     string
+		body    interface{}
+		params  map[string]string
+		handler rest.HandlerFunc
+	}{
+		{name: "apply", method: "POST", url: "/projects/project/credentials/apply",
+			body: []*CredentialRequest{}, handler: s.ApplyCredentialsHandler},
+		{name: "move", method: "POST", url: "/projects/project/credentials/git/move",
+			body: &MoveCredentialRequest{TargetDomain: "github"}, params: map[string]string{"cid": "git"},
+			handler: s.MoveCredentialHandler},
+		{name: "copy", method: "POST", url: "/projects/project/credentials/git/copy",
+			body: &CopyCredentialRequest{TargetProjectId: "other"}, params: map[string]string{"cid": "git"},
+			handler: s.CopyCredentialHandler},
+		{name: "trash", method: "GET", url: "/projects/project/credentials/trash",
+			handler: s.GetCredentialTrashHandler},
+		{name: "restore", method: "POST", url: "/projects/project/credentials/trash/trash-1/restore",
+			params: map[string]string{"tid": "trash-1"}, handler: s.RestoreCredentialHandler},
+		{name: "rename domain", method: "PUT", url: "/projects/project/credentials/domains/github",
+			body: map[string]string{"name": "gitlab"}, params: map[string]string{"domain": "github"},
+			handler: s.RenameCredentialDomainHandler},
+		{name: "delete all", method: "DELETE", url: "/projects/project/credentials",
+			handler: s.DeleteAllCredentialsHandler},
+		{name: "sync", method: "POST", url: "/projects/project/credentials/sync",
+			handler: s.SyncCredentialsHandler},
+	} {
+		w := &recorder{httptest.NewRecorder()}
+		r := newProjectJsonRequest("alice", "project", roles, test.method, test.url, test.body)
+		for name, value := range test.params {
+			r.PathParams[name] = value
+		}
+		test.handler(w, r)
+		if w.Code != http.StatusForbidden {
+			t.Fatalf("%s should follow the role policy and forbid owners, got %d %s", test.name, w.Code,
+				w.Body)
+		}
+	}
+}
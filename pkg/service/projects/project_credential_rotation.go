@@ -0,0 +1,185 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/asaskevich/govalidator"
+
+	"kubesphere.io/devops/pkg/db"
+	"kubesphere.io/devops/pkg/logger"
+	"kubesphere.io/devops/pkg/models"
+	"kubesphere.io/devops/pkg/utils/stringutils"
+	"kubesphere.io/devops/pkg/utils/userutils"
+)
+
+// content field holding the secret rotated for each type, the other types are replaced as a whole
+// by an update: a kubeconfig or an ssh key is its whole content.
+var rotatableCredentialFields = map[string]string{
+	CredentialTypeUsernamePassword: "password",
+	CredentialTypeDockerRegistry:   "password",
+	CredentialTypeSecretText:       "secret",
+}
+
+type RotateCredentialRequest struct {
+	Domain string `json:"domain"`
+	Secret string `json:"secret"`
+}
+
+type RotateCredentialResponse struct {
+	Id         string    `json:"id"`
+	RotateTime time.Time `json:"rotate_time"`
+}
+
+func (s *ProjectService) recordCredentialRotation(projectId, domain, credentialId, operator string) (
+	*models.ProjectCredentialRotation, error) {
+	rotation := models.NewProjectCredentialRotation(projectId, credentialId, domain, operator)
+	_, err := s.Ds.Db.InsertInto(models.ProjectCredentialRotationTableName).
+		Columns(models.ProjectCredentialRotationColumns...).
+		Record(rotation).Exec()
+	return rotation, err
+}
+
+// lastCredentialRotations returns when each credential of the project was last rotated.
+func (s *ProjectService) lastCredentialRotations(projectId string) (map[credentialKey]time.Time, error) {
+	rotations := make([]*models.ProjectCredentialRotation, 0)
+	_, err := s.Ds.Db.Select(models.ProjectCredentialIdColumn, models.ProjectCredentialDomainColumn,
+		fmt.Sprintf("MAX(%s) AS %s", models.ProjectCredentialRotateTimeColumn, models.ProjectCredentialRotateTimeColumn)).
+		From(models.ProjectCredentialRotationTableName).
+		Where(db.Eq(models.ProjectIdColumn, projectId)).
+		GroupBy(models.ProjectCredentialIdColumn, models.ProjectCredentialDomainColumn).Load(&rotations)
+	if err != nil {
+		return nil, err
+	}
+	lastRotations := make(map[credentialKey]time.Time, len(rotations))
+	for _, rotation := range rotations {
+		lastRotations[credentialKey{id: rotation.CredentialId, domain: rotation.Domain}] = rotation.RotateTime
+	}
+	return lastRotations, nil
+}
+
+// credentialChangeTime returns when the credential was last changed, the latest of its creation, its
+// last modification and its last rotation. It is nil for a credential without record nor rotation.
+func credentialChangeTime(credential *CredentialResponse, lastRotations map[credentialKey]time.Time) *time.Time {
+	var changeTime *time.Time
+	for _, t := range []*time.Time{credential.CreateTime, credential.ModifiedTime} {
+		if t != nil && (changeTime == nil || t.After(*changeTime)) {
+			changeTime = t
+		}
+	}
+	if rotateTime, ok := lastRotations[credentialKey{id: credential.Id, domain: credential.Domain}]; ok &&
+		(changeTime == nil || rotateTime.After(*changeTime)) {
+		changeTime = &rotateTime
+	}
+	return changeTime
+}
+
+// RotateCredentialHandler replaces the secret of a credential, keeping its id, domain and other fields.
+func (s *ProjectService) RotateCredentialHandler(w rest.ResponseWriter, r *rest.Request) {
+	request := &RotateCredentialRequest{}
+	projectId := r.PathParams["id"]
+	defer s.invalidateCredentialList(projectId)
+	credentialId := r.PathParams["cid"]
+	operator := userutils.GetUserNameFromRequest(r)
+	err := r.DecodeJsonPayload(request)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	if govalidator.IsNull(request.Domain) {
+		request.Domain = r.URL.Query().Get("domain")
+	}
+	err = s.checkProjectUserInRole(r, operator, projectId, s.credentialActionRoles(CredentialActionRotate))
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
+		return
+	}
+	if govalidator.IsNull(request.Secret) {
+		err := fmt.Errorf("secret should not be empty")
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	target, err := s.credentialTargetOf(projectId, request.Domain, credentialId)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if target.global {
+		err = s.checkGlobalStoreWriter(r, operator, projectId)
+		if err != nil {
+			logger.ErrorContext(r.Context(), "%+v", err)
+			writeCredentialError(w, err, http.StatusForbidden)
+			return
+		}
+	}
+	jenkinsCredential, err := target.get(r.Context(), s.credentialStore(), credentialId)
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeCredentialError(w, err, stringutils.GetJenkinsStatusCode(err))
+		return
+	}
+	credentialType, err := s.getCredentialType(projectId, jenkinsCredential)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	field, ok := rotatableCredentialFields[credentialType]
+	if !ok {
+		err := fmt.Errorf("credential type [%s] can not be rotated, update its whole content instead", credentialType)
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	patch := map[string]interface{}{field: request.Secret}
+	validationContent := map[string]interface{}{"id": credentialId, field: request.Secret}
+	reason, err := s.checkContentValidationWebhook(projectId, operator, CredentialActionUpdate, credentialType,
+		request.Domain, validationContent)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if reason != nil {
+		logger.WarnContext(r.Context(), "%+v", reason)
+		writeCredentialError(w, reason, http.StatusUnprocessableEntity)
+		return
+	}
+	_, err = s.patchCredential(projectId, request.Domain, credentialId, patch)
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeCredentialError(w, err, stringutils.GetJenkinsStatusCode(err))
+		return
+	}
+	s.recordCredentialMutation(projectId, operator, CredentialActionRotate, request.Domain, credentialId, patch)
+	s.markCredentialModified(projectId, request.Domain, credentialId, operator)
+
+	rotation, err := s.recordCredentialRotation(projectId, request.Domain, credentialId, operator)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "failed to record rotation of credential [%s] by [%s]: %+v",
+			credentialId, operator, err)
+	}
+	logger.Info("credential [%s] in project [%s] rotated by [%s]", credentialId, projectId, operator)
+	w.WriteJson(&RotateCredentialResponse{Id: credentialId, RotateTime: rotation.RotateTime})
+	return
+}
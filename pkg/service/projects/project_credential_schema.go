@@ -0,0 +1,125 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ant0ine/go-json-rest/rest"
+)
+
+const jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// timeType is marshaled by encoding/json as an RFC 3339 string, not as the struct it is
+var timeType = reflect.TypeOf(time.Time{})
+
+type JSONSchema struct {
+	Schema     string                 `json:"$schema,omitempty"`
+	Type       string                 `json:"type"`
+	Format     string                 `json:"format,omitempty"`
+	Properties map[string]*JSONSchema `json:"properties,omitempty"`
+	Items      *JSONSchema            `json:"items,omitempty"`
+	Required   []string               `json:"required,omitempty"`
+	Enum       []string               `json:"enum,omitempty"`
+}
+
+type CredentialSchemaResponse struct {
+	Request *JSONSchema            `json:"request"`
+	Types   map[string]*JSONSchema `json:"types"`
+}
+
+// jsonSchemaOf derives the schema of t from its json tags, fields with a `valid:"required"` tag are required.
+func jsonSchemaOf(t reflect.Type) *JSONSchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return &JSONSchema{Type: "string", Format: "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return &JSONSchema{Type: "string"}
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JSONSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &JSONSchema{Type: "array", Items: jsonSchemaOf(t.Elem())}
+	case reflect.Struct:
+		schema := &JSONSchema{Type: "object", Properties: make(map[string]*JSONSchema)}
+		addStructProperties(schema, t)
+		return schema
+	}
+	return &JSONSchema{Type: "object"}
+}
+
+func addStructProperties(schema *JSONSchema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			addStructProperties(schema, field.Type)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = jsonSchemaOf(field.Type)
+		for _, option := range strings.Split(field.Tag.Get("valid"), ",") {
+			if option == "required" {
+				schema.Required = append(schema.Required, name)
+			}
+		}
+	}
+}
+
+// credentialSchemas returns the schema of CredentialRequest and of the content of every credential type,
+// both derived from the request structs so they follow the types supported by the handlers.
+func credentialSchemas() *CredentialSchemaResponse {
+	types := make([]string, 0, len(credentialRequestFactories))
+	contentSchemas := make(map[string]*JSONSchema, len(credentialRequestFactories))
+	for credentialType, factory := range credentialRequestFactories {
+		types = append(types, credentialType)
+		schema := jsonSchemaOf(reflect.TypeOf(factory()))
+		schema.Schema = jsonSchemaDraft
+		if scope, ok := schema.Properties["scope"]; ok {
+			scope.Enum = CredentialScopes
+		}
+		contentSchemas[credentialType] = schema
+	}
+	sort.Strings(types)
+	request := jsonSchemaOf(reflect.TypeOf(CredentialRequest{}))
+	request.Schema = jsonSchemaDraft
+	request.Properties["type"].Enum = types
+	request.Required = []string{"type", "content"}
+	return &CredentialSchemaResponse{Request: request, Types: contentSchemas}
+}
+
+// GetCredentialSchemaHandler returns the JSON schemas of the credential request bodies for client generators.
+func (s *ProjectService) GetCredentialSchemaHandler(w rest.ResponseWriter, r *rest.Request) {
+	w.WriteJson(credentialSchemas())
+	return
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_CredentialSchemas(t *testing.T) {
+	schemas := credentialSchemas()
+	if len(schemas.Types) != len(credentialRequestFactories) {
+		t.Fatalf("every credential type should have a schema, got %d", len(schemas.Types))
+	}
+	ssh := schemas.Types[CredentialTypeSsh]
+	if !reflect.DeepEqual(ssh.Required, []string{"id", "username", "private_key"}) {
+		t.Fatalf("unexpected required fields of ssh %v", ssh.Required)
+	}
+	if ssh.Properties["passphrase"] == nil || ssh.Properties["passphrase"].Type != "string" {
+		t.Fatalf("ssh should have a string passphrase, got %+v", ssh.Properties["passphrase"])
+	}
+	if !reflect.DeepEqual(ssh.Properties["scope"].Enum, CredentialScopes) {
+		t.Fatalf("scope should be one of %v, got %v", CredentialScopes, ssh.Properties["scope"].Enum)
+	}
+	request := schemas.Request
+	if request.Properties["content"].Type != "object" || request.Properties["generate_id"].Type != "boolean" {
+		t.Fatalf("unexpected request schema %+v", request.Properties)
+	}
+	expiresAt := request.Properties["expires_at"]
+	if expiresAt.Type != "string" || expiresAt.Format != "date-time" || expiresAt.Properties != nil {
+		t.Fatalf("expires_at should be a date-time string, got %+v", expiresAt)
+	}
+	if len(request.Properties["type"].Enum) != len(credentialRequestFactories) {
+		t.Fatalf("type should be one of the credential types, got %v", request.Properties["type"].Enum)
+	}
+}
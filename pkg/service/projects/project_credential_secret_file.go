@@ -0,0 +1,50 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/asaskevich/govalidator"
+)
+
+// secret files hold keys or settings of the jobs, anything bigger is not meant for a credential
+const maxSecretFileSize = 1024 * 1024
+
+// validateSecretFile checks content is a base64 encoded file of at most maxSecretFileSize bytes, named
+// fileName without directory.
+func validateSecretFile(fileName, content string) error {
+	if govalidator.IsNull(strings.TrimSpace(fileName)) {
+		return fmt.Errorf("file_name should not be empty")
+	}
+	if strings.ContainsAny(fileName, `/\`) || fileName == "." || fileName == ".." {
+		return fmt.Errorf("file_name [%s] should not be a path", fileName)
+	}
+	if govalidator.IsNull(content) {
+		return fmt.Errorf("content should not be empty")
+	}
+	if base64.StdEncoding.DecodedLen(len(content)) > maxSecretFileSize+2 {
+		return fmt.Errorf("content should not be larger than %d bytes", maxSecretFileSize)
+	}
+	data, err := base64.StdEncoding.DecodeString(content)
+	if err != nil {
+		return fmt.Errorf("content should be base64 encoded: %v", err)
+	}
+	if len(data) > maxSecretFileSize {
+		return fmt.Errorf("content should not be larger than %d bytes", maxSecretFileSize)
+	}
+	return nil
+}
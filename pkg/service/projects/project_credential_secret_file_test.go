@@ -0,0 +1,69 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"kubesphere.io/devops/pkg/models"
+)
+
+func Test_ValidateSecretFile(t *testing.T) {
+	content := base64.StdEncoding.EncodeToString([]byte("registry=https://npm.example.com\n"))
+	if err := validateSecretFile(".npmrc", content); err != nil {
+		t.Fatalf("should not get error %+v", err)
+	}
+
+	for _, file := range [][2]string{
+		{"", content},
+		{"../.npmrc", content},
+		{`conf\.npmrc`, content},
+		{".npmrc", ""},
+		{".npmrc", "not base64!"},
+		{".npmrc", strings.Repeat("A", maxSecretFileSize*2)},
+	} {
+		if err := validateSecretFile(file[0], file[1]); err == nil {
+			t.Fatalf("file [%s] with content [%.20s] should be rejected", file[0], file[1])
+		}
+	}
+}
+
+func TestFormSecretFileContent(t *testing.T) {
+	store := NewFakeCredentialStore()
+	content := base64.StdEncoding.EncodeToString([]byte("registry=https://npm.example.com\n"))
+	_, err := store.CreateSecretFileCredentialInFolder("", "npmrc", ".npmrc", content, "", "", "project")
+	if err != nil {
+		t.Fatalf("should not get error %+v", err)
+	}
+
+	page, err := store.GetCredentialContentInFolder("", "npmrc", "project")
+	if err != nil {
+		t.Fatalf("should not get error %+v", err)
+	}
+	form, err := newCredentialForm(page)
+	if err != nil {
+		t.Fatalf("should not get error %+v", err)
+	}
+	credential, _ := store.GetCredentialInFolder("", "npmrc", "project")
+	secretFile := formCredentialContent(form, CredentialTypeSecretFile, credential,
+		&models.ProjectCredential{}).(*SecretFileCredentialRequest)
+	if err := form.err(""); err != nil {
+		t.Fatalf("should not get error %+v", err)
+	}
+	if secretFile.FileName != ".npmrc" || secretFile.Content != "" {
+		t.Fatalf("only the file name should be shown, got %+v", secretFile)
+	}
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"crypto/x509"
+	"fmt"
+	"strings"
+
+	"github.com/asaskevich/govalidator"
+	"golang.org/x/crypto/ssh"
+
+	"kubesphere.io/devops/pkg/gojenkins"
+	"kubesphere.io/devops/pkg/utils/certutils"
+)
+
+const (
+	ErrorInvalidPrivateKey  = "invalid private key"
+	ErrorPassphraseMismatch = "passphrase mismatch"
+)
+
+// validateSshPrivateKey checks privateKey is a private key Jenkins can use, and that passphrase decrypts it
+// when it is encrypted, in the legacy PEM format as well as in the openssh format. A passphrase given for a
+// clear key is accepted, Jenkins ignores it.
+func validateSshPrivateKey(privateKey, passphrase string) (*CredentialKeySize, error) {
+	key, err := ssh.ParseRawPrivateKey([]byte(privateKey))
+	if err == nil {
+		return sshKeySize(key), nil
+	}
+	if _, encrypted := err.(*ssh.PassphraseMissingError); !encrypted {
+		return nil, fmt.Errorf("%s: %v", ErrorInvalidPrivateKey, err)
+	}
+	if passphrase == "" {
+		return nil, fmt.Errorf("%s: the private key is encrypted, a passphrase is required", ErrorPassphraseMismatch)
+	}
+	key, err = ssh.ParseRawPrivateKeyWithPassphrase([]byte(privateKey), []byte(passphrase))
+	if err == x509.IncorrectPasswordError {
+		return nil, fmt.Errorf("%s: the passphrase does not decrypt the private key", ErrorPassphraseMismatch)
+	}
+	// legacy PEM encryption has no integrity check, a wrong passphrase may only show as a key failing to parse
+	if err != nil {
+		return nil, fmt.Errorf("%s: the passphrase does not decrypt the private key: %v", ErrorPassphraseMismatch, err)
+	}
+	return sshKeySize(key), nil
+}
+
+func sshKeySize(key interface{}) *CredentialKeySize {
+	algorithm, bits := certutils.KeySize(key)
+	if govalidator.IsNull(algorithm) {
+		return nil
+	}
+	return &CredentialKeySize{Algorithm: algorithm, Bits: bits}
+}
+
+// validateSshPrivateKeys checks every key of privateKey with validateSshPrivateKey, the keys of a
+// credential share its passphrase. It returns the smallest of the keys.
+func validateSshPrivateKeys(privateKey, passphrase string) (*CredentialKeySize, error) {
+	privateKeys := gojenkins.SplitPrivateKeys(privateKey)
+	if len(privateKeys) <= 1 {
+		return validateSshPrivateKey(privateKey, passphrase)
+	}
+	var weakest *CredentialKeySize
+	for i, privateKey := range privateKeys {
+		key, err := validateSshPrivateKey(privateKey, passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("private key [%d]: %v", i, err)
+		}
+		weakest = weakerKeySize(weakest, key)
+	}
+	return weakest, nil
+}
+
+// normalizeSshPrivateKeys joins the private_keys of a credential content into its private_key,
+// the keys are concatenated as Jenkins has a single private key text per credential, see
+// gojenkins.NewDirectEntryPrivateKeySource. private_key and private_keys can not be given together.
+func normalizeSshPrivateKeys(content map[string]interface{}) error {
+	value, ok := content["private_keys"]
+	if !ok || value == nil {
+		return nil
+	}
+	if privateKey, _ := content["private_key"].(string); !govalidator.IsNull(privateKey) {
+		return fmt.Errorf("error private_key and private_keys can not be given together")
+	}
+	var values []interface{}
+	switch value := value.(type) {
+	case []interface{}:
+		values = value
+	case []string:
+		for _, privateKey := range value {
+			values = append(values, privateKey)
+		}
+	default:
+		return fmt.Errorf("error private_keys should be a list of private keys")
+	}
+	privateKeys := make([]string, 0, len(values))
+	for _, value := range values {
+		privateKey, ok := value.(string)
+		if !ok || govalidator.IsNull(strings.TrimSpace(privateKey)) {
+			return fmt.Errorf("error private_keys should be a list of private keys")
+		}
+		privateKeys = append(privateKeys, privateKey)
+	}
+	if len(privateKeys) == 0 {
+		return fmt.Errorf("error private_keys should not be empty")
+	}
+	delete(content, "private_keys")
+	content["private_key"] = gojenkins.JoinPrivateKeys(privateKeys)
+	return nil
+}
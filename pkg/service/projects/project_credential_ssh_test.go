@@ -0,0 +1,180 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"kubesphere.io/devops/pkg/gojenkins"
+)
+
+func newTestRsaKey(t *testing.T, passphrase string) string {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("should not get error %+v", err)
+	}
+	block := &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+	if passphrase != "" {
+		block, err = x509.EncryptPEMBlock(rand.Reader, block.Type, block.Bytes, []byte(passphrase),
+			x509.PEMCipherAES256)
+		if err != nil {
+			t.Fatalf("should not get error %+v", err)
+		}
+	}
+	return string(pem.EncodeToMemory(block))
+}
+
+// readTestKey reads a key generated by ssh-keygen from testdata, the encrypted ones have the passphrase "secret".
+func readTestKey(t *testing.T, name string) string {
+	key, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("should not get error %+v", err)
+	}
+	return string(key)
+}
+
+func Test_ValidateSshPrivateKey(t *testing.T) {
+	rsaKey := readTestKey(t, "ssh_rsa.pem")
+	encryptedRsaKey := readTestKey(t, "ssh_rsa_encrypted.pem")
+	ed25519Key := readTestKey(t, "ssh_ed25519.key")
+	encryptedEd25519Key := readTestKey(t, "ssh_ed25519_encrypted.key")
+
+	for _, test := range []struct {
+		name       string
+		privateKey string
+		passphrase string
+		expected   string
+	}{
+		{name: "rsa", privateKey: rsaKey},
+		{name: "ed25519", privateKey: ed25519Key},
+		{name: "generated rsa", privateKey: newTestRsaKey(t, "")},
+		{name: "encrypted rsa", privateKey: encryptedRsaKey, passphrase: "secret"},
+		{name: "encrypted ed25519", privateKey: encryptedEd25519Key, passphrase: "secret"},
+		{name: "passphrase of clear rsa key", privateKey: rsaKey, passphrase: "secret"},
+		{name: "passphrase of clear ed25519 key", privateKey: ed25519Key, passphrase: "secret"},
+		{name: "garbage", privateKey: "not a key", expected: ErrorInvalidPrivateKey},
+		{
+			name:       "truncated rsa",
+			privateKey: strings.Replace(rsaKey, "\n", "", 3),
+			expected:   ErrorInvalidPrivateKey,
+		},
+		{
+			name:       "unsupported block",
+			privateKey: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("x")})),
+			expected:   ErrorInvalidPrivateKey,
+		},
+		{name: "wrong rsa passphrase", privateKey: encryptedRsaKey, passphrase: "other", expected: ErrorPassphraseMismatch},
+		{
+			name:       "wrong ed25519 passphrase",
+			privateKey: encryptedEd25519Key,
+			passphrase: "other",
+			expected:   ErrorPassphraseMismatch,
+		},
+		{name: "missing passphrase", privateKey: encryptedRsaKey, expected: ErrorPassphraseMismatch},
+		{name: "missing openssh passphrase", privateKey: encryptedEd25519Key, expected: ErrorPassphraseMismatch},
+	} {
+		_, err := validateSshPrivateKey(test.privateKey, test.passphrase)
+		if test.expected == "" {
+			if err != nil {
+				t.Fatalf("%s: should not get error %+v", test.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.HasPrefix(err.Error(), test.expected) {
+			t.Fatalf("%s: error [%v] should start with [%s]", test.name, err, test.expected)
+		}
+	}
+}
+
+func Test_ValidateSshPrivateKeysKeySize(t *testing.T) {
+	for _, test := range []struct {
+		name       string
+		privateKey string
+		passphrase string
+		expected   CredentialKeySize
+	}{
+		{name: "rsa", privateKey: readTestKey(t, "ssh_rsa.pem"), expected: CredentialKeySize{"rsa", 2048}},
+		{
+			name:       "ed25519 is stronger than rsa",
+			privateKey: readTestKey(t, "ssh_rsa.pem") + readTestKey(t, "ssh_ed25519.key"),
+			expected:   CredentialKeySize{"rsa", 2048},
+		},
+		{
+			name:       "encrypted ed25519",
+			privateKey: readTestKey(t, "ssh_ed25519_encrypted.key"),
+			passphrase: "secret",
+			expected:   CredentialKeySize{"ed25519", 256},
+		},
+		{
+			name:       "weakest of several keys",
+			privateKey: readTestKey(t, "ssh_rsa.pem") + newTestRsaKey(t, ""),
+			expected:   CredentialKeySize{"rsa", 1024},
+		},
+	} {
+		key, err := validateSshPrivateKeys(test.privateKey, test.passphrase)
+		if err != nil {
+			t.Fatalf("%s: should not get error %+v", test.name, err)
+		}
+		if key == nil || *key != test.expected {
+			t.Fatalf("%s: key should be %+v, got %+v", test.name, test.expected, key)
+		}
+	}
+}
+
+func Test_NormalizeSshPrivateKeys(t *testing.T) {
+	firstKey := newTestRsaKey(t, "")
+	secondKey := newTestRsaKey(t, "")
+
+	content := map[string]interface{}{"id": "deploy", "private_keys": []interface{}{firstKey, secondKey}}
+	if err := normalizeSshPrivateKeys(content); err != nil {
+		t.Fatalf("should not get error %+v", err)
+	}
+	if _, ok := content["private_keys"]; ok {
+		t.Fatalf("private_keys should be joined into private_key")
+	}
+	privateKey := content["private_key"].(string)
+	if keys := gojenkins.SplitPrivateKeys(privateKey); len(keys) != 2 ||
+		strings.TrimSpace(keys[0]) != strings.TrimSpace(firstKey) ||
+		strings.TrimSpace(keys[1]) != strings.TrimSpace(secondKey) {
+		t.Fatalf("joined private key should split back into both keys, got %v", keys)
+	}
+	if _, err := validateSshPrivateKeys(privateKey, ""); err != nil {
+		t.Fatalf("should not get error %+v", err)
+	}
+	if _, err := validateSshPrivateKeys(privateKey+newTestRsaKey(t, "secret"), ""); err == nil ||
+		!strings.Contains(err.Error(), ErrorPassphraseMismatch) {
+		t.Fatalf("an encrypted key among the keys should need the passphrase, got %v", err)
+	}
+	if keys := gojenkins.SplitPrivateKeys(firstKey); len(keys) != 1 || keys[0] != firstKey {
+		t.Fatalf("a single key should split into itself, got %v", keys)
+	}
+
+	for _, content := range []map[string]interface{}{
+		{"private_keys": []interface{}{}},
+		{"private_keys": []interface{}{firstKey, 1}},
+		{"private_keys": firstKey},
+		{"private_keys": []interface{}{firstKey}, "private_key": secondKey},
+	} {
+		if err := normalizeSshPrivateKeys(content); err == nil {
+			t.Fatalf("private_keys of %v should be invalid", content)
+		}
+	}
+}
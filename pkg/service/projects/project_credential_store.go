@@ -0,0 +1,172 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"context"
+
+	"kubesphere.io/devops/pkg/gojenkins"
+)
+
+// CredentialStore creates, updates, deletes and reads the credentials of project folders, and of the
+// system store for the credentials created in the global store.
+// *gojenkins.Jenkins is the store of the service, FakeCredentialStore keeps credentials in memory
+// so handlers can be tested without Jenkins.
+type CredentialStore interface {
+	CreateSshCredentialInFolder(domain, id, username, passphrase, privateKey, description, scope string,
+		folders ...string) (*string, error)
+	CreateSshCredentialInFolderContext(ctx context.Context, domain, id, username, passphrase, privateKey,
+		description, scope string, folders ...string) (*string, error)
+	CreateUsernamePasswordCredentialInFolder(domain, id, username, password, description, scope string,
+		folders ...string) (*string, error)
+	CreateUsernamePasswordCredentialInFolderContext(ctx context.Context, domain, id, username, password,
+		description, scope string, folders ...string) (*string, error)
+	CreateSecretTextCredentialInFolder(domain, id, secret, description, scope string,
+		folders ...string) (*string, error)
+	CreateSecretTextCredentialInFolderContext(ctx context.Context, domain, id, secret, description, scope string,
+		folders ...string) (*string, error)
+	CreateKubeconfigCredentialInFolder(domain, id, content, description, scope string,
+		folders ...string) (*string, error)
+	CreateKubeconfigCredentialInFolderContext(ctx context.Context, domain, id, content, description, scope string,
+		folders ...string) (*string, error)
+	CreateCertificateCredentialInFolder(domain, id, keystore, password, description, scope string,
+		folders ...string) (*string, error)
+	CreateCertificateCredentialInFolderContext(ctx context.Context, domain, id, keystore, password, description,
+		scope string, folders ...string) (*string, error)
+	CreateAWSCredentialInFolder(domain, id, accessKey, secretKey, iamRoleArn, description, scope string,
+		folders ...string) (*string, error)
+	CreateAWSCredentialInFolderContext(ctx context.Context, domain, id, accessKey, secretKey, iamRoleArn,
+		description, scope string, folders ...string) (*string, error)
+
+	CreateSecretFileCredentialInFolder(domain, id, fileName, content, description, scope string,
+		folders ...string) (*string, error)
+	CreateSecretFileCredentialInFolderContext(ctx context.Context, domain, id, fileName, content, description,
+		scope string, folders ...string) (*string, error)
+
+	CreateOpenShiftTokenCredentialInFolder(domain, id, token, description, scope string,
+		folders ...string) (*string, error)
+	CreateOpenShiftTokenCredentialInFolderContext(ctx context.Context, domain, id, token, description, scope string,
+		folders ...string) (*string, error)
+
+	UpdateSshCredentialInFolder(domain, id, username, passphrase, privateKey, description, scope string,
+		folders ...string) (*string, error)
+	UpdateSshCredentialInFolderContext(ctx context.Context, domain, id, username, passphrase, privateKey,
+		description, scope string, folders ...string) (*string, error)
+	UpdateUsernamePasswordCredentialInFolder(domain, id, username, password, description, scope string,
+		folders ...string) (*string, error)
+	UpdateUsernamePasswordCredentialInFolderContext(ctx context.Context, domain, id, username, password,
+		description, scope string, folders ...string) (*string, error)
+	UpdateSecretTextCredentialInFolder(domain, id, secret, description, scope string,
+		folders ...string) (*string, error)
+	UpdateSecretTextCredentialInFolderContext(ctx context.Context, domain, id, secret, description, scope string,
+		folders ...string) (*string, error)
+	UpdateKubeconfigCredentialInFolder(domain, id, content, description, scope string,
+		folders ...string) (*string, error)
+	UpdateKubeconfigCredentialInFolderContext(ctx context.Context, domain, id, content, description, scope string,
+		folders ...string) (*string, error)
+	UpdateCertificateCredentialInFolder(domain, id, keystore, password, description, scope string,
+		folders ...string) (*string, error)
+	UpdateCertificateCredentialInFolderContext(ctx context.Context, domain, id, keystore, password, description,
+		scope string, folders ...string) (*string, error)
+	UpdateAWSCredentialInFolder(domain, id, accessKey, secretKey, iamRoleArn, description, scope string,
+		folders ...string) (*string, error)
+	UpdateAWSCredentialInFolderContext(ctx context.Context, domain, id, accessKey, secretKey, iamRoleArn,
+		description, scope string, folders ...string) (*string, error)
+
+	UpdateSecretFileCredentialInFolder(domain, id, fileName, content, description, scope string,
+		folders ...string) (*string, error)
+	UpdateSecretFileCredentialInFolderContext(ctx context.Context, domain, id, fileName, content, description,
+		scope string, folders ...string) (*string, error)
+
+	UpdateOpenShiftTokenCredentialInFolder(domain, id, token, description, scope string,
+		folders ...string) (*string, error)
+	UpdateOpenShiftTokenCredentialInFolderContext(ctx context.Context, domain, id, token, description, scope string,
+		folders ...string) (*string, error)
+
+	// CopySshCredentialInFolder creates newId with the content of the ssh credential sourceId
+	CopySshCredentialInFolder(domain, sourceId, newId string, folders ...string) (*string, error)
+
+	DeleteCredentialInFolder(domain, id string, folders ...string) (*string, error)
+	DeleteCredentialInFolderContext(ctx context.Context, domain, id string, folders ...string) (*string, error)
+
+	GetCredentialInFolder(domain, id string, folders ...string) (*gojenkins.CredentialResponse, error)
+	GetCredentialInFolderContext(ctx context.Context, domain, id string,
+		folders ...string) (*gojenkins.CredentialResponse, error)
+	GetCredentialContentInFolder(domain, id string, folders ...string) (string, error)
+	GetCredentialContentInFolderContext(ctx context.Context, domain, id string, folders ...string) (string, error)
+	GetCredentialConfigInFolder(domain, id string, folders ...string) (string, error)
+	// CreateCredentialFromConfigInFolder creates a credential from its config.xml, the secrets encrypted by
+	// Jenkins in it are accepted as they are
+	CreateCredentialFromConfigInFolder(domain, config string, folders ...string) error
+	// GetCredentialsInFolder lists the credentials of a domain, an empty domain lists all domains
+	GetCredentialsInFolder(domain string, folders ...string) ([]*gojenkins.CredentialResponse, error)
+
+	// GetCredentialDomainsInFolder returns the domains of the folder store, the default domain "_" first
+	GetCredentialDomainsInFolder(folders ...string) ([]string, error)
+	CreateCredentialDomainInFolder(domain, description, scope string, folders ...string) (*string, error)
+	DeleteCredentialDomainInFolder(domain string, folders ...string) (*string, error)
+	// RenameCredentialDomainInFolder moves the credentials of domain to newDomain, the ids moved are returned
+	// also when the rename fails part way
+	RenameCredentialDomainInFolder(domain, newDomain string, folders ...string) ([]string, error)
+
+	CreateSshCredentialInSystem(domain, id, username, passphrase, privateKey, description,
+		scope string) (*string, error)
+	CreateUsernamePasswordCredentialInSystem(domain, id, username, password, description,
+		scope string) (*string, error)
+	CreateSecretTextCredentialInSystem(domain, id, secret, description, scope string) (*string, error)
+	CreateKubeconfigCredentialInSystem(domain, id, content, description, scope string) (*string, error)
+	CreateCertificateCredentialInSystem(domain, id, keystore, password, description,
+		scope string) (*string, error)
+	CreateAWSCredentialInSystem(domain, id, accessKey, secretKey, iamRoleArn, description,
+		scope string) (*string, error)
+	CreateSecretFileCredentialInSystem(domain, id, fileName, content, description,
+		scope string) (*string, error)
+	CreateOpenShiftTokenCredentialInSystem(domain, id, token, description, scope string) (*string, error)
+	UpdateSshCredentialInSystem(domain, id, username, passphrase, privateKey, description,
+		scope string) (*string, error)
+	UpdateUsernamePasswordCredentialInSystem(domain, id, username, password, description,
+		scope string) (*string, error)
+	UpdateSecretTextCredentialInSystem(domain, id, secret, description, scope string) (*string, error)
+	UpdateKubeconfigCredentialInSystem(domain, id, content, description, scope string) (*string, error)
+	UpdateCertificateCredentialInSystem(domain, id, keystore, password, description,
+		scope string) (*string, error)
+	UpdateAWSCredentialInSystem(domain, id, accessKey, secretKey, iamRoleArn, description,
+		scope string) (*string, error)
+	UpdateSecretFileCredentialInSystem(domain, id, fileName, content, description,
+		scope string) (*string, error)
+	UpdateOpenShiftTokenCredentialInSystem(domain, id, token, description, scope string) (*string, error)
+	DeleteCredentialInSystem(domain, id string) (*string, error)
+	GetCredentialInSystem(domain, id string) (*gojenkins.CredentialResponse, error)
+	GetCredentialContentInSystem(domain, id string) (string, error)
+	GetCredentialConfigInSystem(domain, id string) (string, error)
+	GetCredentialsInSystem(domain string) ([]*gojenkins.CredentialResponse, error)
+
+	// jobs are read to resolve the jobs credentials are used by
+	GetAllJobNames() ([]gojenkins.InnerJob, error)
+	GetFolder(id string, parents ...string) (*gojenkins.Folder, error)
+	GetJob(id string, parentIDs ...string) (*gojenkins.Job, error)
+
+	// GetVersion returns the version of Jenkins, empty when it is not known
+	GetVersion() string
+}
+
+var _ CredentialStore = &gojenkins.Jenkins{}
+
+// credentialStore returns the store of the service, Jenkins unless another store is set.
+func (s *ProjectService) credentialStore() CredentialStore {
+	if s.Credentials != nil {
+		return s.Credentials
+	}
+	return s.Ds.Jenkins
+}
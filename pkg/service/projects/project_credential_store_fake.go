@@ -0,0 +1,865 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"kubesphere.io/devops/pkg/gojenkins"
+)
+
+// FakeCredentialStore is an in-memory CredentialStore for tests. It answers like Jenkins does, with the
+// typed errors of gojenkins, and the content is the html of the update page with its secrets encrypted.
+type FakeCredentialStore struct {
+	sync.Mutex
+	credentials map[string]*fakeCredential
+	// domains holds the folder/domain keys of the domains created, with a credential or on their own
+	domains map[string]bool
+	// jobs holds the jobs by full name, the folders of their parents exist implicitly
+	jobs map[string]*gojenkins.JobResponse
+	// Version is returned as the Jenkins version
+	Version string
+}
+
+type fakeCredential struct {
+	folder   string
+	response gojenkins.CredentialResponse
+	scope    string
+	fields   [][2]string
+}
+
+var _ CredentialStore = &FakeCredentialStore{}
+
+// fakeSecretFields are rendered as password inputs.
+var fakeSecretFields = map[string]bool{"password": true, "passphrase": true, "secret": true, "secretKey": true,
+	"secretBytes": true}
+
+// fakeEncryptedFields are rendered encrypted like Jenkins renders its secrets, values given encrypted are
+// decrypted again like Jenkins accepts its own ciphertext back.
+var fakeEncryptedFields = map[string]bool{"password": true, "passphrase": true, "secret": true, "secretKey": true,
+	"privateKey": true, "token": true}
+
+const fakeCiphertextPrefix = "fake:"
+
+func fakeEncryptSecret(value string) string {
+	if value == "" {
+		return ""
+	}
+	return "{" + base64.StdEncoding.EncodeToString([]byte(fakeCiphertextPrefix+value)) + "}"
+}
+
+func fakeDecryptSecret(value string) string {
+	if !strings.HasPrefix(value, "{") || !strings.HasSuffix(value, "}") {
+		return value
+	}
+	plaintext, err := base64.StdEncoding.DecodeString(value[1 : len(value)-1])
+	if err != nil || !strings.HasPrefix(string(plaintext), fakeCiphertextPrefix) {
+		return value
+	}
+	return strings.TrimPrefix(string(plaintext), fakeCiphertextPrefix)
+}
+
+func NewFakeCredentialStore() *FakeCredentialStore {
+	return &FakeCredentialStore{credentials: make(map[string]*fakeCredential), domains: make(map[string]bool),
+		jobs: make(map[string]*gojenkins.JobResponse)}
+}
+
+// fakeSystemFolder keeps the credentials of the system store, no folder path starts with a slash.
+const fakeSystemFolder = "/system"
+
+func fakeCredentialKey(folder, domain, id string) string {
+	return folder + "/" + domain + "/" + id
+}
+
+func fakeDomainKey(folder, domain string) string {
+	return folder + "/" + domain
+}
+
+// fakeStatusError is the error Jenkins answers a request failing with status with.
+func fakeStatusError(status int) error {
+	return errors.New(strconv.Itoa(status))
+}
+
+func fakeCredentialFolder(folders []string) (string, error) {
+	if len(folders) == 0 {
+		return "", fmt.Errorf("folder name shoud not be nil")
+	}
+	return strings.Join(folders, "/"), nil
+}
+
+func (f *FakeCredentialStore) put(create bool, domain, id, typeName, description, scope string, fields [][2]string,
+	folders []string) (*string, error) {
+	folder, err := fakeCredentialFolder(folders)
+	if err != nil {
+		return nil, err
+	}
+	return f.putInFolder(create, folder, domain, id, typeName, description, scope, fields)
+}
+
+func (f *FakeCredentialStore) putInFolder(create bool, folder, domain, id, typeName, description, scope string,
+	fields [][2]string) (*string, error) {
+	if domain == "" {
+		domain = "_"
+	}
+	if scope == "" {
+		scope = gojenkins.GLOBALScope
+	}
+	f.Lock()
+	defer f.Unlock()
+	key := fakeCredentialKey(folder, domain, id)
+	_, exists := f.credentials[key]
+	if create && exists {
+		return nil, gojenkins.ErrCredentialExists
+	}
+	if !create && !exists {
+		return nil, gojenkins.ErrCredentialNotFound
+	}
+	decrypted := make([][2]string, 0, len(fields))
+	for _, field := range fields {
+		if fakeEncryptedFields[field[0]] {
+			field[1] = fakeDecryptSecret(field[1])
+		}
+		decrypted = append(decrypted, field)
+	}
+	f.credentials[key] = &fakeCredential{
+		folder: folder,
+		response: gojenkins.CredentialResponse{
+			Id:          id,
+			TypeName:    typeName,
+			DisplayName: id,
+			Description: description,
+			Domain:      domain,
+		},
+		scope:  scope,
+		fields: decrypted,
+	}
+	f.domains[fakeDomainKey(folder, domain)] = true
+	return &id, nil
+}
+
+func (f *FakeCredentialStore) get(domain, id string, folders []string) (*fakeCredential, error) {
+	folder, err := fakeCredentialFolder(folders)
+	if err != nil {
+		return nil, err
+	}
+	return f.getInFolder(folder, domain, id)
+}
+
+func (f *FakeCredentialStore) getInFolder(folder, domain, id string) (*fakeCredential, error) {
+	if domain == "" {
+		domain = "_"
+	}
+	f.Lock()
+	defer f.Unlock()
+	credential, ok := f.credentials[fakeCredentialKey(folder, domain, id)]
+	if !ok {
+		return nil, gojenkins.ErrCredentialNotFound
+	}
+	return credential, nil
+}
+
+func (f *FakeCredentialStore) CreateSshCredentialInFolder(domain, id, username, passphrase, privateKey, description,
+	scope string, folders ...string) (*string, error) {
+	return f.CreateSshCredentialInFolderContext(context.Background(), domain, id, username, passphrase, privateKey,
+		description, scope, folders...)
+}
+
+func (f *FakeCredentialStore) CreateSshCredentialInFolderContext(ctx context.Context, domain, id, username,
+	passphrase, privateKey, description, scope string, folders ...string) (*string, error) {
+	return f.put(true, domain, id, "SSH Username with private key", description, scope,
+		[][2]string{{"username", username}, {"passphrase", passphrase}, {"privateKey", privateKey}}, folders)
+}
+
+func (f *FakeCredentialStore) CopySshCredentialInFolder(domain, sourceId, newId string,
+	folders ...string) (*string, error) {
+	source, err := f.get(domain, sourceId, folders)
+	if err != nil {
+		return nil, err
+	}
+	if source.response.TypeName != "SSH Username with private key" {
+		return nil, fmt.Errorf("credential [%s] is not an ssh credential", sourceId)
+	}
+	return f.putInFolder(true, source.folder, domain, newId, source.response.TypeName,
+		source.response.Description, source.scope, source.fields)
+}
+
+func (f *FakeCredentialStore) CreateUsernamePasswordCredentialInFolder(domain, id, username, password, description,
+	scope string, folders ...string) (*string, error) {
+	return f.CreateUsernamePasswordCredentialInFolderContext(context.Background(), domain, id, username, password,
+		description, scope, folders...)
+}
+
+func (f *FakeCredentialStore) CreateUsernamePasswordCredentialInFolderContext(ctx context.Context, domain, id,
+	username, password, description, scope string, folders ...string) (*string, error) {
+	return f.put(true, domain, id, "Username with password", description, scope,
+		[][2]string{{"username", username}, {"password", password}}, folders)
+}
+
+func (f *FakeCredentialStore) CreateSecretTextCredentialInFolder(domain, id, secret, description, scope string,
+	folders ...string) (*string, error) {
+	return f.CreateSecretTextCredentialInFolderContext(context.Background(), domain, id, secret, description, scope,
+		folders...)
+}
+
+func (f *FakeCredentialStore) CreateSecretTextCredentialInFolderContext(ctx context.Context, domain, id, secret,
+	description, scope string, folders ...string) (*string, error) {
+	return f.put(true, domain, id, "Secret text", description, scope, [][2]string{{"secret", secret}}, folders)
+}
+
+func (f *FakeCredentialStore) CreateKubeconfigCredentialInFolder(domain, id, content, description, scope string,
+	folders ...string) (*string, error) {
+	return f.CreateKubeconfigCredentialInFolderContext(context.Background(), domain, id, content, description, scope,
+		folders...)
+}
+
+func (f *FakeCredentialStore) CreateKubeconfigCredentialInFolderContext(ctx context.Context, domain, id, content,
+	description, scope string, folders ...string) (*string, error) {
+	return f.put(true, domain, id, "Kubernetes configuration (kubeconfig)", description, scope,
+		[][2]string{{"content", content}}, folders)
+}
+
+func (f *FakeCredentialStore) CreateCertificateCredentialInFolder(domain, id, keystore, password, description,
+	scope string, folders ...string) (*string, error) {
+	return f.CreateCertificateCredentialInFolderContext(context.Background(), domain, id, keystore, password,
+		description, scope, folders...)
+}
+
+func (f *FakeCredentialStore) CreateCertificateCredentialInFolderContext(ctx context.Context, domain, id, keystore,
+	password, description, scope string, folders ...string) (*string, error) {
+	return f.put(true, domain, id, "Certificate", description, scope,
+		[][2]string{{"keystore", keystore}, {"password", password}}, folders)
+}
+
+func (f *FakeCredentialStore) CreateAWSCredentialInFolder(domain, id, accessKey, secretKey, iamRoleArn, description,
+	scope string, folders ...string) (*string, error) {
+	return f.CreateAWSCredentialInFolderContext(context.Background(), domain, id, accessKey, secretKey, iamRoleArn,
+		description, scope, folders...)
+}
+
+func (f *FakeCredentialStore) CreateAWSCredentialInFolderContext(ctx context.Context, domain, id, accessKey,
+	secretKey, iamRoleArn, description, scope string, folders ...string) (*string, error) {
+	return f.put(true, domain, id, "AWS Credentials", description, scope,
+		[][2]string{{"accessKey", accessKey}, {"secretKey", secretKey}, {"iamRoleArn", iamRoleArn}}, folders)
+}
+
+func (f *FakeCredentialStore) CreateSecretFileCredentialInFolder(domain, id, fileName, content, description,
+	scope string, folders ...string) (*string, error) {
+	return f.CreateSecretFileCredentialInFolderContext(context.Background(), domain, id, fileName, content,
+		description, scope, folders...)
+}
+
+func (f *FakeCredentialStore) CreateSecretFileCredentialInFolderContext(ctx context.Context, domain, id, fileName,
+	content, description, scope string, folders ...string) (*string, error) {
+	return f.put(true, domain, id, "Secret file", description, scope,
+		[][2]string{{"fileName", fileName}, {"secretBytes", content}}, folders)
+}
+
+func (f *FakeCredentialStore) CreateOpenShiftTokenCredentialInFolder(domain, id, token, description, scope string,
+	folders ...string) (*string, error) {
+	return f.CreateOpenShiftTokenCredentialInFolderContext(context.Background(), domain, id, token, description,
+		scope, folders...)
+}
+
+func (f *FakeCredentialStore) CreateOpenShiftTokenCredentialInFolderContext(ctx context.Context, domain, id, token,
+	description, scope string, folders ...string) (*string, error) {
+	return f.put(true, domain, id, "OpenShift Token for OpenShift Client Plugin", description, scope,
+		[][2]string{{"secret", token}}, folders)
+}
+
+func (f *FakeCredentialStore) UpdateSshCredentialInFolder(domain, id, username, passphrase, privateKey, description,
+	scope string, folders ...string) (*string, error) {
+	return f.UpdateSshCredentialInFolderContext(context.Background(), domain, id, username, passphrase, privateKey,
+		description, scope, folders...)
+}
+
+func (f *FakeCredentialStore) UpdateSshCredentialInFolderContext(ctx context.Context, domain, id, username,
+	passphrase, privateKey, description, scope string, folders ...string) (*string, error) {
+	return f.put(false, domain, id, "SSH Username with private key", description, scope,
+		[][2]string{{"username", username}, {"passphrase", passphrase}, {"privateKey", privateKey}}, folders)
+}
+
+func (f *FakeCredentialStore) UpdateUsernamePasswordCredentialInFolder(domain, id, username, password, description,
+	scope string, folders ...string) (*string, error) {
+	return f.UpdateUsernamePasswordCredentialInFolderContext(context.Background(), domain, id, username, password,
+		description, scope, folders...)
+}
+
+func (f *FakeCredentialStore) UpdateUsernamePasswordCredentialInFolderContext(ctx context.Context, domain, id,
+	username, password, description, scope string, folders ...string) (*string, error) {
+	return f.put(false, domain, id, "Username with password", description, scope,
+		[][2]string{{"username", username}, {"password", password}}, folders)
+}
+
+func (f *FakeCredentialStore) UpdateSecretTextCredentialInFolder(domain, id, secret, description, scope string,
+	folders ...string) (*string, error) {
+	return f.UpdateSecretTextCredentialInFolderContext(context.Background(), domain, id, secret, description, scope,
+		folders...)
+}
+
+func (f *FakeCredentialStore) UpdateSecretTextCredentialInFolderContext(ctx context.Context, domain, id, secret,
+	description, scope string, folders ...string) (*string, error) {
+	return f.put(false, domain, id, "Secret text", description, scope, [][2]string{{"secret", secret}}, folders)
+}
+
+func (f *FakeCredentialStore) UpdateKubeconfigCredentialInFolder(domain, id, content, description, scope string,
+	folders ...string) (*string, error) {
+	return f.UpdateKubeconfigCredentialInFolderContext(context.Background(), domain, id, content, description, scope,
+		folders...)
+}
+
+func (f *FakeCredentialStore) UpdateKubeconfigCredentialInFolderContext(ctx context.Context, domain, id, content,
+	description, scope string, folders ...string) (*string, error) {
+	return f.put(false, domain, id, "Kubernetes configuration (kubeconfig)", description, scope,
+		[][2]string{{"content", content}}, folders)
+}
+
+func (f *FakeCredentialStore) UpdateCertificateCredentialInFolder(domain, id, keystore, password, description,
+	scope string, folders ...string) (*string, error) {
+	return f.UpdateCertificateCredentialInFolderContext(context.Background(), domain, id, keystore, password,
+		description, scope, folders...)
+}
+
+func (f *FakeCredentialStore) UpdateCertificateCredentialInFolderContext(ctx context.Context, domain, id, keystore,
+	password, description, scope string, folders ...string) (*string, error) {
+	return f.put(false, domain, id, "Certificate", description, scope,
+		[][2]string{{"keystore", keystore}, {"password", password}}, folders)
+}
+
+func (f *FakeCredentialStore) UpdateAWSCredentialInFolder(domain, id, accessKey, secretKey, iamRoleArn, description,
+	scope string, folders ...string) (*string, error) {
+	return f.UpdateAWSCredentialInFolderContext(context.Background(), domain, id, accessKey, secretKey, iamRoleArn,
+		description, scope, folders...)
+}
+
+func (f *FakeCredentialStore) UpdateAWSCredentialInFolderContext(ctx context.Context, domain, id, accessKey,
+	secretKey, iamRoleArn, description, scope string, folders ...string) (*string, error) {
+	return f.put(false, domain, id, "AWS Credentials", description, scope,
+		[][2]string{{"accessKey", accessKey}, {"secretKey", secretKey}, {"iamRoleArn", iamRoleArn}}, folders)
+}
+
+func (f *FakeCredentialStore) UpdateSecretFileCredentialInFolder(domain, id, fileName, content, description,
+	scope string, folders ...string) (*string, error) {
+	return f.UpdateSecretFileCredentialInFolderContext(context.Background(), domain, id, fileName, content,
+		description, scope, folders...)
+}
+
+func (f *FakeCredentialStore) UpdateSecretFileCredentialInFolderContext(ctx context.Context, domain, id, fileName,
+	content, description, scope string, folders ...string) (*string, error) {
+	return f.put(false, domain, id, "Secret file", description, scope,
+		[][2]string{{"fileName", fileName}, {"secretBytes", content}}, folders)
+}
+
+func (f *FakeCredentialStore) UpdateOpenShiftTokenCredentialInFolder(domain, id, token, description, scope string,
+	folders ...string) (*string, error) {
+	return f.UpdateOpenShiftTokenCredentialInFolderContext(context.Background(), domain, id, token, description,
+		scope, folders...)
+}
+
+func (f *FakeCredentialStore) UpdateOpenShiftTokenCredentialInFolderContext(ctx context.Context, domain, id, token,
+	description, scope string, folders ...string) (*string, error) {
+	return f.put(false, domain, id, "OpenShift Token for OpenShift Client Plugin", description, scope,
+		[][2]string{{"secret", token}}, folders)
+}
+
+func (f *FakeCredentialStore) DeleteCredentialInFolder(domain, id string, folders ...string) (*string, error) {
+	return f.DeleteCredentialInFolderContext(context.Background(), domain, id, folders...)
+}
+
+func (f *FakeCredentialStore) DeleteCredentialInFolderContext(ctx context.Context, domain, id string,
+	folders ...string) (*string, error) {
+	credential, err := f.get(domain, id, folders)
+	if err != nil {
+		return nil, err
+	}
+	f.Lock()
+	defer f.Unlock()
+	delete(f.credentials, fakeCredentialKey(credential.folder, credential.response.Domain, id))
+	return &id, nil
+}
+
+func (f *FakeCredentialStore) GetCredentialInFolder(domain, id string,
+	folders ...string) (*gojenkins.CredentialResponse, error) {
+	return f.GetCredentialInFolderContext(context.Background(), domain, id, folders...)
+}
+
+func (f *FakeCredentialStore) GetCredentialInFolderContext(ctx context.Context, domain, id string,
+	folders ...string) (*gojenkins.CredentialResponse, error) {
+	credential, err := f.get(domain, id, folders)
+	if err != nil {
+		return nil, err
+	}
+	response := credential.response
+	return &response, nil
+}
+
+func (f *FakeCredentialStore) GetCredentialContentInFolder(domain, id string, folders ...string) (string, error) {
+	return f.GetCredentialContentInFolderContext(context.Background(), domain, id, folders...)
+}
+
+// GetCredentialContentInFolderContext renders the fields of the credential as the inputs of the update
+// page, multi-line fields as text areas.
+func (f *FakeCredentialStore) GetCredentialContentInFolderContext(ctx context.Context, domain, id string,
+	folders ...string) (string, error) {
+	credential, err := f.get(domain, id, folders)
+	if err != nil {
+		return "", err
+	}
+	return credential.content(), nil
+}
+
+func (credential *fakeCredential) content() string {
+	id := credential.response.Id
+	page := &strings.Builder{}
+	page.WriteString("<html><body><form>")
+	fmt.Fprintf(page, `<input name="_.id" type="text" value="%s"/>`, html.EscapeString(id))
+	fmt.Fprintf(page, `<input name="_.description" type="text" value="%s"/>`,
+		html.EscapeString(credential.response.Description))
+	fmt.Fprintf(page, `<select name="_.scope"><option value="%s" selected="true"></option></select>`,
+		html.EscapeString(credential.scope))
+	for _, field := range credential.fields {
+		if fakeEncryptedFields[field[0]] {
+			field[1] = fakeEncryptSecret(field[1])
+		}
+		if field[0] == "content" || field[0] == "privateKey" || field[0] == "keystore" {
+			fmt.Fprintf(page, `<textarea name="_.%s">%s</textarea>`, field[0], html.EscapeString(field[1]))
+			continue
+		}
+		inputType := "text"
+		if fakeSecretFields[field[0]] {
+			inputType = "password"
+		}
+		fmt.Fprintf(page, `<input name="_.%s" type="%s" value="%s"/>`, field[0], inputType,
+			html.EscapeString(field[1]))
+	}
+	page.WriteString("</form></body></html>")
+	return page.String()
+}
+
+func (f *FakeCredentialStore) GetCredentialConfigInFolder(domain, id string, folders ...string) (string, error) {
+	credential, err := f.get(domain, id, folders)
+	if err != nil {
+		return "", err
+	}
+	return credential.config(), nil
+}
+
+// config renders the credential as its config.xml, one element per field with its secrets encrypted.
+func (credential *fakeCredential) config() string {
+	config := &strings.Builder{}
+	fmt.Fprintf(config, `<credential typeName="%s">`, html.EscapeString(credential.response.TypeName))
+	fmt.Fprintf(config, "<scope>%s</scope><id>%s</id><description>%s</description>",
+		html.EscapeString(credential.scope), html.EscapeString(credential.response.Id),
+		html.EscapeString(credential.response.Description))
+	for _, field := range credential.fields {
+		if fakeEncryptedFields[field[0]] {
+			field[1] = fakeEncryptSecret(field[1])
+		}
+		fmt.Fprintf(config, "<%s>%s</%s>", field[0], html.EscapeString(field[1]), field[0])
+	}
+	config.WriteString("</credential>")
+	return config.String()
+}
+
+type fakeCredentialConfig struct {
+	TypeName string `xml:"typeName,attr"`
+	Elements []struct {
+		XMLName xml.Name
+		Value   string `xml:",chardata"`
+	} `xml:",any"`
+}
+
+// CreateCredentialFromConfigInFolder creates a credential from the config.xml rendered by config.
+func (f *FakeCredentialStore) CreateCredentialFromConfigInFolder(domain, config string, folders ...string) error {
+	credentialConfig := &fakeCredentialConfig{}
+	if err := xml.Unmarshal([]byte(config), credentialConfig); err != nil {
+		return err
+	}
+	var id, description, scope string
+	fields := make([][2]string, 0, len(credentialConfig.Elements))
+	for _, element := range credentialConfig.Elements {
+		switch element.XMLName.Local {
+		case "id":
+			id = element.Value
+		case "description":
+			description = element.Value
+		case "scope":
+			scope = element.Value
+		default:
+			fields = append(fields, [2]string{element.XMLName.Local, element.Value})
+		}
+	}
+	_, err := f.put(true, domain, id, credentialConfig.TypeName, description, scope, fields, folders)
+	return err
+}
+
+func (f *FakeCredentialStore) GetCredentialsInFolder(domain string,
+	folders ...string) ([]*gojenkins.CredentialResponse, error) {
+	folder, err := fakeCredentialFolder(folders)
+	if err != nil {
+		return nil, err
+	}
+	if domain != "" && domain != "_" && !f.hasDomain(folder, domain) {
+		return nil, fakeStatusError(http.StatusNotFound)
+	}
+	return f.list(folder, domain), nil
+}
+
+func (f *FakeCredentialStore) hasDomain(folder, domain string) bool {
+	f.Lock()
+	defer f.Unlock()
+	return f.domains[fakeDomainKey(folder, domain)]
+}
+
+func (f *FakeCredentialStore) list(folder, domain string) []*gojenkins.CredentialResponse {
+	f.Lock()
+	defer f.Unlock()
+	responses := make([]*gojenkins.CredentialResponse, 0)
+	for _, credential := range f.credentials {
+		if credential.folder != folder || (domain != "" && credential.response.Domain != domain) {
+			continue
+		}
+		response := credential.response
+		responses = append(responses, &response)
+	}
+	sort.Slice(responses, func(i, j int) bool {
+		return responses[i].Domain+"/"+responses[i].Id < responses[j].Domain+"/"+responses[j].Id
+	})
+	return responses
+}
+
+func (f *FakeCredentialStore) GetCredentialDomainsInFolder(folders ...string) ([]string, error) {
+	folder, err := fakeCredentialFolder(folders)
+	if err != nil {
+		return nil, err
+	}
+	f.Lock()
+	defer f.Unlock()
+	domains := []string{"_"}
+	for key := range f.domains {
+		if domain := strings.TrimPrefix(key, folder+"/"); domain != key && domain != "_" &&
+			!strings.Contains(domain, "/") {
+			domains = append(domains, domain)
+		}
+	}
+	sort.Strings(domains[1:])
+	return domains, nil
+}
+
+func (f *FakeCredentialStore) CreateCredentialDomainInFolder(domain, description, scope string,
+	folders ...string) (*string, error) {
+	folder, err := fakeCredentialFolder(folders)
+	if err != nil {
+		return nil, err
+	}
+	f.Lock()
+	defer f.Unlock()
+	key := fakeDomainKey(folder, domain)
+	if domain == "_" || f.domains[key] {
+		return nil, fakeStatusError(http.StatusConflict)
+	}
+	f.domains[key] = true
+	return &domain, nil
+}
+
+// DeleteCredentialDomainInFolder deletes the domain with its credentials like Jenkins does.
+func (f *FakeCredentialStore) DeleteCredentialDomainInFolder(domain string, folders ...string) (*string, error) {
+	folder, err := fakeCredentialFolder(folders)
+	if err != nil {
+		return nil, err
+	}
+	f.Lock()
+	defer f.Unlock()
+	key := fakeDomainKey(folder, domain)
+	if domain == "_" {
+		return nil, fakeStatusError(http.StatusBadRequest)
+	}
+	if !f.domains[key] {
+		return nil, fakeStatusError(http.StatusNotFound)
+	}
+	for credentialKey, credential := range f.credentials {
+		if credential.folder == folder && credential.response.Domain == domain {
+			delete(f.credentials, credentialKey)
+		}
+	}
+	delete(f.domains, key)
+	return &domain, nil
+}
+
+// RenameCredentialDomainInFolder moves the credentials like Jenkins does, nothing is moved when one of their
+// ids is used in newDomain.
+func (f *FakeCredentialStore) RenameCredentialDomainInFolder(domain, newDomain string,
+	folders ...string) ([]string, error) {
+	if domain == "" {
+		domain = "_"
+	}
+	if newDomain == "" || newDomain == domain {
+		return nil, fmt.Errorf("credential domain [%s] should be renamed to another domain", domain)
+	}
+	credentials, err := f.GetCredentialsInFolder(domain, folders...)
+	if err != nil {
+		return nil, err
+	}
+	folder, _ := fakeCredentialFolder(folders)
+	f.Lock()
+	defer f.Unlock()
+	for _, credential := range credentials {
+		if _, ok := f.credentials[fakeCredentialKey(folder, newDomain, credential.Id)]; ok {
+			return nil, fmt.Errorf("credential id [%s] has been used in domain [%s]: %w", credential.Id,
+				newDomain, gojenkins.ErrCredentialExists)
+		}
+	}
+	f.domains[fakeDomainKey(folder, newDomain)] = true
+	moved := make([]string, 0, len(credentials))
+	for _, credential := range credentials {
+		key := fakeCredentialKey(folder, domain, credential.Id)
+		fake := f.credentials[key]
+		delete(f.credentials, key)
+		fake.response.Domain = newDomain
+		f.credentials[fakeCredentialKey(folder, newDomain, credential.Id)] = fake
+		moved = append(moved, credential.Id)
+	}
+	if domain != "_" {
+		delete(f.domains, fakeDomainKey(folder, domain))
+	}
+	return moved, nil
+}
+
+func (f *FakeCredentialStore) CreateSshCredentialInSystem(domain, id, username, passphrase, privateKey, description,
+	scope string) (*string, error) {
+	return f.putInFolder(true, fakeSystemFolder, domain, id, "SSH Username with private key", description, scope,
+		[][2]string{{"username", username}, {"passphrase", passphrase}, {"privateKey", privateKey}})
+}
+
+func (f *FakeCredentialStore) CreateUsernamePasswordCredentialInSystem(domain, id, username, password, description,
+	scope string) (*string, error) {
+	return f.putInFolder(true, fakeSystemFolder, domain, id, "Username with password", description, scope,
+		[][2]string{{"username", username}, {"password", password}})
+}
+
+func (f *FakeCredentialStore) CreateSecretTextCredentialInSystem(domain, id, secret, description,
+	scope string) (*string, error) {
+	return f.putInFolder(true, fakeSystemFolder, domain, id, "Secret text", description, scope,
+		[][2]string{{"secret", secret}})
+}
+
+func (f *FakeCredentialStore) CreateKubeconfigCredentialInSystem(domain, id, content, description,
+	scope string) (*string, error) {
+	return f.putInFolder(true, fakeSystemFolder, domain, id, "Kubernetes configuration (kubeconfig)", description,
+		scope, [][2]string{{"content", content}})
+}
+
+func (f *FakeCredentialStore) CreateCertificateCredentialInSystem(domain, id, keystore, password, description,
+	scope string) (*string, error) {
+	return f.putInFolder(true, fakeSystemFolder, domain, id, "Certificate", description, scope,
+		[][2]string{{"keystore", keystore}, {"password", password}})
+}
+
+func (f *FakeCredentialStore) CreateAWSCredentialInSystem(domain, id, accessKey, secretKey, iamRoleArn, description,
+	scope string) (*string, error) {
+	return f.putInFolder(true, fakeSystemFolder, domain, id, "AWS Credentials", description, scope,
+		[][2]string{{"accessKey", accessKey}, {"secretKey", secretKey}, {"iamRoleArn", iamRoleArn}})
+}
+
+func (f *FakeCredentialStore) CreateSecretFileCredentialInSystem(domain, id, fileName, content, description,
+	scope string) (*string, error) {
+	return f.putInFolder(true, fakeSystemFolder, domain, id, "Secret file", description, scope,
+		[][2]string{{"fileName", fileName}, {"secretBytes", content}})
+}
+
+func (f *FakeCredentialStore) CreateOpenShiftTokenCredentialInSystem(domain, id, token, description,
+	scope string) (*string, error) {
+	return f.putInFolder(true, fakeSystemFolder, domain, id, "OpenShift Token for OpenShift Client Plugin",
+		description, scope, [][2]string{{"secret", token}})
+}
+
+func (f *FakeCredentialStore) UpdateSshCredentialInSystem(domain, id, username, passphrase, privateKey, description,
+	scope string) (*string, error) {
+	return f.putInFolder(false, fakeSystemFolder, domain, id, "SSH Username with private key", description, scope,
+		[][2]string{{"username", username}, {"passphrase", passphrase}, {"privateKey", privateKey}})
+}
+
+func (f *FakeCredentialStore) UpdateUsernamePasswordCredentialInSystem(domain, id, username, password, description,
+	scope string) (*string, error) {
+	return f.putInFolder(false, fakeSystemFolder, domain, id, "Username with password", description, scope,
+		[][2]string{{"username", username}, {"password", password}})
+}
+
+func (f *FakeCredentialStore) UpdateSecretTextCredentialInSystem(domain, id, secret, description,
+	scope string) (*string, error) {
+	return f.putInFolder(false, fakeSystemFolder, domain, id, "Secret text", description, scope,
+		[][2]string{{"secret", secret}})
+}
+
+func (f *FakeCredentialStore) UpdateKubeconfigCredentialInSystem(domain, id, content, description,
+	scope string) (*string, error) {
+	return f.putInFolder(false, fakeSystemFolder, domain, id, "Kubernetes configuration (kubeconfig)", description,
+		scope, [][2]string{{"content", content}})
+}
+
+func (f *FakeCredentialStore) UpdateCertificateCredentialInSystem(domain, id, keystore, password, description,
+	scope string) (*string, error) {
+	return f.putInFolder(false, fakeSystemFolder, domain, id, "Certificate", description, scope,
+		[][2]string{{"keystore", keystore}, {"password", password}})
+}
+
+func (f *FakeCredentialStore) UpdateAWSCredentialInSystem(domain, id, accessKey, secretKey, iamRoleArn, description,
+	scope string) (*string, error) {
+	return f.putInFolder(false, fakeSystemFolder, domain, id, "AWS Credentials", description, scope,
+		[][2]string{{"accessKey", accessKey}, {"secretKey", secretKey}, {"iamRoleArn", iamRoleArn}})
+}
+
+func (f *FakeCredentialStore) UpdateSecretFileCredentialInSystem(domain, id, fileName, content, description,
+	scope string) (*string, error) {
+	return f.putInFolder(false, fakeSystemFolder, domain, id, "Secret file", description, scope,
+		[][2]string{{"fileName", fileName}, {"secretBytes", content}})
+}
+
+func (f *FakeCredentialStore) UpdateOpenShiftTokenCredentialInSystem(domain, id, token, description,
+	scope string) (*string, error) {
+	return f.putInFolder(false, fakeSystemFolder, domain, id, "OpenShift Token for OpenShift Client Plugin",
+		description, scope, [][2]string{{"secret", token}})
+}
+
+func (f *FakeCredentialStore) DeleteCredentialInSystem(domain, id string) (*string, error) {
+	credential, err := f.getInFolder(fakeSystemFolder, domain, id)
+	if err != nil {
+		return nil, err
+	}
+	f.Lock()
+	defer f.Unlock()
+	delete(f.credentials, fakeCredentialKey(fakeSystemFolder, credential.response.Domain, id))
+	return &id, nil
+}
+
+func (f *FakeCredentialStore) GetCredentialInSystem(domain, id string) (*gojenkins.CredentialResponse, error) {
+	credential, err := f.getInFolder(fakeSystemFolder, domain, id)
+	if err != nil {
+		return nil, err
+	}
+	response := credential.response
+	return &response, nil
+}
+
+func (f *FakeCredentialStore) GetCredentialContentInSystem(domain, id string) (string, error) {
+	credential, err := f.getInFolder(fakeSystemFolder, domain, id)
+	if err != nil {
+		return "", err
+	}
+	return credential.content(), nil
+}
+
+func (f *FakeCredentialStore) GetCredentialConfigInSystem(domain, id string) (string, error) {
+	credential, err := f.getInFolder(fakeSystemFolder, domain, id)
+	if err != nil {
+		return "", err
+	}
+	return credential.config(), nil
+}
+
+func (f *FakeCredentialStore) GetCredentialsInSystem(domain string) ([]*gojenkins.CredentialResponse, error) {
+	if domain == "" {
+		domain = "_"
+	}
+	return f.list(fakeSystemFolder, domain), nil
+}
+
+func (f *FakeCredentialStore) GetVersion() string {
+	return f.Version
+}
+
+// PutJob adds the job of fullName, or replaces it, with the class and color Jenkins reports for it.
+func (f *FakeCredentialStore) PutJob(fullName, class, color string) {
+	f.Lock()
+	defer f.Unlock()
+	names := strings.Split(fullName, "/")
+	f.jobs[fullName] = &gojenkins.JobResponse{Class: class, Color: color, Name: names[len(names)-1]}
+}
+
+// children returns the jobs whose parent is the folder of fullName, the root when it is empty. The folder
+// exists when a job is in it.
+func (f *FakeCredentialStore) children(fullName string) ([]gojenkins.InnerJob, bool) {
+	f.Lock()
+	defer f.Unlock()
+	prefix := ""
+	if fullName != "" {
+		prefix = fullName + "/"
+	}
+	children := make(map[string]gojenkins.InnerJob)
+	for jobName, job := range f.jobs {
+		if !strings.HasPrefix(jobName, prefix) {
+			continue
+		}
+		name := strings.SplitN(strings.TrimPrefix(jobName, prefix), "/", 2)[0]
+		child := children[name]
+		child.Name = name
+		if jobName == prefix+name {
+			child.Color = job.Color
+		}
+		children[name] = child
+	}
+	_, isJob := f.jobs[fullName]
+	if len(children) == 0 && !isJob {
+		return nil, false
+	}
+	jobs := make([]gojenkins.InnerJob, 0, len(children))
+	for _, child := range children {
+		jobs = append(jobs, child)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].Name < jobs[j].Name
+	})
+	return jobs, true
+}
+
+func fakeJobFullName(id string, parents []string) string {
+	return strings.Join(append(append([]string{}, parents...), id), "/")
+}
+
+func (f *FakeCredentialStore) GetAllJobNames() ([]gojenkins.InnerJob, error) {
+	jobs, _ := f.children("")
+	return jobs, nil
+}
+
+func (f *FakeCredentialStore) GetFolder(id string, parents ...string) (*gojenkins.Folder, error) {
+	jobs, ok := f.children(fakeJobFullName(id, parents))
+	if !ok {
+		return nil, fakeStatusError(http.StatusNotFound)
+	}
+	return &gojenkins.Folder{Raw: &gojenkins.FolderResponse{Name: id, Jobs: jobs}}, nil
+}
+
+func (f *FakeCredentialStore) GetJob(id string, parentIDs ...string) (*gojenkins.Job, error) {
+	fullName := fakeJobFullName(id, parentIDs)
+	f.Lock()
+	job, ok := f.jobs[fullName]
+	f.Unlock()
+	if !ok {
+		if _, isFolder := f.children(fullName); isFolder {
+			return &gojenkins.Job{Raw: &gojenkins.JobResponse{Name: id}}, nil
+		}
+		return nil, fakeStatusError(http.StatusNotFound)
+	}
+	response := *job
+	return &gojenkins.Job{Raw: &response}, nil
+}
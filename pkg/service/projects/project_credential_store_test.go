@@ -0,0 +1,232 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ant0ine/go-json-rest/rest"
+
+	"kubesphere.io/devops/pkg/config/test_config"
+	"kubesphere.io/devops/pkg/db"
+	"kubesphere.io/devops/pkg/ds"
+	"kubesphere.io/devops/pkg/gojenkins"
+	"kubesphere.io/devops/pkg/models"
+)
+
+// recorder is a rest.ResponseWriter recording the response.
+type recorder struct {
+	*httptest.ResponseRecorder
+}
+
+func (w *recorder) EncodeJson(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (w *recorder) WriteJson(v interface{}) error {
+	b, err := w.EncodeJson(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+func newFakeStoreRequest(username, role, url string) *rest.Request {
+	r := &rest.Request{
+		Request:    httptest.NewRequest("GET", url, nil),
+		PathParams: map[string]string{"id": "project", "cid": "git"},
+		Env: map[string]interface{}{
+			projectRolesEnvKey: map[string]string{username + "/project": role},
+		},
+	}
+	r.Header.Set("X-Token-Username", username)
+	return r
+}
+
+// newFakeStoreJsonRequest returns a request of username with role in the project whose body is v as json.
+func newFakeStoreJsonRequest(username, role, method, url string, v interface{}) *rest.Request {
+	r := newFakeStoreRequest(username, role, url)
+	body, _ := json.Marshal(v)
+	r.Request = httptest.NewRequest(method, url, bytes.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("X-Token-Username", username)
+	return r
+}
+
+// newProjectJsonRequest returns a request of username to the project whose body is v as json, roles holds
+// the role of username in each project.
+func newProjectJsonRequest(username, projectId string, roles map[string]string, method, url string,
+	v interface{}) *rest.Request {
+	r := newFakeStoreJsonRequest(username, "", method, url, v)
+	r.PathParams["id"] = projectId
+	memo := make(map[string]string, len(roles))
+	for roleProjectId, role := range roles {
+		memo[username+"/"+roleProjectId] = role
+	}
+	r.Env[projectRolesEnvKey] = memo
+	return r
+}
+
+// fakeCredentialField returns the value of a field of a credential of the fake store as Jenkins keeps it.
+func fakeCredentialField(t *testing.T, store *FakeCredentialStore, projectId, domain, id, name string) string {
+	credential, err := store.get(domain, id, []string{projectId})
+	if err != nil {
+		t.Fatalf("credential [%s] should be in domain [%s] of project [%s]: %+v", id, domain, projectId, err)
+	}
+	for _, field := range credential.fields {
+		if field[0] == name {
+			return field[1]
+		}
+	}
+	return ""
+}
+
+// dbTestTables are the tables the records of a test project are deleted from once the test is done.
+var dbTestTables = []string{
+	models.ProjectCredentialTableName,
+	models.ProjectCredentialTrashTableName,
+	models.ProjectCredentialUsageThresholdTableName,
+	models.ProjectCredentialTransparencyLogTableName,
+	models.ProjectCredentialDeletionTableName,
+	models.ProjectCredentialRotationTableName,
+	models.CredentialAuditLogTableName,
+}
+
+// newDbTestService returns a service on the test database migrated with the devops schema, whose credentials
+// are kept in a fake store, and the ids of projects no other test uses. The test is skipped without the test
+// database, cleanup deletes the records of the projects.
+func newDbTestService(t *testing.T, projects int) (s *ProjectService, store *FakeCredentialStore,
+	projectIds []string, cleanup func()) {
+	tc := test_config.NewDbTestConfig()
+	tc.CheckDbUnitTest(t)
+	d := tc.GetDatabaseConn()
+	migrations, err := db.LoadMigrations(filepath.Join("..", "..", "db", "schema", "devops"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Migrate(migrations); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < projects; i++ {
+		projectIds = append(projectIds, fmt.Sprintf("project-%d-%d", time.Now().UnixNano(), i))
+	}
+	cleanup = func() {
+		for _, table := range dbTestTables {
+			d.DeleteFrom(table).Where(db.Eq(models.ProjectIdColumn, projectIds)).Exec()
+		}
+	}
+	store = NewFakeCredentialStore()
+	return &ProjectService{Ds: &ds.Ds{Db: d}, Config: tc.EnvConfig, Credentials: store}, store, projectIds, cleanup
+}
+
+// insertTestCredentialRecord saves the record of a credential created in the fake store of a test.
+func insertTestCredentialRecord(t *testing.T, s *ProjectService, projectCredential *models.ProjectCredential) {
+	_, err := s.Ds.Db.InsertInto(models.ProjectCredentialTableName).Columns(models.ProjectCredentialColumns...).
+		Record(projectCredential).Exec()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFakeCredentialStore(t *testing.T) {
+	store := NewFakeCredentialStore()
+	if _, err := store.CreateUsernamePasswordCredentialInFolder("", "git", "admin", "password", "", "",
+		"project"); err != nil {
+		t.Fatal(err)
+	}
+	_, err := store.CreateSecretTextCredentialInFolder("_", "git", "secret", "", "", "project")
+	if !errors.Is(err, gojenkins.ErrCredentialExists) {
+		t.Fatalf("creating an existing credential should conflict, got %v", err)
+	}
+	_, err = store.UpdateSecretTextCredentialInFolder("github", "git", "secret", "", "", "project")
+	if !errors.Is(err, gojenkins.ErrCredentialNotFound) {
+		t.Fatalf("updating a missing credential should not be found, got %v", err)
+	}
+	credentials, err := store.GetCredentialsInFolder("", "project")
+	if err != nil || len(credentials) != 1 ||
+		CredentialTypeMap[credentials[0].TypeName] != CredentialTypeUsernamePassword {
+		t.Fatalf("should list the username password credential, got %v %v", credentials, err)
+	}
+	content, err := store.GetCredentialContentInFolder("_", "git", "project")
+	if err != nil || !strings.Contains(content, `name="_.username" type="text" value="admin"`) {
+		t.Fatalf("content should render the username, got [%s] %v", content, err)
+	}
+	if _, err := store.DeleteCredentialInFolder("", "git", "project"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.GetCredentialInFolder("", "git", "project"); !errors.Is(err, gojenkins.ErrCredentialNotFound) {
+		t.Fatalf("deleted credential should not be found, got %v", err)
+	}
+}
+
+func TestFakeCredentialStoreConfig(t *testing.T) {
+	store := NewFakeCredentialStore()
+	store.CreateUsernamePasswordCredentialInFolder("", "git", "admin", "password", "git", "", "project")
+	config, err := store.GetCredentialConfigInFolder("", "git", "project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(config, "<password>password</password>") {
+		t.Fatalf("config should keep the password encrypted, got [%s]", config)
+	}
+	if err := store.CreateCredentialFromConfigInFolder("", config, "project"); !errors.Is(err,
+		gojenkins.ErrCredentialExists) {
+		t.Fatalf("creating an existing credential should conflict, got %v", err)
+	}
+	if err := store.CreateCredentialFromConfigInFolder("github", config, "project"); err != nil {
+		t.Fatal(err)
+	}
+	credential, err := store.GetCredentialInFolder("github", "git", "project")
+	if err != nil || CredentialTypeMap[credential.TypeName] != CredentialTypeUsernamePassword ||
+		credential.Description != "git" {
+		t.Fatalf("credential should be created from its config, got %+v %v", credential, err)
+	}
+	if password := fakeCredentialField(t, store, "project", "github", "git", "password"); password != "password" {
+		t.Fatalf("the encrypted password should be accepted back, got [%s]", password)
+	}
+}
+
+func TestWriteCredentialConfigXml(t *testing.T) {
+	store := NewFakeCredentialStore()
+	store.CreateUsernamePasswordCredentialInFolder("", "git", "admin", "password", "git", "", "project")
+	s := &ProjectService{Credentials: store}
+	for _, test := range []struct {
+		role   string
+		url    string
+		status int
+	}{
+		{role: ProjectOwner, url: "/projects/project/credentials/git?format=xml", status: http.StatusOK},
+		{role: ProjectOwner, url: "/projects/project/credentials/git?format=yaml", status: http.StatusBadRequest},
+		{role: ProjectOwner, url: "/projects/project/credentials/git?format=xml&domain=github",
+			status: http.StatusNotFound},
+		{role: ProjectMaintainer, url: "/projects/project/credentials/git?format=xml", status: http.StatusForbidden},
+	} {
+		w := &recorder{httptest.NewRecorder()}
+		r := newFakeStoreRequest("alice", test.role, test.url)
+		s.writeCredentialConfigXml(w, r, r.URL.Query().Get("format"))
+		if w.Code != test.status {
+			t.Fatalf("%s of %s should answer %d, got %d %s", test.url, test.role, test.status, w.Code, w.Body)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ant0ine/go-json-rest/rest"
+
+	"kubesphere.io/devops/pkg/logger"
+)
+
+// credentials streamed are completed, encoded and flushed by chunks of this size
+const credentialStreamChunkSize = 100
+
+// streamCredentials writes the credentials of the project as a JSON array encoded chunk by chunk, so the
+// responses of a folder with thousands of credentials are never all in memory. Records are loaded in one
+// query up front, complete filters and fills every chunk like the credentials listed at once.
+func (s *ProjectService) streamCredentials(w rest.ResponseWriter, r *rest.Request, projectId, domain string,
+	complete func([]*CredentialResponse) []*CredentialResponse) {
+	jenkinsCredentials, err := s.getCredentialsInFolder(projectId, domain)
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeJenkinsError(w, r, err)
+		return
+	}
+	projectCredentials, warnings := s.loadCredentialsMetadata(projectId, domain)
+	dbCredentials := indexProjectCredentials(projectCredentials)
+
+	setCredentialListWarnings(w, warnings)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	writer := w.(http.ResponseWriter)
+	flusher, _ := w.(http.Flusher)
+	encoder := json.NewEncoder(writer)
+	written := 0
+	writer.Write([]byte("["))
+	for start := 0; start < len(jenkinsCredentials); start += credentialStreamChunkSize {
+		end := start + credentialStreamChunkSize
+		if end > len(jenkinsCredentials) {
+			end = len(jenkinsCredentials)
+		}
+		chunk := make([]*CredentialResponse, 0, end-start)
+		for i := start; i < end; i++ {
+			jenkinsCredential := jenkinsCredentials[i]
+			dbCredential := dbCredentials[credentialKey{id: jenkinsCredential.Id, domain: jenkinsCredential.Domain}]
+			chunk = append(chunk, formatCredentialResponse(jenkinsCredential, dbCredential))
+			// the Jenkins response is not needed any more once formatted
+			jenkinsCredentials[i] = nil
+		}
+		// the headers are sent, credentials whose scope can not be read are only logged
+		s.fillCredentialsScope(projectId, chunk)
+		for _, credential := range complete(chunk) {
+			if written > 0 {
+				writer.Write([]byte(","))
+			}
+			err = encoder.Encode(credential)
+			if err != nil {
+				logger.ErrorContext(r.Context(), "failed to stream credentials of project [%s]: %+v", projectId, err)
+				return
+			}
+			written++
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	writer.Write([]byte("]"))
+}
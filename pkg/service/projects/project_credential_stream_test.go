@@ -0,0 +1,88 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// flushRecorder is a recorder keeping the body written at every flush.
+type flushRecorder struct {
+	recorder
+	flushed []string
+}
+
+func (w *flushRecorder) Flush() {
+	w.flushed = append(w.flushed, w.Body.String())
+	w.recorder.Flush()
+}
+
+func TestCredentialAuditFlush(t *testing.T) {
+	w := &recorder{httptest.NewRecorder()}
+	var audited http.Flusher = &credentialAudit{ResponseWriter: &operationRecorder{ResponseWriter: w}}
+	audited.Flush()
+	if !w.Flushed {
+		t.Fatalf("flushing the audited response should flush the response")
+	}
+}
+
+func TestStreamCredentialsHandler(t *testing.T) {
+	s, store, projectIds, cleanup := newDbTestService(t, 1)
+	defer cleanup()
+	projectId := projectIds[0]
+	credentials := credentialStreamChunkSize + credentialStreamChunkSize/2
+	for i := 0; i < credentials; i++ {
+		_, err := store.CreateSecretTextCredentialInFolder("_", fmt.Sprintf("token-%03d", i), "secret", "", "",
+			projectId)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w := &flushRecorder{recorder: recorder{httptest.NewRecorder()}}
+	r := newProjectJsonRequest("alice", projectId, map[string]string{projectId: ProjectOwner}, "GET",
+		"/projects/"+projectId+"/credentials?stream=true", nil)
+	handler := s.InstrumentCredentialHandler(CredentialActionList,
+		s.AuditCredentialHandler(CredentialActionList, s.GetCredentialsHandler))
+	handler(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("streaming credentials should succeed, got %d %s", w.Code, w.Body)
+	}
+	if len(w.flushed) != 2 {
+		t.Fatalf("credentials should be flushed by chunks of %d, got %d flushes", credentialStreamChunkSize,
+			len(w.flushed))
+	}
+	for i, body := range w.flushed {
+		var chunk []*CredentialResponse
+		if err := json.Unmarshal([]byte(body+"]"), &chunk); err != nil {
+			t.Fatalf("flush %d should hold whole credentials: %+v", i, err)
+		}
+		expected := (i + 1) * credentialStreamChunkSize
+		if expected > credentials {
+			expected = credentials
+		}
+		if len(chunk) != expected {
+			t.Fatalf("flush %d should hold %d credentials, got %d", i, expected, len(chunk))
+		}
+	}
+	var response []*CredentialResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil || len(response) != credentials {
+		t.Fatalf("streamed credentials should be a JSON array of %d credentials, got %d %v", credentials,
+			len(response), err)
+	}
+}
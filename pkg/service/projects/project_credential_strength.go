@@ -0,0 +1,70 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"github.com/asaskevich/govalidator"
+
+	"kubesphere.io/devops/pkg/db"
+	"kubesphere.io/devops/pkg/models"
+	"kubesphere.io/devops/pkg/utils/strengthutils"
+)
+
+type CredentialStrength struct {
+	Score  int    `json:"score"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// scoreSecret scores the submitted secret from 0 to 4 with the configured thresholds,
+// it returns nil when scoring is disabled. The secret itself is never kept.
+func (s *ProjectService) scoreSecret(secret string) *CredentialStrength {
+	cfg := s.Config.Strength
+	if !cfg.Enabled {
+		return nil
+	}
+	bits, reason := strengthutils.Estimate(secret)
+	strength := &CredentialStrength{Reason: reason}
+	for _, threshold := range []int{cfg.WeakBits, cfg.FairBits, cfg.StrongBits, cfg.VeryStrongBits} {
+		if bits < float64(threshold) {
+			break
+		}
+		strength.Score++
+	}
+	return strength
+}
+
+func setCredentialStrength(projectCredential *models.ProjectCredential, strength *CredentialStrength) {
+	if strength == nil {
+		return
+	}
+	projectCredential.StrengthScore = &strength.Score
+	projectCredential.StrengthReason = db.EncryptedString(strength.Reason)
+}
+
+func (s *ProjectService) updateCredentialStrength(projectId, domain, credentialId string,
+	strength *CredentialStrength) error {
+	if govalidator.IsNull(domain) {
+		domain = "_"
+	}
+	_, err := s.Ds.Db.Update(models.ProjectCredentialTableName).
+		Set(models.ProjectCredentialStrengthScoreColumn, strength.Score).
+		Set(models.ProjectCredentialStrengthReasonColumn, db.EncryptedString(strength.Reason)).
+		Where(db.And(db.Eq(models.ProjectIdColumn, projectId),
+			db.Eq(models.ProjectCredentialIdColumn, credentialId),
+			db.Eq(models.ProjectCredentialDomainColumn, domain))).Exec()
+	if err != nil && err != db.ErrNotFound {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/asaskevich/govalidator"
+	"github.com/gocraft/dbr"
+
+	"kubesphere.io/devops/pkg/db"
+	"kubesphere.io/devops/pkg/logger"
+	"kubesphere.io/devops/pkg/models"
+	"kubesphere.io/devops/pkg/utils/userutils"
+)
+
+// ImportedCredentialCreator is the creator of the records of credentials found in Jenkins
+// but never created through devops, e.g. after migrating a Jenkins instance.
+const ImportedCredentialCreator = "imported"
+
+type CredentialSyncItem struct {
+	Id     string `json:"id"`
+	Domain string `json:"domain"`
+}
+
+type CredentialSyncReport struct {
+	Imported []*CredentialSyncItem `json:"imported"`
+	Orphaned []*CredentialSyncItem `json:"orphaned,omitempty"`
+}
+
+// SyncCredentialsHandler reconciles the db records of a project with the credentials in its Jenkins folder.
+// Records are inserted for the credentials missing one, records whose credential no longer exists in
+// Jenkins are only reported when check_orphaned=true, they are not removed. The records of the credentials
+// the project created in the global store are checked against the global store, the other credentials of
+// the global store belong to no project and are not imported.
+func (s *ProjectService) SyncCredentialsHandler(w rest.ResponseWriter, r *rest.Request) {
+	projectId := r.PathParams["id"]
+	operator := userutils.GetUserNameFromRequest(r)
+	err := s.checkProjectUserInRole(r, operator, projectId, s.credentialActionRoles(CredentialActionSync))
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
+		return
+	}
+	checkOrphaned := false
+	if value := r.URL.Query().Get("check_orphaned"); !govalidator.IsNull(value) {
+		checkOrphaned, err = strconv.ParseBool(value)
+		if err != nil {
+			err := fmt.Errorf("error check_orphaned [%s] is not a bool", value)
+			logger.ErrorContext(r.Context(), "%+v", err)
+			writeCredentialError(w, err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	jenkinsCredentials, err := s.credentialStore().GetCredentialsInFolder("", projectId)
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeJenkinsError(w, r, err)
+		return
+	}
+	projectCredentials := make([]*models.ProjectCredential, 0)
+	_, err = s.Ds.Db.Select(models.ProjectCredentialColumns...).From(models.ProjectCredentialTableName).
+		Where(db.Eq(models.ProjectIdColumn, projectId)).Load(&projectCredentials)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	dbCredentials := make(map[credentialKey]bool, len(projectCredentials))
+	globalCredentials := make(map[string]bool)
+	for _, projectCredential := range projectCredentials {
+		if projectCredential.Store == models.CredentialStoreGlobal {
+			globalCredentials[projectCredential.CredentialId] = true
+		}
+		dbCredentials[credentialKey{id: projectCredential.CredentialId, domain: projectCredential.Domain}] = true
+	}
+
+	report := &CredentialSyncReport{Imported: make([]*CredentialSyncItem, 0)}
+	jenkinsKeys := make(map[credentialKey]bool, len(jenkinsCredentials))
+	globalKeys := make(map[string]bool, len(globalCredentials))
+	if checkOrphaned && len(globalCredentials) > 0 {
+		systemCredentials, err := s.credentialStore().GetCredentialsInSystem("_")
+		if err != nil {
+			logCredentialError(r.Context(), err)
+			writeJenkinsError(w, r, err)
+			return
+		}
+		for _, systemCredential := range systemCredentials {
+			globalKeys[systemCredential.Id] = true
+		}
+	}
+	missing := make([]*models.ProjectCredential, 0)
+	for _, jenkinsCredential := range jenkinsCredentials {
+		key := credentialKey{id: jenkinsCredential.Id, domain: jenkinsCredential.Domain}
+		jenkinsKeys[key] = true
+		if dbCredentials[key] {
+			continue
+		}
+		missing = append(missing, models.NewProjectCredential(projectId, jenkinsCredential.Id,
+			jenkinsCredential.Domain, ImportedCredentialCreator))
+		report.Imported = append(report.Imported, &CredentialSyncItem{Id: key.id, Domain: key.domain})
+	}
+
+	if len(missing) > 0 {
+		err = s.Ds.Db.WithTransaction(func(tx *dbr.Tx) error {
+			for _, projectCredential := range missing {
+				_, err := tx.InsertInto(models.ProjectCredentialTableName).Columns(models.ProjectCredentialColumns...).
+					Record(projectCredential).Exec()
+				if err != nil {
+					logger.ErrorContext(r.Context(), "failed to import credential [%s] of project [%s]: %+v",
+						projectCredential.CredentialId, projectId, err)
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			logger.ErrorContext(r.Context(), "%+v", err)
+			writeCredentialError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if checkOrphaned {
+		report.Orphaned = make([]*CredentialSyncItem, 0)
+		for _, projectCredential := range projectCredentials {
+			key := credentialKey{id: projectCredential.CredentialId, domain: projectCredential.Domain}
+			found := jenkinsKeys[key]
+			if projectCredential.Store == models.CredentialStoreGlobal {
+				found = globalKeys[key.id]
+			}
+			if !found {
+				report.Orphaned = append(report.Orphaned, &CredentialSyncItem{Id: key.id, Domain: key.domain})
+			}
+		}
+	}
+	w.WriteJson(report)
+	return
+}
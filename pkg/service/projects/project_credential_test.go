@@ -0,0 +1,325 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ant0ine/go-json-rest/rest"
+
+	"kubesphere.io/devops/pkg/config"
+	"kubesphere.io/devops/pkg/gojenkins"
+	"kubesphere.io/devops/pkg/models"
+)
+
+// nestedScanCredentialsResponse is the former merge scanning every db record for each credential.
+func nestedScanCredentialsResponse(jenkinsCredentialsResponse []*gojenkins.CredentialResponse,
+	projectCredentials []*models.ProjectCredential) []*CredentialResponse {
+	responseSlice := make([]*CredentialResponse, 0)
+	for _, jenkinsCredential := range jenkinsCredentialsResponse {
+		var dbCredential *models.ProjectCredential = nil
+		for _, projectCredential := range projectCredentials {
+			if projectCredential.CredentialId == jenkinsCredential.Id &&
+				projectCredential.Domain == jenkinsCredential.Domain {
+				dbCredential = projectCredential
+			}
+		}
+		responseSlice = append(responseSlice, formatCredentialResponse(jenkinsCredential, dbCredential))
+	}
+	return responseSlice
+}
+
+// newCredentialsFixture returns n Jenkins credentials spread over two domains, and db records for
+// all but every tenth of them, in reverse order.
+func newCredentialsFixture(n int) ([]*gojenkins.CredentialResponse, []*models.ProjectCredential) {
+	jenkinsCredentials := make([]*gojenkins.CredentialResponse, 0, n)
+	projectCredentials := make([]*models.ProjectCredential, 0, n)
+	for i := 0; i < n; i++ {
+		domain := "_"
+		if i%2 == 1 {
+			domain = "deploy"
+		}
+		id := fmt.Sprintf("credential-%d", i)
+		jenkinsCredentials = append(jenkinsCredentials, &gojenkins.CredentialResponse{
+			Id: id, Domain: domain, TypeName: "Secret text", DisplayName: id,
+		})
+		if i%10 == 0 {
+			continue
+		}
+		projectCredentials = append([]*models.ProjectCredential{{
+			ProjectId:    "project-1",
+			CredentialId: id,
+			Domain:       domain,
+			Creator:      "admin",
+			CreateTime:   time.Unix(int64(i), 0),
+		}}, projectCredentials...)
+	}
+	return jenkinsCredentials, projectCredentials
+}
+
+func Test_FormatCredentialsResponse(t *testing.T) {
+	jenkinsCredentials, projectCredentials := newCredentialsFixture(100)
+	// same id in another domain must not be matched
+	projectCredentials = append(projectCredentials, &models.ProjectCredential{
+		ProjectId: "project-1", CredentialId: "credential-0", Domain: "other", Creator: "guest",
+	})
+	expected := nestedScanCredentialsResponse(jenkinsCredentials, projectCredentials)
+	output := formatCredentialsResponse(jenkinsCredentials, projectCredentials)
+	if !reflect.DeepEqual(output, expected) {
+		t.Fatalf("merged credentials should equal the nested scan merge")
+	}
+	if output[0].Creator != "" {
+		t.Fatalf("credential without db record should have no creator, got %s", output[0].Creator)
+	}
+	if output[1].Creator != "admin" {
+		t.Fatalf("credential with db record should have its creator, got %s", output[1].Creator)
+	}
+}
+
+func Test_FormatImportedCredentialCreateTime(t *testing.T) {
+	jenkinsCredential := &gojenkins.CredentialResponse{}
+	err := json.Unmarshal([]byte(`{"id": "imported", "typeName": "Secret text",
+		"fingerprint": {"hash": "abc", "timestamp": 1500000000123}}`), jenkinsCredential)
+	if err != nil {
+		t.Fatal(err)
+	}
+	firstUsedTime := time.Unix(1500000000, 123000000)
+	response := formatCredentialResponse(jenkinsCredential, nil)
+	if response.FirstUsedTime == nil || !response.FirstUsedTime.Equal(firstUsedTime) {
+		t.Fatalf("imported credential should be first used at its fingerprint timestamp, got %v",
+			response.FirstUsedTime)
+	}
+	if response.CreateTime != nil {
+		t.Fatalf("imported credential should have no create time, got %v", response.CreateTime)
+	}
+	recordTime := time.Unix(1600000000, 0)
+	response = formatCredentialResponse(jenkinsCredential, &models.ProjectCredential{CreateTime: recordTime})
+	if !response.CreateTime.Equal(recordTime) || !response.FirstUsedTime.Equal(firstUsedTime) {
+		t.Fatalf("credential with a record should be created at its record time, got %v", response.CreateTime)
+	}
+	response = formatCredentialResponse(&gojenkins.CredentialResponse{Id: "unused"}, nil)
+	if response.CreateTime != nil || response.FirstUsedTime != nil {
+		t.Fatalf("credential without record nor fingerprint should have no time, got %v", response.FirstUsedTime)
+	}
+}
+
+func benchmarkFormatCredentialsResponse(b *testing.B, n int, format func([]*gojenkins.CredentialResponse,
+	[]*models.ProjectCredential) []*CredentialResponse) {
+	jenkinsCredentials, projectCredentials := newCredentialsFixture(n)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		format(jenkinsCredentials, projectCredentials)
+	}
+}
+
+func BenchmarkFormatCredentialsResponse500(b *testing.B) {
+	benchmarkFormatCredentialsResponse(b, 500, formatCredentialsResponse)
+}
+
+func BenchmarkFormatCredentialsResponseNestedScan500(b *testing.B) {
+	benchmarkFormatCredentialsResponse(b, 500, nestedScanCredentialsResponse)
+}
+
+func BenchmarkFormatCredentialsResponse2000(b *testing.B) {
+	benchmarkFormatCredentialsResponse(b, 2000, formatCredentialsResponse)
+}
+
+func BenchmarkFormatCredentialsResponseNestedScan2000(b *testing.B) {
+	benchmarkFormatCredentialsResponse(b, 2000, nestedScanCredentialsResponse)
+}
+
+func Test_NormalizeCredentialScope(t *testing.T) {
+	for _, test := range []struct {
+		scope    interface{}
+		expected interface{}
+		valid    bool
+	}{
+		{scope: nil, expected: nil, valid: true},
+		{scope: "", expected: nil, valid: true},
+		{scope: "GLOBAL", expected: "GLOBAL", valid: true},
+		{scope: "system", expected: "SYSTEM", valid: true},
+		{scope: " System ", expected: "SYSTEM", valid: true},
+		{scope: "USER", valid: false},
+		{scope: 1, valid: false},
+	} {
+		content := map[string]interface{}{"id": "git"}
+		if test.scope != nil {
+			content["scope"] = test.scope
+		}
+		err := normalizeCredentialScope(content)
+		if test.valid != (err == nil) {
+			t.Fatalf("scope [%v] valid should be %t, got error %v", test.scope, test.valid, err)
+		}
+		if test.valid && content["scope"] != test.expected {
+			t.Fatalf("scope [%v] should be normalized to %v, got %v", test.scope, test.expected, content["scope"])
+		}
+	}
+}
+
+func Test_DecodeDeleteCredentialRequest(t *testing.T) {
+	for _, test := range []struct {
+		createDomain string
+		body         string
+		query        string
+	}{
+		{createDomain: "github", body: `{"domain": "github"}`},
+		{createDomain: "github", query: "?domain=github"},
+		{createDomain: "github", body: `{}`, query: "?domain=github"},
+		{createDomain: "github", body: `{"domain": "github"}`, query: "?domain=gitlab"},
+		{createDomain: "", body: `{}`},
+		{createDomain: "", query: "?domain=_"},
+		{createDomain: "_"},
+		{createDomain: ""},
+	} {
+		// the record a credential created in the domain is stored with
+		projectCredential := models.NewProjectCredential("project", "git", test.createDomain, "admin")
+		r := &rest.Request{Request: httptest.NewRequest("DELETE", "/projects/project/credentials/git"+test.query,
+			strings.NewReader(test.body))}
+		request, err := decodeDeleteCredentialRequest(r)
+		if err != nil {
+			t.Fatalf("delete with body [%s] and query [%s] should be decoded, got %v", test.body, test.query, err)
+		}
+		if request.Domain != projectCredential.Domain {
+			t.Fatalf("delete with body [%s] and query [%s] should delete domain [%s], got [%s]",
+				test.body, test.query, projectCredential.Domain, request.Domain)
+		}
+	}
+
+	r := &rest.Request{Request: httptest.NewRequest("DELETE", "/projects/project/credentials/git",
+		strings.NewReader(`{"domain":`))}
+	if _, err := decodeDeleteCredentialRequest(r); err == nil {
+		t.Fatalf("malformed body should be rejected")
+	}
+}
+
+func Test_CheckUuidCredentialDomain(t *testing.T) {
+	r := &rest.Request{Request: httptest.NewRequest("DELETE", "/projects/project/credentials/uuid/1", nil),
+		PathParams: map[string]string{"uuid": "1"}}
+	if err := checkUuidCredentialDomain(r, "gitlab"); err != nil {
+		t.Fatalf("requests not made by uuid should not be checked, got %v", err)
+	}
+	r.Env = map[string]interface{}{credentialUuidDomainEnvKey: "_"}
+	if err := checkUuidCredentialDomain(r, ""); err != nil {
+		t.Fatalf("the default domain should match, got %v", err)
+	}
+	if err := checkUuidCredentialDomain(r, "gitlab"); err == nil {
+		t.Fatalf("a domain other than the one of the uuid should be rejected")
+	}
+}
+
+func Test_ServeCredentialByUuidChecksRoleFirst(t *testing.T) {
+	// the service has no db, a lookup of the uuid would panic
+	s := &ProjectService{Credentials: NewFakeCredentialStore()}
+	r := newFakeStoreRequest("alice", "", "/projects/project/credentials/uuid/1")
+	r.PathParams["uuid"] = "1"
+	w := httptest.NewRecorder()
+	s.DeleteCredentialByUuidHandler(&recorder{w}, r)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected users outside the project to be forbidden, got %d", w.Code)
+	}
+}
+
+func Test_FilterCredentialsByQuery(t *testing.T) {
+	credentials := []*CredentialResponse{
+		{Id: "github-token", Description: "CI token"},
+		{Id: "gitlab", Description: "Mirror of GitHub"},
+		{Id: "registry", Description: "docker hub"},
+	}
+	for _, test := range []struct {
+		q   string
+		ids []string
+	}{
+		{q: "GITHUB", ids: []string{"github-token", "gitlab"}},
+		{q: "hub", ids: []string{"github-token", "gitlab", "registry"}},
+		{q: "git*", ids: []string{"github-token", "gitlab"}},
+		{q: "*hub", ids: []string{"gitlab", "registry"}},
+		{q: "*.*", ids: []string{}},
+		{q: "svn", ids: []string{}},
+	} {
+		ids := make([]string, 0)
+		for _, credential := range filterCredentialsByQuery(credentials, test.q) {
+			ids = append(ids, credential.Id)
+		}
+		if !reflect.DeepEqual(ids, test.ids) {
+			t.Fatalf("q [%s] should match %v, got %v", test.q, test.ids, ids)
+		}
+	}
+}
+
+func Test_DetachCredentialWrite(t *testing.T) {
+	type key struct{}
+	parent, cancelParent := context.WithCancel(context.WithValue(context.Background(), key{}, "request"))
+	s := &ProjectService{Config: &config.Config{}}
+	s.Config.Jenkins.WriteTimeout = time.Minute
+	ctx, cancel := s.detachCredentialWrite(parent)
+	defer cancel()
+	cancelParent()
+	if ctx.Err() != nil {
+		t.Fatalf("the write should outlive the request, got %v", ctx.Err())
+	}
+	if ctx.Value(key{}) != "request" {
+		t.Fatalf("the write should keep the values of the request")
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		t.Fatalf("the write should have the write timeout")
+	}
+	cancel()
+	if ctx.Err() == nil {
+		t.Fatalf("the write should be canceled with its own cancel")
+	}
+}
+
+func Test_SetCredentialListWarnings(t *testing.T) {
+	w := &recorder{httptest.NewRecorder()}
+	setCredentialListWarnings(w, []string{credentialMetadataUnavailableWarning})
+	w.WriteJson([]*CredentialResponse{{Id: "git"}})
+	warnings := w.Header()["Warning"]
+	if len(warnings) != 1 || !strings.HasPrefix(warnings[0], `199 devops "credential metadata is unavailable`) {
+		t.Fatalf("expected the warning in a Warning header, got %v", warnings)
+	}
+	credentials := make([]*CredentialResponse, 0)
+	if err := json.Unmarshal(w.ResponseRecorder.Body.Bytes(), &credentials); err != nil || len(credentials) != 1 {
+		t.Fatalf("expected the list to stay a bare array, got %s", w.ResponseRecorder.Body.String())
+	}
+}
+
+func TestFillCredentialsScopeWarnsOfUnreadableConfigs(t *testing.T) {
+	store := NewFakeCredentialStore()
+	store.CreateSecretTextCredentialInFolder("", "token", "secret", "", gojenkins.SYSTEMScope, "project")
+	s := &ProjectService{Credentials: store}
+	credentials := []*CredentialResponse{
+		{Id: "token", Domain: "_"},
+		{Id: "missing", Domain: "_"},
+	}
+	warnings := s.fillCredentialsScope("project", credentials)
+	if credentials[0].Scope != gojenkins.SYSTEMScope || credentials[1].Scope != "" {
+		t.Fatalf("expected scopes [%s] and none, got [%s] and [%s]", gojenkins.SYSTEMScope, credentials[0].Scope,
+			credentials[1].Scope)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "[missing]") {
+		t.Fatalf("expected a warning about credential [missing], got %v", warnings)
+	}
+	filtered := filterCredentialsByScope(credentials, gojenkins.GLOBALScope)
+	if len(filtered) != 1 || filtered[0].Id != "missing" {
+		t.Fatalf("credentials without scope should be kept by the scope filter, got %v", filtered)
+	}
+}
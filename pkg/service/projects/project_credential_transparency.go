@@ -0,0 +1,241 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/asaskevich/govalidator"
+	"github.com/gocraft/dbr"
+
+	"kubesphere.io/devops/pkg/db"
+	"kubesphere.io/devops/pkg/logger"
+	"kubesphere.io/devops/pkg/models"
+	"kubesphere.io/devops/pkg/utils/userutils"
+)
+
+// previous hash of the first entry of a chain
+var transparencyGenesisHash = strings.Repeat("0", sha256.Size*2)
+
+// serializes appends so each entry links to the latest one, a concurrent append by another
+// instance fails on the primary key instead of forking the chain
+var transparencyLogLock sync.Mutex
+
+type TransparencyLogVerification struct {
+	Valid   bool   `json:"valid"`
+	Entries int    `json:"entries"`
+	Head    string `json:"head"`
+	// seq of the first entry breaking the chain
+	BrokenAt *int64 `json:"broken_at,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+type TransparencyLogResponse struct {
+	Entries      []*models.ProjectCredentialTransparencyLog `json:"entries"`
+	Verification *TransparencyLogVerification               `json:"verification"`
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// transparencyContentHash hashes the non secret fields of content with the hashes of its secret fields,
+// so the log proves what was written without holding any secret.
+func transparencyContentHash(content map[string]interface{}) string {
+	metadata, hashes := hashCredentialContent(content)
+	data, _ := json.Marshal(struct {
+		Content      map[string]interface{} `json:"content"`
+		SecretHashes map[string]string      `json:"secret_hashes"`
+	}{Content: metadata, SecretHashes: hashes})
+	return sha256Hex(data)
+}
+
+func transparencyEntryHash(entry *models.ProjectCredentialTransparencyLog) string {
+	data, _ := json.Marshal([]string{
+		entry.ProjectId,
+		strconv.FormatInt(entry.Seq, 10),
+		entry.CredentialId,
+		entry.Domain,
+		entry.Operation,
+		entry.Operator,
+		entry.ContentHash,
+		strconv.FormatInt(entry.CreateTime.Unix(), 10),
+		entry.PrevHash,
+	})
+	return sha256Hex(data)
+}
+
+func signTransparencyHash(key, hash string) string {
+	if key == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(hash))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// verifyTransparencyChain checks entries, ordered by seq, start at 1 without gaps, link to the previous
+// entry, match their hash and, when key is set, their signature.
+func verifyTransparencyChain(entries []*models.ProjectCredentialTransparencyLog, key string) *TransparencyLogVerification {
+	verification := &TransparencyLogVerification{Valid: true, Entries: len(entries), Head: transparencyGenesisHash}
+	broken := func(entry *models.ProjectCredentialTransparencyLog, format string, v ...interface{}) *TransparencyLogVerification {
+		seq := entry.Seq
+		verification.Valid = false
+		verification.BrokenAt = &seq
+		verification.Error = fmt.Sprintf(format, v...)
+		return verification
+	}
+	for i, entry := range entries {
+		if entry.Seq != int64(i+1) {
+			return broken(entry, "entry %d found where entry %d is expected", entry.Seq, i+1)
+		}
+		if entry.PrevHash != verification.Head {
+			return broken(entry, "entry %d does not link to the previous entry", entry.Seq)
+		}
+		if transparencyEntryHash(entry) != entry.Hash {
+			return broken(entry, "entry %d does not match its hash", entry.Seq)
+		}
+		if key != "" && !hmac.Equal([]byte(signTransparencyHash(key, entry.Hash)), []byte(entry.Signature)) {
+			return broken(entry, "entry %d has an invalid signature", entry.Seq)
+		}
+		verification.Head = entry.Hash
+	}
+	return verification
+}
+
+func (s *ProjectService) getTransparencyLog(projectId string) ([]*models.ProjectCredentialTransparencyLog, error) {
+	entries := make([]*models.ProjectCredentialTransparencyLog, 0)
+	_, err := s.Ds.Db.Select(models.ProjectCredentialTransparencyLogColumns...).
+		From(models.ProjectCredentialTransparencyLogTableName).
+		Where(db.Eq(models.ProjectIdColumn, projectId)).
+		OrderDir(models.ProjectCredentialTransparencyLogSeqColumn, true).
+		Load(&entries)
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// appendTransparencyLog links a new entry for the credential mutation to the head of the project chain.
+func (s *ProjectService) appendTransparencyLog(projectId, operator, operation, domain, credentialId string,
+	content map[string]interface{}) error {
+	transparencyLogLock.Lock()
+	defer transparencyLogLock.Unlock()
+	return s.Ds.Db.WithTransaction(func(tx *dbr.Tx) error {
+		return s.appendTransparencyLogTx(tx, projectId, operator, operation, domain, credentialId, content)
+	})
+}
+
+// appendTransparencyLogTx appends the entry like appendTransparencyLog within tx, so the entry is only
+// kept with the records the mutation changes. The caller holds transparencyLogLock until tx is done.
+func (s *ProjectService) appendTransparencyLogTx(tx *dbr.Tx, projectId, operator, operation, domain,
+	credentialId string, content map[string]interface{}) error {
+	if govalidator.IsNull(domain) {
+		domain = "_"
+	}
+	entry := &models.ProjectCredentialTransparencyLog{
+		ProjectId:    projectId,
+		Seq:          1,
+		CredentialId: credentialId,
+		Domain:       domain,
+		Operation:    operation,
+		Operator:     operator,
+		ContentHash:  transparencyContentHash(content),
+		CreateTime:   time.Unix(time.Now().Unix(), 0),
+		PrevHash:     transparencyGenesisHash,
+	}
+	last := &models.ProjectCredentialTransparencyLog{}
+	err := tx.Select(models.ProjectCredentialTransparencyLogColumns...).
+		From(models.ProjectCredentialTransparencyLogTableName).
+		Where(db.Eq(models.ProjectIdColumn, projectId)).
+		OrderDir(models.ProjectCredentialTransparencyLogSeqColumn, false).
+		Limit(1).
+		LoadOne(last)
+	if err != nil && err != db.ErrNotFound {
+		return err
+	}
+	if err == nil {
+		entry.Seq = last.Seq + 1
+		entry.PrevHash = last.Hash
+	}
+	entry.Hash = transparencyEntryHash(entry)
+	entry.Signature = signTransparencyHash(s.Config.Transparency.SigningKey, entry.Hash)
+	_, err = tx.InsertInto(models.ProjectCredentialTransparencyLogTableName).
+		Columns(models.ProjectCredentialTransparencyLogColumns...).
+		Record(entry).Exec()
+	return err
+}
+
+// recordCredentialMutation appends the mutation to the transparency log, the mutation is already
+// done in Jenkins so a failure is only logged.
+func (s *ProjectService) recordCredentialMutation(projectId, operator, operation, domain, credentialId string,
+	content map[string]interface{}) {
+	err := s.appendTransparencyLog(projectId, operator, operation, domain, credentialId, content)
+	if err != nil {
+		logger.Error("failed to append %s of credential [%s] in project [%s] to the transparency log: %+v",
+			operation, credentialId, projectId, err)
+	}
+}
+
+func (s *ProjectService) GetCredentialTransparencyLogHandler(w rest.ResponseWriter, r *rest.Request) {
+	projectId := r.PathParams["id"]
+	operator := userutils.GetUserNameFromRequest(r)
+	err := s.checkProjectUserInRole(r, operator, projectId, []string{ProjectOwner, ProjectMaintainer})
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
+		return
+	}
+	entries, err := s.getTransparencyLog(projectId)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	w.WriteJson(&TransparencyLogResponse{
+		Entries:      entries,
+		Verification: verifyTransparencyChain(entries, s.Config.Transparency.SigningKey),
+	})
+	return
+}
+
+func (s *ProjectService) VerifyCredentialTransparencyLogHandler(w rest.ResponseWriter, r *rest.Request) {
+	projectId := r.PathParams["id"]
+	operator := userutils.GetUserNameFromRequest(r)
+	err := s.checkProjectUserInRole(r, operator, projectId, []string{ProjectOwner, ProjectMaintainer})
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
+		return
+	}
+	entries, err := s.getTransparencyLog(projectId)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	w.WriteJson(verifyTransparencyChain(entries, s.Config.Transparency.SigningKey))
+	return
+}
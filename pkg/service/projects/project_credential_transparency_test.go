@@ -0,0 +1,109 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"testing"
+	"time"
+
+	"kubesphere.io/devops/pkg/models"
+)
+
+func newTransparencyChain(key string, operations ...string) []*models.ProjectCredentialTransparencyLog {
+	entries := make([]*models.ProjectCredentialTransparencyLog, 0, len(operations))
+	prevHash := transparencyGenesisHash
+	for i, operation := range operations {
+		entry := &models.ProjectCredentialTransparencyLog{
+			ProjectId:    "project-1",
+			Seq:          int64(i + 1),
+			CredentialId: "cred",
+			Domain:       "_",
+			Operation:    operation,
+			Operator:     "admin",
+			ContentHash:  transparencyContentHash(map[string]interface{}{"id": "cred", "secret": operation}),
+			CreateTime:   time.Unix(1500000000+int64(i), 0),
+			PrevHash:     prevHash,
+		}
+		entry.Hash = transparencyEntryHash(entry)
+		entry.Signature = signTransparencyHash(key, entry.Hash)
+		prevHash = entry.Hash
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func Test_VerifyTransparencyChain(t *testing.T) {
+	operations := []string{CredentialActionCreate, CredentialActionUpdate, CredentialActionDelete}
+	for _, test := range []struct {
+		name     string
+		key      string
+		tamper   func(entries []*models.ProjectCredentialTransparencyLog) []*models.ProjectCredentialTransparencyLog
+		brokenAt int64
+	}{
+		{
+			name: "valid",
+		},
+		{
+			name: "valid signed",
+			key:  "signing-key",
+		},
+		{
+			name: "changed operator",
+			tamper: func(entries []*models.ProjectCredentialTransparencyLog) []*models.ProjectCredentialTransparencyLog {
+				entries[1].Operator = "someone"
+				return entries
+			},
+			brokenAt: 2,
+		},
+		{
+			name: "removed entry",
+			tamper: func(entries []*models.ProjectCredentialTransparencyLog) []*models.ProjectCredentialTransparencyLog {
+				return append(entries[:1], entries[2:]...)
+			},
+			brokenAt: 3,
+		},
+		{
+			name: "wrong signature",
+			key:  "signing-key",
+			tamper: func(entries []*models.ProjectCredentialTransparencyLog) []*models.ProjectCredentialTransparencyLog {
+				entries[2].Signature = signTransparencyHash("other-key", entries[2].Hash)
+				return entries
+			},
+			brokenAt: 3,
+		},
+	} {
+		entries := newTransparencyChain(test.key, operations...)
+		if test.tamper != nil {
+			entries = test.tamper(entries)
+		}
+		verification := verifyTransparencyChain(entries, test.key)
+		if test.brokenAt == 0 {
+			if !verification.Valid || verification.Head != entries[len(entries)-1].Hash {
+				t.Fatalf("%s: chain should be valid, got %+v", test.name, verification)
+			}
+			continue
+		}
+		if verification.Valid || verification.BrokenAt == nil || *verification.BrokenAt != test.brokenAt {
+			t.Fatalf("%s: chain should break at %d, got %+v", test.name, test.brokenAt, verification)
+		}
+	}
+}
+
+func Test_TransparencyContentHashCoversSecrets(t *testing.T) {
+	first := transparencyContentHash(map[string]interface{}{"id": "cred", "password": "a"})
+	second := transparencyContentHash(map[string]interface{}{"id": "cred", "password": "b"})
+	if first == second {
+		t.Fatalf("content hash should change with the secret")
+	}
+}
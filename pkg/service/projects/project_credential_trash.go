@@ -0,0 +1,279 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/asaskevich/govalidator"
+
+	"kubesphere.io/devops/pkg/db"
+	"kubesphere.io/devops/pkg/gojenkins"
+	"kubesphere.io/devops/pkg/logger"
+	"kubesphere.io/devops/pkg/models"
+	"kubesphere.io/devops/pkg/utils/cryptoutils"
+	"kubesphere.io/devops/pkg/utils/userutils"
+)
+
+type RestoreCredentialResponse struct {
+	Id     string `json:"id"`
+	Domain string `json:"domain"`
+}
+
+func (s *ProjectService) credentialTrashEnabled() bool {
+	return !govalidator.IsNull(s.Config.Trash.Key) && s.Config.Trash.Retention > 0
+}
+
+// CredentialNotRestorableError refuses to delete a credential that could not be restored from the trash,
+// it is only deleted permanently with force.
+type CredentialNotRestorableError struct {
+	CredentialId string
+	Reason       string
+}
+
+func (e *CredentialNotRestorableError) Error() string {
+	return fmt.Sprintf("credential [%s] can not be restored once deleted, %s, delete it with force=true "+
+		"to delete it permanently", e.CredentialId, e.Reason)
+}
+
+// trashBeforeDelete trashes a credential of target about to be deleted when the trash is enabled,
+// nil is returned when it is not or when force deletes an unrestorable credential permanently.
+func (s *ProjectService) trashBeforeDelete(target credentialTarget, operator, credentialId string,
+	force bool) (*models.ProjectCredentialTrash, error) {
+	if !s.credentialTrashEnabled() {
+		return nil, nil
+	}
+	return s.trashCredential(target, operator, credentialId, force)
+}
+
+// trashCredential stores the encrypted content of a credential of target about to be deleted. Credentials whose
+// secret can not be read back from Jenkins can not be restored, a CredentialNotRestorableError is returned for
+// them unless force deletes them permanently, then nil is returned.
+func (s *ProjectService) trashCredential(target credentialTarget, operator, credentialId string, force bool) (
+	*models.ProjectCredentialTrash, error) {
+	projectId, domain := target.projectId, target.domain
+	jenkinsCredential, err := target.get(context.Background(), s.credentialStore(), credentialId)
+	if err != nil {
+		return nil, err
+	}
+	credentialType, err := s.getCredentialType(projectId, jenkinsCredential)
+	if err != nil {
+		return nil, err
+	}
+	secretField, ok := copyCredentialSecretFields[credentialType]
+	if !ok {
+		if !force {
+			return nil, &CredentialNotRestorableError{CredentialId: credentialId,
+				Reason: fmt.Sprintf("the secret of %s credentials can not be read back", credentialType)}
+		}
+		logger.Warn("%s credential [%s] of project [%s] is deleted permanently, its secret can not be read back",
+			credentialType, credentialId, projectId)
+		return nil, nil
+	}
+	content, err := s.getCredentialContent(projectId, domain, credentialId, credentialType)
+	if err != nil {
+		return nil, err
+	}
+	if secret, _ := content[secretField].(string); govalidator.IsNull(secret) {
+		if !force {
+			return nil, &CredentialNotRestorableError{CredentialId: credentialId,
+				Reason: fmt.Sprintf("its %s can not be read back", secretField)}
+		}
+		logger.Warn("credential [%s] of project [%s] is deleted permanently, its %s can not be read back",
+			credentialId, projectId, secretField)
+		return nil, nil
+	}
+	plaintext, err := json.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := cryptoutils.Encrypt(cryptoutils.DeriveKey(s.Config.Trash.Key), plaintext)
+	if err != nil {
+		return nil, err
+	}
+	trash := models.NewProjectCredentialTrash(projectId, credentialId, domain, credentialType, encrypted, operator,
+		s.Config.Trash.Retention)
+	if target.global {
+		trash.Store = models.CredentialStoreGlobal
+	}
+	_, err = s.Ds.Db.InsertInto(models.ProjectCredentialTrashTableName).
+		Columns(models.ProjectCredentialTrashColumns...).Record(trash).Exec()
+	if err != nil {
+		return nil, err
+	}
+	return trash, nil
+}
+
+// untrashCredential removes the trash of a credential whose delete failed, trash may be nil.
+func (s *ProjectService) untrashCredential(ctx context.Context, trash *models.ProjectCredentialTrash) {
+	if trash == nil {
+		return
+	}
+	if err := s.removeCredentialTrash(trash.TrashId); err != nil {
+		logger.WarnContext(ctx, "failed to remove credential [%s] from trash: %+v", trash.TrashId, err)
+	}
+}
+
+func (s *ProjectService) removeCredentialTrash(trashId string) error {
+	_, err := s.Ds.Db.DeleteFrom(models.ProjectCredentialTrashTableName).
+		Where(db.Eq(models.ProjectCredentialTrashIdColumn, trashId)).Exec()
+	return err
+}
+
+// GetCredentialTrashHandler lists a page of the deleted credentials of the project that can still be restored,
+// the ones expiring last first.
+func (s *ProjectService) GetCredentialTrashHandler(w rest.ResponseWriter, r *rest.Request) {
+	projectId := r.PathParams["id"]
+	operator := userutils.GetUserNameFromRequest(r)
+	err := s.checkProjectUserInRole(r, operator, projectId, s.credentialActionRoles(CredentialActionView))
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
+		return
+	}
+	limit, offset, err := parsePage(r)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	trashes := make([]*models.ProjectCredentialTrash, 0)
+	query := s.Ds.Db.Select(models.ProjectCredentialTrashColumns...).
+		From(models.ProjectCredentialTrashTableName).
+		Where(db.And(db.Eq(models.ProjectIdColumn, projectId),
+			db.Gt(models.ProjectCredentialTrashExpireTimeColumn, time.Now())))
+	total, err := db.Count(query)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	_, err = db.Paginate(query, limit, offset, "-"+models.ProjectCredentialTrashExpireTimeColumn,
+		models.ProjectCredentialTrashIdColumn).Load(&trashes)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set(totalCountHeader, strconv.FormatUint(uint64(total), 10))
+	w.WriteJson(trashes)
+	return
+}
+
+// RestoreCredentialHandler creates a deleted credential again with the content it had when it was deleted,
+// as long as its retention has not expired. Its secrets are encrypted by Jenkins, the restored credential is
+// recorded without strength or key size.
+func (s *ProjectService) RestoreCredentialHandler(w rest.ResponseWriter, r *rest.Request) {
+	projectId := r.PathParams["id"]
+	defer s.invalidateCredentialList(projectId)
+	trashId := r.PathParams["tid"]
+	operator := userutils.GetUserNameFromRequest(r)
+	err := s.checkProjectUserInRole(r, operator, projectId, s.credentialActionRoles(CredentialActionCreate))
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
+		return
+	}
+	trash := &models.ProjectCredentialTrash{}
+	err = s.Ds.Db.Select(models.ProjectCredentialTrashColumns...).
+		From(models.ProjectCredentialTrashTableName).
+		Where(db.And(db.Eq(models.ProjectIdColumn, projectId),
+			db.Eq(models.ProjectCredentialTrashIdColumn, trashId))).LoadOne(trash)
+	if err == nil && time.Now().After(trash.ExpireTime) {
+		err = db.ErrNotFound
+	}
+	if err == db.ErrNotFound {
+		err := fmt.Errorf("deleted credential [%s] not found or its retention expired", trashId)
+		logger.WarnContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	plaintext, err := cryptoutils.Decrypt(cryptoutils.DeriveKey(s.Config.Trash.Key), trash.Content)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "failed to decrypt deleted credential [%s]: %+v", trashId, err)
+		writeCredentialError(w, fmt.Errorf("deleted credential [%s] can not be decrypted", trashId),
+			http.StatusInternalServerError)
+		return
+	}
+	content := make(map[string]interface{})
+	err = json.Unmarshal(plaintext, &content)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	target := newCredentialTarget(projectId, trash.Domain, trash.Store)
+	if target.global {
+		err = s.checkGlobalStoreWriter(r, operator, projectId)
+		if err != nil {
+			logger.ErrorContext(r.Context(), "%+v", err)
+			writeCredentialError(w, err, http.StatusForbidden)
+			return
+		}
+	}
+	existing, err := target.get(r.Context(), s.credentialStore(), trash.CredentialId)
+	if existing != nil {
+		err := fmt.Errorf("credential id [%s] has been used in domain [%s]", trash.CredentialId, trash.Domain)
+		logger.WarnContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusConflict)
+		return
+	}
+	if err != nil && !errors.Is(err, gojenkins.ErrCredentialNotFound) {
+		logCredentialError(r.Context(), err)
+		writeJenkinsError(w, r, err)
+		return
+	}
+	setCredentialOperationType(r, trash.Type)
+	_, err = s.createCredentialContent(r.Context(), target, operator,
+		&CredentialRequest{Type: trash.Type, Domain: trash.Domain, Store: trash.Store, Content: content,
+			jenkinsContent: true})
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeJenkinsError(w, r, err)
+		return
+	}
+	err = s.removeCredentialTrash(trash.TrashId)
+	if err != nil {
+		logger.WarnContext(r.Context(), "failed to remove restored credential [%s] from trash: %+v", trash.TrashId, err)
+	}
+	logger.Info("credential [%s] of project [%s] restored by [%s]", trash.CredentialId, projectId, operator)
+	w.WriteJson(&RestoreCredentialResponse{Id: trash.CredentialId, Domain: trash.Domain})
+	return
+}
+
+// PurgeExpiredCredentialTrash permanently removes the content of deleted credentials whose retention expired.
+func (s *ProjectService) PurgeExpiredCredentialTrash() {
+	result, err := s.Ds.Db.DeleteFrom(models.ProjectCredentialTrashTableName).
+		Where(db.Lte(models.ProjectCredentialTrashExpireTimeColumn, time.Now())).Exec()
+	if err != nil {
+		logger.Error("failed to purge expired deleted credentials: %+v", err)
+		return
+	}
+	if purged, err := result.RowsAffected(); err == nil && purged > 0 {
+		logger.Info("purged %d expired deleted credentials", purged)
+	}
+}
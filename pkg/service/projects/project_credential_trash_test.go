@@ -0,0 +1,56 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"kubesphere.io/devops/pkg/models"
+)
+
+func TestRestoreCredentialHandlerSsh(t *testing.T) {
+	s, store, projectIds, cleanup := newDbTestService(t, 1)
+	defer cleanup()
+	projectId := projectIds[0]
+	s.Config.Trash.Key = "trash key"
+	s.Config.Trash.Retention = time.Hour
+	key := readTestKey(t, "ssh_ed25519.key")
+	if _, err := store.CreateSshCredentialInFolder("_", "deploy", "git", "", key, "deploy key", "",
+		projectId); err != nil {
+		t.Fatal(err)
+	}
+	trash, err := s.trashCredential(newCredentialTarget(projectId, "_", models.CredentialStoreFolder), "alice",
+		"deploy", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.DeleteCredentialInFolder("_", "deploy", projectId); err != nil {
+		t.Fatal(err)
+	}
+
+	w := &recorder{httptest.NewRecorder()}
+	r := newProjectJsonRequest("alice", projectId, map[string]string{projectId: ProjectOwner}, "POST",
+		"/projects/"+projectId+"/credentials/trash/"+trash.TrashId+"/restore", nil)
+	r.PathParams["tid"] = trash.TrashId
+	s.RestoreCredentialHandler(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("deleted ssh credential should be restored, got %d %s", w.Code, w.Body)
+	}
+	if restored := fakeCredentialField(t, store, projectId, "_", "deploy", "privateKey"); restored != key {
+		t.Fatalf("restored credential should have its private key, got [%s]", restored)
+	}
+}
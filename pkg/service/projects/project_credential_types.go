@@ -0,0 +1,81 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/ant0ine/go-json-rest/rest"
+)
+
+// CredentialTypeResponse describes a credential type supported by the handlers, the label is the
+// name Jenkins gives the type and the fields are the ones of its content.
+type CredentialTypeResponse struct {
+	Type            string   `json:"type"`
+	Label           string   `json:"label"`
+	JenkinsTypeName string   `json:"jenkins_type_name"`
+	Fields          []string `json:"fields"`
+	RequiredFields  []string `json:"required_fields"`
+}
+
+// derivedCredentialTypes are stored in Jenkins as another type, they are told apart by their db records.
+var derivedCredentialTypes = map[string]struct {
+	baseType string
+	label    string
+}{
+	CredentialTypeDockerRegistry: {baseType: CredentialTypeUsernamePassword, label: "Docker registry"},
+}
+
+// credentialTypes lists the types of credentialRequestFactories, named after CredentialTypeMap
+// and with the fields of their request structs.
+func credentialTypes() []*CredentialTypeResponse {
+	jenkinsTypeNames := make(map[string]string, len(CredentialTypeMap))
+	for typeName, credentialType := range CredentialTypeMap {
+		jenkinsTypeNames[credentialType] = typeName
+	}
+	types := make([]*CredentialTypeResponse, 0, len(credentialRequestFactories))
+	for credentialType, factory := range credentialRequestFactories {
+		schema := jsonSchemaOf(reflect.TypeOf(factory()))
+		fields := make([]string, 0, len(schema.Properties))
+		for field := range schema.Properties {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		required := append([]string{}, schema.Required...)
+		sort.Strings(required)
+		response := &CredentialTypeResponse{
+			Type:            credentialType,
+			Label:           jenkinsTypeNames[credentialType],
+			JenkinsTypeName: jenkinsTypeNames[credentialType],
+			Fields:          fields,
+			RequiredFields:  required,
+		}
+		if derived, ok := derivedCredentialTypes[credentialType]; ok {
+			response.Label = derived.label
+			response.JenkinsTypeName = jenkinsTypeNames[derived.baseType]
+		}
+		types = append(types, response)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		return types[i].Type < types[j].Type
+	})
+	return types
+}
+
+// GetCredentialTypesHandler returns the supported credential types with their labels and content fields.
+func (s *ProjectService) GetCredentialTypesHandler(w rest.ResponseWriter, r *rest.Request) {
+	w.WriteJson(credentialTypes())
+	return
+}
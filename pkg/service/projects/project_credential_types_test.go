@@ -0,0 +1,45 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCredentialTypes(t *testing.T) {
+	types := credentialTypes()
+	if len(types) != len(credentialRequestFactories) {
+		t.Fatalf("expected %d credential types, got %d", len(credentialRequestFactories), len(types))
+	}
+	byType := make(map[string]*CredentialTypeResponse, len(types))
+	for _, credentialType := range types {
+		if credentialType.Label == "" || credentialType.JenkinsTypeName == "" {
+			t.Fatalf("expected credential type [%s] to be labeled, got %+v", credentialType.Type, credentialType)
+		}
+		byType[credentialType.Type] = credentialType
+	}
+
+	secretFile := byType[CredentialTypeSecretFile]
+	if secretFile.JenkinsTypeName != "Secret file" {
+		t.Fatalf("unexpected jenkins type name %s", secretFile.JenkinsTypeName)
+	}
+	if !reflect.DeepEqual(secretFile.RequiredFields, []string{"content", "file_name", "id"}) {
+		t.Fatalf("unexpected required fields %v", secretFile.RequiredFields)
+	}
+	registry := byType[CredentialTypeDockerRegistry]
+	if registry.JenkinsTypeName != "Username with password" || registry.Label != "Docker registry" {
+		t.Fatalf("unexpected docker registry type %+v", registry)
+	}
+}
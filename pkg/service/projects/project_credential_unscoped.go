@@ -0,0 +1,73 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"net/http"
+
+	"github.com/ant0ine/go-json-rest/rest"
+
+	"kubesphere.io/devops/pkg/config"
+	"kubesphere.io/devops/pkg/logger"
+	"kubesphere.io/devops/pkg/utils/stringutils"
+	"kubesphere.io/devops/pkg/utils/userutils"
+)
+
+const CredentialUnscopedRemediation = "move it to a domain restricted to the hosts it is used for"
+
+type UnscopedCredentialResponse struct {
+	Id          string `json:"id"`
+	Type        string `json:"type"`
+	Domain      string `json:"domain"`
+	Remediation string `json:"remediation"`
+}
+
+// isCredentialUnscoped reports whether the credential type requires a domain scope
+// but the credential is in the global domain.
+func isCredentialUnscoped(cfg *config.LintConfig, credential *CredentialResponse) bool {
+	return credential.Domain == "_" && stringutils.StringIn(credential.Type, cfg.DomainScopedTypes)
+}
+
+func (s *ProjectService) GetUnscopedCredentialsHandler(w rest.ResponseWriter, r *rest.Request) {
+	projectId := r.PathParams["id"]
+	operator := userutils.GetUserNameFromRequest(r)
+	err := s.checkProjectUserInRole(r, operator, projectId, []string{ProjectOwner, ProjectMaintainer})
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
+		return
+	}
+
+	credentials, err := s.listCredentials(projectId, "_")
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeCredentialError(w, err, stringutils.GetJenkinsStatusCode(err))
+		return
+	}
+
+	response := make([]*UnscopedCredentialResponse, 0)
+	for _, credential := range credentials {
+		if !isCredentialUnscoped(&s.Config.Lint, credential) {
+			continue
+		}
+		response = append(response, &UnscopedCredentialResponse{
+			Id:          credential.Id,
+			Type:        credential.Type,
+			Domain:      credential.Domain,
+			Remediation: CredentialUnscopedRemediation,
+		})
+	}
+	w.WriteJson(response)
+	return
+}
@@ -0,0 +1,369 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/ant0ine/go-json-rest/rest"
+
+	"kubesphere.io/devops/pkg/gojenkins"
+	"kubesphere.io/devops/pkg/logger"
+	"kubesphere.io/devops/pkg/utils/stringutils"
+	"kubesphere.io/devops/pkg/utils/userutils"
+)
+
+const (
+	CredentialReferenceActive   = "active"
+	CredentialReferenceDisabled = "disabled"
+	CredentialReferenceMissing  = "missing"
+)
+
+const jenkinsMultiBranchProjectClass = "org.jenkinsci.plugins.workflow.multibranch.WorkflowMultiBranchProject"
+
+type CredentialReference struct {
+	Name   string `json:"name"`
+	Builds int    `json:"builds"`
+	Status string `json:"status"`
+	// the job is not one of the jobs the credential is intended for, which is not enforced
+	Unintended bool `json:"unintended,omitempty"`
+}
+
+// CredentialConfigReference is a reference from configuration, which leaves no build fingerprint.
+type CredentialConfigReference struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// path of the element holding the credential id in the configuration
+	Path string `json:"path"`
+}
+
+type CredentialUsageResponse struct {
+	Id         string                 `json:"id"`
+	Domain     string                 `json:"domain"`
+	Active     int                    `json:"active"`
+	Stale      int                    `json:"stale"`
+	References []*CredentialReference `json:"references"`
+	// only filled when config scan is enabled
+	ConfigReferences []*CredentialConfigReference `json:"config_references,omitempty"`
+}
+
+type BranchUsage struct {
+	// branch name as shown in the SCM, the job name is url encoded by Jenkins
+	Branch string `json:"branch"`
+	Job    string `json:"job"`
+	Builds int    `json:"builds"`
+	Status string `json:"status"`
+}
+
+type PipelineBranchUsage struct {
+	Pipeline string         `json:"pipeline"`
+	Branches []*BranchUsage `json:"branches"`
+}
+
+type CredentialBranchUsageResponse struct {
+	Id        string                 `json:"id"`
+	Domain    string                 `json:"domain"`
+	Pipelines []*PipelineBranchUsage `json:"pipelines"`
+}
+
+// jobTree resolves the current status of jobs by their full name,
+// the children of each folder are fetched once and cached.
+type jobTree struct {
+	store    CredentialStore
+	children map[string][]gojenkins.InnerJob
+}
+
+func newJobTree(store CredentialStore) *jobTree {
+	return &jobTree{store: store, children: make(map[string][]gojenkins.InnerJob)}
+}
+
+func (t *jobTree) getChildren(parents []string) ([]gojenkins.InnerJob, error) {
+	key := strings.Join(parents, "/")
+	if jobs, ok := t.children[key]; ok {
+		return jobs, nil
+	}
+	var jobs []gojenkins.InnerJob
+	if len(parents) == 0 {
+		innerJobs, err := t.store.GetAllJobNames()
+		if err != nil {
+			return nil, err
+		}
+		jobs = innerJobs
+	} else {
+		folderParents := make([]string, len(parents)-1)
+		copy(folderParents, parents)
+		folder, err := t.store.GetFolder(parents[len(parents)-1], folderParents...)
+		if err != nil && stringutils.GetJenkinsStatusCode(err) != http.StatusNotFound {
+			return nil, err
+		}
+		if folder != nil {
+			jobs = folder.Raw.Jobs
+		}
+	}
+	t.children[key] = jobs
+	return jobs, nil
+}
+
+// getStatus returns missing if any level of the job full name does not exist anymore,
+// disabled if the job is disabled, otherwise active.
+func (t *jobTree) getStatus(fullName string) (string, error) {
+	names := strings.Split(fullName, "/")
+	for i, name := range names {
+		jobs, err := t.getChildren(names[:i])
+		if err != nil {
+			return "", err
+		}
+		var current *gojenkins.InnerJob
+		for j := range jobs {
+			if jobs[j].Name == name {
+				current = &jobs[j]
+				break
+			}
+		}
+		if current == nil {
+			return CredentialReferenceMissing, nil
+		}
+		if strings.HasPrefix(current.Color, "disabled") {
+			return CredentialReferenceDisabled, nil
+		}
+	}
+	return CredentialReferenceActive, nil
+}
+
+func (s *ProjectService) getCredentialUsage(credential *gojenkins.CredentialResponse) (*CredentialUsageResponse, error) {
+	response := &CredentialUsageResponse{
+		Id:         credential.Id,
+		Domain:     credential.Domain,
+		References: make([]*CredentialReference, 0),
+	}
+	if credential.Fingerprint == nil {
+		return response, nil
+	}
+
+	tree := newJobTree(s.credentialStore())
+	for _, usage := range credential.Fingerprint.Usage {
+		reference := &CredentialReference{Name: usage.Name}
+		for _, buildRange := range usage.Ranges.Ranges {
+			reference.Builds += buildRange.End - buildRange.Start
+		}
+		status, err := tree.getStatus(usage.Name)
+		if err != nil {
+			return nil, err
+		}
+		reference.Status = status
+		if status == CredentialReferenceActive {
+			response.Active++
+		} else {
+			response.Stale++
+		}
+		response.References = append(response.References, reference)
+	}
+	return response, nil
+}
+
+// getCredentialBranchUsage groups the fingerprint usage of the credential by multibranch pipeline,
+// usage by jobs which are not branches of a multibranch pipeline is left out. Branches of pipelines
+// which have been deleted can not be told apart from other jobs and are left out too.
+func (s *ProjectService) getCredentialBranchUsage(credential *gojenkins.CredentialResponse) (*CredentialBranchUsageResponse, error) {
+	response := &CredentialBranchUsageResponse{
+		Id:        credential.Id,
+		Domain:    credential.Domain,
+		Pipelines: make([]*PipelineBranchUsage, 0),
+	}
+	if credential.Fingerprint == nil {
+		return response, nil
+	}
+
+	tree := newJobTree(s.credentialStore())
+	multiBranch := make(map[string]bool)
+	pipelines := make(map[string]*PipelineBranchUsage)
+	for _, usage := range credential.Fingerprint.Usage {
+		names := strings.Split(usage.Name, "/")
+		// branch jobs are at least project/pipeline/branch
+		if len(names) < 3 {
+			continue
+		}
+		parents, pipelineName, branchName := names[:len(names)-2], names[len(names)-2], names[len(names)-1]
+		pipelineFullName := strings.Join(names[:len(names)-1], "/")
+		isMultiBranch, ok := multiBranch[pipelineFullName]
+		if !ok {
+			job, err := s.credentialStore().GetJob(pipelineName, parents...)
+			if err != nil && stringutils.GetJenkinsStatusCode(err) != http.StatusNotFound {
+				return nil, err
+			}
+			isMultiBranch = job != nil && job.Raw.Class == jenkinsMultiBranchProjectClass
+			multiBranch[pipelineFullName] = isMultiBranch
+		}
+		if !isMultiBranch {
+			continue
+		}
+
+		branch := &BranchUsage{Branch: branchName, Job: usage.Name}
+		if unescaped, err := url.PathUnescape(branchName); err == nil {
+			branch.Branch = unescaped
+		}
+		for _, buildRange := range usage.Ranges.Ranges {
+			branch.Builds += buildRange.End - buildRange.Start
+		}
+		status, err := tree.getStatus(usage.Name)
+		if err != nil {
+			return nil, err
+		}
+		branch.Status = status
+
+		pipeline, ok := pipelines[pipelineFullName]
+		if !ok {
+			pipeline = &PipelineBranchUsage{Pipeline: pipelineFullName, Branches: make([]*BranchUsage, 0)}
+			pipelines[pipelineFullName] = pipeline
+			response.Pipelines = append(response.Pipelines, pipeline)
+		}
+		pipeline.Branches = append(pipeline.Branches, branch)
+	}
+
+	sort.Slice(response.Pipelines, func(i, j int) bool {
+		return response.Pipelines[i].Pipeline < response.Pipelines[j].Pipeline
+	})
+	for _, pipeline := range response.Pipelines {
+		branches := pipeline.Branches
+		sort.Slice(branches, func(i, j int) bool {
+			return branches[i].Branch < branches[j].Branch
+		})
+	}
+	return response, nil
+}
+
+// getCredentialFullUsage returns the fingerprint usage of the credential, with its config references
+// when config scan is enabled.
+func (s *ProjectService) getCredentialFullUsage(projectId string,
+	credential *gojenkins.CredentialResponse) (*CredentialUsageResponse, error) {
+	response, err := s.getCredentialUsage(credential)
+	if err != nil {
+		return nil, err
+	}
+	projectCredential, err := s.getProjectCredential(projectId, credential.Domain, credential.Id)
+	if err != nil {
+		return nil, err
+	}
+	markUnintendedReferences(projectId, response, parseIntendedJobs(projectCredential))
+	if s.Config.ConfigScan.Enabled {
+		response.ConfigReferences, err = s.getCredentialConfigReferences(projectId, credential.Id)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return response, nil
+}
+
+// checkCredentialNotInUse fails with a CredentialInUseError when active jobs or configuration still
+// reference the credential, references by deleted or disabled jobs do not count.
+func (s *ProjectService) checkCredentialNotInUse(projectId, domain, credentialId string) error {
+	target, err := s.credentialTargetOf(projectId, domain, credentialId)
+	if err != nil {
+		return err
+	}
+	credential, err := target.get(context.Background(), s.credentialStore(), credentialId)
+	if err != nil {
+		return err
+	}
+	usage, err := s.getCredentialFullUsage(projectId, credential)
+	if err != nil {
+		return err
+	}
+	if usage.Active == 0 && len(usage.ConfigReferences) == 0 {
+		return nil
+	}
+	return &CredentialInUseError{
+		CredentialError: CredentialError{
+			Code: ErrorCodeCredentialInUse,
+			Message: fmt.Sprintf("credential [%s] is used by %d jobs and %d configurations, "+
+				"delete it with force=true to delete it anyway", credentialId, usage.Active, len(usage.ConfigReferences)),
+		},
+		Usage: usage,
+	}
+}
+
+func (s *ProjectService) GetCredentialUsageHandler(w rest.ResponseWriter, r *rest.Request) {
+	projectId := r.PathParams["id"]
+	credentialId := r.PathParams["cid"]
+	domain := r.URL.Query().Get("domain")
+	operator := userutils.GetUserNameFromRequest(r)
+	err := s.checkProjectUserInRole(r, operator, projectId, []string{ProjectOwner, ProjectMaintainer})
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
+		return
+	}
+
+	target, err := s.credentialTargetOf(projectId, domain, credentialId)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	credential, err := target.get(r.Context(), s.credentialStore(), credentialId)
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeCredentialError(w, err, stringutils.GetJenkinsStatusCode(err))
+		return
+	}
+
+	response, err := s.getCredentialFullUsage(projectId, credential)
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeCredentialError(w, err, stringutils.GetJenkinsStatusCode(err))
+		return
+	}
+	w.WriteJson(response)
+	return
+}
+
+func (s *ProjectService) GetCredentialBranchUsageHandler(w rest.ResponseWriter, r *rest.Request) {
+	projectId := r.PathParams["id"]
+	credentialId := r.PathParams["cid"]
+	domain := r.URL.Query().Get("domain")
+	operator := userutils.GetUserNameFromRequest(r)
+	err := s.checkProjectUserInRole(r, operator, projectId, []string{ProjectOwner, ProjectMaintainer})
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
+		return
+	}
+
+	target, err := s.credentialTargetOf(projectId, domain, credentialId)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	credential, err := target.get(r.Context(), s.credentialStore(), credentialId)
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeCredentialError(w, err, stringutils.GetJenkinsStatusCode(err))
+		return
+	}
+
+	response, err := s.getCredentialBranchUsage(credential)
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeCredentialError(w, err, stringutils.GetJenkinsStatusCode(err))
+		return
+	}
+	w.WriteJson(response)
+	return
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"encoding/json"
+	"testing"
+
+	"kubesphere.io/devops/pkg/config"
+	"kubesphere.io/devops/pkg/gojenkins"
+)
+
+func newUsedCredential(t *testing.T, jobs ...string) *gojenkins.CredentialResponse {
+	usage := make([]map[string]interface{}, 0, len(jobs))
+	for _, job := range jobs {
+		usage = append(usage, map[string]interface{}{"name": job,
+			"ranges": map[string]interface{}{"ranges": []map[string]int{{"start": 1, "end": 3}}}})
+	}
+	body, _ := json.Marshal(map[string]interface{}{"id": "git", "domain": "_",
+		"fingerprint": map[string]interface{}{"usage": usage}})
+	credential := &gojenkins.CredentialResponse{}
+	if err := json.Unmarshal(body, credential); err != nil {
+		t.Fatal(err)
+	}
+	return credential
+}
+
+func TestGetCredentialUsage(t *testing.T) {
+	store := NewFakeCredentialStore()
+	store.PutJob("project/build", "", "blue")
+	store.PutJob("project/legacy", "", "disabled")
+	s := &ProjectService{Credentials: store, Config: &config.Config{}}
+
+	usage, err := s.getCredentialUsage(newUsedCredential(t, "project/build", "project/legacy", "project/deleted"))
+	if err != nil {
+		t.Fatalf("failed to resolve the usage: %+v", err)
+	}
+	statuses := make([]string, 0, len(usage.References))
+	for _, reference := range usage.References {
+		statuses = append(statuses, reference.Status)
+	}
+	expected := []string{CredentialReferenceActive, CredentialReferenceDisabled, CredentialReferenceMissing}
+	if len(statuses) != len(expected) || usage.Active != 1 || usage.Stale != 2 {
+		t.Fatalf("usage should resolve the status of every job, got %v %+v", statuses, usage)
+	}
+	for i := range expected {
+		if statuses[i] != expected[i] {
+			t.Fatalf("job %s should be %s, got %s", usage.References[i].Name, expected[i], statuses[i])
+		}
+	}
+}
+
+func TestGetCredentialBranchUsage(t *testing.T) {
+	store := NewFakeCredentialStore()
+	store.PutJob("project/service", jenkinsMultiBranchProjectClass, "")
+	store.PutJob("project/service/main", "", "blue")
+	store.PutJob("project/folder/build", "", "blue")
+	s := &ProjectService{Credentials: store, Config: &config.Config{}}
+
+	usage, err := s.getCredentialBranchUsage(newUsedCredential(t, "project/service/main",
+		"project/service/feature%2Fold", "project/folder/build"))
+	if err != nil {
+		t.Fatalf("failed to resolve the branch usage: %+v", err)
+	}
+	if len(usage.Pipelines) != 1 || usage.Pipelines[0].Pipeline != "project/service" {
+		t.Fatalf("only the multibranch pipeline should be listed, got %+v", usage.Pipelines)
+	}
+	branches := usage.Pipelines[0].Branches
+	if len(branches) != 2 || branches[0].Branch != "feature/old" || branches[0].Status != CredentialReferenceMissing ||
+		branches[1].Branch != "main" || branches[1].Status != CredentialReferenceActive {
+		t.Fatalf("branches should be listed with their status, got %+v %+v", branches[0], branches[1])
+	}
+}
@@ -0,0 +1,301 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/asaskevich/govalidator"
+
+	"kubesphere.io/devops/pkg/db"
+	"kubesphere.io/devops/pkg/gojenkins"
+	"kubesphere.io/devops/pkg/logger"
+	"kubesphere.io/devops/pkg/models"
+	"kubesphere.io/devops/pkg/utils/stringutils"
+	"kubesphere.io/devops/pkg/utils/userutils"
+	"kubesphere.io/devops/pkg/utils/webhookutils"
+)
+
+const (
+	CredentialUsageAlertSpike = "spike"
+	CredentialUsageAlertZero  = "zero"
+)
+
+type UpdateCredentialUsageThresholdRequest struct {
+	SpikeThreshold *int  `json:"spike_threshold"`
+	ZeroAlert      *bool `json:"zero_alert"`
+}
+
+// CredentialUsageAlertEvent is the payload sent to the notification webhook when a usage threshold is crossed.
+type CredentialUsageAlertEvent struct {
+	ProjectId      string `json:"project_id"`
+	CredentialId   string `json:"credential_id"`
+	Domain         string `json:"domain"`
+	Alert          string `json:"alert"`
+	Usage          int    `json:"usage"`
+	Delta          int    `json:"delta"`
+	SpikeThreshold int    `json:"spike_threshold,omitempty"`
+}
+
+// countCredentialBuilds returns the number of builds recorded by the credential fingerprint.
+func countCredentialBuilds(credential *gojenkins.CredentialResponse) int {
+	builds := 0
+	if credential.Fingerprint == nil {
+		return builds
+	}
+	for _, usage := range credential.Fingerprint.Usage {
+		for _, buildRange := range usage.Ranges.Ranges {
+			builds += buildRange.End - buildRange.Start
+		}
+	}
+	return builds
+}
+
+// evaluateUsageThreshold records usage as the last usage of threshold and returns the alerts it crosses.
+// The delta is the number of builds since the previous evaluation, a spike alert fires when it reaches
+// the spike threshold and a zero alert fires once when it drops to zero after a period with usage.
+// The first evaluation only records the usage.
+func evaluateUsageThreshold(threshold *models.ProjectCredentialUsageThreshold, usage int, now time.Time) []string {
+	alerts := make([]string, 0)
+	if threshold.LastUsage != nil {
+		delta := usage - *threshold.LastUsage
+		// build discarders remove builds from the fingerprint, which is no usage
+		if delta < 0 {
+			delta = 0
+		}
+		if threshold.SpikeThreshold > 0 && delta >= threshold.SpikeThreshold {
+			alerts = append(alerts, CredentialUsageAlertSpike)
+		}
+		if threshold.ZeroAlert && delta == 0 && threshold.LastDelta != nil && *threshold.LastDelta > 0 {
+			alerts = append(alerts, CredentialUsageAlertZero)
+		}
+		threshold.LastDelta = &delta
+	}
+	threshold.LastUsage = &usage
+	threshold.LastEvaluatedTime = &now
+	return alerts
+}
+
+func (s *ProjectService) getCredentialUsageThreshold(projectId, domain, credentialId string) (*models.ProjectCredentialUsageThreshold, error) {
+	if govalidator.IsNull(domain) {
+		domain = "_"
+	}
+	threshold := &models.ProjectCredentialUsageThreshold{}
+	err := s.Ds.Db.Select(models.ProjectCredentialUsageThresholdColumns...).
+		From(models.ProjectCredentialUsageThresholdTableName).
+		Where(db.And(db.Eq(models.ProjectIdColumn, projectId),
+			db.Eq(models.ProjectCredentialIdColumn, credentialId),
+			db.Eq(models.ProjectCredentialDomainColumn, domain))).
+		LoadOne(threshold)
+	if err == db.ErrNotFound {
+		return models.NewProjectCredentialUsageThreshold(projectId, credentialId, domain), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return threshold, nil
+}
+
+func (s *ProjectService) deleteCredentialUsageThreshold(projectId, domain, credentialId string) error {
+	if govalidator.IsNull(domain) {
+		domain = "_"
+	}
+	_, err := s.Ds.Db.DeleteFrom(models.ProjectCredentialUsageThresholdTableName).
+		Where(db.And(db.Eq(models.ProjectIdColumn, projectId),
+			db.Eq(models.ProjectCredentialIdColumn, credentialId),
+			db.Eq(models.ProjectCredentialDomainColumn, domain))).Exec()
+	if err != nil && err != db.ErrNotFound {
+		return err
+	}
+	return nil
+}
+
+// EvaluateCredentialUsageThresholds compares the current usage of every credential with thresholds
+// against its last evaluated usage and sends the crossed alerts to the notification webhook.
+func (s *ProjectService) EvaluateCredentialUsageThresholds() {
+	webhookConfig := s.Config.Webhook
+	if govalidator.IsNull(webhookConfig.NotificationUrl) {
+		return
+	}
+	thresholds := make([]*models.ProjectCredentialUsageThreshold, 0)
+	_, err := s.Ds.Db.Select(models.ProjectCredentialUsageThresholdColumns...).
+		From(models.ProjectCredentialUsageThresholdTableName).Load(&thresholds)
+	if err != nil {
+		logger.Error("failed to load credential usage thresholds: %+v", err)
+		return
+	}
+
+	for _, threshold := range thresholds {
+		credential, err := s.credentialStore().GetCredentialInFolder(threshold.Domain, threshold.CredentialId,
+			threshold.ProjectId)
+		if err != nil {
+			logger.Warn("failed to get usage of credential [%s] in project [%s]: %+v",
+				threshold.CredentialId, threshold.ProjectId, err)
+			continue
+		}
+		usage := countCredentialBuilds(credential)
+		alerts := evaluateUsageThreshold(threshold, usage, time.Now())
+		sent := true
+		for _, alert := range alerts {
+			event := &CredentialUsageAlertEvent{
+				ProjectId:    threshold.ProjectId,
+				CredentialId: threshold.CredentialId,
+				Domain:       threshold.Domain,
+				Alert:        alert,
+				Usage:        usage,
+				Delta:        *threshold.LastDelta,
+			}
+			if alert == CredentialUsageAlertSpike {
+				event.SpikeThreshold = threshold.SpikeThreshold
+			}
+			statusCode, err := webhookutils.PostJSON(webhookConfig.NotificationUrl, webhookConfig.Secret,
+				webhookConfig.Timeout, event, nil)
+			if err == nil && (statusCode < 200 || statusCode > 299) {
+				err = fmt.Errorf("notification webhook returned %d", statusCode)
+			}
+			if err != nil {
+				logger.Error("failed to send %s usage alert of credential [%s] in project [%s]: %+v",
+					alert, threshold.CredentialId, threshold.ProjectId, err)
+				sent = false
+				continue
+			}
+			logger.Info("sent %s usage alert of credential [%s] in project [%s]",
+				alert, threshold.CredentialId, threshold.ProjectId)
+		}
+		// the usage is evaluated again against the previous one, so the alert is sent on the next evaluation
+		if !sent {
+			continue
+		}
+
+		_, err = s.Ds.Db.Update(models.ProjectCredentialUsageThresholdTableName).
+			Set(models.ProjectCredentialUsageThresholdLastUsageColumn, threshold.LastUsage).
+			Set(models.ProjectCredentialUsageThresholdLastDeltaColumn, threshold.LastDelta).
+			Set(models.ProjectCredentialUsageThresholdLastEvaluatedTimeColumn, threshold.LastEvaluatedTime).
+			Where(db.And(db.Eq(models.ProjectIdColumn, threshold.ProjectId),
+				db.Eq(models.ProjectCredentialIdColumn, threshold.CredentialId),
+				db.Eq(models.ProjectCredentialDomainColumn, threshold.Domain))).Exec()
+		if err != nil {
+			logger.Error("failed to record usage of credential [%s] in project [%s]: %+v",
+				threshold.CredentialId, threshold.ProjectId, err)
+		}
+	}
+}
+
+func (s *ProjectService) GetCredentialUsageThresholdHandler(w rest.ResponseWriter, r *rest.Request) {
+	projectId := r.PathParams["id"]
+	credentialId := r.PathParams["cid"]
+	domain := r.URL.Query().Get("domain")
+	operator := userutils.GetUserNameFromRequest(r)
+	err := s.checkProjectUserInRole(r, operator, projectId, []string{ProjectOwner, ProjectMaintainer})
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
+		return
+	}
+	threshold, err := s.getCredentialUsageThreshold(projectId, domain, credentialId)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	w.WriteJson(threshold)
+	return
+}
+
+// UpdateCredentialUsageThresholdHandler sets the usage thresholds of the credential, a spike threshold of 0
+// disables spike alerts. Changing the thresholds keeps the last evaluated usage.
+func (s *ProjectService) UpdateCredentialUsageThresholdHandler(w rest.ResponseWriter, r *rest.Request) {
+	request := &UpdateCredentialUsageThresholdRequest{}
+	projectId := r.PathParams["id"]
+	credentialId := r.PathParams["cid"]
+	domain := r.URL.Query().Get("domain")
+	operator := userutils.GetUserNameFromRequest(r)
+	err := r.DecodeJsonPayload(request)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	if request.SpikeThreshold != nil && *request.SpikeThreshold < 0 {
+		err = fmt.Errorf("spike_threshold should not be negative")
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusBadRequest)
+		return
+	}
+	err = s.checkProjectUserInRole(r, operator, projectId, []string{ProjectOwner, ProjectMaintainer})
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
+		return
+	}
+	_, err = s.credentialStore().GetCredentialInFolder(domain, credentialId, projectId)
+	if err != nil {
+		logCredentialError(r.Context(), err)
+		writeCredentialError(w, err, stringutils.GetJenkinsStatusCode(err))
+		return
+	}
+	threshold, err := s.getCredentialUsageThreshold(projectId, domain, credentialId)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if request.SpikeThreshold != nil {
+		threshold.SpikeThreshold = *request.SpikeThreshold
+	}
+	if request.ZeroAlert != nil {
+		threshold.ZeroAlert = *request.ZeroAlert
+	}
+
+	err = s.deleteCredentialUsageThreshold(projectId, domain, credentialId)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	_, err = s.Ds.Db.InsertInto(models.ProjectCredentialUsageThresholdTableName).
+		Columns(models.ProjectCredentialUsageThresholdColumns...).
+		Record(threshold).Exec()
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	w.WriteJson(threshold)
+	return
+}
+
+func (s *ProjectService) DeleteCredentialUsageThresholdHandler(w rest.ResponseWriter, r *rest.Request) {
+	projectId := r.PathParams["id"]
+	credentialId := r.PathParams["cid"]
+	domain := r.URL.Query().Get("domain")
+	operator := userutils.GetUserNameFromRequest(r)
+	err := s.checkProjectUserInRole(r, operator, projectId, []string{ProjectOwner, ProjectMaintainer})
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusForbidden)
+		return
+	}
+	err = s.deleteCredentialUsageThreshold(projectId, domain, credentialId)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "%+v", err)
+		writeCredentialError(w, err, http.StatusInternalServerError)
+		return
+	}
+	w.WriteJson(models.NewProjectCredentialUsageThreshold(projectId, credentialId, domain))
+	return
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"kubesphere.io/devops/pkg/models"
+)
+
+func TestEvaluateUsageThreshold(t *testing.T) {
+	threshold := models.NewProjectCredentialUsageThreshold("project-1", "credential-1", "_")
+	threshold.SpikeThreshold = 10
+	threshold.ZeroAlert = true
+	now := time.Now()
+	for _, test := range []struct {
+		usage  int
+		alerts []string
+		delta  int
+	}{
+		// the first evaluation only records the usage
+		{usage: 5, alerts: []string{}},
+		{usage: 9, alerts: []string{}, delta: 4},
+		{usage: 19, alerts: []string{CredentialUsageAlertSpike}, delta: 10},
+		{usage: 19, alerts: []string{CredentialUsageAlertZero}, delta: 0},
+		// the zero alert fires once until the credential is used again
+		{usage: 19, alerts: []string{}, delta: 0},
+		// builds removed by discarders are no usage
+		{usage: 12, alerts: []string{}, delta: 0},
+		{usage: 13, alerts: []string{}, delta: 1},
+	} {
+		alerts := evaluateUsageThreshold(threshold, test.usage, now)
+		if !reflect.DeepEqual(alerts, test.alerts) {
+			t.Fatalf("usage %d should alert %v, got %v", test.usage, test.alerts, alerts)
+		}
+		if *threshold.LastUsage != test.usage || *threshold.LastEvaluatedTime != now {
+			t.Fatalf("usage %d should be recorded, got %d", test.usage, *threshold.LastUsage)
+		}
+		if threshold.LastDelta != nil && *threshold.LastDelta != test.delta {
+			t.Fatalf("usage %d should have delta %d, got %d", test.usage, test.delta, *threshold.LastDelta)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/mitchellh/mapstructure"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"kubesphere.io/devops/pkg/utils/verifyutils"
+)
+
+// checkCreateVerification proves the connectivity of the credential before it is created
+// if the project requires verification. verifyErr is the connectivity failure,
+// err means the policy could not be checked.
+func (s *ProjectService) checkCreateVerification(projectId string, request *CredentialRequest) (verifyErr error, err error) {
+	policy, err := s.getCredentialPolicy(projectId)
+	if err != nil {
+		return nil, err
+	}
+	if !policy.RequireVerification {
+		return nil, nil
+	}
+	err = s.verifyCredentialRequest(request)
+	if err != nil {
+		return fmt.Errorf("credential verification failed: %v", err), nil
+	}
+	return nil, nil
+}
+
+// verifier connects to the servers of credentials within timeout, with the allowed networks and
+// known hosts of the configuration.
+func (s *ProjectService) verifier(timeout time.Duration) (*verifyutils.Verifier, error) {
+	allowedNetworks, err := verifyutils.ParseNetworks(s.Config.Verify.AllowedNetworks)
+	if err != nil {
+		return nil, fmt.Errorf("invalid allowed networks: %v", err)
+	}
+	verifier := &verifyutils.Verifier{Timeout: timeout, AllowedNetworks: allowedNetworks}
+	if !govalidator.IsNull(s.Config.Verify.KnownHostsFile) {
+		verifier.HostKeyCallback, err = knownhosts.New(s.Config.Verify.KnownHostsFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return verifier, nil
+}
+
+// verifyCredentialRequest connects with the submitted credential. Username password credentials
+// are verified against a git repository over http, ssh credentials against an ssh server,
+// kubeconfig and OpenShift tokens against their api server. Types that can not be verified pass.
+func (s *ProjectService) verifyCredentialRequest(request *CredentialRequest) error {
+	verifier, err := s.verifier(s.Config.Verify.Timeout)
+	if err != nil {
+		return err
+	}
+	switch request.Type {
+	case CredentialTypeUsernamePassword:
+		UPRequest := &UsernamePasswordCredentialRequest{}
+		err := mapstructure.Decode(request.Content, UPRequest)
+		if err != nil {
+			return err
+		}
+		verifyUrl, err := url.Parse(request.VerifyUrl)
+		if err != nil || (verifyUrl.Scheme != "http" && verifyUrl.Scheme != "https") || verifyUrl.Host == "" {
+			return fmt.Errorf("verify_url should be the http url of a git repository")
+		}
+		return verifier.GitHttp(request.VerifyUrl, UPRequest.Username, UPRequest.Password)
+	case CredentialTypeSsh:
+		SshRequest := &SshCredentialRequest{}
+		err := mapstructure.Decode(request.Content, SshRequest)
+		if err != nil {
+			return err
+		}
+		if govalidator.IsNull(request.VerifyUrl) {
+			return fmt.Errorf("verify_url should be the address of a ssh server")
+		}
+		return verifier.Ssh(request.VerifyUrl, SshRequest.Username, SshRequest.PrivateKey, SshRequest.Passphrase)
+	case CredentialTypeKubeConfig:
+		KubeconfigRequest := &KubeconfigCredentialRequest{}
+		err := mapstructure.Decode(request.Content, KubeconfigRequest)
+		if err != nil {
+			return err
+		}
+		return verifier.Kubeconfig(KubeconfigRequest.Content)
+	case CredentialTypeOpenShiftToken:
+		TokenRequest := &OpenShiftTokenCredentialRequest{}
+		err := mapstructure.Decode(request.Content, TokenRequest)
+		if err != nil {
+			return err
+		}
+		err = validateOpenShiftServerUrl(TokenRequest.ServerUrl)
+		if err != nil {
+			return err
+		}
+		return verifier.KubernetesToken(TokenRequest.ServerUrl, TokenRequest.Token)
+	default:
+		return nil
+	}
+}
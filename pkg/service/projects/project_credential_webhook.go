@@ -0,0 +1,197 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/asaskevich/govalidator"
+
+	"kubesphere.io/devops/pkg/logger"
+	"kubesphere.io/devops/pkg/utils/webhookutils"
+)
+
+// CredentialWebhookEvent is the payload sent to credential webhooks,
+// it never carries secret values.
+type CredentialWebhookEvent struct {
+	ProjectId    string `json:"project_id"`
+	CredentialId string `json:"credential_id"`
+	Type         string `json:"type"`
+	Domain       string `json:"domain"`
+	Operator     string `json:"operator"`
+}
+
+type PreCreateWebhookResponse struct {
+	Allow  *bool  `json:"allow"`
+	Reason string `json:"reason"`
+}
+
+const (
+	CredentialActionCreate = "create"
+	CredentialActionUpdate = "update"
+	CredentialActionDelete = "delete"
+	CredentialActionRotate = "rotate"
+)
+
+// CredentialContentValidationEvent carries the non secret content fields as they are
+// and the hex encoded SHA-256 of the secret fields.
+type CredentialContentValidationEvent struct {
+	CredentialWebhookEvent
+	Action       string                 `json:"action"`
+	Content      map[string]interface{} `json:"content"`
+	SecretHashes map[string]string      `json:"secret_hashes"`
+}
+
+// checkPreCreateWebhook asks the configured policy service whether the credential may be created,
+// a non-nil reason means the creation is rejected.
+func (s *ProjectService) checkPreCreateWebhook(projectId, operator string, request *CredentialRequest) (reason error, err error) {
+	webhookConfig := s.Config.Webhook
+	if govalidator.IsNull(webhookConfig.PreCreateUrl) {
+		return nil, nil
+	}
+	policy, err := s.getCredentialPolicy(projectId)
+	if err != nil {
+		return nil, err
+	}
+	if !policy.PreCreateWebhook {
+		return nil, nil
+	}
+
+	credentialId, _ := request.Content["id"].(string)
+	event := &CredentialWebhookEvent{
+		ProjectId:    projectId,
+		CredentialId: credentialId,
+		Type:         request.Type,
+		Domain:       request.Domain,
+		Operator:     operator,
+	}
+	response := &PreCreateWebhookResponse{}
+	statusCode, postErr := webhookutils.PostJSON(webhookConfig.PreCreateUrl, webhookConfig.Secret,
+		webhookConfig.Timeout, event, response)
+	if statusCode == 0 {
+		if webhookConfig.PreCreateFailOpen {
+			logger.Warn("pre-create webhook unavailable, allow credential [%s] creation: %+v", credentialId, postErr)
+			return nil, nil
+		}
+		return fmt.Errorf("credential creation rejected: pre-create webhook unavailable"), nil
+	}
+	if statusCode < 200 || statusCode > 299 {
+		if !govalidator.IsNull(response.Reason) {
+			return fmt.Errorf("credential creation rejected by policy: %s", response.Reason), nil
+		}
+		return fmt.Errorf("credential creation rejected by policy: webhook returned %d", statusCode), nil
+	}
+	// a response that can not be read may have meant a denial, it only allows the creation when failing open
+	if postErr != nil {
+		if !webhookConfig.PreCreateFailOpen {
+			return fmt.Errorf("credential creation rejected: pre-create webhook response is invalid: %v", postErr), nil
+		}
+		logger.Warn("failed to decode pre-create webhook response, allow credential [%s] creation: %+v",
+			credentialId, postErr)
+	}
+	if response.Allow != nil && !*response.Allow {
+		return fmt.Errorf("credential creation rejected by policy: %s", response.Reason), nil
+	}
+	return nil, nil
+}
+
+// hashCredentialContent splits content into its non secret fields and the hashes of its secret fields,
+// empty secrets are left out.
+func hashCredentialContent(content map[string]interface{}) (map[string]interface{}, map[string]string) {
+	metadata := make(map[string]interface{})
+	hashes := make(map[string]string)
+	for field, value := range content {
+		isSecret := false
+		for _, secretField := range credentialSecretFields {
+			if field == secretField {
+				isSecret = true
+				break
+			}
+		}
+		if !isSecret {
+			metadata[field] = value
+			continue
+		}
+		secret, _ := value.(string)
+		if secret == "" {
+			continue
+		}
+		sum := sha256.Sum256([]byte(secret))
+		hashes[field] = hex.EncodeToString(sum[:])
+	}
+	return metadata, hashes
+}
+
+// checkContentValidationWebhook asks the configured validation service whether the content of a created
+// or updated credential is valid, a non-nil reason means the operation is rejected. Secrets are never sent.
+func (s *ProjectService) checkContentValidationWebhook(projectId, operator, action, credentialType, domain string,
+	content map[string]interface{}) (reason error, err error) {
+	webhookConfig := s.Config.Webhook
+	if govalidator.IsNull(webhookConfig.ContentValidationUrl) {
+		return nil, nil
+	}
+	policy, err := s.getCredentialPolicy(projectId)
+	if err != nil {
+		return nil, err
+	}
+	if !policy.ContentValidationWebhook {
+		return nil, nil
+	}
+
+	credentialId, _ := content["id"].(string)
+	metadata, hashes := hashCredentialContent(content)
+	event := &CredentialContentValidationEvent{
+		CredentialWebhookEvent: CredentialWebhookEvent{
+			ProjectId:    projectId,
+			CredentialId: credentialId,
+			Type:         credentialType,
+			Domain:       domain,
+			Operator:     operator,
+		},
+		Action:       action,
+		Content:      metadata,
+		SecretHashes: hashes,
+	}
+	response := &PreCreateWebhookResponse{}
+	statusCode, postErr := webhookutils.PostJSON(webhookConfig.ContentValidationUrl, webhookConfig.Secret,
+		webhookConfig.Timeout, event, response)
+	if statusCode == 0 {
+		if webhookConfig.ContentValidationFailOpen {
+			logger.Warn("content validation webhook unavailable, allow credential [%s] %s: %+v", credentialId, action, postErr)
+			return nil, nil
+		}
+		return fmt.Errorf("credential %s rejected: content validation webhook unavailable", action), nil
+	}
+	if statusCode < 200 || statusCode > 299 {
+		if !govalidator.IsNull(strings.TrimSpace(response.Reason)) {
+			return fmt.Errorf("credential content rejected: %s", response.Reason), nil
+		}
+		return fmt.Errorf("credential content rejected: webhook returned %d", statusCode), nil
+	}
+	if postErr != nil {
+		if !webhookConfig.ContentValidationFailOpen {
+			return fmt.Errorf("credential %s rejected: content validation webhook response is invalid: %v",
+				action, postErr), nil
+		}
+		logger.Warn("failed to decode content validation webhook response, allow credential [%s] %s: %+v",
+			credentialId, action, postErr)
+	}
+	if response.Allow != nil && !*response.Allow {
+		return fmt.Errorf("credential content rejected: %s", response.Reason), nil
+	}
+	return nil, nil
+}
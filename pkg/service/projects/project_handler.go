@@ -59,10 +59,10 @@ type ProjectRoleResponse struct {
 func (s *ProjectService) GetProjectHandler(w rest.ResponseWriter, r *rest.Request) {
 	projectId := r.PathParams["id"]
 	operator := userutils.GetUserNameFromRequest(r)
-	err := s.checkProjectUserInRole(operator, projectId,
+	err := s.checkProjectUserInRole(r, operator, projectId,
 		[]string{ProjectOwner, ProjectMaintainer, ProjectReporter, ProjectDeveloper})
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusForbidden)
 		return
 	}
@@ -72,12 +72,12 @@ func (s *ProjectService) GetProjectHandler(w rest.ResponseWriter, r *rest.Reques
 		Where(db.Eq(models.ProjectIdColumn, projectId)).
 		LoadOne(project)
 	if err != nil && err != dbr.ErrNotFound {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 	if err == dbr.ErrNotFound {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
@@ -111,7 +111,7 @@ func (s *ProjectService) GetProjectsHandler(w rest.ResponseWriter, r *rest.Reque
 			Where(db.And(membershipCondition...)).
 			Load(&projectMemberships)
 		if err != nil {
-			logger.Error("%+v", err)
+			logger.ErrorContext(r.Context(), "%+v", err)
 			rest.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -127,7 +127,7 @@ func (s *ProjectService) GetProjectsHandler(w rest.ResponseWriter, r *rest.Reque
 	}
 	_, err := query.Load(&projects)
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -140,14 +140,14 @@ func (s *ProjectService) CreateProjectHandler(w rest.ResponseWriter, r *rest.Req
 	request := &CreateProjectRequest{}
 	err := r.DecodeJsonPayload(request)
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	project := models.NewProject(request.Name, request.Description, creator, request.Extra)
 	_, err = s.Ds.Jenkins.CreateFolder(project.ProjectId, project.Description)
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 		return
 	}
@@ -176,7 +176,7 @@ func (s *ProjectService) CreateProjectHandler(w rest.ResponseWriter, r *rest.Req
 	close(addRoleCh)
 	for addRoleResponse := range addRoleCh {
 		if addRoleResponse.Err != nil {
-			logger.Error("%+v", addRoleResponse.Err)
+			logger.ErrorContext(r.Context(), "%+v", addRoleResponse.Err)
 			rest.Error(w, addRoleResponse.Err.Error(), stringutils.GetJenkinsStatusCode(addRoleResponse.Err))
 			return
 		}
@@ -184,7 +184,7 @@ func (s *ProjectService) CreateProjectHandler(w rest.ResponseWriter, r *rest.Req
 
 	globalRole, err := s.Ds.Jenkins.GetGlobalRole(constants.JenkinsAllUserRoleName)
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 		return
 	}
@@ -199,40 +199,40 @@ func (s *ProjectService) CreateProjectHandler(w rest.ResponseWriter, r *rest.Req
 	}
 	err = globalRole.AssignRole(creator)
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 		return
 	}
 
 	projectRole, err := s.Ds.Jenkins.GetProjectRole(GetProjectRoleName(project.ProjectId, ProjectOwner))
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 	err = projectRole.AssignRole(creator)
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	pipelineRole, err := s.Ds.Jenkins.GetProjectRole(GetPipelineRoleName(project.ProjectId, ProjectOwner))
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 		return
 	}
 	err = pipelineRole.AssignRole(creator)
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 		return
 	}
 	_, err = s.Ds.Db.InsertInto(models.ProjectTableName).
 		Columns(models.ProjectColumns...).Record(project).Exec()
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -241,7 +241,7 @@ func (s *ProjectService) CreateProjectHandler(w rest.ResponseWriter, r *rest.Req
 	_, err = s.Ds.Db.InsertInto(models.ProjectMembershipTableName).
 		Columns(models.ProjectMembershipColumns...).Record(projectMembership).Exec()
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -252,16 +252,16 @@ func (s *ProjectService) CreateProjectHandler(w rest.ResponseWriter, r *rest.Req
 func (s *ProjectService) DeleteProjectHandler(w rest.ResponseWriter, r *rest.Request) {
 	projectId := r.PathParams["id"]
 	operator := userutils.GetUserNameFromRequest(r)
-	err := s.checkProjectUserInRole(operator, projectId, []string{ProjectOwner})
+	err := s.checkProjectUserInRole(r, operator, projectId, []string{ProjectOwner})
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusForbidden)
 		return
 	}
 	_, err = s.Ds.Jenkins.DeleteJob(projectId)
 
 	if err != nil && stringutils.GetJenkinsStatusCode(err) != http.StatusNotFound {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 		return
 	}
@@ -273,14 +273,14 @@ func (s *ProjectService) DeleteProjectHandler(w rest.ResponseWriter, r *rest.Req
 	}
 	err = s.Ds.Jenkins.DeleteProjectRoles(roleNames...)
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 		return
 	}
 	_, err = s.Ds.Db.DeleteFrom(models.ProjectMembershipTableName).
 		Where(db.Eq(models.ProjectMembershipProjectIdColumn, projectId)).Exec()
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -288,7 +288,7 @@ func (s *ProjectService) DeleteProjectHandler(w rest.ResponseWriter, r *rest.Req
 		Set(constants.StatusColumn, constants.StatusDeleted).
 		Where(db.Eq(models.ProjectIdColumn, projectId)).Exec()
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -298,7 +298,7 @@ func (s *ProjectService) DeleteProjectHandler(w rest.ResponseWriter, r *rest.Req
 		Where(db.Eq(models.ProjectIdColumn, projectId)).
 		LoadOne(project)
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -312,13 +312,13 @@ func (s *ProjectService) UpdateProjectHandler(w rest.ResponseWriter, r *rest.Req
 	request := &UpdateProjectRequest{}
 	err := r.DecodeJsonPayload(request)
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	err = s.checkProjectUserInRole(operator, projectId, []string{ProjectOwner})
+	err = s.checkProjectUserInRole(r, operator, projectId, []string{ProjectOwner})
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusForbidden)
 		return
 	}
@@ -333,7 +333,7 @@ func (s *ProjectService) UpdateProjectHandler(w rest.ResponseWriter, r *rest.Req
 		query.
 			Where(db.Eq(models.ProjectIdColumn, projectId)).Exec()
 		if err != nil {
-			logger.Error("%+v", err)
+			logger.ErrorContext(r.Context(), "%+v", err)
 			rest.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -344,7 +344,7 @@ func (s *ProjectService) UpdateProjectHandler(w rest.ResponseWriter, r *rest.Req
 		Where(db.Eq(models.ProjectIdColumn, projectId)).
 		LoadOne(project)
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
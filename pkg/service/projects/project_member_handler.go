@@ -57,10 +57,10 @@ var DefaultRoles = []*Role{
 func (s *ProjectService) GetMembersHandler(w rest.ResponseWriter, r *rest.Request) {
 	projectId := r.PathParams["id"]
 	operator := userutils.GetUserNameFromRequest(r)
-	err := s.checkProjectUserInRole(operator, projectId, []string{
+	err := s.checkProjectUserInRole(r, operator, projectId, []string{
 		ProjectOwner, ProjectMaintainer, ProjectReporter, ProjectDeveloper})
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusForbidden)
 		return
 	}
@@ -70,7 +70,7 @@ func (s *ProjectService) GetMembersHandler(w rest.ResponseWriter, r *rest.Reques
 		Where(db.Eq(models.ProjectIdColumn, projectId)).
 		Load(&memberships)
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -82,10 +82,10 @@ func (s *ProjectService) GetMemberHandler(w rest.ResponseWriter, r *rest.Request
 	projectId := r.PathParams["id"]
 	operator := userutils.GetUserNameFromRequest(r)
 	username := r.PathParams["uid"]
-	err := s.checkProjectUserInRole(operator, projectId, []string{
+	err := s.checkProjectUserInRole(r, operator, projectId, []string{
 		ProjectOwner, ProjectMaintainer, ProjectReporter, ProjectDeveloper})
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusForbidden)
 		return
 	}
@@ -97,7 +97,7 @@ func (s *ProjectService) GetMemberHandler(w rest.ResponseWriter, r *rest.Request
 			db.Eq(models.ProjectMembershipUsernameColumn, username))).
 		LoadOne(&memberships)
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -111,26 +111,26 @@ func (s *ProjectService) AddProjectMemberHandler(w rest.ResponseWriter, r *rest.
 	request := &AddProjectMemberRequest{}
 	err := r.DecodeJsonPayload(request)
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	if govalidator.IsNull(request.Username) {
 		err := fmt.Errorf("error need username")
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	if !reflectutils.In(request.Role, AllRoleSlice) {
 		err := fmt.Errorf("err role [%s] not in [%s]", request.Role,
 			AllRoleSlice)
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	err = s.checkProjectUserInRole(operator, projectId, []string{ProjectOwner})
+	err = s.checkProjectUserInRole(r, operator, projectId, []string{ProjectOwner})
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusForbidden)
 		return
 	}
@@ -141,20 +141,20 @@ func (s *ProjectService) AddProjectMemberHandler(w rest.ResponseWriter, r *rest.
 			db.Eq(models.ProjectMembershipUsernameColumn, request.Username),
 			db.Eq(models.ProjectMembershipProjectIdColumn, projectId))).LoadOne(membership)
 	if err != nil && err != db.ErrNotFound {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 	if err != db.ErrNotFound {
 		err = fmt.Errorf("user [%s] have been added to project", request.Username)
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	globalRole, err := s.Ds.Jenkins.GetGlobalRole(constants.JenkinsAllUserRoleName)
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 		return
 	}
@@ -169,31 +169,31 @@ func (s *ProjectService) AddProjectMemberHandler(w rest.ResponseWriter, r *rest.
 	}
 	err = globalRole.AssignRole(request.Username)
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 		return
 	}
 	projectRole, err := s.Ds.Jenkins.GetProjectRole(GetProjectRoleName(projectId, request.Role))
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 		return
 	}
 	err = projectRole.AssignRole(request.Username)
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 		return
 	}
 	pipelineRole, err := s.Ds.Jenkins.GetProjectRole(GetPipelineRoleName(projectId, request.Role))
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 		return
 	}
 	err = pipelineRole.AssignRole(request.Username)
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 		return
 	}
@@ -203,7 +203,7 @@ func (s *ProjectService) AddProjectMemberHandler(w rest.ResponseWriter, r *rest.
 		Columns(models.ProjectMembershipColumns...).
 		Record(projectMembership).Exec()
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -218,25 +218,25 @@ func (s *ProjectService) UpdateMemberHandler(w rest.ResponseWriter, r *rest.Requ
 	request := &UpdateProjectMemberRequest{}
 	err := r.DecodeJsonPayload(request)
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	if username == operator {
 		err := fmt.Errorf("you can not change your role")
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	if !reflectutils.In(request.Role, AllRoleSlice) {
 		err := fmt.Errorf("err role [%s] not in [%s]", request.Role, AllRoleSlice)
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	err = s.checkProjectUserInRole(operator, projectId, []string{ProjectOwner})
+	err = s.checkProjectUserInRole(r, operator, projectId, []string{ProjectOwner})
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusForbidden)
 		return
 	}
@@ -248,58 +248,58 @@ func (s *ProjectService) UpdateMemberHandler(w rest.ResponseWriter, r *rest.Requ
 			db.Eq(models.ProjectMembershipProjectIdColumn, projectId),
 		)).LoadOne(oldMembership)
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	oldProjectRole, err := s.Ds.Jenkins.GetProjectRole(GetProjectRoleName(projectId, oldMembership.Role))
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 		return
 	}
 	err = oldProjectRole.UnAssignRole(username)
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 		return
 	}
 
 	oldPipelineRole, err := s.Ds.Jenkins.GetProjectRole(GetPipelineRoleName(projectId, oldMembership.Role))
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 		return
 	}
 	err = oldPipelineRole.UnAssignRole(username)
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 		return
 	}
 
 	projectRole, err := s.Ds.Jenkins.GetProjectRole(GetProjectRoleName(projectId, request.Role))
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 		return
 	}
 	err = projectRole.AssignRole(username)
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 		return
 	}
 	pipelineRole, err := s.Ds.Jenkins.GetProjectRole(GetPipelineRoleName(projectId, request.Role))
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 		return
 	}
 	err = pipelineRole.AssignRole(username)
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 		return
 	}
@@ -310,7 +310,7 @@ func (s *ProjectService) UpdateMemberHandler(w rest.ResponseWriter, r *rest.Requ
 			db.Eq(models.ProjectMembershipUsernameColumn, username),
 		)).Exec()
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -323,7 +323,7 @@ func (s *ProjectService) UpdateMemberHandler(w rest.ResponseWriter, r *rest.Requ
 			db.Eq(models.ProjectMembershipProjectIdColumn, projectId),
 		)).LoadOne(responseMembership)
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -337,9 +337,9 @@ func (s *ProjectService) DeleteMemberHandler(w rest.ResponseWriter, r *rest.Requ
 	username := r.PathParams["uid"]
 	operator := userutils.GetUserNameFromRequest(r)
 
-	err := s.checkProjectUserInRole(operator, projectId, []string{ProjectOwner})
+	err := s.checkProjectUserInRole(r, operator, projectId, []string{ProjectOwner})
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusForbidden)
 		return
 	}
@@ -352,12 +352,12 @@ func (s *ProjectService) DeleteMemberHandler(w rest.ResponseWriter, r *rest.Requ
 			db.Eq(models.ProjectMembershipProjectIdColumn, projectId),
 		)).LoadOne(oldMembership)
 	if err != nil && err != db.ErrNotFound {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 	if err == db.ErrNotFound {
-		logger.Warn("user [%s] not found in project", username)
+		logger.WarnContext(r.Context(), "user [%s] not found in project", username)
 		w.WriteJson(struct {
 			Username string `json:"username"`
 		}{Username: username})
@@ -370,13 +370,13 @@ func (s *ProjectService) DeleteMemberHandler(w rest.ResponseWriter, r *rest.Requ
 				db.Eq(models.ProjectIdColumn, projectId),
 				db.Eq(models.ProjectMembershipRoleColumn, ProjectOwner))).Count()
 		if err != nil {
-			logger.Error("%+v", err)
+			logger.ErrorContext(r.Context(), "%+v", err)
 			rest.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 		if count == 1 {
 			err = fmt.Errorf("project must has at least one admin")
-			logger.Error("%+v", err)
+			logger.ErrorContext(r.Context(), "%+v", err)
 			rest.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
@@ -384,26 +384,26 @@ func (s *ProjectService) DeleteMemberHandler(w rest.ResponseWriter, r *rest.Requ
 
 	oldProjectRole, err := s.Ds.Jenkins.GetProjectRole(GetProjectRoleName(projectId, oldMembership.Role))
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 		return
 	}
 	err = oldProjectRole.UnAssignRole(username)
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 		return
 	}
 
 	oldPipelineRole, err := s.Ds.Jenkins.GetProjectRole(GetPipelineRoleName(projectId, oldMembership.Role))
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 		return
 	}
 	err = oldPipelineRole.UnAssignRole(username)
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 		return
 	}
@@ -414,7 +414,7 @@ func (s *ProjectService) DeleteMemberHandler(w rest.ResponseWriter, r *rest.Requ
 			db.Eq(models.ProjectMembershipUsernameColumn, username),
 		)).Exec()
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
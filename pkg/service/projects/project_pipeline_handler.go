@@ -33,14 +33,14 @@ func (s *ProjectService) CreatePipelineHandler(w rest.ResponseWriter, r *rest.Re
 
 	err := r.DecodeJsonPayload(request)
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	err = s.checkProjectUserInRole(operator, projectId, []string{ProjectOwner, ProjectMaintainer})
+	err = s.checkProjectUserInRole(r, operator, projectId, []string{ProjectOwner, ProjectMaintainer})
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusForbidden)
 		return
 	}
@@ -50,13 +50,13 @@ func (s *ProjectService) CreatePipelineHandler(w rest.ResponseWriter, r *rest.Re
 		pipeline := &Pipeline{}
 		err := mapstructure.Decode(request.Define, pipeline)
 		if err != nil {
-			logger.Error("%+v", err)
+			logger.ErrorContext(r.Context(), "%+v", err)
 			rest.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 		config, err := createPipelineConfigXml(pipeline)
 		if err != nil {
-			logger.Error("%+v", err)
+			logger.ErrorContext(r.Context(), "%+v", err)
 			rest.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -64,20 +64,20 @@ func (s *ProjectService) CreatePipelineHandler(w rest.ResponseWriter, r *rest.Re
 		job, err := s.Ds.Jenkins.GetJob(pipeline.Name, projectId)
 		if job != nil {
 			err := fmt.Errorf("job name [%s] has been used", job.GetName())
-			logger.Warn(err.Error())
+			logger.WarnContext(r.Context(), "%+v", err)
 			rest.Error(w, err.Error(), http.StatusConflict)
 			return
 		}
 
 		if err != nil && stringutils.GetJenkinsStatusCode(err) != http.StatusNotFound {
-			logger.Error("%+v", err)
+			logger.ErrorContext(r.Context(), "%+v", err)
 			rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 			return
 		}
 
 		_, err = s.Ds.Jenkins.CreateJobInFolder(config, pipeline.Name, projectId)
 		if err != nil {
-			logger.Error("%+v", err)
+			logger.ErrorContext(r.Context(), "%+v", err)
 			rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 			return
 		}
@@ -89,13 +89,13 @@ func (s *ProjectService) CreatePipelineHandler(w rest.ResponseWriter, r *rest.Re
 		pipeline := &MultiBranchPipeline{}
 		err := mapstructure.Decode(request.Define, pipeline)
 		if err != nil {
-			logger.Error("%+v", err)
+			logger.ErrorContext(r.Context(), "%+v", err)
 			rest.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 		config, err := createMultiBranchPipelineConfigXml(projectId, pipeline)
 		if err != nil {
-			logger.Error("%+v", err)
+			logger.ErrorContext(r.Context(), "%+v", err)
 			rest.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -103,20 +103,20 @@ func (s *ProjectService) CreatePipelineHandler(w rest.ResponseWriter, r *rest.Re
 		job, err := s.Ds.Jenkins.GetJob(pipeline.Name, projectId)
 		if job != nil {
 			err := fmt.Errorf("job name [%s] has been used", job.GetName())
-			logger.Warn(err.Error())
+			logger.WarnContext(r.Context(), "%+v", err)
 			rest.Error(w, err.Error(), http.StatusConflict)
 			return
 		}
 
 		if err != nil && stringutils.GetJenkinsStatusCode(err) != http.StatusNotFound {
-			logger.Error("%+v", err)
+			logger.ErrorContext(r.Context(), "%+v", err)
 			rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 			return
 		}
 
 		_, err = s.Ds.Jenkins.CreateJobInFolder(config, pipeline.Name, projectId)
 		if err != nil {
-			logger.Error("%+v", err)
+			logger.ErrorContext(r.Context(), "%+v", err)
 			rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 			return
 		}
@@ -127,7 +127,7 @@ func (s *ProjectService) CreatePipelineHandler(w rest.ResponseWriter, r *rest.Re
 
 	default:
 		err := fmt.Errorf("error unsupport job type")
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -137,15 +137,15 @@ func (s *ProjectService) DeletePipelineHandler(w rest.ResponseWriter, r *rest.Re
 	projectId := r.PathParams["id"]
 	operator := userutils.GetUserNameFromRequest(r)
 	pipelineId := r.PathParams["pid"]
-	err := s.checkProjectUserInRole(operator, projectId, []string{ProjectOwner, ProjectMaintainer})
+	err := s.checkProjectUserInRole(r, operator, projectId, []string{ProjectOwner, ProjectMaintainer})
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusForbidden)
 		return
 	}
 	_, err = s.Ds.Jenkins.DeleteJob(pipelineId, projectId)
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 		return
 	}
@@ -162,14 +162,14 @@ func (s *ProjectService) UpdatePipelineHandler(w rest.ResponseWriter, r *rest.Re
 
 	err := r.DecodeJsonPayload(request)
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	err = s.checkProjectUserInRole(operator, projectId, []string{ProjectOwner, ProjectMaintainer})
+	err = s.checkProjectUserInRole(r, operator, projectId, []string{ProjectOwner, ProjectMaintainer})
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusForbidden)
 		return
 	}
@@ -179,25 +179,25 @@ func (s *ProjectService) UpdatePipelineHandler(w rest.ResponseWriter, r *rest.Re
 		pipeline := &Pipeline{}
 		err := mapstructure.Decode(request.Define, pipeline)
 		if err != nil {
-			logger.Error("%+v", err)
+			logger.ErrorContext(r.Context(), "%+v", err)
 			rest.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 		config, err := createPipelineConfigXml(pipeline)
 		if err != nil {
-			logger.Error("%+v", err)
+			logger.ErrorContext(r.Context(), "%+v", err)
 			rest.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 		job, err := s.Ds.Jenkins.GetJob(pipelineId, projectId)
 		if err != nil {
-			logger.Error("%+v", err)
+			logger.ErrorContext(r.Context(), "%+v", err)
 			rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 			return
 		}
 		err = job.UpdateConfig(config)
 		if err != nil {
-			logger.Error("%+v", err)
+			logger.ErrorContext(r.Context(), "%+v", err)
 			rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 			return
 		}
@@ -209,25 +209,25 @@ func (s *ProjectService) UpdatePipelineHandler(w rest.ResponseWriter, r *rest.Re
 		multiBranchPipeline := &MultiBranchPipeline{}
 		err := mapstructure.Decode(request.Define, multiBranchPipeline)
 		if err != nil {
-			logger.Error("%+v", err)
+			logger.ErrorContext(r.Context(), "%+v", err)
 			rest.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 		config, err := createMultiBranchPipelineConfigXml(projectId, multiBranchPipeline)
 		if err != nil {
-			logger.Error("%+v", err)
+			logger.ErrorContext(r.Context(), "%+v", err)
 			rest.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 		job, err := s.Ds.Jenkins.GetJob(pipelineId, projectId)
 		if err != nil {
-			logger.Error("%+v", err)
+			logger.ErrorContext(r.Context(), "%+v", err)
 			rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 			return
 		}
 		err = job.UpdateConfig(config)
 		if err != nil {
-			logger.Error("%+v", err)
+			logger.ErrorContext(r.Context(), "%+v", err)
 			rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 			return
 		}
@@ -237,7 +237,7 @@ func (s *ProjectService) UpdatePipelineHandler(w rest.ResponseWriter, r *rest.Re
 		return
 	default:
 		err := fmt.Errorf("error unsupport job type")
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusBadRequest)
 		return
 
@@ -248,15 +248,15 @@ func (s *ProjectService) GetPipelineHandler(w rest.ResponseWriter, r *rest.Reque
 	projectId := r.PathParams["id"]
 	pipelineId := r.PathParams["pid"]
 	operator := userutils.GetUserNameFromRequest(r)
-	err := s.checkProjectUserInRole(operator, projectId, []string{ProjectOwner, ProjectMaintainer})
+	err := s.checkProjectUserInRole(r, operator, projectId, []string{ProjectOwner, ProjectMaintainer})
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusForbidden)
 		return
 	}
 	job, err := s.Ds.Jenkins.GetJob(pipelineId, projectId)
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 		return
 	}
@@ -264,13 +264,13 @@ func (s *ProjectService) GetPipelineHandler(w rest.ResponseWriter, r *rest.Reque
 	case "org.jenkinsci.plugins.workflow.job.WorkflowJob":
 		config, err := job.GetConfig()
 		if err != nil {
-			logger.Error("%+v", err)
+			logger.ErrorContext(r.Context(), "%+v", err)
 			rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 			return
 		}
 		pipeline, err := parsePipelineConfigXml(config)
 		if err != nil {
-			logger.Error("%+v", err)
+			logger.ErrorContext(r.Context(), "%+v", err)
 			rest.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -280,13 +280,13 @@ func (s *ProjectService) GetPipelineHandler(w rest.ResponseWriter, r *rest.Reque
 		}
 		jsonByte, err := json.Marshal(pipeline)
 		if err != nil {
-			logger.Error("%+v", err)
+			logger.ErrorContext(r.Context(), "%+v", err)
 			rest.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 		err = json.Unmarshal(jsonByte, &jobRequest.Define)
 		if err != nil {
-			logger.Error("%+v", err)
+			logger.ErrorContext(r.Context(), "%+v", err)
 			rest.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -296,13 +296,13 @@ func (s *ProjectService) GetPipelineHandler(w rest.ResponseWriter, r *rest.Reque
 	case "org.jenkinsci.plugins.workflow.multibranch.WorkflowMultiBranchProject":
 		config, err := job.GetConfig()
 		if err != nil {
-			logger.Error("%+v", err)
+			logger.ErrorContext(r.Context(), "%+v", err)
 			rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 			return
 		}
 		pipeline, err := parseMultiBranchPipelineConfigXml(config)
 		if err != nil {
-			logger.Error("%+v", err)
+			logger.ErrorContext(r.Context(), "%+v", err)
 			rest.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -312,13 +312,13 @@ func (s *ProjectService) GetPipelineHandler(w rest.ResponseWriter, r *rest.Reque
 		}
 		jsonByte, err := json.Marshal(pipeline)
 		if err != nil {
-			logger.Error("%+v", err)
+			logger.ErrorContext(r.Context(), "%+v", err)
 			rest.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 		err = json.Unmarshal(jsonByte, &jobRequest.Define)
 		if err != nil {
-			logger.Error("%+v", err)
+			logger.ErrorContext(r.Context(), "%+v", err)
 			rest.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -327,7 +327,7 @@ func (s *ProjectService) GetPipelineHandler(w rest.ResponseWriter, r *rest.Reque
 
 	default:
 		err := fmt.Errorf("error unsupport job type")
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -337,15 +337,15 @@ func (s *ProjectService) GetPipelineScmHandler(w rest.ResponseWriter, r *rest.Re
 	projectId := r.PathParams["id"]
 	pipelineId := r.PathParams["pid"]
 	operator := userutils.GetUserNameFromRequest(r)
-	err := s.checkProjectUserInRole(operator, projectId, AllRoleSlice)
+	err := s.checkProjectUserInRole(r, operator, projectId, AllRoleSlice)
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusForbidden)
 		return
 	}
 	job, err := s.Ds.Jenkins.GetJob(pipelineId, projectId)
 	if err != nil {
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 		return
 	}
@@ -353,13 +353,13 @@ func (s *ProjectService) GetPipelineScmHandler(w rest.ResponseWriter, r *rest.Re
 	case "org.jenkinsci.plugins.workflow.multibranch.WorkflowMultiBranchProject":
 		config, err := job.GetConfig()
 		if err != nil {
-			logger.Error("%+v", err)
+			logger.ErrorContext(r.Context(), "%+v", err)
 			rest.Error(w, err.Error(), stringutils.GetJenkinsStatusCode(err))
 			return
 		}
 		scm, err := parseMultiBranchPipelineScm(config)
 		if err != nil {
-			logger.Error("%+v", err)
+			logger.ErrorContext(r.Context(), "%+v", err)
 			rest.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -368,7 +368,7 @@ func (s *ProjectService) GetPipelineScmHandler(w rest.ResponseWriter, r *rest.Re
 
 	default:
 		err := fmt.Errorf("error unsupport job type")
-		logger.Error("%+v", err)
+		logger.ErrorContext(r.Context(), "%+v", err)
 		rest.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
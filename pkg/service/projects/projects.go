@@ -16,6 +16,9 @@ package projects
 import (
 	"fmt"
 
+	"github.com/ant0ine/go-json-rest/rest"
+
+	"kubesphere.io/devops/pkg/config"
 	"kubesphere.io/devops/pkg/constants"
 	"kubesphere.io/devops/pkg/db"
 	"kubesphere.io/devops/pkg/ds"
@@ -25,7 +28,15 @@ import (
 )
 
 type ProjectService struct {
-	Ds *ds.Ds
+	Ds          *ds.Ds
+	Config      *config.Config
+	Credentials CredentialStore
+	// ChangeDispatcher is notified of the credentials changed by the handlers, nil disables notifications
+	ChangeDispatcher CredentialChangeDispatcher
+	// RolePolicy sets the roles allowed to perform each credential action, nil keeps the default roles
+	RolePolicy CredentialRolePolicy
+	// ListCache caches the credentials listed in project folders, nil lists them from Jenkins every time
+	ListCache *CredentialListCache
 }
 
 const (
@@ -240,9 +251,53 @@ func GetPipelineRolePattern(projectId string) string {
 	return fmt.Sprintf("^%s/.*", projectId)
 }
 
-func (s *ProjectService) checkProjectUserInRole(username, projectId string, roles []string) error {
-	if username == constants.KS_ADMIN {
-		return nil
+// projectRolesEnvKey is the Env key of the roles looked up while serving a request.
+const projectRolesEnvKey = "PROJECT_ROLES"
+
+// NotProjectMemberError rejects a user who is not a member of the project.
+type NotProjectMemberError struct {
+	Username  string
+	ProjectId string
+}
+
+func (e *NotProjectMemberError) Error() string {
+	return fmt.Sprintf("user [%s] is not a member of project [%s]", e.Username, e.ProjectId)
+}
+
+// ProjectRoleError rejects a member of the project whose role is not one of the roles required.
+type ProjectRoleError struct {
+	Username  string
+	ProjectId string
+	Role      string
+	Roles     []string
+}
+
+func (e *ProjectRoleError) Error() string {
+	return fmt.Sprintf("user [%s] in project [%s] role is not in %s", e.Username, e.ProjectId, e.Roles)
+}
+
+// getProjectUserRole returns the role of username in the project, or a NotProjectMemberError when it is not
+// a member. Roles are memoized in the Env of r, an empty role for non-members, so every role is looked up
+// once per request and none outlives it, they are not memoized when r is nil.
+// It is not safe to call with the same r from several goroutines.
+func (s *ProjectService) getProjectUserRole(r *rest.Request, username, projectId string) (string, error) {
+	key := username + "/" + projectId
+	var roles map[string]string
+	if r != nil {
+		if r.Env == nil {
+			r.Env = make(map[string]interface{})
+		}
+		roles, _ = r.Env[projectRolesEnvKey].(map[string]string)
+		if roles == nil {
+			roles = make(map[string]string)
+			r.Env[projectRolesEnvKey] = roles
+		}
+		if role, ok := roles[key]; ok {
+			if role == "" {
+				return "", &NotProjectMemberError{Username: username, ProjectId: projectId}
+			}
+			return role, nil
+		}
 	}
 	membership := &models.ProjectMembership{}
 	err := s.Ds.Db.Select(models.ProjectMembershipColumns...).
@@ -250,11 +305,33 @@ func (s *ProjectService) checkProjectUserInRole(username, projectId string, role
 		Where(db.And(
 			db.Eq(models.ProjectMembershipUsernameColumn, username),
 			db.Eq(models.ProjectMembershipProjectIdColumn, projectId))).LoadOne(membership)
+	if err == db.ErrNotFound {
+		if roles != nil {
+			roles[key] = ""
+		}
+		return "", &NotProjectMemberError{Username: username, ProjectId: projectId}
+	}
+	if err != nil {
+		return "", err
+	}
+	if roles != nil {
+		roles[key] = membership.Role
+	}
+	return membership.Role, nil
+}
+
+// checkProjectUserInRole returns a NotProjectMemberError when username is not a member of the project,
+// and a ProjectRoleError when its role is not one of roles.
+func (s *ProjectService) checkProjectUserInRole(r *rest.Request, username, projectId string, roles []string) error {
+	if username == constants.KS_ADMIN {
+		return nil
+	}
+	role, err := s.getProjectUserRole(r, username, projectId)
 	if err != nil {
 		return err
 	}
-	if !reflectutils.In(membership.Role, roles) {
-		return fmt.Errorf("user [%s] in project [%s] role is not in %s", username, projectId, roles)
+	if !reflectutils.In(role, roles) {
+		return &ProjectRoleError{Username: username, ProjectId: projectId, Role: role, Roles: roles}
 	}
 	return nil
 }
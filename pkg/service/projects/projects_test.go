@@ -0,0 +1,59 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projects
+
+import (
+	"testing"
+
+	"github.com/ant0ine/go-json-rest/rest"
+)
+
+func Test_CheckProjectUserInRoleMemoized(t *testing.T) {
+	// the service has no db, so only memoized roles can be checked
+	s := &ProjectService{}
+	r := &rest.Request{Env: map[string]interface{}{
+		projectRolesEnvKey: map[string]string{"alice/project": ProjectMaintainer},
+	}}
+	err := s.checkProjectUserInRole(r, "alice", "project", []string{ProjectOwner, ProjectMaintainer})
+	if err != nil {
+		t.Fatalf("alice should be maintainer, got %v", err)
+	}
+	err = s.checkProjectUserInRole(r, "alice", "project", []string{ProjectOwner})
+	if err == nil {
+		t.Fatalf("alice should not be owner")
+	}
+	role, err := s.getProjectUserRole(r, "alice", "project")
+	if err != nil || role != ProjectMaintainer {
+		t.Fatalf("role of alice should be maintainer, got [%s] %v", role, err)
+	}
+}
+
+func Test_CheckProjectUserInRoleErrors(t *testing.T) {
+	s := &ProjectService{}
+	r := &rest.Request{Env: map[string]interface{}{
+		projectRolesEnvKey: map[string]string{"alice/project": ProjectDeveloper, "bob/project": ""},
+	}}
+	err := s.checkProjectUserInRole(r, "alice", "project", []string{ProjectOwner, ProjectMaintainer})
+	roleErr, ok := err.(*ProjectRoleError)
+	if !ok {
+		t.Fatalf("expected a role error for alice, got %#v", err)
+	}
+	if roleErr.Role != ProjectDeveloper {
+		t.Fatalf("expected the role of alice to be developer, got [%s]", roleErr.Role)
+	}
+	err = s.checkProjectUserInRole(r, "bob", "project", []string{ProjectOwner, ProjectMaintainer})
+	if _, ok := err.(*NotProjectMemberError); !ok {
+		t.Fatalf("expected bob not to be a member, got %#v", err)
+	}
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"regexp"
+
+	"github.com/ant0ine/go-json-rest/rest"
+
+	"kubesphere.io/devops/pkg/logger"
+	"kubesphere.io/devops/pkg/utils/idutils"
+)
+
+// requestIdPattern limits the request ids accepted from clients, so that they can be written to logs and
+// passed to Jenkins as is.
+var requestIdPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// RequestIdMiddleware accepts the request id from the X-Request-ID header or generates one, puts it into the
+// request context for logger.ErrorContext, logger.WarnContext and the Jenkins calls made with that context,
+// and sends it back in the response header.
+func RequestIdMiddleware(handler rest.HandlerFunc) rest.HandlerFunc {
+	return func(w rest.ResponseWriter, r *rest.Request) {
+		requestId := r.Header.Get(logger.RequestIdHeader)
+		if !requestIdPattern.MatchString(requestId) {
+			requestId = idutils.GetUuid36("req-")
+		}
+		r.Request = r.WithContext(logger.WithRequestId(r.Context(), requestId))
+		w.Header().Set(logger.RequestIdHeader, requestId)
+		handler(w, r)
+	}
+}
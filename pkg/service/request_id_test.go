@@ -0,0 +1,53 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ant0ine/go-json-rest/rest"
+
+	"kubesphere.io/devops/pkg/logger"
+)
+
+func TestRequestIdMiddleware(t *testing.T) {
+	var got string
+	api := rest.NewApi()
+	api.Use(rest.MiddlewareSimple(RequestIdMiddleware))
+	api.SetApp(rest.AppSimple(func(w rest.ResponseWriter, r *rest.Request) {
+		got = logger.GetRequestId(r.Context())
+		w.WriteJson(map[string]string{})
+	}))
+	handler := api.MakeHandler()
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(logger.RequestIdHeader, "client-id.1")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if got != "client-id.1" || w.Header().Get(logger.RequestIdHeader) != "client-id.1" {
+		t.Fatalf("expected client request id to be kept, got [%s] and [%s]", got, w.Header().Get(logger.RequestIdHeader))
+	}
+
+	for _, requestId := range []string{"", "bad id\nwith newline"} {
+		req = httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(logger.RequestIdHeader, requestId)
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if got == "" || got == requestId || w.Header().Get(logger.RequestIdHeader) != got {
+			t.Fatalf("expected a generated request id for [%q], got [%s]", requestId, got)
+		}
+	}
+}
@@ -17,11 +17,15 @@ import (
 	"github.com/ant0ine/go-json-rest/rest"
 
 	"kubesphere.io/devops/pkg/logger"
+	"kubesphere.io/devops/pkg/service/projects"
 )
 
 func Router(s *Server) (app rest.App) {
 
 	app, err := rest.MakeRouter(
+		rest.Get("/health/jenkins", s.Projects.JenkinsHealthHandler),
+		rest.Get("/credentials/schema", s.Projects.GetCredentialSchemaHandler),
+		rest.Get("/credentials/types", s.Projects.GetCredentialTypesHandler),
 		rest.Get("/projects", s.Projects.GetProjectsHandler),
 		rest.Get("/projects/:id", s.Projects.GetProjectHandler),
 		rest.Post("/projects", s.Projects.CreateProjectHandler),
@@ -32,11 +36,75 @@ func Router(s *Server) (app rest.App) {
 		rest.Post("/projects/:id/members", s.Projects.AddProjectMemberHandler),
 		rest.Patch("/projects/:id/members/:uid", s.Projects.UpdateMemberHandler),
 		rest.Delete("/projects/:id/members/:uid", s.Projects.DeleteMemberHandler),
-		rest.Post("/projects/:id/credentials", s.Projects.CreateCredentialHandler),
-		rest.Delete("/projects/:id/credentials/:cid", s.Projects.DeleteCredentialHandler),
-		rest.Put("/projects/:id/credentials/:cid", s.Projects.UpdateCredentialHandler),
-		rest.Get("/projects/:id/credentials/:cid", s.Projects.GetCredentialHandler),
-		rest.Get("/projects/:id/credentials", s.Projects.GetCredentialsHandler),
+		rest.Post("/projects/:id/credentials", s.Projects.InstrumentCredentialHandler(projects.CredentialActionCreate,
+			s.Projects.CreateCredentialHandler)),
+		rest.Post("/projects/:id/credentials/bulk-describe", s.Projects.AuditCredentialHandler(
+			projects.CredentialActionBulkDescribe, s.Projects.BulkDescribeCredentialsHandler)),
+		rest.Post("/projects/:id/credentials/batch-get", s.Projects.BatchGetCredentialsHandler),
+		rest.Post("/projects/:id/credentials/apply", s.Projects.AuditCredentialHandler(projects.CredentialActionApply,
+			s.Projects.ApplyCredentialsHandler)),
+		rest.Post("/projects/:id/credentials/batch", s.Projects.InstrumentCredentialHandler(projects.CredentialActionBatch,
+			s.Projects.AuditCredentialHandler(projects.CredentialActionBatch, s.Projects.CreateCredentialsBatchHandler))),
+		rest.Post("/projects/:id/credentials/break-glass", s.Projects.AuditCredentialHandler(
+			projects.CredentialActionBreakGlass, s.Projects.BreakGlassCreateCredentialHandler)),
+		rest.Post("/projects/:id/credentials/:cid/break-glass", s.Projects.AuditCredentialHandler(
+			projects.CredentialActionBreakGlassReveal, s.Projects.BreakGlassRevealCredentialHandler)),
+		rest.Post("/projects/:id/credentials/sync", s.Projects.AuditCredentialHandler(projects.CredentialActionSync,
+			s.Projects.SyncCredentialsHandler)),
+		rest.Delete("/projects/:id/credentials/:cid", s.Projects.InstrumentCredentialHandler(projects.CredentialActionDelete,
+			s.Projects.DeleteCredentialHandler)),
+		rest.Put("/projects/:id/credentials/:cid", s.Projects.InstrumentCredentialHandler(projects.CredentialActionUpdate,
+			s.Projects.UpdateCredentialHandler)),
+		rest.Post("/projects/:id/credentials/:cid/rotate", s.Projects.InstrumentCredentialHandler(
+			projects.CredentialActionRotate, s.Projects.AuditCredentialHandler(projects.CredentialActionRotate,
+				s.Projects.RotateCredentialHandler))),
+		rest.Post("/projects/:id/credentials/:cid/copy", s.Projects.InstrumentCredentialHandler(projects.CredentialActionCopy,
+			s.Projects.AuditCredentialHandler(projects.CredentialActionCopy, s.Projects.CopyCredentialHandler))),
+		rest.Post("/projects/:id/credentials/:cid/duplicate", s.Projects.InstrumentCredentialHandler(
+			projects.CredentialActionCopy, s.Projects.AuditCredentialHandler(projects.CredentialActionCopy,
+				s.Projects.CopySshCredentialHandler))),
+		rest.Post("/projects/:id/credentials/:cid/move", s.Projects.InstrumentCredentialHandler(projects.CredentialActionMove,
+			s.Projects.AuditCredentialHandler(projects.CredentialActionMove, s.Projects.MoveCredentialHandler))),
+		rest.Post("/projects/:id/credentials/test", s.Projects.InstrumentCredentialHandler(projects.CredentialActionTest,
+			s.Projects.TestNewCredentialHandler)),
+		rest.Post("/projects/:id/credentials/:cid/test", s.Projects.InstrumentCredentialHandler(projects.CredentialActionTest,
+			s.Projects.TestCredentialHandler)),
+		rest.Get("/projects/:id/credentials/lint", s.Projects.LintCredentialsHandler),
+		rest.Get("/projects/:id/credentials/unscoped", s.Projects.GetUnscopedCredentialsHandler),
+		rest.Get("/projects/:id/credentials/dependency-graph", s.Projects.GetCredentialDependencyGraphHandler),
+		rest.Get("/projects/:id/credentials/transparency-log", s.Projects.GetCredentialTransparencyLogHandler),
+		rest.Get("/projects/:id/credentials/transparency-log/verify", s.Projects.VerifyCredentialTransparencyLogHandler),
+		rest.Get("/projects/:id/credentials/audit", s.Projects.GetCredentialAuditHandler),
+		rest.Get("/projects/:id/credentials/trash", s.Projects.GetCredentialTrashHandler),
+		rest.Post("/projects/:id/credentials/trash/:tid/restore", s.Projects.InstrumentCredentialHandler(
+			projects.CredentialActionRestore, s.Projects.AuditCredentialHandler(projects.CredentialActionRestore,
+				s.Projects.RestoreCredentialHandler))),
+		rest.Get("/projects/:id/credentials/domains", s.Projects.GetCredentialDomainsHandler),
+		rest.Post("/projects/:id/credentials/domains/:domain/rename", s.Projects.AuditCredentialHandler(
+			projects.CredentialActionRenameDomain, s.Projects.RenameCredentialDomainHandler)),
+		rest.Get("/projects/:id/credentials/rotation-calendar.ics", s.Projects.GetCredentialsCalendarHandler),
+		rest.Get("/projects/:id/credentials/expiring", s.Projects.GetExpiringCredentialsHandler),
+		rest.Get("/projects/:id/credentials/uuid/:uuid", s.Projects.AuditCredentialHandler(projects.CredentialActionGet,
+			s.Projects.GetCredentialByUuidHandler)),
+		rest.Put("/projects/:id/credentials/uuid/:uuid", s.Projects.UpdateCredentialByUuidHandler),
+		rest.Delete("/projects/:id/credentials/uuid/:uuid", s.Projects.DeleteCredentialByUuidHandler),
+		rest.Get("/projects/:id/credentials/:cid", s.Projects.InstrumentCredentialHandler(projects.CredentialActionGet,
+			s.Projects.AuditCredentialHandler(projects.CredentialActionGet, s.Projects.GetCredentialHandler))),
+		rest.Head("/projects/:id/credentials/:cid", s.Projects.CredentialExistsHandler),
+		rest.Get("/projects/:id/credentials/:cid/usage", s.Projects.GetCredentialUsageHandler),
+		rest.Get("/projects/:id/credentials/:cid/branch-usage", s.Projects.GetCredentialBranchUsageHandler),
+		rest.Get("/projects/:id/credentials/:cid/usage_threshold", s.Projects.GetCredentialUsageThresholdHandler),
+		rest.Put("/projects/:id/credentials/:cid/usage_threshold", s.Projects.UpdateCredentialUsageThresholdHandler),
+		rest.Delete("/projects/:id/credentials/:cid/usage_threshold", s.Projects.DeleteCredentialUsageThresholdHandler),
+		rest.Get("/projects/:id/credentials", s.Projects.InstrumentCredentialHandler(projects.CredentialActionList,
+			s.Projects.AuditCredentialHandler(projects.CredentialActionList, s.Projects.GetCredentialsHandler))),
+		rest.Delete("/projects/:id/credentials", s.Projects.AuditCredentialHandler(projects.CredentialActionDeleteAll,
+			s.Projects.DeleteAllCredentialsHandler)),
+		rest.Get("/projects/:id/credential_policy", s.Projects.GetCredentialPolicyHandler),
+		rest.Put("/projects/:id/credential_policy", s.Projects.UpdateCredentialPolicyHandler),
+		rest.Get("/projects/:id/credential_description_templates", s.Projects.GetCredentialDescriptionTemplatesHandler),
+		rest.Put("/projects/:id/credential_description_templates/:type", s.Projects.UpdateCredentialDescriptionTemplateHandler),
+		rest.Delete("/projects/:id/credential_description_templates/:type", s.Projects.DeleteCredentialDescriptionTemplateHandler),
 		rest.Get("/projects/:id/pipelines/:pid/config", s.Projects.GetPipelineHandler),
 		rest.Post("/projects/:id/pipelines", s.Projects.CreatePipelineHandler),
 		rest.Put("/projects/:id/pipelines/:pid", s.Projects.UpdatePipelineHandler),
@@ -23,6 +23,7 @@ import (
 	"kubesphere.io/devops/pkg/ds"
 	"kubesphere.io/devops/pkg/logger"
 	"kubesphere.io/devops/pkg/service/projects"
+	"kubesphere.io/devops/pkg/utils/metricsutils"
 )
 
 type Server struct {
@@ -36,7 +37,20 @@ func Serve(cfg *config.Config) {
 
 	s := Server{}
 	s.Ds = ds.NewDs(cfg)
-	s.Projects = &projects.ProjectService{Ds: s.Ds}
+	s.Projects = &projects.ProjectService{Ds: s.Ds, Config: cfg, Credentials: s.Ds.Jenkins}
+	rolePolicy, err := projects.NewCredentialRolePolicy(&cfg.Roles)
+	if err != nil {
+		logger.Critical("%+v", err)
+		panic(err)
+	}
+	s.Projects.RolePolicy = rolePolicy
+	if len(cfg.Webhook.ChangeUrls) > 0 {
+		s.Projects.ChangeDispatcher = projects.NewWebhookChangeDispatcher(&cfg.Webhook)
+	}
+	if cfg.ListCache.Ttl > 0 {
+		s.Projects.ListCache = projects.NewCredentialListCache(cfg.ListCache.Ttl)
+	}
+	s.Projects.EncryptCredentialRecords()
 
 	// func to connect jenkins solve https://issues.jenkins-ci.org/browse/JENKINS-2489
 	go func() {
@@ -46,9 +60,47 @@ func Serve(cfg *config.Config) {
 		}
 	}()
 
+	if cfg.UsageAlert.Interval > 0 {
+		go func() {
+			for {
+				time.Sleep(cfg.UsageAlert.Interval)
+				s.Projects.EvaluateCredentialUsageThresholds()
+			}
+		}()
+	}
+
+	if cfg.Expiry.CheckInterval > 0 {
+		go func() {
+			for {
+				time.Sleep(cfg.Expiry.CheckInterval)
+				s.Projects.WarnExpiringCredentials()
+			}
+		}()
+	}
+
+	if cfg.Trash.PurgeInterval > 0 {
+		go func() {
+			for {
+				time.Sleep(cfg.Trash.PurgeInterval)
+				s.Projects.PurgeExpiredCredentialTrash()
+			}
+		}()
+	}
+
+	if cfg.Idempotency.PurgeInterval > 0 {
+		go func() {
+			for {
+				time.Sleep(cfg.Idempotency.PurgeInterval)
+				s.Projects.PurgeExpiredIdempotencyKeys()
+			}
+		}()
+	}
+
 	api := rest.NewApi()
 	api.Use(rest.DefaultDevStack...)
+	api.Use(rest.MiddlewareSimple(RequestIdMiddleware))
 	api.SetApp(Router(&s))
 	http.Handle(APIVersion+"/", http.StripPrefix(APIVersion, api.MakeHandler()))
+	http.Handle("/metrics", metricsutils.DefaultRegistry)
 	logger.Critical("%+v", http.ListenAndServe(":8080", nil))
 }
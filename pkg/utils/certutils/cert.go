@@ -0,0 +1,137 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certutils
+
+import (
+	"crypto/dsa"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"time"
+
+	"golang.org/x/crypto/pkcs12"
+)
+
+var (
+	ErrNoCertificate = errors.New("no certificate found")
+	ErrNoPrivateKey  = errors.New("no private key found")
+	ErrNotPkcs12     = errors.New("a PKCS#12 keystore is expected, not PEM")
+)
+
+const (
+	KeyAlgorithmRsa     = "rsa"
+	KeyAlgorithmDsa     = "dsa"
+	KeyAlgorithmEcdsa   = "ecdsa"
+	KeyAlgorithmEd25519 = "ed25519"
+)
+
+// GetKeystoreNotAfter returns the earliest NotAfter of the certificates in a PKCS#12 keystore, which must
+// hold a private key to authenticate with. Only the algorithms of golang.org/x/crypto/pkcs12 are read,
+// keystores of OpenSSL 3 need its -legacy option.
+func GetKeystoreNotAfter(keystore []byte, password string) (*time.Time, error) {
+	blocks, err := readKeystore(keystore, password)
+	if err != nil {
+		return nil, err
+	}
+	pemBytes := make([]byte, 0)
+	for _, block := range blocks {
+		pemBytes = append(pemBytes, pem.EncodeToMemory(block)...)
+	}
+	return GetPemNotAfter(pemBytes)
+}
+
+// GetKeystoreKeySize returns the algorithm and the size in bits of the private key of a PKCS#12 keystore.
+func GetKeystoreKeySize(keystore []byte, password string) (string, int, error) {
+	blocks, err := readKeystore(keystore, password)
+	if err != nil {
+		return "", 0, err
+	}
+	for _, block := range blocks {
+		if block.Type != "PRIVATE KEY" {
+			continue
+		}
+		// pkcs12.ToPEM keeps the PKCS#1 form of RSA keys and the SEC 1 form of EC keys
+		if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+			algorithm, bits := KeySize(key)
+			return algorithm, bits, nil
+		}
+		key, err := x509.ParseECPrivateKey(block.Bytes)
+		if err != nil {
+			return "", 0, err
+		}
+		algorithm, bits := KeySize(key)
+		return algorithm, bits, nil
+	}
+	return "", 0, ErrNoPrivateKey
+}
+
+// KeySize returns the algorithm and the size in bits of a private key, ed25519 keys have a fixed size of
+// 256 bits. Unknown keys are returned with an empty algorithm.
+func KeySize(key interface{}) (string, int) {
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		return KeyAlgorithmRsa, key.N.BitLen()
+	case *dsa.PrivateKey:
+		return KeyAlgorithmDsa, key.P.BitLen()
+	case *ecdsa.PrivateKey:
+		return KeyAlgorithmEcdsa, key.Curve.Params().BitSize
+	case ed25519.PrivateKey, *ed25519.PrivateKey:
+		return KeyAlgorithmEd25519, 256
+	}
+	return "", 0
+}
+
+func readKeystore(keystore []byte, password string) ([]*pem.Block, error) {
+	if block, _ := pem.Decode(keystore); block != nil {
+		return nil, ErrNotPkcs12
+	}
+	blocks, err := pkcs12.ToPEM(keystore, password)
+	if err != nil {
+		return nil, err
+	}
+	for _, block := range blocks {
+		if block.Type == "PRIVATE KEY" {
+			return blocks, nil
+		}
+	}
+	return nil, ErrNoPrivateKey
+}
+
+func GetPemNotAfter(pemBytes []byte) (*time.Time, error) {
+	var notAfter *time.Time
+	for {
+		var block *pem.Block
+		block, pemBytes = pem.Decode(pemBytes)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		if notAfter == nil || certificate.NotAfter.Before(*notAfter) {
+			notAfter = &certificate.NotAfter
+		}
+	}
+	if notAfter == nil {
+		return nil, ErrNoCertificate
+	}
+	return notAfter, nil
+}
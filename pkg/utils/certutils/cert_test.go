@@ -0,0 +1,113 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certutils
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+// the certificate of the keystores, with an EC P-256 key, expires on 2036-10-13 19:04:29 UTC
+var testNotAfter = time.Date(2036, 10, 13, 19, 4, 29, 0, time.UTC)
+
+func readTestdata(t *testing.T, name string) []byte {
+	data, err := ioutil.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestGetKeystoreNotAfter(t *testing.T) {
+	notAfter, err := GetKeystoreNotAfter(readTestdata(t, "certificate.p12"), "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !notAfter.Equal(testNotAfter) {
+		t.Fatalf("expected the keystore to expire at %s, got %s", testNotAfter, notAfter)
+	}
+	_, err = GetKeystoreNotAfter(readTestdata(t, "certificate.p12"), "wrong")
+	if err == nil {
+		t.Fatal("a wrong password should not read the keystore")
+	}
+	_, err = GetKeystoreNotAfter(readTestdata(t, "certificate.pem"), "secret")
+	if err != ErrNotPkcs12 {
+		t.Fatalf("expected %v for a PEM certificate, got %v", ErrNotPkcs12, err)
+	}
+	// pkcs12 itself refuses keystores without a private key
+	_, err = GetKeystoreNotAfter(readTestdata(t, "certificate_no_key.p12"), "secret")
+	if err == nil {
+		t.Fatal("a keystore without private key should be rejected")
+	}
+}
+
+func TestGetKeystoreKeySize(t *testing.T) {
+	algorithm, bits, err := GetKeystoreKeySize(readTestdata(t, "certificate.p12"), "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if algorithm != KeyAlgorithmEcdsa || bits != 256 {
+		t.Fatalf("expected an ecdsa key of 256 bits, got %s %d", algorithm, bits)
+	}
+}
+
+func TestGetPemNotAfter(t *testing.T) {
+	notAfter, err := GetPemNotAfter(readTestdata(t, "certificate.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !notAfter.Equal(testNotAfter) {
+		t.Fatalf("expected the certificate to expire at %s, got %s", testNotAfter, notAfter)
+	}
+	_, err = GetPemNotAfter([]byte("no certificate"))
+	if err != ErrNoCertificate {
+		t.Fatalf("expected %v, got %v", ErrNoCertificate, err)
+	}
+}
+
+func TestKeySize(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		key       interface{}
+		algorithm string
+		bits      int
+	}{
+		{key: rsaKey, algorithm: KeyAlgorithmRsa, bits: 1024},
+		{key: ecKey, algorithm: KeyAlgorithmEcdsa, bits: 384},
+		{key: edKey, algorithm: KeyAlgorithmEd25519, bits: 256},
+		{key: "unknown"},
+	} {
+		algorithm, bits := KeySize(test.key)
+		if algorithm != test.algorithm || bits != test.bits {
+			t.Fatalf("expected a %s key of %d bits, got %s %d", test.algorithm, test.bits, algorithm, bits)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cryptoutils
+
+import (
+	"testing"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	key := DeriveKey("secret")
+	ciphertext, err := Encrypt(key, []byte("content"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := Encrypt(key, []byte("content"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ciphertext == again {
+		t.Fatalf("every encryption should use another nonce")
+	}
+	plaintext, err := Decrypt(key, ciphertext)
+	if err != nil || string(plaintext) != "content" {
+		t.Fatalf("should decrypt content, got [%s] %v", plaintext, err)
+	}
+	if _, err := Decrypt(DeriveKey("other"), ciphertext); err == nil {
+		t.Fatalf("should not decrypt with another key")
+	}
+	if _, err := Decrypt(key, "c2hvcnQ="); err == nil {
+		t.Fatalf("should not decrypt truncated ciphertext")
+	}
+}
+
+func TestEnvelope(t *testing.T) {
+	provider, err := NewKeyProvider(LocalKeyProviderName, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	envelope, err := NewEnvelope(provider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext, err := envelope.Encrypt([]byte("content"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// another envelope has another data key but unwraps it with the same provider
+	other, err := NewEnvelope(provider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext, err := other.Decrypt(ciphertext)
+	if err != nil || string(plaintext) != "content" {
+		t.Fatalf("should decrypt content, got [%s] %v", plaintext, err)
+	}
+	otherProvider, _ := NewKeyProvider(LocalKeyProviderName, "other")
+	stranger, _ := NewEnvelope(otherProvider)
+	if _, err := stranger.Decrypt(ciphertext); err == nil {
+		t.Fatalf("should not unwrap the data key with another key")
+	}
+	if _, err := NewKeyProvider("kms", "key"); err == nil {
+		t.Fatalf("should reject unknown providers")
+	}
+	if _, err := NewKeyProvider(LocalKeyProviderName, ""); err == nil {
+		t.Fatalf("should require a local key")
+	}
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cryptoutils
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// KeyProvider wraps the data keys of an Envelope with a key encryption key it holds, a local key
+// or the key of a KMS.
+type KeyProvider interface {
+	WrapKey(dataKey []byte) ([]byte, error)
+	UnwrapKey(wrappedKey []byte) ([]byte, error)
+}
+
+// KeyProviderFactories creates key providers by name from their configured key, KMS providers
+// register themselves here.
+var KeyProviderFactories = map[string]func(key string) (KeyProvider, error){
+	LocalKeyProviderName: func(key string) (KeyProvider, error) {
+		if key == "" {
+			return nil, errors.New("local key provider requires a key")
+		}
+		return &LocalKeyProvider{key: DeriveKey(key)}, nil
+	},
+}
+
+const LocalKeyProviderName = "local"
+
+// LocalKeyProvider wraps data keys with a key derived from a configured secret, meant for development.
+type LocalKeyProvider struct {
+	key []byte
+}
+
+func (p *LocalKeyProvider) WrapKey(dataKey []byte) ([]byte, error) {
+	return seal(p.key, dataKey)
+}
+
+func (p *LocalKeyProvider) UnwrapKey(wrappedKey []byte) ([]byte, error) {
+	return open(p.key, wrappedKey)
+}
+
+func NewKeyProvider(name, key string) (KeyProvider, error) {
+	factory, ok := KeyProviderFactories[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown key provider [%s]", name)
+	}
+	return factory(key)
+}
+
+// Envelope encrypts values with a data key generated when it is created, the data key wrapped by the
+// key provider is stored with every value. Unwrapped data keys are cached, so the provider is called
+// once per data key.
+type Envelope struct {
+	provider   KeyProvider
+	dataKey    []byte
+	wrappedKey string
+	sync.Mutex
+	dataKeys map[string][]byte
+}
+
+func NewEnvelope(provider KeyProvider) (*Envelope, error) {
+	dataKey := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, err
+	}
+	wrappedKey, err := provider.WrapKey(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	encodedKey := base64.StdEncoding.EncodeToString(wrappedKey)
+	return &Envelope{
+		provider:   provider,
+		dataKey:    dataKey,
+		wrappedKey: encodedKey,
+		dataKeys:   map[string][]byte{encodedKey: dataKey},
+	}, nil
+}
+
+// Encrypt returns the wrapped data key and the sealed plaintext separated by a dot.
+func (e *Envelope) Encrypt(plaintext []byte) (string, error) {
+	ciphertext, err := Encrypt(e.dataKey, plaintext)
+	if err != nil {
+		return "", err
+	}
+	return e.wrappedKey + "." + ciphertext, nil
+}
+
+func (e *Envelope) Decrypt(value string) ([]byte, error) {
+	parts := strings.SplitN(value, ".", 2)
+	if len(parts) != 2 {
+		return nil, errors.New("value is not encrypted by an envelope")
+	}
+	dataKey, err := e.unwrap(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	return Decrypt(dataKey, parts[1])
+}
+
+func (e *Envelope) unwrap(encodedKey string) ([]byte, error) {
+	e.Lock()
+	defer e.Unlock()
+	if dataKey, ok := e.dataKeys[encodedKey]; ok {
+		return dataKey, nil
+	}
+	wrappedKey, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := e.provider.UnwrapKey(wrappedKey)
+	if err != nil {
+		return nil, err
+	}
+	e.dataKeys[encodedKey] = dataKey
+	return dataKey, nil
+}
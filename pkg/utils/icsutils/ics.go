@@ -0,0 +1,96 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package icsutils
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	dateFormat     = "20060102"
+	dateTimeFormat = "20060102T150405Z"
+	maxLineOctets  = 75
+)
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// Event is an all day event with an optional display alarm before it.
+type Event struct {
+	Uid         string
+	Summary     string
+	Description string
+	Date        time.Time
+	AlarmBefore time.Duration
+}
+
+type Calendar struct {
+	ProdId string
+	Name   string
+	Events []*Event
+}
+
+// Bytes renders the calendar as RFC 5545 iCalendar.
+func (c *Calendar) Bytes() []byte {
+	buf := &bytes.Buffer{}
+	stamp := time.Now().UTC().Format(dateTimeFormat)
+	writeLine(buf, "BEGIN:VCALENDAR")
+	writeLine(buf, "VERSION:2.0")
+	writeLine(buf, "PRODID:"+c.ProdId)
+	writeLine(buf, "CALSCALE:GREGORIAN")
+	if c.Name != "" {
+		writeLine(buf, "X-WR-CALNAME:"+textEscaper.Replace(c.Name))
+	}
+	for _, event := range c.Events {
+		writeLine(buf, "BEGIN:VEVENT")
+		writeLine(buf, "UID:"+event.Uid)
+		writeLine(buf, "DTSTAMP:"+stamp)
+		writeLine(buf, "DTSTART;VALUE=DATE:"+event.Date.UTC().Format(dateFormat))
+		writeLine(buf, "DTEND;VALUE=DATE:"+event.Date.UTC().AddDate(0, 0, 1).Format(dateFormat))
+		writeLine(buf, "SUMMARY:"+textEscaper.Replace(event.Summary))
+		if event.Description != "" {
+			writeLine(buf, "DESCRIPTION:"+textEscaper.Replace(event.Description))
+		}
+		if event.AlarmBefore > 0 {
+			writeLine(buf, "BEGIN:VALARM")
+			writeLine(buf, "ACTION:DISPLAY")
+			writeLine(buf, "DESCRIPTION:"+textEscaper.Replace(event.Summary))
+			writeLine(buf, fmt.Sprintf("TRIGGER:-PT%dM", int(event.AlarmBefore.Minutes())))
+			writeLine(buf, "END:VALARM")
+		}
+		writeLine(buf, "END:VEVENT")
+	}
+	writeLine(buf, "END:VCALENDAR")
+	return buf.Bytes()
+}
+
+// writeLine folds the content line at 75 octets without splitting utf-8 characters.
+func writeLine(buf *bytes.Buffer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		buf.WriteString(line[:cut])
+		buf.WriteString("\r\n ")
+		line = line[cut:]
+		// continuation lines start with a space, which counts
+		limit = maxLineOctets - 1
+	}
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
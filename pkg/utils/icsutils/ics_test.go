@@ -0,0 +1,65 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package icsutils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCalendarBytes(t *testing.T) {
+	calendar := &Calendar{
+		ProdId: "-//KubeSphere//DevOps//EN",
+		Name:   "Rotations, project-1",
+		Events: []*Event{{
+			Uid:         "credential-1@devops",
+			Summary:     "Rotate credential-1; it expires",
+			Description: "line one\nline two",
+			Date:        time.Date(2026, 10, 16, 23, 0, 0, 0, time.UTC),
+			AlarmBefore: 24 * time.Hour,
+		}},
+	}
+	ics := string(calendar.Bytes())
+	for _, line := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"X-WR-CALNAME:Rotations\\, project-1\r\n",
+		"UID:credential-1@devops\r\n",
+		"DTSTART;VALUE=DATE:20261016\r\n",
+		"DTEND;VALUE=DATE:20261017\r\n",
+		"SUMMARY:Rotate credential-1\\; it expires\r\n",
+		"DESCRIPTION:line one\\nline two\r\n",
+		"TRIGGER:-PT1440M\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(ics, line) {
+			t.Fatalf("calendar should contain [%q], got\n%s", line, ics)
+		}
+	}
+}
+
+func TestWriteLineFolds(t *testing.T) {
+	calendar := &Calendar{ProdId: "test", Name: strings.Repeat("é", 100)}
+	for _, line := range strings.Split(string(calendar.Bytes()), "\r\n") {
+		if len(line) > maxLineOctets {
+			t.Fatalf("line should be folded at %d octets, got %d", maxLineOctets, len(line))
+		}
+		if !strings.HasPrefix(line, " ") && !strings.Contains(line, ":") && line != "" {
+			t.Fatalf("line should be a continuation or a property, got [%s]", line)
+		}
+		if strings.HasPrefix(line, " ") && !strings.HasPrefix(line[1:], "é") {
+			t.Fatalf("folding should not split characters, got [%s]", line)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeconfigutils
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+type Kubeconfig struct {
+	ApiVersion     string         `yaml:"apiVersion,omitempty"`
+	Kind           string         `yaml:"kind,omitempty"`
+	CurrentContext string         `yaml:"current-context,omitempty"`
+	Clusters       []NamedCluster `yaml:"clusters"`
+	Contexts       []NamedContext `yaml:"contexts"`
+	Users          []NamedUser    `yaml:"users"`
+}
+
+type NamedCluster struct {
+	Name    string  `yaml:"name"`
+	Cluster Cluster `yaml:"cluster"`
+}
+
+type Cluster struct {
+	Server                   string `yaml:"server"`
+	CertificateAuthorityData string `yaml:"certificate-authority-data,omitempty"`
+	InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify,omitempty"`
+}
+
+type NamedContext struct {
+	Name    string  `yaml:"name"`
+	Context Context `yaml:"context"`
+}
+
+type Context struct {
+	Cluster   string `yaml:"cluster"`
+	User      string `yaml:"user"`
+	Namespace string `yaml:"namespace,omitempty"`
+}
+
+type NamedUser struct {
+	Name string `yaml:"name"`
+	User User   `yaml:"user"`
+}
+
+type User struct {
+	ClientCertificateData string `yaml:"client-certificate-data,omitempty"`
+	ClientKeyData         string `yaml:"client-key-data,omitempty"`
+	Token                 string `yaml:"token,omitempty"`
+	Username              string `yaml:"username,omitempty"`
+	Password              string `yaml:"password,omitempty"`
+}
+
+func Parse(content string) (*Kubeconfig, error) {
+	kubeconfig := &Kubeconfig{}
+	err := yaml.Unmarshal([]byte(content), kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	return kubeconfig, nil
+}
+
+// Validate loads content like kubectl does and checks it has a cluster and a context, and that the
+// contexts refer to clusters and users of the kubeconfig. The error names the field that failed.
+func Validate(content string) error {
+	if strings.TrimSpace(content) == "" {
+		return fmt.Errorf("kubeconfig is empty")
+	}
+	kubeconfig, err := Parse(content)
+	if err != nil {
+		return fmt.Errorf("kubeconfig is not valid yaml: %v", err)
+	}
+	if kubeconfig.Kind != "" && kubeconfig.Kind != "Config" {
+		return fmt.Errorf("kind: expected Config, got [%s]", kubeconfig.Kind)
+	}
+	if kubeconfig.ApiVersion != "" && kubeconfig.ApiVersion != "v1" {
+		return fmt.Errorf("apiVersion: expected v1, got [%s]", kubeconfig.ApiVersion)
+	}
+	if len(kubeconfig.Clusters) == 0 {
+		return fmt.Errorf("clusters: at least one cluster is required")
+	}
+	if len(kubeconfig.Contexts) == 0 {
+		return fmt.Errorf("contexts: at least one context is required")
+	}
+	clusters := make(map[string]bool, len(kubeconfig.Clusters))
+	for i, cluster := range kubeconfig.Clusters {
+		if cluster.Name == "" {
+			return fmt.Errorf("clusters[%d].name: is empty", i)
+		}
+		if cluster.Cluster.Server == "" {
+			return fmt.Errorf("clusters[%d].cluster.server: is empty", i)
+		}
+		clusters[cluster.Name] = true
+	}
+	users := make(map[string]bool, len(kubeconfig.Users))
+	for i, user := range kubeconfig.Users {
+		if user.Name == "" {
+			return fmt.Errorf("users[%d].name: is empty", i)
+		}
+		users[user.Name] = true
+	}
+	contexts := make(map[string]bool, len(kubeconfig.Contexts))
+	for i, context := range kubeconfig.Contexts {
+		if context.Name == "" {
+			return fmt.Errorf("contexts[%d].name: is empty", i)
+		}
+		if !clusters[context.Context.Cluster] {
+			return fmt.Errorf("contexts[%d].context.cluster: cluster [%s] not found", i, context.Context.Cluster)
+		}
+		if context.Context.User != "" && !users[context.Context.User] {
+			return fmt.Errorf("contexts[%d].context.user: user [%s] not found", i, context.Context.User)
+		}
+		contexts[context.Name] = true
+	}
+	if kubeconfig.CurrentContext != "" && !contexts[kubeconfig.CurrentContext] {
+		return fmt.Errorf("current-context: context [%s] not found", kubeconfig.CurrentContext)
+	}
+	return nil
+}
+
+// Current returns the cluster and user of the current context,
+// the only context is used when current-context is not set.
+func (k *Kubeconfig) Current() (*Cluster, *User, error) {
+	contextName := k.CurrentContext
+	if contextName == "" && len(k.Contexts) == 1 {
+		contextName = k.Contexts[0].Name
+	}
+	var context *Context
+	for i := range k.Contexts {
+		if k.Contexts[i].Name == contextName {
+			context = &k.Contexts[i].Context
+		}
+	}
+	if context == nil {
+		return nil, nil, fmt.Errorf("context [%s] not found in kubeconfig", contextName)
+	}
+
+	var cluster *Cluster
+	for i := range k.Clusters {
+		if k.Clusters[i].Name == context.Cluster {
+			cluster = &k.Clusters[i].Cluster
+		}
+	}
+	if cluster == nil {
+		return nil, nil, fmt.Errorf("cluster [%s] not found in kubeconfig", context.Cluster)
+	}
+	user := &User{}
+	for i := range k.Users {
+		if k.Users[i].Name == context.User {
+			user = &k.Users[i].User
+		}
+	}
+	return cluster, user, nil
+}
+
+// ClusterUser returns the user the contexts of the kubeconfig use for the cluster, the user of the current
+// context first. Clusters no context refers to get an empty user.
+func (k *Kubeconfig) ClusterUser(clusterName string) *User {
+	userName := ""
+	found := false
+	for _, context := range k.Contexts {
+		if context.Context.Cluster != clusterName {
+			continue
+		}
+		if !found || context.Name == k.CurrentContext {
+			userName = context.Context.User
+			found = true
+		}
+	}
+	for i := range k.Users {
+		if found && k.Users[i].Name == userName {
+			return &k.Users[i].User
+		}
+	}
+	return &User{}
+}
+
+// RedactedValue replaces the secret values of a redacted kubeconfig.
+const RedactedValue = "***"
+
+// redactedUserKeys are the keys of a user entry, at any depth, whose values are secret.
+var redactedUserKeys = map[string]bool{
+	"client-certificate-data": true,
+	"client-key-data":         true,
+	"token":                   true,
+	"password":                true,
+	"id-token":                true,
+	"refresh-token":           true,
+	"access-token":            true,
+	"client-secret":           true,
+	"value":                   true,
+}
+
+// Redact returns the kubeconfig with its clusters, contexts and users intact,
+// but with the credentials of every user replaced by RedactedValue.
+func Redact(content string) (string, error) {
+	kubeconfig := yaml.MapSlice{}
+	err := yaml.Unmarshal([]byte(content), &kubeconfig)
+	if err != nil {
+		return "", err
+	}
+	for i := range kubeconfig {
+		if kubeconfig[i].Key != "users" {
+			continue
+		}
+		users, ok := kubeconfig[i].Value.([]interface{})
+		if !ok {
+			return "", fmt.Errorf("users of kubeconfig is not a list")
+		}
+		for j := range users {
+			users[j] = redactUser(users[j])
+		}
+	}
+	redacted, err := yaml.Marshal(kubeconfig)
+	if err != nil {
+		return "", err
+	}
+	return string(redacted), nil
+}
+
+func redactUser(value interface{}) interface{} {
+	switch v := value.(type) {
+	case yaml.MapSlice:
+		for i := range v {
+			if key, ok := v[i].Key.(string); ok && redactedUserKeys[key] && v[i].Value != nil {
+				v[i].Value = RedactedValue
+				continue
+			}
+			v[i].Value = redactUser(v[i].Value)
+		}
+		return v
+	case []interface{}:
+		for i := range v {
+			v[i] = redactUser(v[i])
+		}
+		return v
+	}
+	return value
+}
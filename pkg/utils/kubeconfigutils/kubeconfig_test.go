@@ -0,0 +1,92 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeconfigutils
+
+import (
+	"strings"
+	"testing"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+current-context: dev
+clusters:
+- name: dev
+  cluster:
+    server: https://10.0.0.1:6443
+    certificate-authority-data: Y2E=
+contexts:
+- name: dev
+  context:
+    cluster: dev
+    user: deployer
+users:
+- name: deployer
+  user:
+    token: bearer-secret
+    client-key-data: a2V5LXNlY3JldA==
+- name: oidc
+  user:
+    auth-provider:
+      name: oidc
+      config:
+        client-secret: oidc-secret
+        refresh-token: refresh-secret
+    exec:
+      command: get-token
+      env:
+      - name: TOKEN
+        value: env-secret
+`
+
+func TestRedact(t *testing.T) {
+	redacted, err := Redact(testKubeconfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"bearer-secret", "a2V5LXNlY3JldA==", "oidc-secret", "refresh-secret",
+		"env-secret"} {
+		if strings.Contains(redacted, secret) {
+			t.Fatalf("secret [%s] should be redacted, got\n%s", secret, redacted)
+		}
+	}
+	kubeconfig, err := Parse(redacted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cluster, user, err := kubeconfig.Current()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cluster.Server != "https://10.0.0.1:6443" || cluster.CertificateAuthorityData != "Y2E=" {
+		t.Fatalf("cluster should be kept, got %+v", cluster)
+	}
+	if user.Token != RedactedValue || user.ClientKeyData != RedactedValue {
+		t.Fatalf("user credentials should be %s, got %+v", RedactedValue, user)
+	}
+	if !strings.Contains(redacted, "name: TOKEN") || !strings.Contains(redacted, "command: get-token") {
+		t.Fatalf("names and commands should be kept, got\n%s", redacted)
+	}
+}
+
+func TestRedactInvalid(t *testing.T) {
+	_, err := Redact("users: deployer")
+	if err == nil {
+		t.Fatal("users that are not a list should be invalid")
+	}
+	_, err = Redact("users: [")
+	if err == nil {
+		t.Fatal("invalid yaml should be invalid")
+	}
+}
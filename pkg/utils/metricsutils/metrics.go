@@ -0,0 +1,218 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metricsutils keeps counters and histograms and exposes them in the Prometheus text format.
+package metricsutils
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefBuckets are the default histogram buckets in seconds, like the ones of the Prometheus clients.
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+type collector interface {
+	writeTo(w io.Writer)
+}
+
+// Registry holds the metrics exposed by its handler.
+type Registry struct {
+	sync.Mutex
+	collectors []collector
+}
+
+var DefaultRegistry = &Registry{}
+
+func (r *Registry) register(c collector) {
+	r.Lock()
+	defer r.Unlock()
+	r.collectors = append(r.collectors, c)
+}
+
+// WriteText writes the metrics of the registry in the Prometheus text format.
+func (r *Registry) WriteText(w io.Writer) {
+	r.Lock()
+	collectors := append([]collector{}, r.collectors...)
+	r.Unlock()
+	for _, c := range collectors {
+		c.writeTo(w)
+	}
+}
+
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	buf := &bytes.Buffer{}
+	r.WriteText(buf)
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write(buf.Bytes())
+}
+
+type labeled struct {
+	name   string
+	help   string
+	labels []string
+}
+
+func (l *labeled) key(values []string) string {
+	if len(values) != len(l.labels) {
+		panic(fmt.Sprintf("metric %s has labels %v, got values %v", l.name, l.labels, values))
+	}
+	return strings.Join(values, "\xff")
+}
+
+// labelPairs formats the labels of a series with the extra label when it is set.
+func (l *labeled) labelPairs(key string, extraName, extraValue string) string {
+	pairs := make([]string, 0, len(l.labels)+1)
+	if len(l.labels) > 0 {
+		for i, value := range strings.Split(key, "\xff") {
+			pairs = append(pairs, fmt.Sprintf("%s=%s", l.labels[i], strconv.Quote(value)))
+		}
+	}
+	if extraName != "" {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", extraName, strconv.Quote(extraValue)))
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func (l *labeled) writeHeader(w io.Writer, metricType string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", l.name, l.help, l.name, metricType)
+}
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// CounterVec counts events by label values.
+type CounterVec struct {
+	labeled
+	sync.Mutex
+	values map[string]float64
+}
+
+// NewCounterVec creates a counter registered in DefaultRegistry.
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{labeled: labeled{name: name, help: help, labels: labels}, values: map[string]float64{}}
+	DefaultRegistry.register(c)
+	return c
+}
+
+func (c *CounterVec) Inc(labelValues ...string) {
+	key := c.key(labelValues)
+	c.Lock()
+	defer c.Unlock()
+	c.values[key]++
+}
+
+func (c *CounterVec) Value(labelValues ...string) float64 {
+	key := c.key(labelValues)
+	c.Lock()
+	defer c.Unlock()
+	return c.values[key]
+}
+
+func (c *CounterVec) writeTo(w io.Writer) {
+	c.Lock()
+	defer c.Unlock()
+	c.writeHeader(w, "counter")
+	for _, key := range sortedKeys(c.values) {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, c.labelPairs(key, "", ""), formatFloat(c.values[key]))
+	}
+}
+
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// HistogramVec samples observations by label values in cumulative buckets.
+type HistogramVec struct {
+	labeled
+	sync.Mutex
+	buckets []float64
+	series  map[string]*histogram
+}
+
+// NewHistogramVec creates a histogram registered in DefaultRegistry, DefBuckets are used when buckets is nil.
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if buckets == nil {
+		buckets = DefBuckets
+	}
+	h := &HistogramVec{
+		labeled: labeled{name: name, help: help, labels: labels},
+		buckets: buckets,
+		series:  map[string]*histogram{},
+	}
+	DefaultRegistry.register(h)
+	return h
+}
+
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	key := h.key(labelValues)
+	h.Lock()
+	defer h.Unlock()
+	series, ok := h.series[key]
+	if !ok {
+		series = &histogram{counts: make([]uint64, len(h.buckets))}
+		h.series[key] = series
+	}
+	for i, bound := range h.buckets {
+		if value <= bound {
+			series.counts[i]++
+		}
+	}
+	series.sum += value
+	series.count++
+}
+
+func (h *HistogramVec) writeTo(w io.Writer) {
+	h.Lock()
+	defer h.Unlock()
+	h.writeHeader(w, "histogram")
+	keys := make([]string, 0, len(h.series))
+	for key := range h.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		series := h.series[key]
+		for i, bound := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.labelPairs(key, "le", formatFloat(bound)), series.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.labelPairs(key, "le", "+Inf"), series.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, h.labelPairs(key, "", ""), formatFloat(series.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, h.labelPairs(key, "", ""), series.count)
+	}
+}
+
+func sortedKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
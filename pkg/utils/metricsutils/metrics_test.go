@@ -0,0 +1,72 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricsutils
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCounterVec(t *testing.T) {
+	registry := &Registry{}
+	counter := &CounterVec{labeled: labeled{name: "ops_total", help: "Operations.", labels: []string{"action", "result"}},
+		values: map[string]float64{}}
+	registry.register(counter)
+	counter.Inc("create", "success")
+	counter.Inc("create", "success")
+	counter.Inc("delete", "failure")
+	if counter.Value("create", "success") != 2 {
+		t.Fatalf("expected 2 create successes, got %v", counter.Value("create", "success"))
+	}
+
+	buf := &bytes.Buffer{}
+	registry.WriteText(buf)
+	expected := `# HELP ops_total Operations.
+# TYPE ops_total counter
+ops_total{action="create",result="success"} 2
+ops_total{action="delete",result="failure"} 1
+`
+	if buf.String() != expected {
+		t.Fatalf("expected\n%s\ngot\n%s", expected, buf.String())
+	}
+}
+
+func TestHistogramVec(t *testing.T) {
+	registry := &Registry{}
+	histogram := &HistogramVec{labeled: labeled{name: "call_seconds", help: "Calls.", labels: []string{"method"}},
+		buckets: []float64{0.1, 1}, series: map[string]*histogram{}}
+	registry.register(histogram)
+	histogram.Observe(0.05, "GET")
+	histogram.Observe(0.5, "GET")
+	histogram.Observe(2, "GET")
+
+	recorder := httptest.NewRecorder()
+	registry.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	if !strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Fatalf("unexpected content type %s", recorder.Header().Get("Content-Type"))
+	}
+	expected := `# HELP call_seconds Calls.
+# TYPE call_seconds histogram
+call_seconds_bucket{method="GET",le="0.1"} 1
+call_seconds_bucket{method="GET",le="1"} 2
+call_seconds_bucket{method="GET",le="+Inf"} 3
+call_seconds_sum{method="GET"} 2.55
+call_seconds_count{method="GET"} 3
+`
+	if recorder.Body.String() != expected {
+		t.Fatalf("expected\n%s\ngot\n%s", expected, recorder.Body.String())
+	}
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package strengthutils
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+const (
+	ReasonCommonPassword = "contains a common password"
+	ReasonPattern        = "contains repeated or sequential characters"
+	ReasonTooShort       = "is shorter than 8 characters"
+	ReasonSingleClass    = "uses a single character class"
+)
+
+// commonPasswords are matched case insensitively as substrings of the secret.
+var commonPasswords = []string{
+	"password", "passw0rd", "123456", "12345678", "qwerty", "abc123", "111111",
+	"letmein", "welcome", "admin", "monkey", "dragon", "iloveyou", "changeme",
+	"secret", "master", "login", "root", "toor", "default",
+}
+
+// Estimate returns the estimated entropy bits of secret, zxcvbn style.
+// Common passwords contained in the secret are counted as one guess of the dictionary
+// and repeated or sequential characters are counted as one bit each.
+// reason describes the most significant weakness, or is empty. Patterns are only reported
+// as the reason when they make up a quarter of the secret.
+func Estimate(secret string) (bits float64, reason string) {
+	if secret == "" {
+		return 0, ReasonTooShort
+	}
+	runes := []rune(secret)
+	charBits := math.Log2(float64(poolSize(runes)))
+
+	lower := strings.ToLower(secret)
+	matched := make([]bool, len(runes))
+	for _, word := range commonPasswords {
+		index := strings.Index(lower, word)
+		if index < 0 {
+			continue
+		}
+		reason = ReasonCommonPassword
+		start := len([]rune(lower[:index]))
+		for i := start; i < start+len([]rune(word)) && i < len(matched); i++ {
+			matched[i] = true
+		}
+		bits += math.Log2(float64(len(commonPasswords)))
+	}
+
+	patterns := 0
+	for i, r := range runes {
+		if matched[i] {
+			continue
+		}
+		if i > 0 && isPattern(runes[i-1], r) {
+			bits += 1
+			patterns++
+			continue
+		}
+		bits += charBits
+	}
+
+	if reason == "" && patterns*4 >= len(runes) {
+		reason = ReasonPattern
+	}
+
+	if reason == "" && len(runes) < 8 {
+		reason = ReasonTooShort
+	}
+	if reason == "" && classCount(runes) == 1 {
+		reason = ReasonSingleClass
+	}
+	return bits, reason
+}
+
+func isPattern(previous, current rune) bool {
+	delta := current - previous
+	return delta == 0 || delta == 1 || delta == -1
+}
+
+func poolSize(runes []rune) int {
+	var lower, upper, digit, symbol, other bool
+	for _, r := range runes {
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digit = true
+		case r < unicode.MaxASCII && unicode.IsPrint(r):
+			symbol = true
+		default:
+			other = true
+		}
+	}
+	size := 0
+	if lower {
+		size += 26
+	}
+	if upper {
+		size += 26
+	}
+	if digit {
+		size += 10
+	}
+	if symbol {
+		size += 33
+	}
+	if other {
+		size += 100
+	}
+	return size
+}
+
+func classCount(runes []rune) int {
+	classes := make(map[int]bool)
+	for _, r := range runes {
+		switch {
+		case unicode.IsLower(r):
+			classes[0] = true
+		case unicode.IsUpper(r):
+			classes[1] = true
+		case unicode.IsDigit(r):
+			classes[2] = true
+		default:
+			classes[3] = true
+		}
+	}
+	return len(classes)
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package strengthutils
+
+import (
+	"testing"
+)
+
+func TestEstimate(t *testing.T) {
+	for _, test := range []struct {
+		secret  string
+		reason  string
+		minBits float64
+		maxBits float64
+	}{
+		{secret: "", reason: ReasonTooShort},
+		{secret: "Tq7#", reason: ReasonTooShort, minBits: 20, maxBits: 30},
+		{secret: "MyPassword1", reason: ReasonCommonPassword, maxBits: 25},
+		{secret: "zzzzzzzzzzzzzzzz", reason: ReasonPattern, maxBits: 25},
+		{secret: "abcdefghijklmnop", reason: ReasonPattern, maxBits: 25},
+		{secret: "kqzvjwmxrhtb", reason: ReasonSingleClass, minBits: 50, maxBits: 60},
+		{secret: "T7#kq9!Zm2@wVx4$", reason: "", minBits: 100},
+	} {
+		bits, reason := Estimate(test.secret)
+		if reason != test.reason {
+			t.Fatalf("secret [%s] should have reason [%s], got [%s]", test.secret, test.reason, reason)
+		}
+		if bits < test.minBits || (test.maxBits > 0 && bits > test.maxBits) {
+			t.Fatalf("secret [%s] should have between %.0f and %.0f bits, got %.2f", test.secret,
+				test.minBits, test.maxBits, bits)
+		}
+	}
+}
+
+func TestEstimateCountsCommonPasswordOnce(t *testing.T) {
+	common, _ := Estimate("password")
+	random, _ := Estimate("qhwzkxvm")
+	if common >= random {
+		t.Fatalf("a common password should have less bits than random letters, got %.2f and %.2f", common, random)
+	}
+	longer, _ := Estimate("password" + "qhwzkxvm")
+	if longer-common < random-1 || longer-common > random+1 {
+		t.Fatalf("characters after a common password should add their own bits, got %.2f", longer-common)
+	}
+}
@@ -14,6 +14,9 @@ limitations under the License.
 package stringutils
 
 import (
+	"context"
+	"errors"
+	"net"
 	"net/http"
 	"strconv"
 	"unicode/utf8"
@@ -80,6 +83,12 @@ func Reverse(s string) string {
 }
 
 func GetJenkinsStatusCode(jenkinsErr error) int {
+	switch {
+	case errors.Is(jenkinsErr, gojenkins.ErrCredentialNotFound):
+		return http.StatusNotFound
+	case errors.Is(jenkinsErr, gojenkins.ErrCredentialExists):
+		return http.StatusConflict
+	}
 	if code, err := strconv.Atoi(jenkinsErr.Error()); err == nil {
 		message := http.StatusText(code)
 		if !govalidator.IsNull(message) {
@@ -89,5 +98,16 @@ func GetJenkinsStatusCode(jenkinsErr error) int {
 	if jErr, ok := jenkinsErr.(*gojenkins.ErrorResponse); ok {
 		return jErr.Response.StatusCode
 	}
+	// Jenkins could not be reached or did not answer in time
+	if errors.Is(jenkinsErr, context.DeadlineExceeded) {
+		return http.StatusGatewayTimeout
+	}
+	var netErr net.Error
+	if errors.As(jenkinsErr, &netErr) {
+		if netErr.Timeout() {
+			return http.StatusGatewayTimeout
+		}
+		return http.StatusServiceUnavailable
+	}
 	return http.StatusInternalServerError
 }
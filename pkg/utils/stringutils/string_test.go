@@ -0,0 +1,68 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stringutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"kubesphere.io/devops/pkg/gojenkins"
+)
+
+func TestGetJenkinsStatusCode(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+	_, dialErr := net.DialTimeout("tcp", address, time.Second)
+	if dialErr == nil {
+		t.Fatalf("expected dialing a closed port to fail")
+	}
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now())
+	defer cancel()
+	<-ctx.Done()
+
+	request, _ := http.NewRequest(http.MethodGet, "http://jenkins/job/project/credentials/", nil)
+	notFound := &gojenkins.ErrorResponse{Response: &http.Response{StatusCode: http.StatusNotFound, Request: request}}
+
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"jenkins 404", notFound, http.StatusNotFound},
+		{"status text", errors.New("403"), http.StatusForbidden},
+		{"credential not found", gojenkins.ErrCredentialNotFound, http.StatusNotFound},
+		{"dial error", dialErr, http.StatusServiceUnavailable},
+		{"wrapped dial error", &url.Error{Op: "Get", URL: "http://" + address, Err: dialErr},
+			http.StatusServiceUnavailable},
+		{"context deadline", ctx.Err(), http.StatusGatewayTimeout},
+		{"wrapped context deadline", fmt.Errorf("get credential: %w", ctx.Err()), http.StatusGatewayTimeout},
+		{"other error", errors.New("unexpected"), http.StatusInternalServerError},
+	}
+	for _, test := range tests {
+		if code := GetJenkinsStatusCode(test.err); code != test.code {
+			t.Errorf("%s: expected %d, got %d", test.name, test.code, code)
+		}
+	}
+}
@@ -0,0 +1,322 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifyutils
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"kubesphere.io/devops/pkg/utils/kubeconfigutils"
+)
+
+var (
+	ErrForbiddenAddress = errors.New("address is not allowed")
+	ErrNoKnownHosts     = errors.New("no known hosts to check the host key of ssh servers")
+)
+
+// blockedNetworks reach the host of the service or its network rather than a server meant for a credential:
+// unspecified, loopback, link-local with the metadata endpoints of cloud providers, private and shared
+// address space, for IPv4 and IPv6.
+var blockedNetworks = mustParseNetworks("0.0.0.0/8", "10.0.0.0/8", "100.64.0.0/10", "127.0.0.0/8",
+	"169.254.0.0/16", "172.16.0.0/12", "192.168.0.0/16", "::/128", "::1/128", "fc00::/7", "fe80::/10")
+
+// ParseNetworks parses CIDR notations, like the allowed networks of a Verifier.
+func ParseNetworks(cidrs []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+func mustParseNetworks(cidrs ...string) []*net.IPNet {
+	networks, err := ParseNetworks(cidrs)
+	if err != nil {
+		panic(err)
+	}
+	return networks
+}
+
+func containsIp(networks []*net.IPNet, ip net.IP) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Verifier connects with credentials to the servers given by users. Every address it dials is checked
+// after name resolution, so a server can not point it at the blocked networks, even by redirects.
+type Verifier struct {
+	Timeout time.Duration
+	// blocked networks that are dialed anyway, e.g. the private network of an internal git server
+	AllowedNetworks []*net.IPNet
+	// checks the host keys of ssh servers, ssh verification fails when it is nil
+	HostKeyCallback ssh.HostKeyCallback
+}
+
+// CheckIp returns ErrForbiddenAddress for ips of the blocked networks or multicast ones, unless they are
+// in the allowed networks.
+func (v *Verifier) CheckIp(ip net.IP) error {
+	if ip == nil {
+		return ErrForbiddenAddress
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	if containsIp(v.AllowedNetworks, ip) {
+		return nil
+	}
+	if ip.IsMulticast() || containsIp(blockedNetworks, ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, ip)
+	}
+	return nil
+}
+
+// CheckHost resolves host and checks all its addresses, for servers dialed by another service than the
+// Verifier. That service resolves host again, so this does not stop a name changing its addresses.
+func (v *Verifier) CheckHost(host string) error {
+	if ip := net.ParseIP(strings.Trim(host, "[]")); ip != nil {
+		return v.CheckIp(ip)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), v.Timeout)
+	defer cancel()
+	addresses, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return err
+	}
+	for _, address := range addresses {
+		err := v.CheckIp(address.IP)
+		if err != nil {
+			return fmt.Errorf("host %s: %v", host, err)
+		}
+	}
+	return nil
+}
+
+func (v *Verifier) dialer() *net.Dialer {
+	return &net.Dialer{
+		Timeout: v.Timeout,
+		// called with the resolved address right before connecting
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			return v.CheckIp(net.ParseIP(host))
+		},
+	}
+}
+
+// httpClient does not use the proxy of the environment, which would dial the servers instead of the Verifier.
+func (v *Verifier) httpClient(tlsConfig *tls.Config) *http.Client {
+	return &http.Client{
+		Timeout: v.Timeout,
+		Transport: &http.Transport{
+			DialContext:     v.dialer().DialContext,
+			TLSClientConfig: tlsConfig,
+		},
+	}
+}
+
+// GitHttp authenticates to the smart http endpoint of a git repository.
+func (v *Verifier) GitHttp(repoUrl, username, password string) error {
+	infoRefsUrl := strings.TrimSuffix(repoUrl, "/") + "/info/refs?service=git-upload-pack"
+	req, err := http.NewRequest(http.MethodGet, infoRefsUrl, nil)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(username, password)
+	resp, err := v.httpClient(nil).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("git server %s returned %d", req.URL.Host, resp.StatusCode)
+	}
+	return nil
+}
+
+// ParseSshAddress returns host:port and the user of ssh://user@host:port/path,
+// user@host:path or host[:port] addresses.
+func ParseSshAddress(address string) (hostPort, user string, err error) {
+	if strings.HasPrefix(address, "ssh://") {
+		u, err := url.Parse(address)
+		if err != nil {
+			return "", "", err
+		}
+		if u.User != nil {
+			user = u.User.Username()
+		}
+		hostPort = u.Host
+	} else {
+		hostPort = address
+		if index := strings.Index(hostPort, "@"); index >= 0 {
+			user = hostPort[:index]
+			hostPort = hostPort[index+1:]
+		}
+		// scp like address has the path after the colon
+		if index := strings.Index(hostPort, ":"); index >= 0 && strings.ContainsAny(hostPort[index+1:], "/~.") {
+			hostPort = hostPort[:index]
+		}
+	}
+	if hostPort == "" {
+		return "", "", fmt.Errorf("no host in ssh address [%s]", address)
+	}
+	if _, _, err := net.SplitHostPort(hostPort); err != nil {
+		hostPort = net.JoinHostPort(hostPort, "22")
+	}
+	return hostPort, user, nil
+}
+
+// Ssh authenticates to the ssh server with the private key, once HostKeyCallback accepted its host key.
+func (v *Verifier) Ssh(address, username, privateKey, passphrase string) error {
+	if v.HostKeyCallback == nil {
+		return ErrNoKnownHosts
+	}
+	var signer ssh.Signer
+	var err error
+	if passphrase != "" {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(privateKey), []byte(passphrase))
+	} else {
+		signer, err = ssh.ParsePrivateKey([]byte(privateKey))
+	}
+	if err != nil {
+		return err
+	}
+	hostPort, addressUser, err := ParseSshAddress(address)
+	if err != nil {
+		return err
+	}
+	if username == "" {
+		username = addressUser
+	}
+	conn, err := v.dialer().Dial("tcp", hostPort)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	// the timeout of the dialer ends with the connection, the handshake has its own deadline
+	err = conn.SetDeadline(time.Now().Add(v.Timeout))
+	if err != nil {
+		return err
+	}
+	client, channels, requests, err := ssh.NewClientConn(conn, hostPort, &ssh.ClientConfig{
+		User:            username,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: v.HostKeyCallback,
+	})
+	if err != nil {
+		return err
+	}
+	return ssh.NewClient(client, channels, requests).Close()
+}
+
+// Kubeconfig authenticates to the api server of the current context.
+func (v *Verifier) Kubeconfig(content string) error {
+	kubeconfig, err := kubeconfigutils.Parse(content)
+	if err != nil {
+		return err
+	}
+	cluster, user, err := kubeconfig.Current()
+	if err != nil {
+		return err
+	}
+	return v.getKubernetesApi(cluster, user, "/api")
+}
+
+// KubernetesVersion gets the version of the api server of cluster with the credentials of user,
+// a lightweight request any authenticated user may send.
+func (v *Verifier) KubernetesVersion(cluster *kubeconfigutils.Cluster, user *kubeconfigutils.User) error {
+	return v.getKubernetesApi(cluster, user, "/version")
+}
+
+// KubernetesToken authenticates to the api server at server with a bearer token.
+func (v *Verifier) KubernetesToken(server, token string) error {
+	return v.getKubernetesApi(&kubeconfigutils.Cluster{Server: server}, &kubeconfigutils.User{Token: token}, "/api")
+}
+
+func (v *Verifier) getKubernetesApi(cluster *kubeconfigutils.Cluster, user *kubeconfigutils.User,
+	path string) error {
+	tlsConfig := &tls.Config{InsecureSkipVerify: cluster.InsecureSkipTLSVerify}
+	if cluster.CertificateAuthorityData != "" {
+		caData, err := base64.StdEncoding.DecodeString(cluster.CertificateAuthorityData)
+		if err != nil {
+			return fmt.Errorf("invalid certificate-authority-data: %v", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		tlsConfig.RootCAs.AppendCertsFromPEM(caData)
+	}
+	if user.ClientCertificateData != "" && user.ClientKeyData != "" {
+		certData, err := base64.StdEncoding.DecodeString(user.ClientCertificateData)
+		if err != nil {
+			return fmt.Errorf("invalid client-certificate-data: %v", err)
+		}
+		keyData, err := base64.StdEncoding.DecodeString(user.ClientKeyData)
+		if err != nil {
+			return fmt.Errorf("invalid client-key-data: %v", err)
+		}
+		certificate, err := tls.X509KeyPair(certData, keyData)
+		if err != nil {
+			return err
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(cluster.Server, "/")+path, nil)
+	if err != nil {
+		return err
+	}
+	if user.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+user.Token)
+	} else if user.Username != "" {
+		req.SetBasicAuth(user.Username, user.Password)
+	}
+	resp, err := v.httpClient(tlsConfig).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("kubernetes api server %s returned %d", req.URL.Host, resp.StatusCode)
+	}
+	return nil
+}
+
+// Tcp checks the address accepts tcp connections, nothing is sent.
+func (v *Verifier) Tcp(address string) error {
+	conn, err := v.dialer().Dial("tcp", address)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifyutils
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseSshAddress(t *testing.T) {
+	for _, test := range []struct {
+		address  string
+		hostPort string
+		user     string
+	}{
+		{address: "git@github.com:kubesphere/devops.git", hostPort: "github.com:22", user: "git"},
+		{address: "ssh://deployer@10.0.0.2:2222/repo.git", hostPort: "10.0.0.2:2222", user: "deployer"},
+		{address: "example.com:2222", hostPort: "example.com:2222"},
+		{address: "example.com", hostPort: "example.com:22"},
+	} {
+		hostPort, user, err := ParseSshAddress(test.address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hostPort != test.hostPort || user != test.user {
+			t.Fatalf("address [%s] should have host [%s] and user [%s], got [%s] and [%s]", test.address,
+				test.hostPort, test.user, hostPort, user)
+		}
+	}
+	_, _, err := ParseSshAddress("ssh://")
+	if err == nil {
+		t.Fatal("an address without host should be invalid")
+	}
+}
+
+func TestCheckIp(t *testing.T) {
+	allowed, err := ParseNetworks([]string{"10.1.0.0/16"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier := &Verifier{AllowedNetworks: allowed}
+	for _, test := range []struct {
+		ip        string
+		forbidden bool
+	}{
+		{ip: "127.0.0.1", forbidden: true},
+		{ip: "169.254.169.254", forbidden: true},
+		{ip: "192.168.1.1", forbidden: true},
+		{ip: "10.2.0.1", forbidden: true},
+		{ip: "0.0.0.0", forbidden: true},
+		{ip: "224.0.0.1", forbidden: true},
+		{ip: "::1", forbidden: true},
+		{ip: "fe80::1", forbidden: true},
+		{ip: "fd00::1", forbidden: true},
+		{ip: "::ffff:127.0.0.1", forbidden: true},
+		{ip: "10.1.0.1"},
+		{ip: "8.8.8.8"},
+		{ip: "2001:4860:4860::8888"},
+	} {
+		err := verifier.CheckIp(net.ParseIP(test.ip))
+		if test.forbidden != errors.Is(err, ErrForbiddenAddress) {
+			t.Fatalf("ip [%s] should be forbidden: %t, got %v", test.ip, test.forbidden, err)
+		}
+	}
+}
+
+func TestGitHttp(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, _ := r.BasicAuth()
+		if r.URL.Path != "/repo.git/info/refs" || username != "admin" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+	}))
+	defer server.Close()
+
+	err := (&Verifier{Timeout: time.Second}).GitHttp(server.URL+"/repo.git", "admin", "secret")
+	if !errors.Is(err, ErrForbiddenAddress) {
+		t.Fatalf("a loopback server should be forbidden, got %v", err)
+	}
+	loopback, _ := ParseNetworks([]string{"127.0.0.0/8", "::1/128"})
+	verifier := &Verifier{Timeout: time.Second, AllowedNetworks: loopback}
+	err = verifier.GitHttp(server.URL+"/repo.git", "admin", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = verifier.GitHttp(server.URL+"/repo.git", "admin", "wrong")
+	if err == nil {
+		t.Fatal("a wrong password should not be verified")
+	}
+}
+
+func TestGitHttpRefusesRedirectToForbiddenAddress(t *testing.T) {
+	server := httptest.NewServer(http.RedirectHandler("http://169.254.169.254/latest/meta-data",
+		http.StatusFound))
+	defer server.Close()
+
+	loopback, _ := ParseNetworks([]string{"127.0.0.0/8", "::1/128"})
+	err := (&Verifier{Timeout: time.Second, AllowedNetworks: loopback}).GitHttp(server.URL, "admin", "secret")
+	if !errors.Is(err, ErrForbiddenAddress) {
+		t.Fatalf("a redirect to the metadata endpoint should be forbidden, got %v", err)
+	}
+}
+
+func TestSshRequiresKnownHosts(t *testing.T) {
+	err := (&Verifier{Timeout: time.Second}).Ssh("git@github.com:kubesphere/devops.git", "", "key", "")
+	if err != ErrNoKnownHosts {
+		t.Fatalf("ssh verification without known hosts should fail, got %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhookutils
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	// echo -n '{"id":"1"}' | openssl dgst -sha256 -hmac secret
+	expected := "sha256=6146142a2ce0159e84c0767881e4ec80bc397da62526e7d19f70795eb79460c0"
+	signature := Sign("secret", []byte(`{"id":"1"}`))
+	if signature != expected {
+		t.Fatalf("expected signature %s, got %s", expected, signature)
+	}
+}
+
+func TestPostJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.Header.Get("Content-Type") != "application/json" || r.Header.Get(SignatureHeader) != Sign("secret", body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"allowed":true}`))
+	}))
+	defer server.Close()
+
+	response := &struct {
+		Allowed bool `json:"allowed"`
+	}{}
+	status, err := PostJSON(server.URL, "secret", time.Second, map[string]string{"id": "1"}, response)
+	if err != nil || status != http.StatusOK || !response.Allowed {
+		t.Fatalf("expected an allowed response, got %d %+v %v", status, response, err)
+	}
+	status, err = PostJSON(server.URL, "wrong", time.Second, map[string]string{"id": "1"}, nil)
+	if err != nil || status != http.StatusUnauthorized {
+		t.Fatalf("expected a wrong signature to be unauthorized, got %d %v", status, err)
+	}
+	server.Close()
+	status, err = PostJSON(server.URL, "secret", time.Second, map[string]string{"id": "1"}, nil)
+	if err == nil || status != 0 {
+		t.Fatalf("expected no status when the request can not be sent, got %d %v", status, err)
+	}
+}